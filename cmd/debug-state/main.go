@@ -33,10 +33,14 @@ func main() {
 
 	// Get vehicles
 	vehicles, err := client.GetVehicles(ctx)
-	if err != nil || len(vehicles) == 0 {
+	if err != nil {
 		fmt.Printf("Error getting vehicles: %v\n", err)
 		os.Exit(1)
 	}
+	if len(vehicles) == 0 {
+		fmt.Println(rivian.NoVehiclesHint)
+		os.Exit(1)
+	}
 
 	vehicle := vehicles[0]
 	fmt.Printf("Vehicle: %s (%s)\n\n", vehicle.Name, vehicle.Model)
@@ -2,12 +2,18 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,10 +21,12 @@ import (
 	"github.com/pfrederiksen/rivian-ls/internal/auth"
 	"github.com/pfrederiksen/rivian-ls/internal/cli"
 	"github.com/pfrederiksen/rivian-ls/internal/config"
+	"github.com/pfrederiksen/rivian-ls/internal/model"
 	"github.com/pfrederiksen/rivian-ls/internal/rivian"
 	"github.com/pfrederiksen/rivian-ls/internal/store"
 	"github.com/pfrederiksen/rivian-ls/internal/tui"
 	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 )
 
 // Version information - set by GoReleaser via ldflags
@@ -30,11 +38,15 @@ var (
 
 // Exit codes
 const (
-	ExitSuccess       = 0
-	ExitAuthFailure   = 1
+	ExitSuccess         = 0
+	ExitAuthFailure     = 1
 	ExitVehicleNotFound = 2
-	ExitAPIError      = 3
-	ExitInvalidArgs   = 4
+	ExitAPIError        = 3
+	ExitInvalidArgs     = 4
+
+	// Condition exit codes for status --exit-below / --exit-on-issues
+	ExitBatteryBelow  = 5
+	ExitVehicleIssues = 6
 )
 
 func printVersion(w io.Writer) error {
@@ -72,51 +84,43 @@ func run(args []string) int {
 
 	// Parse command line flags (using config values as defaults)
 	fs := flag.NewFlagSet("rivian-ls", flag.ExitOnError)
-	email := fs.String("email", cfg.Email, "Email address for authentication")
-	password := fs.String("password", cfg.Password, "Password (will prompt if not provided)")
-	vehicleIndex := fs.Int("vehicle", cfg.Vehicle, "Vehicle index (0-based)")
-	dbPath := fs.String("db", cfg.DBPath, "Database path (default: ~/.local/share/rivian-ls/state.db)")
-	versionFlag := fs.Bool("version", false, "Print version and exit")
-	quiet := fs.Bool("quiet", cfg.Quiet, "Suppress informational output")
-	verbose := fs.Bool("verbose", cfg.Verbose, "Enable verbose logging")
-	noStore := fs.Bool("no-store", cfg.DisableStore, "Don't persist snapshots locally")
-
-	if err := fs.Parse(args[1:]); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+	opts, err := resolveFlags(cfg, fs, args[1:])
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
 		return ExitInvalidArgs
 	}
 
-	// Check for subcommands in remaining args after flag parsing
-	var subcommand string
-	var subcommandArgs []string
-	remainingArgs := fs.Args()
-	if len(remainingArgs) > 0 {
-		subcommand = remainingArgs[0]
-		subcommandArgs = remainingArgs[1:]
-	}
-
 	// Handle version flag
-	if *versionFlag {
+	if opts.showVersion {
 		if err := printVersion(os.Stdout); err != nil {
 			return ExitInvalidArgs
 		}
 		return ExitSuccess
 	}
 
-	// Set verbosity based on flags
-	if *quiet && *verbose {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: --quiet and --verbose cannot be used together\n")
-		return ExitInvalidArgs
+	// Dumb terminals can't draw the boxed layout
+	if os.Getenv("TERM") == "dumb" {
+		opts.plain = true
+	}
+
+	// Colors are decoration only; status symbols still render without them
+	if opts.noColor || opts.plain || os.Getenv("NO_COLOR") != "" {
+		tui.DisableColor()
 	}
 
-	// Apply verbosity settings to logger (we'll add proper logging later)
-	// For now, just store the flags
-	_ = quiet
-	_ = verbose
+	// config only reads and writes the config file
+	if opts.subcommand == "config" {
+		effective, err := effectiveConfig(cfg, fs)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Invalid flag: %v\n", err)
+			return ExitInvalidArgs
+		}
+		return runConfigCommand(effective, os.Stdout, opts.subcommandArgs)
+	}
 
 	// Ensure database directory exists (unless --no-store is set)
-	if !*noStore {
-		dbDir := filepath.Dir(*dbPath)
+	if !opts.noStore {
+		dbDir := filepath.Dir(opts.dbPath)
 		if err := os.MkdirAll(dbDir, 0750); err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "Error creating database directory: %v\n", err)
 			return ExitInvalidArgs
@@ -125,8 +129,28 @@ func run(args []string) int {
 
 	ctx := context.Background()
 
-	// Create HTTP client
-	client := rivian.NewHTTPClient()
+	// Prune only touches the local database, so it doesn't need to authenticate
+	if opts.subcommand == "prune" {
+		return runPruneCommand(ctx, opts.dbPath, opts.noStore, opts.resetDB, opts.subcommandArgs)
+	}
+
+	// Live update logging is off unless --log-file is given
+	logger := slog.New(slog.DiscardHandler)
+	if opts.logFile != "" {
+		var closeLog func() error
+		logger, closeLog, err = openLogFile(opts.logFile)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Invalid --log-file: %v\n", err)
+			return ExitInvalidArgs
+		}
+		defer func() { _ = closeLog() }()
+	}
+
+	client, err := newAPIClient(opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return ExitInvalidArgs
+	}
 
 	// Create credentials cache
 	credCache, err := auth.NewCredentialsCache()
@@ -134,9 +158,32 @@ func run(args []string) int {
 		_, _ = fmt.Fprintf(os.Stderr, "Warning: Could not create credentials cache: %v\n", err)
 		credCache = nil
 	}
+	if credCache != nil {
+		// Keep the cache current when sessions refresh mid-run
+		client.SetRefreshHandler(func(creds *rivian.Credentials) {
+			cacheRefreshedCredentials(credCache, creds)
+		})
+	}
+
+	// Prompts share one reader so piped answers buffered by one aren't lost
+	// to the next
+	stdin := bufio.NewReader(os.Stdin)
+
+	if opts.subcommand == "setup" {
+		return runSetupCommand(ctx, client, credCache, stdin, cfg)
+	}
+
+	// auth-check stops after authenticating, before any vehicle queries
+	if opts.subcommand == "auth-check" {
+		return runAuthCheckCommand(ctx, client, credCache, stdin, opts.email, opts.password)
+	}
+
+	if opts.subcommand == "" && *opts.email == "" && !config.Exists() {
+		_, _ = fmt.Fprintln(os.Stderr, "Tip: run 'rivian-ls setup' to save your account, vehicle and preferences.")
+	}
 
 	// Try to authenticate
-	if err := authenticate(ctx, client, credCache, email, password); err != nil {
+	if err := authenticate(ctx, client, credCache, stdin, opts.email, opts.password); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
 		return ExitAuthFailure
 	}
@@ -145,59 +192,533 @@ func run(args []string) int {
 	vehicles, err := client.GetVehicles(ctx)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Failed to get vehicles: %v\n", err)
+		if errors.Is(err, rivian.ErrVehiclesUnavailable) {
+			return ExitVehicleNotFound
+		}
 		return ExitAPIError
 	}
 
 	if len(vehicles) == 0 {
-		_, _ = fmt.Fprintf(os.Stderr, "No vehicles found\n")
+		_, _ = fmt.Fprintln(os.Stderr, rivian.NoVehiclesHint)
 		return ExitVehicleNotFound
 	}
 
-	if *vehicleIndex < 0 || *vehicleIndex >= len(vehicles) {
-		_, _ = fmt.Fprintf(os.Stderr, "Vehicle index %d out of range (have %d vehicles)\n", *vehicleIndex, len(vehicles))
+	index, chosen, err := selectVehicle(opts, stdin, vehicles)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
 		return ExitVehicleNotFound
 	}
-
-	vehicle := vehicles[*vehicleIndex]
+	// Other vehicles on the account keep the estimate
+	model.SetBatteryCapacity(vehicles[index].ID, opts.batteryCapacity)
 
 	// Open database (unless --no-store is set)
-	var db *store.Store
-	if !*noStore {
-		var err error
-		db, err = store.NewStore(*dbPath)
+	db, err := openHistory(ctx, opts, stdin)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return ExitInvalidArgs
+	}
+	if db != nil {
+		defer func() { _ = db.Close() }()
+	}
+
+	return dispatchSubcommand(ctx, &commandEnv{
+		cfg:      cfg,
+		opts:     opts,
+		client:   client,
+		db:       db,
+		logger:   logger,
+		vehicles: vehicles,
+		index:    index,
+		chosen:   chosen,
+	})
+}
+
+// globalOptions are the global flags, parsed and validated, and the
+// subcommand that follows them.
+type globalOptions struct {
+	fs *flag.FlagSet // Tells flags given on the command line from defaults
+
+	email, password         *string
+	vehicleIndex            int
+	vehicleVIN, vehicleName string
+	dbPath                  string
+	showVersion             bool
+	quiet, verbose, debug   bool
+	noStore, saveRaw        bool
+	resetDB                 bool
+	noColor, plain          bool
+	localTime               bool
+	redact                  cli.Redaction
+	stateCacheTTL           time.Duration
+	queryOverrides, pinCert string
+	autoRefresh             time.Duration
+	fallbackInterval        time.Duration
+	readyByFlag             string
+	readyBy                 *time.Duration
+	batteryCapacity         float64
+	retention               time.Duration
+	logFile                 string
+
+	subcommand     string
+	subcommandArgs []string
+}
+
+// resolveFlags defines the global flags on fs, with config values as
+// defaults, parses args and validates the result. The range unit is applied
+// to the model as it is validated. With --version, nothing is validated.
+func resolveFlags(cfg *config.Config, fs *flag.FlagSet, args []string) (*globalOptions, error) {
+	opts := &globalOptions{fs: fs}
+	opts.email = fs.String("email", cfg.Email, "Email address for authentication")
+	opts.password = fs.String("password", cfg.Password, "Password (will prompt if not provided)")
+	fs.IntVar(&opts.vehicleIndex, "vehicle", cfg.Vehicle, "Vehicle index (0-based)")
+	fs.StringVar(&opts.vehicleVIN, "vehicle-vin", "", "Select the vehicle with this VIN (case-insensitive); overrides --vehicle")
+	fs.StringVar(&opts.vehicleName, "vehicle-name", "", "Select the vehicle whose name contains this text (case-insensitive); overrides --vehicle")
+	fs.StringVar(&opts.dbPath, "db", cfg.DBPath, "Database path (default: ~/.local/share/rivian-ls/state.db)")
+	fs.BoolVar(&opts.showVersion, "version", false, "Print version and exit")
+	fs.BoolVar(&opts.quiet, "quiet", cfg.Quiet, "Suppress informational output")
+	fs.BoolVar(&opts.verbose, "verbose", cfg.Verbose, "Enable verbose logging")
+	fs.BoolVar(&opts.debug, "debug", false, "Log GraphQL requests and responses to stderr (secrets redacted)")
+	fs.BoolVar(&opts.noStore, "no-store", cfg.DisableStore, "Don't persist snapshots locally")
+	fs.BoolVar(&opts.saveRaw, "save-raw", false, "Also store the raw API response with each saved state, for debugging (see raw-state)")
+	fs.BoolVar(&opts.resetDB, "reset-db", false, "If the database is corrupt, back it up and start a fresh one")
+	fs.BoolVar(&opts.noColor, "no-color", false, "Disable colored output (also set by the NO_COLOR env var)")
+	fs.BoolVar(&opts.plain, "plain", false, "Render the TUI as linear plain text without boxes or color, for screen readers (also set by TERM=dumb)")
+	fs.BoolVar(&opts.localTime, "local-time", false, "Show timestamps in the local time zone")
+	fs.BoolVar(&opts.redact.Location, "redact-location", false, "Omit GPS coordinates from status, watch and export output")
+	fs.BoolVar(&opts.redact.VIN, "redact-vin", false, "Omit the VIN from status, watch and export output")
+	fs.DurationVar(&opts.stateCacheTTL, "state-cache-ttl", cfg.StateCacheTTL, "Reuse vehicle state API responses for this long, e.g. 10s (0 = off)")
+	rangeUnit := fs.String("range-unit", defaultRangeUnit(cfg.RangeUnit), "Unit the API reports the range estimate in: km or mi")
+	fs.StringVar(&opts.queryOverrides, "query-overrides", cfg.QueryOverrides, "GraphQL file whose GetVehicles/GetVehicleState queries replace the built-in ones")
+	fs.StringVar(&opts.pinCert, "pin-cert", "", "Only trust API servers presenting a certificate with one of these comma-separated SHA-256 fingerprints")
+	fs.DurationVar(&opts.autoRefresh, "auto-refresh", 0, "In the TUI, also re-fetch the state over HTTP this often, e.g. 5m (0 = off)")
+	fs.DurationVar(&opts.fallbackInterval, "fallback-interval", time.Minute, "In the TUI, poll the state over HTTP this often while live updates are unavailable")
+	fs.Float64Var(&opts.batteryCapacity, "battery-capacity", cfg.BatteryCapacity, "Usable battery pack capacity in kWh of the selected vehicle for energy figures, e.g. 135 (0 = estimate from range)")
+	fs.StringVar(&opts.readyByFlag, "ready-by", cfg.ReadyBy, "Time of day charging should be done by, e.g. 07:00; the charge view reports whether it will be")
+	retention := fs.String("retention", cfg.Retention, "Delete history older than this when watch or the TUI starts, e.g. 180d (0 = keep forever)")
+	fs.StringVar(&opts.logFile, "log-file", "", "Append JSON logs of live update connects, disconnects and errors in the TUI and watch to this file")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("Error parsing flags: %w", err)
+	}
+
+	// Check for subcommands in remaining args after flag parsing
+	if remaining := fs.Args(); len(remaining) > 0 {
+		opts.subcommand = remaining[0]
+		opts.subcommandArgs = remaining[1:]
+	}
+	if opts.showVersion {
+		return opts, nil
+	}
+
+	if opts.quiet && opts.verbose {
+		return nil, fmt.Errorf("Error: --quiet and --verbose cannot be used together")
+	}
+
+	var err error
+	if opts.retention, err = config.ParseRetentionSetting(*retention); err != nil {
+		return nil, fmt.Errorf("Invalid --retention: %w", err)
+	}
+	if opts.autoRefresh < 0 {
+		return nil, fmt.Errorf("Error: --auto-refresh must not be negative")
+	}
+	if opts.fallbackInterval <= 0 {
+		return nil, fmt.Errorf("Error: --fallback-interval must be positive")
+	}
+	for _, fp := range strings.Split(opts.pinCert, ",") {
+		if strings.TrimSpace(fp) == "" {
+			continue
+		}
+		if err := rivian.ValidateFingerprint(fp); err != nil {
+			return nil, fmt.Errorf("Invalid --pin-cert: %w", err)
+		}
+	}
+	if opts.readyBy, err = config.ParseReadyBy(opts.readyByFlag); err != nil {
+		return nil, fmt.Errorf("Invalid --ready-by: %w", err)
+	}
+	if err := model.SetAPIRangeUnit(model.DistanceUnit(*rangeUnit)); err != nil {
+		return nil, fmt.Errorf("Invalid --range-unit: %w", err)
+	}
+	if opts.batteryCapacity < 0 || opts.batteryCapacity > config.MaxBatteryCapacity {
+		return nil, fmt.Errorf("Invalid --battery-capacity: %g kWh: expected 0 (estimate) up to %d", opts.batteryCapacity, config.MaxBatteryCapacity)
+	}
+
+	return opts, nil
+}
+
+// newAPIClient creates the API client the global flags describe.
+func newAPIClient(opts *globalOptions) (*rivian.HTTPClient, error) {
+	var clientOpts []rivian.Option
+	if opts.subcommand != "" {
+		// Warnings on stderr would corrupt the TUI, so only surface them in CLI mode
+		clientOpts = append(clientOpts, rivian.WithWarningLog(os.Stderr))
+	}
+	if opts.debug {
+		clientOpts = append(clientOpts, rivian.WithDebugLogging(os.Stderr))
+	}
+	if opts.stateCacheTTL > 0 {
+		clientOpts = append(clientOpts, rivian.WithStateCacheTTL(opts.stateCacheTTL))
+	}
+	if opts.pinCert != "" {
+		clientOpts = append(clientOpts, rivian.WithPinnedCert(strings.Split(opts.pinCert, ",")...))
+	}
+	if opts.queryOverrides != "" {
+		overrides, err := rivian.LoadQueryOverrides(opts.queryOverrides)
 		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
-			return ExitInvalidArgs
+			return nil, fmt.Errorf("Invalid --query-overrides: %w", err)
 		}
-		defer func() { _ = db.Close() }()
+		if !opts.quiet {
+			names := make([]string, 0, len(overrides))
+			for name := range overrides {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			_, _ = fmt.Fprintf(os.Stderr, "Using query overrides from %s: %s\n", opts.queryOverrides, strings.Join(names, ", "))
+		}
+		clientOpts = append(clientOpts, rivian.WithQueryOverrides(overrides))
+	}
+	return rivian.NewHTTPClient(clientOpts...), nil
+}
+
+// selectVehicle picks the vehicle to use: by VIN or name, by index, or by
+// asking on a terminal. chosen reports whether the user picked one
+// explicitly rather than falling back to the default index.
+func selectVehicle(opts *globalOptions, stdin *bufio.Reader, vehicles []rivian.Vehicle) (index int, chosen bool, err error) {
+	index = opts.vehicleIndex
+
+	// A VIN or name keeps selecting the same vehicle when the list is reordered
+	named := strings.TrimSpace(opts.vehicleVIN) != "" || strings.TrimSpace(opts.vehicleName) != ""
+	if named {
+		if index, err = resolveVehicle(vehicles, opts.vehicleVIN, opts.vehicleName); err != nil {
+			return 0, false, fmt.Errorf("Error: %w", err)
+		}
+	}
+	chosen = named || flagSet(opts.fs, "vehicle")
+
+	// Headless commands on a multi-vehicle account with no saved choice
+	// would silently use vehicle 0, so ask instead. The TUI has its own menu.
+	interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	unchosen := opts.subcommand != "" && opts.subcommand != "compare-vehicles" && len(vehicles) > 1 && !chosen && !config.Exists()
+	invalid := index < 0 || index >= len(vehicles)
+	if invalid && !interactive {
+		return 0, false, fmt.Errorf("Vehicle index %d out of range (have %d vehicles)", index, len(vehicles))
+	}
+	if interactive && (invalid || unchosen) {
+		if invalid {
+			_, _ = fmt.Fprintf(os.Stderr, "Vehicle index %d out of range\n", index)
+		}
+		index = promptVehicle(stdin, os.Stderr, vehicles, 0)
+	}
+	return index, chosen, nil
+}
+
+// openHistory opens the state database, or returns nil with --no-store.
+// Long-running modes also trim old history once on startup.
+func openHistory(ctx context.Context, opts *globalOptions, stdin *bufio.Reader) (*store.Store, error) {
+	if opts.noStore {
+		return nil, nil
+	}
+
+	db, err := openStore(opts.dbPath, opts.resetDB, os.Stderr)
+	if err != nil {
+		return nil, err
 	}
+	db.SetSaveRaw(opts.saveRaw)
+	migrateLegacyDB(ctx, db, opts.dbPath, stdin)
+
+	if opts.retention > 0 && (opts.subcommand == "watch" || opts.subcommand == "") {
+		prune := cli.NewPruneCommand(db, os.Stderr)
+		if err := prune.Run(ctx, cli.PruneOptions{OlderThan: opts.retention}); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to apply retention: %v\n", err)
+		}
+	}
+	return db, nil
+}
 
-	// Route to subcommand or launch TUI
-	switch subcommand {
+// commandEnv is what a subcommand or the TUI runs with once the vehicle is
+// selected.
+type commandEnv struct {
+	cfg      *config.Config
+	opts     *globalOptions
+	client   *rivian.HTTPClient
+	db       *store.Store // nil with --no-store
+	logger   *slog.Logger
+	vehicles []rivian.Vehicle
+	index    int  // Selected vehicle
+	chosen   bool // Selected explicitly rather than by default
+}
+
+// dispatchSubcommand routes to the subcommand, or launches the TUI when
+// there is none.
+func dispatchSubcommand(ctx context.Context, env *commandEnv) int {
+	opts, db := env.opts, env.db
+	vehicleID := env.vehicles[env.index].ID
+	args := opts.subcommandArgs
+
+	switch opts.subcommand {
 	case "status":
-		return runStatusCommand(ctx, client, db, vehicle.ID, subcommandArgs)
+		return runStatusCommand(ctx, env.client, db, vehicleID, opts.localTime, opts.redact, opts.readyByFlag, defaultFormat(env.cfg.Format), env.cfg.PollInterval, args)
 	case "watch":
-		return runWatchCommand(ctx, client, db, vehicle.ID, subcommandArgs)
+		return runWatchCommand(ctx, env.client, db, vehicleID, opts.localTime, opts.redact, defaultFormat(env.cfg.Format), env.logger, args)
 	case "export":
-		return runExportCommand(ctx, db, vehicle.ID, subcommandArgs)
+		return runExportCommand(ctx, db, vehicleID, opts.localTime, opts.redact, args)
+	case "summary":
+		return runSummaryCommand(ctx, db, vehicleID, args)
+	case "diff":
+		return runDiffCommand(ctx, db, vehicleID, opts.localTime, args)
+	case "plan":
+		return runPlanCommand(ctx, env.client, vehicleID, args)
+	case "compare-vehicles":
+		return runCompareCommand(ctx, env.client, db, env.vehicles, opts.localTime, args)
+	case "raw-state":
+		return runRawStateCommand(ctx, db, vehicleID, args)
 	case "":
-		// No subcommand - launch TUI
-		model := tui.NewModel(client, db, vehicles, *vehicleIndex)
-		p := tea.NewProgram(model, tea.WithAltScreen())
-
-		if _, err := p.Run(); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
-			return ExitAPIError
-		}
-		return ExitSuccess
+		return runTUI(env)
 	default:
-		_, _ = fmt.Fprintf(os.Stderr, "Unknown command: %s\n", subcommand)
-		_, _ = fmt.Fprintf(os.Stderr, "Available commands: status, watch, export\n")
+		_, _ = fmt.Fprintf(os.Stderr, "Unknown command: %s\n", opts.subcommand)
+		_, _ = fmt.Fprintf(os.Stderr, "Available commands: setup, auth-check, status, watch, export, summary, diff, plan, compare-vehicles, raw-state, prune, config\n")
 		return ExitInvalidArgs
 	}
 }
 
-func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *auth.CredentialsCache, email, password *string) error {
+// runTUI launches the interactive dashboard.
+func runTUI(env *commandEnv) int {
+	opts := env.opts
+
+	// Without an explicit vehicle, reopen the last vehicle used in the TUI
+	startIndex := env.index
+	if !env.chosen {
+		startIndex = -1
+	}
+	// Units and theme come from the config file, e.g. as chosen in setup
+	if env.cfg.Theme == config.ThemeLight {
+		tui.UseLightTheme()
+	}
+	// The last view, chart settings and vehicle are remembered between runs
+	model := tui.NewModel(env.client, env.db, env.vehicles, startIndex, config.LoadPreferences())
+	if env.cfg.Units == config.UnitsMetric {
+		model.SetDefaultTempUnit(tui.TempCelsius)
+	}
+	model.SetLocalTime(opts.localTime)
+	model.SetAutoRefresh(opts.autoRefresh)
+	model.SetPollInterval(opts.fallbackInterval)
+	model.SetPlain(opts.plain)
+	model.SetLogger(env.logger)
+	if opts.readyBy != nil {
+		model.SetReadyBy(*opts.readyBy)
+	}
+	var programOpts []tea.ProgramOption
+	if !opts.plain {
+		// Plain output stays in the normal buffer where screen readers can review it
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(model, programOpts...)
+
+	if _, err := p.Run(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
+		return ExitAPIError
+	}
+	// Best-effort: failing to remember preferences shouldn't fail the exit
+	_ = model.Preferences().Save()
+	return ExitSuccess
+}
+
+// openStore opens the database at dbPath. A corrupt database is backed up
+// and replaced with a fresh one when reset is set (reported on w); otherwise
+// the error explains how to recover.
+func openStore(dbPath string, reset bool, w io.Writer) (*store.Store, error) {
+	db, err := store.NewStore(dbPath)
+	if err == nil || !errors.Is(err, store.ErrCorrupt) {
+		return db, err
+	}
+	if !reset {
+		return nil, fmt.Errorf("%s is damaged or not a rivian-ls database (%w); run again with --reset-db to back it up and start fresh", dbPath, err)
+	}
+
+	db, backup, err := store.Reset(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("reset database: %w", err)
+	}
+	_, _ = fmt.Fprintf(w, "Database was corrupt: backed it up to %s and started a fresh one\n", backup)
+	return db, nil
+}
+
+// openLogFile opens path for appending JSON log records, creating it
+// readable only by the user, and returns a logger writing to it.
+func openLogFile(path string) (*slog.Logger, func() error, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open log file: %w", err)
+	}
+	logger := slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return logger, f.Close, nil
+}
+
+// migrateLegacyDB offers to copy history from a database left at a legacy
+// location (see config.LegacyDBPaths) into a still-empty store. The legacy
+// file is only read, never moved or modified. A declined offer is recorded
+// in a marker file next to the database so it isn't repeated.
+func migrateLegacyDB(ctx context.Context, db *store.Store, dbPath string, stdin *bufio.Reader) {
+	declined := legacyDeclinedPath(dbPath)
+	if _, err := os.Stat(declined); err == nil {
+		return
+	}
+	stats, err := db.GetStats(ctx)
+	if err != nil || stats.TotalStates > 0 {
+		return
+	}
+
+	current, _ := os.Stat(dbPath)
+	for _, legacy := range config.LegacyDBPaths() {
+		info, err := os.Stat(legacy)
+		if err != nil || info.Size() == 0 || (current != nil && os.SameFile(info, current)) {
+			continue
+		}
+
+		// Prompts go to stderr so stdout stays clean for JSON/CSV output
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			_, _ = fmt.Fprintf(os.Stderr, "Found history in legacy database %s; run rivian-ls in a terminal to import it into %s\n", legacy, dbPath)
+			return
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Found history in legacy database %s. Copy it into %s? [Y/n]: ", legacy, dbPath)
+		answer, _ := stdin.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+			if err := os.WriteFile(declined, nil, 0600); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to record the answer, you will be asked again: %v\n", err)
+				return
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Not importing; delete %s to be asked again\n", declined)
+			return
+		}
+
+		n, err := db.ImportStates(ctx, legacy)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: Imported %d states from %s before failing: %v\n", n, legacy, err)
+			return
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Imported %d states from %s (the original file was left in place)\n", n, legacy)
+		return
+	}
+}
+
+// legacyDeclinedPath returns the marker file recording that importing a
+// legacy database into dbPath was declined.
+func legacyDeclinedPath(dbPath string) string {
+	return dbPath + ".legacy-declined"
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// errNoCredentials means authentication needs input that can't be prompted
+// for because stdin is not a terminal.
+var errNoCredentials = errors.New("credentials not available in non-interactive mode")
+
+// readSecret reads a password without echo from a terminal, or as the next
+// line of a stdin pipe.
+func readSecret(stdin *bufio.Reader, interactive bool, prompt string) (string, error) {
+	if interactive {
+		fmt.Print(prompt)
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		return string(b), err
+	}
+
+	line, err := stdin.ReadString('\n')
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%w: use --password, RIVIAN_PASSWORD or pipe it on stdin", errNoCredentials)
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+// enterOTP reads an OTP code (RIVIAN_OTP, then stdin) and submits it. On a
+// terminal a rejected code is asked for again; startOver is set when the
+// user leaves the code blank to log in with a different email.
+func enterOTP(ctx context.Context, client *rivian.HTTPClient, stdin *bufio.Reader, interactive bool) (startOver bool, err error) {
+	otpCode := os.Getenv("RIVIAN_OTP")
+	for {
+		if otpCode == "" {
+			if interactive {
+				fmt.Print("Enter OTP code (blank to start over with a different email): ")
+			}
+			line, _ := stdin.ReadString('\n')
+			otpCode = strings.TrimSpace(line)
+			if otpCode == "" {
+				if !interactive {
+					return false, fmt.Errorf("%w: OTP required (use RIVIAN_OTP or pipe it after the password)", errNoCredentials)
+				}
+				return true, nil
+			}
+		}
+
+		err := submitOTP(ctx, client, otpCode, os.Stderr)
+		if err == nil {
+			return false, nil
+		}
+		if !interactive || !errors.Is(err, rivian.ErrOTPRejected) {
+			return false, fmt.Errorf("OTP submission failed: %w", err)
+		}
+		fmt.Println("OTP code rejected. Check it and try again, or use the newest code if another was sent.")
+		otpCode = ""
+	}
+}
+
+// otpNetworkRetries is how many times submitOTP resubmits a code after
+// network failures.
+const otpNetworkRetries = 3
+
+// otpRetryDelay is the wait before the first resubmission; it grows with
+// each attempt.
+var otpRetryDelay = 2 * time.Second
+
+// submitOTP submits code, resubmitting the same code after network failures
+// since the challenge is still pending. Rejections and other errors are
+// returned at once. Retries are reported on progress.
+func submitOTP(ctx context.Context, client *rivian.HTTPClient, code string, progress io.Writer) error {
+	for attempt := 1; ; attempt++ {
+		err := client.SubmitOTP(ctx, code)
+		var netErr *rivian.OTPNetworkError
+		if err == nil || !errors.As(err, &netErr) || attempt > otpNetworkRetries {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(progress, "OTP submission didn't get through (%v), retrying the same code (%d/%d)\n", netErr.Err, attempt, otpNetworkRetries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * otpRetryDelay):
+		}
+	}
+}
+
+// cacheRefreshedCredentials replaces the cached tokens with refreshed ones,
+// keeping the cached email. Nothing is saved when no account is cached.
+func cacheRefreshedCredentials(credCache *auth.CredentialsCache, creds *rivian.Credentials) {
+	cached, err := credCache.Load()
+	if err != nil || cached == nil {
+		return
+	}
+	if err := credCache.Save(cached.Email, creds); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to cache refreshed credentials: %v\n", err)
+	}
+}
+
+func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *auth.CredentialsCache, stdin *bufio.Reader, email, password *string) error {
+	// Without a terminal, the password and OTP come from the environment or
+	// one line each on a stdin pipe
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+
 	// If no email provided, try to load from cache
 	if *email == "" {
 		if credCache != nil {
@@ -208,10 +729,12 @@ func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *aut
 			}
 		}
 
+		if !interactive {
+			return fmt.Errorf("%w: no email (use --email or RIVIAN_EMAIL)", errNoCredentials)
+		}
 		fmt.Print("Email: ")
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
-		emailInput := strings.TrimSpace(scanner.Text())
+		emailInput, _ := stdin.ReadString('\n')
+		emailInput = strings.TrimSpace(emailInput)
 		email = &emailInput
 	}
 
@@ -227,12 +750,9 @@ func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *aut
 				} else {
 					// Try to refresh
 					client.SetCredentials(cached.ToRivianCredentials())
+					// The refresh handler saves the new tokens
 					if err := client.RefreshToken(ctx); err == nil {
 						needsAuth = false
-						// Save refreshed credentials
-						if creds := client.GetCredentials(); creds != nil {
-							_ = credCache.Save(*email, creds)
-						}
 					}
 				}
 			}
@@ -241,33 +761,40 @@ func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *aut
 
 	// Perform full authentication if needed
 	if needsAuth {
-		// Prompt for password if not provided
-		if *password == "" {
-			fmt.Print("Password: ")
-			passBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
-			fmt.Println()
-			if err != nil {
-				return fmt.Errorf("failed to read password: %w", err)
+		for {
+			// Prompt for password if not provided
+			if *password == "" {
+				pwd, err := readSecret(stdin, interactive, "Password: ")
+				if err != nil {
+					return fmt.Errorf("failed to read password: %w", err)
+				}
+				password = &pwd
 			}
-			pwd := string(passBytes)
-			password = &pwd
-		}
 
-		err := client.Authenticate(ctx, *email, *password)
-		if err != nil {
+			err := client.Authenticate(ctx, *email, *password)
+			if err == nil {
+				break
+			}
 			// Check if it's OTP required
-			if _, ok := err.(*rivian.OTPRequiredError); ok {
-				fmt.Print("Enter OTP code: ")
-				scanner := bufio.NewScanner(os.Stdin)
-				scanner.Scan()
-				otpCode := strings.TrimSpace(scanner.Text())
-
-				if err := client.SubmitOTP(ctx, otpCode); err != nil {
-					return fmt.Errorf("OTP submission failed: %w", err)
-				}
-			} else {
+			if _, ok := err.(*rivian.OTPRequiredError); !ok {
 				return err
 			}
+
+			startOver, err := enterOTP(ctx, client, stdin, interactive)
+			if err != nil {
+				return err
+			}
+			if !startOver {
+				break
+			}
+
+			// Start over so the abandoned OTP challenge isn't reused
+			client.ResetAuthState()
+			fmt.Print("Email: ")
+			emailInput, _ := stdin.ReadString('\n')
+			emailInput = strings.TrimSpace(emailInput)
+			noPassword := ""
+			email, password = &emailInput, &noPassword
 		}
 
 		// Verify authentication
@@ -288,25 +815,103 @@ func authenticate(ctx context.Context, client *rivian.HTTPClient, credCache *aut
 	return nil
 }
 
-func runStatusCommand(ctx context.Context, client rivian.Client, db *store.Store, vehicleID string, args []string) int {
+func runStatusCommand(ctx context.Context, client rivian.Client, db *store.Store, vehicleID string, localTime bool, redact cli.Redaction, defaultReadyBy, defaultFormat string, defaultInterval time.Duration, args []string) int {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
-	format := fs.String("format", "text", "Output format (text|json|yaml|csv|table)")
+	format := fs.String("format", defaultFormat, "Output format (text|json|yaml|csv|table|auto)")
 	pretty := fs.Bool("pretty", false, "Pretty-print JSON/YAML output")
 	offline := fs.Bool("offline", false, "Use cached data (offline mode)")
+	timeFormat := fs.String("time-format", "", "Timestamp format for csv/table output (rfc3339|unix|local|<Go layout>)")
+	exitOnIssues := fs.Bool("exit-on-issues", false, fmt.Sprintf("Exit with code %d if the vehicle reports any warning or critical issues", ExitVehicleIssues))
+	exitBelow := fs.Float64("exit-below", 0, fmt.Sprintf("Exit with code %d if battery %% is below this value", ExitBatteryBelow))
+	watch := fs.Bool("watch", false, "Repeat the status every --interval until Ctrl+C (like 'watch --interval')")
+	interval := fs.Duration("interval", defaultInterval, "Polling interval for --watch")
+	readyByFlag := fs.String("ready-by", defaultReadyBy, "Also report whether charging reaches the limit by this time of day, e.g. 07:00")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: rivian-ls status [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+		_, _ = fmt.Fprintf(fs.Output(), "\nCondition exit codes (status is still printed):\n")
+		_, _ = fmt.Fprintf(fs.Output(), "  %d  battery below --exit-below (checked first)\n", ExitBatteryBelow)
+		_, _ = fmt.Fprintf(fs.Output(), "  %d  vehicle has issues (--exit-on-issues)\n", ExitVehicleIssues)
+	}
 
 	if err := fs.Parse(args); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error parsing status flags: %v\n", err)
 		return ExitInvalidArgs
 	}
 
+	if err := cli.TimeFormat(*timeFormat).Validate(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitInvalidArgs
+	}
+	if *exitBelow < 0 || *exitBelow > 100 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --exit-below must be between 0 and 100\n")
+		return ExitInvalidArgs
+	}
+	readyBy, err := config.ParseReadyBy(*readyByFlag)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: invalid --ready-by: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	// Resolve "auto" to table for terminals and json for pipes
+	outputFormat := cli.ResolveFormat(cli.OutputFormat(*format), term.IsTerminal(int(os.Stdout.Fd())))
+
+	if *watch {
+		if *offline || *exitOnIssues || flagSet(fs, "exit-below") {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: --watch cannot be combined with --offline, --exit-on-issues or --exit-below\n")
+			return ExitInvalidArgs
+		}
+		if *interval <= 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: --interval must be positive\n")
+			return ExitInvalidArgs
+		}
+
+		// Same polling loop as 'watch --interval'
+		cmd := cli.NewWatchCommand(client, db, vehicleID, "", "", os.Stdout)
+		opts := cli.WatchOptions{
+			Format:   outputFormat,
+			Pretty:   *pretty,
+			Interval: *interval,
+
+			TimeFormat: cli.TimeFormat(*timeFormat),
+			LocalTime:  localTime,
+			Redact:     redact,
+
+			ReadyBy: readyBy,
+		}
+		if err := cmd.Run(ctx, opts); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Status command failed: %v\n", err)
+			return ExitAPIError
+		}
+		return ExitSuccess
+	}
+
 	cmd := cli.NewStatusCommand(client, db, vehicleID, os.Stdout)
 	opts := cli.StatusOptions{
-		Format:  cli.OutputFormat(*format),
+		Format:  outputFormat,
 		Pretty:  *pretty,
 		Offline: *offline,
+
+		TimeFormat: cli.TimeFormat(*timeFormat),
+		LocalTime:  localTime,
+		Redact:     redact,
+
+		ExitOnIssues: *exitOnIssues,
+		ExitBelow:    *exitBelow,
+	}
+	if readyBy != nil {
+		opts.ReadyBy = model.NextClockTime(time.Now(), *readyBy)
 	}
 
 	if err := cmd.Run(ctx, opts); err != nil {
+		switch {
+		case errors.Is(err, cli.ErrBatteryBelow):
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return ExitBatteryBelow
+		case errors.Is(err, cli.ErrVehicleIssues):
+			_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+			return ExitVehicleIssues
+		}
 		_, _ = fmt.Fprintf(os.Stderr, "Status command failed: %v\n", err)
 		return ExitAPIError
 	}
@@ -314,20 +919,40 @@ func runStatusCommand(ctx context.Context, client rivian.Client, db *store.Store
 	return ExitSuccess
 }
 
-func runWatchCommand(ctx context.Context, client rivian.Client, db *store.Store, vehicleID string, args []string) int {
+func runWatchCommand(ctx context.Context, client rivian.Client, db *store.Store, vehicleID string, localTime bool, redact cli.Redaction, defaultFormat string, logger *slog.Logger, args []string) int {
 	fs := flag.NewFlagSet("watch", flag.ExitOnError)
-	format := fs.String("format", "text", "Output format (text|json|yaml|csv|table)")
+	format := fs.String("format", defaultFormat, "Output format (text|json|yaml|csv|table)")
 	pretty := fs.Bool("pretty", false, "Pretty-print JSON/YAML output")
 	interval := fs.Duration("interval", 0, "Polling interval (0 = use WebSocket)")
+	adaptive := fs.Bool("adaptive", false, "Poll faster while charging/driving and slower when idle")
+	fastInterval := fs.Duration("fast-interval", cli.DefaultFastInterval, "Adaptive polling interval while active")
+	slowInterval := fs.Duration("slow-interval", cli.DefaultSlowInterval, "Adaptive polling interval while idle")
+	timeFormat := fs.String("time-format", "", "Timestamp format for csv/table output (rfc3339|unix|local|<Go layout>)")
+	refreshInPlace := fs.Bool("refresh-in-place", false, "Redraw a single table in place on each update (table format, terminal only)")
 
 	if err := fs.Parse(args); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error parsing watch flags: %v\n", err)
 		return ExitInvalidArgs
 	}
 
+	if err := cli.TimeFormat(*timeFormat).Validate(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if *refreshInPlace && cli.OutputFormat(*format) != cli.FormatTable {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --refresh-in-place requires --format table\n")
+		return ExitInvalidArgs
+	}
+
+	if *adaptive && (*fastInterval <= 0 || *slowInterval < *fastInterval) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --slow-interval must be >= --fast-interval and both must be positive\n")
+		return ExitInvalidArgs
+	}
+
 	// Get CSRF token and app session ID for WebSocket mode
 	var csrfToken, appSessionID string
-	if *interval == 0 {
+	if *interval == 0 && !*adaptive {
 		// WebSocket mode requires fresh session tokens
 		httpClient, ok := client.(*rivian.HTTPClient)
 		if !ok {
@@ -335,21 +960,41 @@ func runWatchCommand(ctx context.Context, client rivian.Client, db *store.Store,
 			return ExitInvalidArgs
 		}
 
-		// Create fresh session for WebSocket
-		if err := httpClient.CreateSession(ctx); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Failed to create session: %v\n", err)
+		// Create fresh session for WebSocket, refreshing an expired token once
+		refreshed, err := httpClient.CreateSessionWithRefresh(ctx)
+		if err != nil {
+			if errors.Is(err, rivian.ErrUnauthorized) {
+				_, _ = fmt.Fprintf(os.Stderr, "Failed to create session: credentials rejected (log in again): %v\n", err)
+				return ExitAuthFailure
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to create session: network or API error: %v\n", err)
 			return ExitAPIError
 		}
+		if refreshed {
+			_, _ = fmt.Fprintln(os.Stderr, "Session expired; refreshed access token")
+		}
 
 		csrfToken = httpClient.GetCSRFToken()
 		appSessionID = httpClient.GetAppSessionID()
 	}
 
 	cmd := cli.NewWatchCommand(client, db, vehicleID, csrfToken, appSessionID, os.Stdout)
+	cmd.SetLogger(logger)
 	opts := cli.WatchOptions{
 		Format:   cli.OutputFormat(*format),
 		Pretty:   *pretty,
 		Interval: *interval,
+
+		TimeFormat: cli.TimeFormat(*timeFormat),
+		LocalTime:  localTime,
+		Redact:     redact,
+
+		// Cursor control would garble piped output, so append there instead
+		RefreshInPlace: *refreshInPlace && term.IsTerminal(int(os.Stdout.Fd())),
+
+		Adaptive:     *adaptive,
+		FastInterval: *fastInterval,
+		SlowInterval: *slowInterval,
 	}
 
 	if err := cmd.Run(ctx, opts); err != nil {
@@ -360,24 +1005,69 @@ func runWatchCommand(ctx context.Context, client rivian.Client, db *store.Store,
 	return ExitSuccess
 }
 
-func runExportCommand(ctx context.Context, db *store.Store, vehicleID string, args []string) int {
+// defaultExportMaxRows caps buffered range exports so years of history
+// don't exhaust memory.
+const defaultExportMaxRows = 100000
+
+func runExportCommand(ctx context.Context, db *store.Store, vehicleID string, localTime bool, redact cli.Redaction, args []string) int {
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
-	format := fs.String("format", "csv", "Output format (json|yaml|csv)")
+	format := fs.String("format", "csv", "Output format (json|ndjson|yaml|csv|table|sqlite|xlsx)")
 	pretty := fs.Bool("pretty", false, "Pretty-print JSON/YAML output")
-	since := fs.String("since", "", "Start time (RFC3339 or duration like '24h')")
+	since := fs.String("since", "", "Start time (RFC3339 or duration like '24h' or '7d')")
 	until := fs.String("until", "", "End time (RFC3339)")
 	limit := fs.Int("limit", 0, "Maximum number of states to export")
+	maxRows := fs.Int("max-rows", defaultExportMaxRows, "Refuse --since/--until exports above this many states in json/yaml/xlsx or with --split-by (0 = no limit; csv and ndjson stream)")
+	timeFormat := fs.String("time-format", "", "Timestamp format for csv/table output (rfc3339|unix|local|<Go layout>)")
+	output := fs.String("output", "", "Write to this file instead of stdout (required for sqlite, and for xlsx on a terminal)")
+	splitBy := fs.String("split-by", "", "Write one file per period (day|week|month); requires --output")
+	summary := fs.Bool("summary", false, "End table output with min/max/avg battery and range rows")
+	follow := fs.Bool("follow", false, "After existing states, keep printing newly saved ones until Ctrl+C (requires --format ndjson)")
+	sessions := fs.Bool("sessions", false, "Write one row per charging session (start/end, SoC, kWh added, avg/peak kW) instead of one per state (csv only)")
 
 	if err := fs.Parse(args); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error parsing export flags: %v\n", err)
 		return ExitInvalidArgs
 	}
 
+	if err := cli.TimeFormat(*timeFormat).Validate(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if err := cli.SplitPeriod(*splitBy).Validate(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitInvalidArgs
+	}
+	if *splitBy != "" && *output == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --split-by requires --output\n")
+		return ExitInvalidArgs
+	}
+	if *summary && cli.OutputFormat(*format) != cli.FormatTable {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --summary requires --format table\n")
+		return ExitInvalidArgs
+	}
+	if *follow && cli.OutputFormat(*format) != cli.FormatNDJSON {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --follow requires --format ndjson\n")
+		return ExitInvalidArgs
+	}
+	if *sessions && (cli.OutputFormat(*format) != cli.FormatCSV || *follow || *splitBy != "" || *limit > 0) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --sessions requires --format csv and cannot be combined with --follow, --split-by or --limit\n")
+		return ExitInvalidArgs
+	}
+	if *follow && (*output != "" || *until != "") {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --follow cannot be combined with --output or --until\n")
+		return ExitInvalidArgs
+	}
+	if cli.OutputFormat(*format) == cli.FormatXLSX && *output == "" && term.IsTerminal(int(os.Stdout.Fd())) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --format xlsx writes a binary workbook: use --output or redirect stdout to a file\n")
+		return ExitInvalidArgs
+	}
+
 	// Parse time arguments
 	var sinceTime, untilTime time.Time
 	if *since != "" {
 		// Try parsing as duration first
-		if d, err := time.ParseDuration(*since); err == nil {
+		if d, err := config.ParseRetention(*since); err == nil {
 			sinceTime = time.Now().Add(-d)
 		} else {
 			// Try parsing as RFC3339
@@ -406,6 +1096,20 @@ func runExportCommand(ctx context.Context, db *store.Store, vehicleID string, ar
 		Since:  sinceTime,
 		Until:  untilTime,
 		Limit:  *limit,
+
+		MaxRows: *maxRows,
+
+		TimeFormat: cli.TimeFormat(*timeFormat),
+		LocalTime:  localTime,
+		Redact:     redact,
+		Summary:    *summary,
+
+		OutputPath: *output,
+		SplitBy:    cli.SplitPeriod(*splitBy),
+
+		Follow: *follow,
+
+		Sessions: *sessions,
 	}
 
 	if err := cmd.Run(ctx, opts); err != nil {
@@ -420,3 +1124,625 @@ func main() {
 	exitCode := run(os.Args)
 	os.Exit(exitCode)
 }
+
+func runAuthCheckCommand(ctx context.Context, client *rivian.HTTPClient, credCache *auth.CredentialsCache, stdin *bufio.Reader, email, password *string) int {
+	if err := authenticate(ctx, client, credCache, stdin, email, password); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
+		return ExitAuthFailure
+	}
+
+	if err := printAuthCheck(os.Stdout, client.GetCredentials(), time.Now()); err != nil {
+		return ExitAPIError
+	}
+	return ExitSuccess
+}
+
+// printAuthCheck reports a successful authentication and when the access
+// token expires.
+func printAuthCheck(w io.Writer, creds *rivian.Credentials, now time.Time) error {
+	if _, err := fmt.Fprintln(w, "Authentication: OK"); err != nil {
+		return err
+	}
+	if creds == nil || creds.ExpiresAt.IsZero() {
+		_, err := fmt.Fprintln(w, "Token expires: unknown")
+		return err
+	}
+
+	remaining := creds.ExpiresAt.Sub(now).Round(time.Minute)
+	_, err := fmt.Fprintf(w, "Token expires: %s (in %s)\n", creds.ExpiresAt.Format(time.RFC3339), remaining)
+	return err
+}
+
+func runSummaryCommand(ctx context.Context, db *store.Store, vehicleID string, args []string) int {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	period := fs.String("period", "30d", "How far back to summarize (e.g. '30d', '168h')")
+	format := fs.String("format", "text", "Output format (text|markdown)")
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing summary flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if *format != "text" && *format != "markdown" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: unsupported summary format %q (want text or markdown)\n", *format)
+		return ExitInvalidArgs
+	}
+
+	d, err := config.ParseRetention(*period)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid --period: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	cmd := cli.NewSummaryCommand(db, vehicleID, os.Stdout)
+	opts := cli.SummaryOptions{
+		Period:   d,
+		Markdown: *format == "markdown",
+	}
+
+	if err := cmd.Run(ctx, opts); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Summary command failed: %v\n", err)
+		return ExitAPIError
+	}
+
+	return ExitSuccess
+}
+
+func runDiffCommand(ctx context.Context, db *store.Store, vehicleID string, localTime bool, args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	from := fs.String("from", "", "Compare the state stored nearest to this RFC3339 time (required)")
+	to := fs.String("to", "", "...with the state stored nearest to this RFC3339 time (default: now)")
+	format := fs.String("format", "text", "Output format (text|json)")
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing diff flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if *format != "text" && *format != "json" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: unsupported diff format %q (want text or json)\n", *format)
+		return ExitInvalidArgs
+	}
+	if db == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: diff cannot be used with --no-store\n")
+		return ExitInvalidArgs
+	}
+	if *from == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --from is required\n")
+		return ExitInvalidArgs
+	}
+
+	fromTime, err := time.Parse(time.RFC3339, *from)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid --from time: %v\n", err)
+		return ExitInvalidArgs
+	}
+	toTime := time.Now()
+	if *to != "" {
+		if toTime, err = time.Parse(time.RFC3339, *to); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Invalid --to time: %v\n", err)
+			return ExitInvalidArgs
+		}
+	}
+	if !fromTime.Before(toTime) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --from must be before --to\n")
+		return ExitInvalidArgs
+	}
+
+	cmd := cli.NewDiffCommand(db, vehicleID, os.Stdout)
+	opts := cli.DiffOptions{
+		From:      fromTime,
+		To:        toTime,
+		JSON:      *format == "json",
+		LocalTime: localTime,
+	}
+
+	if err := cmd.Run(ctx, opts); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Diff command failed: %v\n", err)
+		return ExitAPIError
+	}
+
+	return ExitSuccess
+}
+
+func runRawStateCommand(ctx context.Context, db *store.Store, vehicleID string, args []string) int {
+	fs := flag.NewFlagSet("raw-state", flag.ExitOnError)
+	at := fs.String("at", "", "Show the response saved at or before this RFC3339 time instead of the latest")
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing raw-state flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if db == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: raw-state cannot be used with --no-store\n")
+		return ExitInvalidArgs
+	}
+
+	var atTime time.Time
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Invalid --at time: %v\n", err)
+			return ExitInvalidArgs
+		}
+		atTime = t
+	}
+
+	raw, timestamp, err := db.GetRawState(ctx, vehicleID, atTime)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Raw-state command failed: %v\n", err)
+		return ExitAPIError
+	}
+	if raw == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "No raw response saved for this vehicle; run status or watch with --save-raw to keep them\n")
+		return ExitAPIError
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		out.Reset()
+		out.Write(raw)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Raw response saved with the state at %s\n", timestamp.Format(time.RFC3339))
+	_, _ = fmt.Fprintln(os.Stdout, out.String())
+
+	return ExitSuccess
+}
+
+func runCompareCommand(ctx context.Context, client rivian.Client, db *store.Store, vehicles []rivian.Vehicle, localTime bool, args []string) int {
+	fs := flag.NewFlagSet("compare-vehicles", flag.ExitOnError)
+	period := fs.String("period", "7d", "Window for miles driven, efficiency and charges (e.g. '7d', '72h')")
+	offline := fs.Bool("offline", false, "Use cached states instead of querying the API")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: rivian-ls compare-vehicles [flags] [<vehicle> <vehicle>]\n\n")
+		_, _ = fmt.Fprintf(fs.Output(), "Vehicles are 0-based indexes or vehicle IDs; without them the first two are compared.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing compare-vehicles flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	d, err := config.ParseRetention(*period)
+	if err != nil || d <= 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid --period: %q (want a positive duration like '7d')\n", *period)
+		return ExitInvalidArgs
+	}
+
+	selected, err := selectCompareVehicles(vehicles, fs.Args())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, errVehicleNotFound) {
+			return ExitVehicleNotFound
+		}
+		return ExitInvalidArgs
+	}
+
+	cmd := cli.NewCompareCommand(client, db, selected, os.Stdout)
+	if err := cmd.Run(ctx, cli.CompareOptions{Period: d, Offline: *offline, LocalTime: localTime}); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Compare command failed: %v\n", err)
+		return ExitAPIError
+	}
+
+	return ExitSuccess
+}
+
+// errVehicleNotFound is returned when a vehicle argument matches no vehicle.
+var errVehicleNotFound = errors.New("vehicle not found")
+
+// resolveVehicle returns the index of the vehicle whose VIN equals vin, or
+// else whose name equals or, failing that, contains name, all ignoring case.
+// vin wins when both are given. Matching no vehicle, or several by name, is
+// an error listing the candidates.
+func resolveVehicle(vehicles []rivian.Vehicle, vin, name string) (int, error) {
+	if vin = strings.TrimSpace(vin); vin != "" {
+		for i, v := range vehicles {
+			if strings.EqualFold(v.VIN, vin) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("%w: no vehicle has VIN %q (have %s)", errVehicleNotFound, vin, describeVehicles(vehicles))
+	}
+
+	// An exact name wins, so "R1T" isn't ambiguous next to "R1T Adventure"
+	name = strings.TrimSpace(name)
+	for i, v := range vehicles {
+		if strings.EqualFold(v.Name, name) {
+			return i, nil
+		}
+	}
+
+	needle := strings.ToLower(name)
+	var matches []int
+	for i, v := range vehicles {
+		if strings.Contains(strings.ToLower(v.Name), needle) {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return -1, fmt.Errorf("%w: no vehicle name contains %q (have %s)", errVehicleNotFound, name, describeVehicles(vehicles))
+	case 1:
+		return matches[0], nil
+	}
+	candidates := make([]rivian.Vehicle, len(matches))
+	for i, index := range matches {
+		candidates[i] = vehicles[index]
+	}
+	return -1, fmt.Errorf("%q matches %d vehicles (%s); use a longer name or --vehicle-vin", name, len(matches), describeVehicles(candidates))
+}
+
+// describeVehicles lists vehicles as "Name (VIN)" for error messages.
+func describeVehicles(vehicles []rivian.Vehicle) string {
+	names := make([]string, len(vehicles))
+	for i, v := range vehicles {
+		names[i] = fmt.Sprintf("%q (%s)", v.Name, v.VIN)
+	}
+	return strings.Join(names, ", ")
+}
+
+// selectCompareVehicles picks the vehicles named by args (0-based indexes or
+// IDs), or the first two. A single-vehicle account yields just that one.
+func selectCompareVehicles(vehicles []rivian.Vehicle, args []string) ([]rivian.Vehicle, error) {
+	if len(args) == 0 {
+		return vehicles[:min(2, len(vehicles))], nil
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("compare-vehicles takes two vehicles, got %d", len(args))
+	}
+
+	selected := make([]rivian.Vehicle, 0, len(args))
+	for _, arg := range args {
+		found := false
+		for i, v := range vehicles {
+			if v.ID == arg || strconv.Itoa(i) == arg {
+				selected = append(selected, v)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q (have %d vehicles)", errVehicleNotFound, arg, len(vehicles))
+		}
+	}
+	if selected[0].ID == selected[1].ID {
+		return nil, fmt.Errorf("compare-vehicles needs two different vehicles")
+	}
+	return selected, nil
+}
+
+func runPlanCommand(ctx context.Context, client rivian.Client, vehicleID string, args []string) int {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	distance := fs.Float64("distance", 0, "Trip distance in miles (required)")
+	margin := fs.Float64("margin", 10, "Minimum battery % to arrive with")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: rivian-ls plan --distance <miles> [flags]\n\n")
+		fs.PrintDefaults()
+		_, _ = fmt.Fprintf(fs.Output(), "\nExits %d when the destination can't be reached with the margin.\n", ExitBatteryBelow)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing plan flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if *distance <= 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --distance must be a positive number of miles\n")
+		return ExitInvalidArgs
+	}
+	if *margin < 0 || *margin >= 100 {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: --margin must be between 0 and 100\n")
+		return ExitInvalidArgs
+	}
+
+	cmd := cli.NewPlanCommand(client, vehicleID, os.Stdout)
+	opts := cli.PlanOptions{
+		Distance: *distance,
+		Margin:   *margin,
+	}
+
+	if err := cmd.Run(ctx, opts); err != nil {
+		if errors.Is(err, cli.ErrBatteryBelow) {
+			return ExitBatteryBelow
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "Plan command failed: %v\n", err)
+		return ExitAPIError
+	}
+
+	return ExitSuccess
+}
+
+func runPruneCommand(ctx context.Context, dbPath string, noStore, resetDB bool, args []string) int {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	olderThan := fs.String("older-than", "90d", "Delete states older than this (e.g. '30d', '72h')")
+	vacuum := fs.Bool("vacuum", false, "Run VACUUM afterwards to reclaim disk space (can be slow on large databases)")
+	dryRun := fs.Bool("dry-run", false, "Only report how many states would be deleted")
+
+	if err := fs.Parse(args); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error parsing prune flags: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	if noStore {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: prune cannot be used with --no-store\n")
+		return ExitInvalidArgs
+	}
+
+	retention, err := config.ParseRetention(*olderThan)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid --older-than: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	db, err := openStore(dbPath, resetDB, os.Stderr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return ExitInvalidArgs
+	}
+	defer func() { _ = db.Close() }()
+
+	cmd := cli.NewPruneCommand(db, os.Stdout)
+	opts := cli.PruneOptions{
+		OlderThan: retention,
+		Vacuum:    *vacuum,
+		DryRun:    *dryRun,
+	}
+
+	if err := cmd.Run(ctx, opts); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Prune command failed: %v\n", err)
+		return ExitAPIError
+	}
+
+	return ExitSuccess
+}
+
+// configFlags maps global flags to the config file keys they override.
+var configFlags = map[string]string{
+	"email":            "email",
+	"vehicle":          "vehicle",
+	"db":               "db_path",
+	"no-store":         "disable_store",
+	"retention":        "retention",
+	"state-cache-ttl":  "state_cache_ttl",
+	"query-overrides":  "query_overrides",
+	"range-unit":       "range_unit",
+	"ready-by":         "ready_by",
+	"battery-capacity": "battery_capacity",
+	"quiet":            "quiet",
+	"verbose":          "verbose",
+}
+
+// effectiveConfig returns a copy of cfg with the global flags given on the
+// command line applied, so config show reports what a run would use.
+func effectiveConfig(cfg *config.Config, fs *flag.FlagSet) (*config.Config, error) {
+	out := *cfg
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := configFlags[f.Name]; ok && err == nil {
+			err = out.Set(key, f.Value.String())
+		}
+	})
+	return &out, err
+}
+
+// defaultFormat returns the configured default --format for status and
+// watch, or text when none is set.
+func defaultFormat(configured string) string {
+	if configured == "" {
+		return "text"
+	}
+	return configured
+}
+
+func runConfigCommand(cfg *config.Config, w io.Writer, args []string) int {
+	usage := func() int {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: rivian-ls config show|path|set <key> <value>\n\nKeys: %s\n", strings.Join(config.Keys(), ", "))
+		return ExitInvalidArgs
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	switch args[0] {
+	case "path":
+		_, _ = fmt.Fprintln(w, config.Path())
+
+	case "show":
+		out := *cfg
+		if out.Password != "" {
+			out.Password = "********"
+		}
+		data, err := yaml.Marshal(&out)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitInvalidArgs
+		}
+		source := config.Path()
+		if !config.Exists() {
+			source += " (not created yet)"
+		}
+		_, _ = fmt.Fprintf(w, "# Effective settings: flags > environment > %s > defaults\n%s", source, data)
+
+	case "set":
+		if len(args) != 3 {
+			return usage()
+		}
+		// Start from the file alone so environment variables aren't saved
+		fileCfg, err := config.LoadFile()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitInvalidArgs
+		}
+		if err := fileCfg.Set(args[1], args[2]); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitInvalidArgs
+		}
+		if err := fileCfg.Save(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			return ExitInvalidArgs
+		}
+		_, _ = fmt.Fprintf(w, "Set %s = %s in %s\n", args[1], args[2], config.Path())
+
+	default:
+		return usage()
+	}
+	return ExitSuccess
+}
+
+// defaultRangeUnit returns the configured API range unit, or km, which the
+// API has reported so far.
+func defaultRangeUnit(configured string) string {
+	if configured == "" {
+		return string(model.DistanceKilometers)
+	}
+	return configured
+}
+
+// runSetupCommand walks a new user through authentication, vehicle selection
+// and display preferences, then writes the config file. cfg supplies the
+// suggested answers; only the file's own settings and the answers are saved.
+func runSetupCommand(ctx context.Context, client *rivian.HTTPClient, credCache *auth.CredentialsCache, reader *bufio.Reader, cfg *config.Config) int {
+	// Start from the file alone so environment variables aren't saved
+	fileCfg, err := config.LoadFile()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitInvalidArgs
+	}
+
+	fmt.Println("Welcome to rivian-ls! Let's get you set up.")
+	fmt.Println()
+
+	// Step 1: Authentication (password and OTP are prompted by authenticate)
+	email := promptWithDefault(reader, "Email", cfg.Email)
+	if email == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: email is required\n")
+		return ExitInvalidArgs
+	}
+	password := ""
+	if err := authenticate(ctx, client, credCache, reader, &email, &password); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Authentication failed: %v\n", err)
+		return ExitAuthFailure
+	}
+	fmt.Println("✓ Signed in")
+	fmt.Println()
+
+	// Step 2: Vehicle selection
+	vehicles, err := client.GetVehicles(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to get vehicles: %v\n", err)
+		if errors.Is(err, rivian.ErrVehiclesUnavailable) {
+			return ExitVehicleNotFound
+		}
+		return ExitAPIError
+	}
+	if len(vehicles) == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, rivian.NoVehiclesHint)
+		return ExitVehicleNotFound
+	}
+
+	vehicleIndex := 0
+	if len(vehicles) > 1 {
+		def := cfg.Vehicle
+		if def < 0 || def >= len(vehicles) {
+			def = 0
+		}
+		vehicleIndex = promptVehicle(reader, os.Stdout, vehicles, def)
+	}
+	vehicle := vehicles[vehicleIndex]
+	fmt.Printf("✓ Using %s %s\n\n", vehicle.Model, vehicle.Name)
+
+	// Step 3: Storage and display preferences. Units only pick the TUI's
+	// temperature unit; distances are always in miles.
+	dbPath := promptWithDefault(reader, "Database path", cfg.DBPath)
+	units := promptChoice(reader, "Temperature units", []string{config.UnitsImperial, config.UnitsMetric}, cfg.Units)
+	theme := promptChoice(reader, "Theme", []string{config.ThemeDark, config.ThemeLight}, cfg.Theme)
+
+	fileCfg.Email = email
+	fileCfg.Vehicle = vehicleIndex
+	fileCfg.DBPath = dbPath
+	fileCfg.Units = units
+	fileCfg.Theme = theme
+	if err := fileCfg.Save(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+		return ExitInvalidArgs
+	}
+	fmt.Printf("\n✓ Saved configuration to %s\n", config.Path())
+
+	// Step 4: Confirm everything works end to end
+	state, err := client.GetVehicleState(ctx, vehicle.ID)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Setup saved, but fetching vehicle status failed: %v\n", err)
+		return ExitAPIError
+	}
+	fmt.Printf("✓ Status check: %.0f%% battery, %.0f mi range\n\n", state.BatteryLevel, state.RangeEstimate)
+	fmt.Println("All set! Run 'rivian-ls' for the dashboard or 'rivian-ls status' for a snapshot.")
+
+	return ExitSuccess
+}
+
+// promptVehicle lists vehicles on w and asks until a valid index is entered,
+// returning def on an empty answer or EOF.
+func promptVehicle(reader *bufio.Reader, w io.Writer, vehicles []rivian.Vehicle, def int) int {
+	_, _ = fmt.Fprintln(w, "Vehicles:")
+	for i, v := range vehicles {
+		_, _ = fmt.Fprintf(w, "  [%d] %s %s (%s)\n", i, v.Model, v.Name, v.VIN)
+	}
+	for {
+		_, _ = fmt.Fprintf(w, "Vehicle [%d]: ", def)
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return def
+		}
+		n, convErr := strconv.Atoi(answer)
+		if convErr == nil && n >= 0 && n < len(vehicles) {
+			return n
+		}
+		if err != nil {
+			return def
+		}
+		_, _ = fmt.Fprintf(w, "Please enter a number between 0 and %d\n", len(vehicles)-1)
+	}
+}
+
+// promptWithDefault asks for a value, returning def when the answer is empty.
+func promptWithDefault(reader *bufio.Reader, label, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+
+	answer, _ := reader.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+	return answer
+}
+
+// promptChoice asks until the answer is one of choices (case-insensitive).
+func promptChoice(reader *bufio.Reader, label string, choices []string, def string) string {
+	label = fmt.Sprintf("%s (%s)", label, strings.Join(choices, "/"))
+
+	// An empty answer must always be valid, or EOF would loop forever
+	valid := false
+	for _, c := range choices {
+		valid = valid || c == def
+	}
+	if !valid {
+		def = choices[0]
+	}
+
+	for {
+		answer := strings.ToLower(promptWithDefault(reader, label, def))
+		for _, c := range choices {
+			if answer == c {
+				return c
+			}
+		}
+		fmt.Printf("Please choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
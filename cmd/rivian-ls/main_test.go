@@ -1,10 +1,23 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/auth"
+	"github.com/pfrederiksen/rivian-ls/internal/config"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
 )
 
 // errorWriter always returns an error when Write is called
@@ -131,3 +144,477 @@ func TestPrintVersionDateError(t *testing.T) {
 		t.Error("Expected error when writer fails, got nil")
 	}
 }
+
+func TestPrintAuthCheck(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	creds := &rivian.Credentials{ExpiresAt: now.Add(90 * time.Minute)}
+	if err := printAuthCheck(&buf, creds, now); err != nil {
+		t.Fatalf("printAuthCheck() error = %v", err)
+	}
+	want := "Authentication: OK\nToken expires: 2024-01-15T11:30:00Z (in 1h30m0s)\n"
+	if buf.String() != want {
+		t.Errorf("printAuthCheck() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := printAuthCheck(&buf, nil, now); err != nil {
+		t.Fatalf("printAuthCheck() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Token expires: unknown") {
+		t.Errorf("printAuthCheck() without credentials = %q", buf.String())
+	}
+}
+
+func TestPromptChoice(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		def   string
+		want  string
+	}{
+		{"explicit answer", "metric\n", "imperial", "metric"},
+		{"case-insensitive", "METRIC\n", "imperial", "metric"},
+		{"empty uses default", "\n", "imperial", "imperial"},
+		{"retries invalid answer", "kelvin\nmetric\n", "imperial", "metric"},
+		{"EOF with invalid default", "", "bogus", "imperial"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			got := promptChoice(reader, "Units", []string{"imperial", "metric"}, tt.def)
+			if got != tt.want {
+				t.Errorf("promptChoice() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptVehicle(t *testing.T) {
+	vehicles := []rivian.Vehicle{
+		{Name: "Truck", Model: "R1T", VIN: "7FCTGAAA0PN000001"},
+		{Name: "SUV", Model: "R1S", VIN: "7PDSGABA0PN000002"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"explicit answer", "1\n", 1},
+		{"empty uses default", "\n", 0},
+		{"retries out of range", "5\n1\n", 1},
+		{"retries non-number", "suv\n1\n", 1},
+		{"EOF uses default", "", 0},
+		{"answer without newline", "1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			got := promptVehicle(reader, &out, vehicles, 0)
+			if got != tt.want {
+				t.Errorf("promptVehicle() = %d, want %d", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "[1] R1S SUV (7PDSGABA0PN000002)") {
+				t.Errorf("promptVehicle() should list vehicles, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestReadSecretNonInteractive(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"piped line", "hunter2\n", "hunter2", false},
+		{"CRLF line", "hunter2\r\n", "hunter2", false},
+		{"no trailing newline", "hunter2", "hunter2", false},
+		{"keeps surrounding spaces", " pass word \n", " pass word ", false},
+		{"empty stdin", "", "", true},
+		{"blank line", "\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSecret(bufio.NewReader(strings.NewReader(tt.input)), false, "Password: ")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errNoCredentials) {
+				t.Errorf("readSecret() error = %v, want errNoCredentials", err)
+			}
+			if got != tt.want {
+				t.Errorf("readSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenStoreCorrupt(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	if err := os.WriteFile(dbPath, []byte(strings.Repeat("garbage ", 1024)), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// Without --reset-db the error says how to recover
+	var out bytes.Buffer
+	if _, err := openStore(dbPath, false, &out); err == nil || !strings.Contains(err.Error(), "--reset-db") {
+		t.Fatalf("openStore() error = %v, want a --reset-db hint", err)
+	}
+
+	db, err := openStore(dbPath, true, &out)
+	if err != nil {
+		t.Fatalf("openStore() with reset failed: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	if !strings.Contains(out.String(), "backed it up to "+dbPath+".corrupt-") {
+		t.Errorf("openStore() should report the backup, got %q", out.String())
+	}
+}
+
+// otpTestServer returns an API server that asks for an OTP on login and
+// answers LoginWithOTP with submit, given the 1-based submission count.
+// Login variables are passed to login when it is not nil.
+func otpTestServer(t *testing.T, login func(vars map[string]any), submit func(w http.ResponseWriter, call int)) *httptest.Server {
+	t.Helper()
+	submissions := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(req.Query, "LoginWithOTP"):
+			submissions++
+			submit(w, submissions)
+		case strings.Contains(req.Query, "Login"):
+			if login != nil {
+				login(req.Variables)
+			}
+			_, _ = w.Write([]byte(`{"data":{"login":{"__typename":"MobileMFALoginResponse","otpToken":"otp-token"}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"createCsrfToken":{"csrfToken":"csrf","appSessionToken":"session"}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// otpTestClient returns a client waiting for an OTP from a server whose
+// LoginWithOTP is answered by submit, with the 1-based submission count.
+func otpTestClient(t *testing.T, submit func(w http.ResponseWriter, call int)) *rivian.HTTPClient {
+	t.Helper()
+	server := otpTestServer(t, nil, submit)
+	client := rivian.NewHTTPClient(rivian.WithBaseURL(server.URL))
+	var otpErr *rivian.OTPRequiredError
+	if err := client.Authenticate(context.Background(), "user@example.com", "password"); !errors.As(err, &otpErr) {
+		t.Fatalf("Authenticate error = %v, want OTPRequiredError", err)
+	}
+	return client
+}
+
+func TestSubmitOTP_RetriesNetworkErrors(t *testing.T) {
+	delay := otpRetryDelay
+	otpRetryDelay = time.Millisecond
+	t.Cleanup(func() { otpRetryDelay = delay })
+
+	client := otpTestClient(t, func(w http.ResponseWriter, call int) {
+		if call <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"loginWithOTP":{"userSessionToken":"user-session","refreshToken":"refresh"}}}`))
+	})
+
+	var progress bytes.Buffer
+	if err := submitOTP(context.Background(), client, "123456", &progress); err != nil {
+		t.Fatalf("submitOTP failed: %v", err)
+	}
+	if !client.IsAuthenticated() {
+		t.Error("client should be authenticated once a retry gets through")
+	}
+	if got := strings.Count(progress.String(), "retrying the same code"); got != 2 {
+		t.Errorf("reported %d retries, want 2:\n%s", got, progress.String())
+	}
+}
+
+func TestSubmitOTP_GivesUpOnNetworkErrors(t *testing.T) {
+	delay := otpRetryDelay
+	otpRetryDelay = time.Millisecond
+	t.Cleanup(func() { otpRetryDelay = delay })
+
+	calls := 0
+	client := otpTestClient(t, func(w http.ResponseWriter, call int) {
+		calls = call
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	err := submitOTP(context.Background(), client, "123456", &bytes.Buffer{})
+	var netErr *rivian.OTPNetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("submitOTP error = %v, want *rivian.OTPNetworkError", err)
+	}
+	if calls != otpNetworkRetries+1 {
+		t.Errorf("submitted %d times, want %d", calls, otpNetworkRetries+1)
+	}
+}
+
+func TestSubmitOTP_RejectionNotRetried(t *testing.T) {
+	calls := 0
+	client := otpTestClient(t, func(w http.ResponseWriter, call int) {
+		calls = call
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"Invalid OTP code"}]}`))
+	})
+
+	var progress bytes.Buffer
+	err := submitOTP(context.Background(), client, "000000", &progress)
+	if !errors.Is(err, rivian.ErrOTPRejected) {
+		t.Fatalf("submitOTP error = %v, want rivian.ErrOTPRejected", err)
+	}
+	if calls != 1 || progress.Len() != 0 {
+		t.Errorf("a rejected code should not be resubmitted: %d submissions, progress %q", calls, progress.String())
+	}
+}
+
+func TestSelectCompareVehicles(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	tests := []struct {
+		name     string
+		vehicles []rivian.Vehicle
+		args     []string
+		want     []string
+		notFound bool
+		wantErr  bool
+	}{
+		{name: "defaults to the first two", vehicles: vehicles, want: []string{"a", "b"}},
+		{name: "single vehicle account", vehicles: vehicles[:1], want: []string{"a"}},
+		{name: "by index and ID", vehicles: vehicles, args: []string{"2", "a"}, want: []string{"c", "a"}},
+		{name: "unknown vehicle", vehicles: vehicles, args: []string{"0", "z"}, notFound: true, wantErr: true},
+		{name: "same vehicle twice", vehicles: vehicles, args: []string{"1", "b"}, wantErr: true},
+		{name: "one argument", vehicles: vehicles, args: []string{"1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectCompareVehicles(tt.vehicles, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectCompareVehicles error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, errVehicleNotFound) != tt.notFound {
+				t.Errorf("error %v: vehicle not found = %v, want %v", err, !tt.notFound, tt.notFound)
+			}
+			var ids []string
+			for _, v := range got {
+				ids = append(ids, v.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("selected %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVehicle(t *testing.T) {
+	vehicles := []rivian.Vehicle{
+		{ID: "a", Name: "Road Trip", VIN: "7FCTGAAL0NN000001"},
+		{ID: "b", Name: "Trail Truck", VIN: "7FCTGAAL0NN000002"},
+		{ID: "c", Name: "Family Hauler", VIN: "7PDSGABA5NN000003"},
+		{ID: "d", Name: "R1T Adventure", VIN: "7FCTGAAL0NN000004"},
+		{ID: "e", Name: "R1T", VIN: "7FCTGAAL0NN000005"},
+	}
+
+	tests := []struct {
+		name      string
+		vin       string
+		vehicle   string
+		want      int
+		notFound  bool
+		ambiguous bool
+	}{
+		{name: "exact VIN ignoring case", vin: "7fctgaal0nn000002", want: 1},
+		{name: "VIN wins over name", vin: "7PDSGABA5NN000003", vehicle: "Road", want: 2},
+		{name: "partial VIN", vin: "7FCTGAAL0NN", notFound: true},
+		{name: "exact name", vehicle: "Road Trip", want: 0},
+		{name: "name substring ignoring case", vehicle: "haul", want: 2},
+		{name: "exact name wins over substring", vehicle: "r1t", want: 4},
+		{name: "ambiguous name", vehicle: "tr", ambiguous: true},
+		{name: "no matching name", vehicle: "Cybertruck", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveVehicle(vehicles, tt.vin, tt.vehicle)
+			if tt.notFound || tt.ambiguous {
+				if err == nil {
+					t.Fatalf("resolveVehicle = %d, want an error", got)
+				}
+				if errors.Is(err, errVehicleNotFound) != tt.notFound {
+					t.Errorf("error %v: vehicle not found = %v, want %v", err, !tt.notFound, tt.notFound)
+				}
+				if tt.ambiguous && (!strings.Contains(err.Error(), "Road Trip") || !strings.Contains(err.Error(), "Trail Truck")) {
+					t.Errorf("ambiguous error %q should list the matching vehicles", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveVehicle failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveVehicle = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// runCaptured runs the command line and returns its exit code and stdout.
+func runCaptured(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	code := run(append([]string{"rivian-ls"}, args...))
+	os.Stdout = stdout
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	return code, string(out)
+}
+
+func TestConfigCommand_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("RIVIAN_EMAIL", "")
+
+	// default < config file
+	if code, out := runCaptured(t, "config", "set", "email", "file@example.com"); code != ExitSuccess || !strings.Contains(out, "Set email") {
+		t.Fatalf("config set = %d %q", code, out)
+	}
+	for _, kv := range [][2]string{{"vehicle", "1"}, {"format", "json"}, {"db_path", "/file/state.db"}} {
+		if code, _ := runCaptured(t, "config", "set", kv[0], kv[1]); code != ExitSuccess {
+			t.Fatalf("config set %s = %d", kv[0], code)
+		}
+	}
+	if code, _ := runCaptured(t, "config", "set", "format", "xml"); code != ExitInvalidArgs {
+		t.Errorf("config set with an invalid value = %d, want %d", code, ExitInvalidArgs)
+	}
+
+	// config file < env < flag
+	t.Setenv("RIVIAN_EMAIL", "env@example.com")
+	t.Setenv("RIVIAN_DB_PATH", "/env/state.db")
+	code, out := runCaptured(t, "--db", "/flag/state.db", "config", "show")
+	if code != ExitSuccess {
+		t.Fatalf("config show = %d", code)
+	}
+	for _, want := range []string{
+		"email: env@example.com",
+		"db_path: /flag/state.db",
+		"vehicle: 1",
+		"format: json",
+		"poll_interval: 30s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config show should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// Environment values aren't written back by set
+	if code, _ := runCaptured(t, "config", "set", "quiet", "true"); code != ExitSuccess {
+		t.Fatalf("config set quiet = %d", code)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "rivian-ls", "config.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "env@example.com") || !strings.Contains(string(data), "quiet: true") {
+		t.Errorf("config file after set:\n%s", data)
+	}
+
+	if code, out := runCaptured(t, "config", "path"); code != ExitSuccess || strings.TrimSpace(out) != filepath.Join(dir, "rivian-ls", "config.yaml") {
+		t.Errorf("config path = %d %q", code, out)
+	}
+}
+
+func TestAuthenticate_FromEnvironment(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("RIVIAN_EMAIL", "env@example.com")
+	t.Setenv("RIVIAN_PASSWORD", "env-password")
+	t.Setenv("RIVIAN_OTP", "123456")
+
+	var loginVars map[string]any
+	server := otpTestServer(t, func(vars map[string]any) { loginVars = vars }, func(w http.ResponseWriter, call int) {
+		_, _ = w.Write([]byte(`{"data":{"loginWithOTP":{"userSessionToken":"user-session","refreshToken":"refresh"}}}`))
+	})
+	client := rivian.NewHTTPClient(rivian.WithBaseURL(server.URL))
+
+	// The flags default to the environment through the config
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := authenticate(context.Background(), client, nil, bufio.NewReader(os.Stdin), &cfg.Email, &cfg.Password); err != nil {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+	if loginVars["email"] != "env@example.com" || loginVars["password"] != "env-password" {
+		t.Errorf("login variables = %v, want the environment's credentials", loginVars)
+	}
+	if !client.IsAuthenticated() {
+		t.Error("client should be authenticated with the OTP from RIVIAN_OTP")
+	}
+}
+
+func TestAuthenticate_SharedReader(t *testing.T) {
+	t.Setenv("RIVIAN_OTP", "")
+	server := otpTestServer(t, nil, func(w http.ResponseWriter, call int) {
+		_, _ = w.Write([]byte(`{"data":{"loginWithOTP":{"userSessionToken":"user-session","refreshToken":"refresh"}}}`))
+	})
+	client := rivian.NewHTTPClient(rivian.WithBaseURL(server.URL))
+
+	// Answers after the password and OTP stay buffered for later prompts
+	stdin := bufio.NewReader(strings.NewReader("password\n123456\n1\n"))
+	email, password := "user@example.com", ""
+	if err := authenticate(context.Background(), client, nil, stdin, &email, &password); err != nil {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+	if next, _ := stdin.ReadString('\n'); next != "1\n" {
+		t.Errorf("next answer = %q, want the vehicle answer after the OTP", next)
+	}
+}
+
+func TestCacheRefreshedCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	credCache, err := auth.NewCredentialsCache()
+	if err != nil {
+		t.Fatalf("NewCredentialsCache failed: %v", err)
+	}
+
+	// Nothing cached, nothing saved
+	cacheRefreshedCredentials(credCache, &rivian.Credentials{AccessToken: "new-access"})
+	if cached, err := credCache.Load(); err != nil || cached != nil {
+		t.Fatalf("Load = %+v, %v; want no cache", cached, err)
+	}
+
+	if err := credCache.Save("user@example.com", &rivian.Credentials{AccessToken: "old-access", RefreshToken: "old-refresh"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	cacheRefreshedCredentials(credCache, &rivian.Credentials{AccessToken: "new-access", RefreshToken: "new-refresh"})
+	cached, err := credCache.Load()
+	if err != nil || cached == nil {
+		t.Fatalf("Load = %+v, %v", cached, err)
+	}
+	if cached.Email != "user@example.com" || cached.AccessToken != "new-access" || cached.RefreshToken != "new-refresh" {
+		t.Errorf("cached = %+v, want the refreshed tokens for user@example.com", cached)
+	}
+}
@@ -74,6 +74,7 @@ func main() {
 
 	if len(vehicles) == 0 {
 		fmt.Println("\n✅ Authentication works, but no vehicles found.")
+		fmt.Println(rivian.NoVehiclesHint)
 		return
 	}
 
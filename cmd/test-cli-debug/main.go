@@ -110,6 +110,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if len(vehicles) == 0 {
+		fmt.Println(rivian.NoVehiclesHint)
+		os.Exit(1)
+	}
+
 	fmt.Printf("✓ Found %d vehicle(s):\n", len(vehicles))
 	for i, v := range vehicles {
 		fmt.Printf("  [%d] %s (%s %s) - VIN: %s\n", i, v.Name, v.Model, v.VIN, v.ID)
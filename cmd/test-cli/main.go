@@ -143,7 +143,7 @@ func main() {
 	}
 
 	if len(vehicles) == 0 {
-		fmt.Println("No vehicles found")
+		fmt.Println(rivian.NoVehiclesHint)
 		return
 	}
 
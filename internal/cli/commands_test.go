@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -84,6 +87,10 @@ func (m *mockClient) GetVehicleState(ctx context.Context, vehicleID string) (*ri
 	return m.state, nil
 }
 
+func (m *mockClient) GetVehicleSoftwareInfo(ctx context.Context, vehicleID string) (*rivian.SoftwareInfo, error) {
+	return &rivian.SoftwareInfo{VehicleID: vehicleID}, nil
+}
+
 func (m *mockClient) GetCredentials() *rivian.Credentials {
 	return &rivian.Credentials{
 		AccessToken:  "test-token",
@@ -165,6 +172,19 @@ func TestStatusCommand_Run(t *testing.T) {
 	if !strings.Contains(output, "85.5") {
 		t.Error("Output missing battery level")
 	}
+
+	// Derived metrics come from the reducer, as in the TUI
+	var envelope struct {
+		Metrics struct {
+			ReadyScore *float64 `json:"readyScore"`
+		} `json:"metrics"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if envelope.Metrics.ReadyScore == nil {
+		t.Error("Output missing readyScore")
+	}
 }
 
 func TestStatusCommand_Run_Offline(t *testing.T) {
@@ -182,7 +202,7 @@ func TestStatusCommand_Run_Offline(t *testing.T) {
 		VIN:           "VIN123",
 		Name:          "Test Vehicle",
 		Model:         "R1T",
-		UpdatedAt:     time.Now(),
+		UpdatedAt:     time.Now().Add(-(3*time.Hour + 12*time.Minute)),
 		BatteryLevel:  80.0,
 		RangeEstimate: 200.0,
 		ChargeState:   model.ChargeStateNotCharging,
@@ -229,6 +249,100 @@ func TestStatusCommand_Run_Offline(t *testing.T) {
 	if !strings.Contains(output, "Test Vehicle") {
 		t.Error("Output missing vehicle name from cache")
 	}
+	if !strings.Contains(output, "Data age: 3h 12m (as of ") {
+		t.Errorf("Output missing the data age:\n%s", output)
+	}
+}
+
+func TestStatusCommand_Run_ConditionChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*rivian.VehicleState)
+		opts    StatusOptions
+		wantErr error
+	}{
+		{name: "checks off", opts: StatusOptions{}},
+		{name: "battery above threshold", opts: StatusOptions{ExitBelow: 50}},
+		{name: "battery below threshold", opts: StatusOptions{ExitBelow: 90}, wantErr: ErrBatteryBelow},
+		{name: "no issues", opts: StatusOptions{ExitOnIssues: true}},
+		{
+			name:  "info issues only",
+			setup: func(s *rivian.VehicleState) { s.IsLocked = false },
+			opts:  StatusOptions{ExitOnIssues: true},
+		},
+		{
+			name:    "door open",
+			setup:   func(s *rivian.VehicleState) { s.Doors.FrontLeft = rivian.ClosureStatusOpen },
+			opts:    StatusOptions{ExitOnIssues: true},
+			wantErr: ErrVehicleIssues,
+		},
+		{
+			name:    "battery takes precedence",
+			setup:   func(s *rivian.VehicleState) { s.Doors.FrontLeft = rivian.ClosureStatusOpen },
+			opts:    StatusOptions{ExitOnIssues: true, ExitBelow: 90},
+			wantErr: ErrBatteryBelow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := makeMockRivianState()
+			if tt.setup != nil {
+				tt.setup(state)
+			}
+
+			var buf bytes.Buffer
+			cmd := NewStatusCommand(&mockClient{state: state}, nil, "vehicle-123", &buf)
+			tt.opts.Format = FormatJSON
+
+			err := cmd.Run(context.Background(), tt.opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			// The status is printed regardless of the condition result
+			if !strings.Contains(buf.String(), "vehicle-123") {
+				t.Error("status should be printed even when a condition fails")
+			}
+		})
+	}
+}
+
+func TestStatusCommand_Run_ReadyBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*rivian.VehicleState)
+		want  string
+	}{
+		{name: "already complete", want: "already at the 80% charge limit"},
+		{
+			name: "not charging",
+			setup: func(s *rivian.VehicleState) {
+				s.BatteryLevel = 40
+				s.ChargeState = rivian.ChargeStateNotCharging
+			},
+			want: "✗ not charging (needs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := makeMockRivianState()
+			if tt.setup != nil {
+				tt.setup(state)
+			}
+
+			var buf bytes.Buffer
+			cmd := NewStatusCommand(&mockClient{state: state}, nil, "vehicle-123", &buf)
+			opts := StatusOptions{Format: FormatText, ReadyBy: time.Now().Add(8 * time.Hour)}
+
+			if err := cmd.Run(context.Background(), opts); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), "Ready by") || !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output missing ready-by line %q:\n%s", tt.want, buf.String())
+			}
+		})
+	}
 }
 
 func TestExportCommand_Run(t *testing.T) {
@@ -331,12 +445,926 @@ func TestExportCommand_Run_RangeQuery(t *testing.T) {
 
 	// Verify we got the right number of states
 	output := buf.String()
-	var states []*model.VehicleState
-	if err := json.Unmarshal([]byte(output), &states); err != nil {
+	var history HistoryOutput
+	if err := json.Unmarshal([]byte(output), &history); err != nil {
 		t.Fatalf("Invalid JSON output: %v", err)
 	}
 
-	if len(states) != 5 { // Hours 3, 4, 5, 6, 7
-		t.Errorf("Expected 5 states, got %d", len(states))
+	if len(history.States) != 5 { // Hours 3, 4, 5, 6, 7
+		t.Errorf("Expected 5 states, got %d", len(history.States))
+	}
+}
+
+func TestExportCommand_Run_RangeStreamsAndCaps(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	saveTestStates(t, testStore, ctx, now, 10, func(i int) float64 { return float64(50 + i) })
+
+	opts := ExportOptions{
+		Since:   now.Add(3 * time.Hour),
+		Until:   now.Add(7 * time.Hour),
+		MaxRows: 2,
+	}
+
+	// Streaming formats ignore the cap
+	var buf bytes.Buffer
+	opts.Format = FormatNDJSON
+	if err := NewExportCommand(testStore, "vehicle-123", &buf).Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 5 {
+		t.Errorf("Expected 5 NDJSON lines, got %d", lines)
+	}
+
+	path := filepath.Join(tmpDir, "history.csv")
+	buf.Reset()
+	opts.Format, opts.OutputPath = FormatCSV, path
+	if err := NewExportCommand(testStore, "vehicle-123", &buf).Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Wrote 5 states") {
+		t.Errorf("Expected a count of streamed states, got %q", buf.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 6 { // Header + 5 rows
+		t.Errorf("Expected 6 CSV lines, got %d", lines)
+	}
+
+	// Buffered formats refuse ranges above the cap
+	opts.Format, opts.OutputPath = FormatJSON, ""
+	err = NewExportCommand(testStore, "vehicle-123", &buf).Run(ctx, opts)
+	if err == nil || !strings.Contains(err.Error(), "row limit") {
+		t.Errorf("Expected row limit error, got %v", err)
+	}
+}
+
+func TestAdaptiveInterval_Next(t *testing.T) {
+	fast, slow := time.Minute, 30*time.Minute
+	schedule := newAdaptiveInterval(fast, slow)
+
+	parked := func(odometer float64) *model.VehicleState {
+		return &model.VehicleState{ChargeState: model.ChargeStateNotCharging, Odometer: odometer}
+	}
+
+	steps := []struct {
+		name  string
+		state *model.VehicleState
+		want  time.Duration
+	}{
+		{"first poll idle", parked(100), fast},
+		{"second poll idle", parked(100), fast},
+		{"third idle poll backs off", parked(100), slow},
+		{"still idle", parked(100), slow},
+		{"odometer changed", parked(105), fast},
+		{"idle again", parked(105), fast},
+		{"charging", &model.VehicleState{ChargeState: model.ChargeStateCharging, Odometer: 105}, fast},
+	}
+
+	for _, step := range steps {
+		if got := schedule.Next(step.state); got != step.want {
+			t.Errorf("%s: Next() = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestWatchCommand_Run_InvalidAdaptiveBounds(t *testing.T) {
+	cmd := NewWatchCommand(&mockClient{state: makeMockRivianState()}, nil, "vehicle-123", "", "", &bytes.Buffer{})
+
+	err := cmd.Run(context.Background(), WatchOptions{
+		Format:       FormatJSON,
+		Adaptive:     true,
+		FastInterval: 10 * time.Minute,
+		SlowInterval: time.Minute,
+	})
+	if err == nil || !strings.Contains(err.Error(), "shorter than fast interval") {
+		t.Errorf("Expected interval bounds error, got: %v", err)
+	}
+}
+
+func TestPruneCommand_Run(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	// 10 hourly states, the oldest 10 hours ago
+	ctx := context.Background()
+	saveTestStates(t, testStore, ctx, time.Now().Add(-10*time.Hour), 10, func(i int) float64 { return 80 })
+
+	var buf bytes.Buffer
+	cmd := NewPruneCommand(testStore, &buf)
+
+	err = cmd.Run(ctx, PruneOptions{OlderThan: 5*time.Hour + 30*time.Minute, Vacuum: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Deleted 5 states") {
+		t.Errorf("Expected 5 deleted states, got: %s", output)
+	}
+	if !strings.Contains(output, "Vacuumed database") {
+		t.Errorf("Expected vacuum report, got: %s", output)
+	}
+
+	stats, err := testStore.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalStates != 5 {
+		t.Errorf("Expected 5 remaining states, got %d", stats.TotalStates)
+	}
+}
+
+func TestPruneCommand_Run_DryRun(t *testing.T) {
+	testStore, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	saveTestStates(t, testStore, ctx, time.Now().Add(-10*time.Hour), 10, func(i int) float64 { return 80 })
+
+	var buf bytes.Buffer
+	err = NewPruneCommand(testStore, &buf).Run(ctx, PruneOptions{OlderThan: 5*time.Hour + 30*time.Minute, Vacuum: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Would delete 5 states") || strings.Contains(output, "Vacuumed") {
+		t.Errorf("Expected only a count of 5 states, got: %s", output)
+	}
+
+	stats, err := testStore.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalStates != 10 {
+		t.Errorf("dry run deleted states: %d remaining, want 10", stats.TotalStates)
+	}
+}
+
+func TestPruneCommand_Run_InvalidOptions(t *testing.T) {
+	cmd := NewPruneCommand(nil, &bytes.Buffer{})
+	if err := cmd.Run(context.Background(), PruneOptions{OlderThan: time.Hour}); err == nil {
+		t.Error("Expected error without a store")
+	}
+}
+
+func TestWatchCommand_RecordSavesChargingSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	cmd := NewWatchCommand(&mockClient{}, testStore, "vehicle-123", "", "", &bytes.Buffer{})
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	rate := 11.0
+	for i, charge := range []model.ChargeState{model.ChargeStateCharging, model.ChargeStateCharging, model.ChargeStateComplete} {
+		state := makeTestState()
+		state.UpdatedAt = start.Add(time.Duration(i) * time.Hour)
+		state.BatteryLevel = 50 + float64(i)*15
+		state.ChargeState = charge
+		state.ChargingRate = nil
+		if charge == model.ChargeStateCharging {
+			state.ChargingRate = &rate
+		}
+		cmd.record(ctx, state)
+	}
+
+	sessions, err := testStore.GetChargingSessions(ctx, "vehicle-123", time.Time{})
+	if err != nil {
+		t.Fatalf("GetChargingSessions failed: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 saved session, got %d", len(sessions))
+	}
+	if sessions[0].SOCDelta() != 30 || sessions[0].PeakRateKW != rate {
+		t.Errorf("session = %+v, want SOC delta 30 and peak %v kW", sessions[0], rate)
+	}
+}
+
+// fieldsClient is a mockClient that can also fetch part of the state
+type fieldsClient struct {
+	mockClient
+	full, partial int // Calls of each kind
+}
+
+func (c *fieldsClient) GetVehicleState(ctx context.Context, vehicleID string) (*rivian.VehicleState, error) {
+	c.full++
+	return c.mockClient.GetVehicleState(ctx, vehicleID)
+}
+
+func (c *fieldsClient) GetVehicleStateFields(ctx context.Context, vehicleID string, fields []rivian.StateField) (*rivian.VehicleState, error) {
+	c.partial++
+	return &rivian.VehicleState{VehicleID: vehicleID, UpdatedAt: time.Now(), BatteryLevel: 60}, nil
+}
+
+func TestWatchCommand_FetchStatePollsFields(t *testing.T) {
+	client := &fieldsClient{mockClient: mockClient{state: makeMockRivianState()}}
+	cmd := NewWatchCommand(client, nil, "vehicle-123", "", "", &bytes.Buffer{})
+	ctx := context.Background()
+
+	for i := 0; i < fullStatePolls+1; i++ {
+		state, err := cmd.fetchState(ctx)
+		if err != nil {
+			t.Fatalf("fetchState failed: %v", err)
+		}
+		if i == 1 && (state.BatteryLevel != 60 || state.TirePressures != client.state.TirePressures) {
+			t.Errorf("partial poll = battery %v tires %+v, want the polled battery on the full state", state.BatteryLevel, state.TirePressures)
+		}
+	}
+
+	// The first poll and every fullStatePolls-th are full fetches
+	if client.full != 2 || client.partial != fullStatePolls-1 {
+		t.Errorf("got %d full and %d partial fetches, want 2 and %d", client.full, client.partial, fullStatePolls-1)
+	}
+}
+
+func TestWatchCommand_OutputsReadyBy(t *testing.T) {
+	output := &bytes.Buffer{}
+	cmd := NewWatchCommand(&mockClient{state: makeMockRivianState()}, nil, "vehicle-123", "", "", output)
+	clock := 7 * time.Hour
+	cmd.readyBy, cmd.textOutput = &clock, true
+
+	formatter, err := NewFormatter(FormatText, FormatOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+	if _, err := cmd.fetchAndOutput(context.Background(), formatter); err != nil {
+		t.Fatalf("fetchAndOutput failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "Ready by") {
+		t.Errorf("output missing ready-by line:\n%s", output.String())
+	}
+}
+
+func TestWatchCommand_ApplyUpdateSkipsDuplicates(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	output := &bytes.Buffer{}
+	cmd := NewWatchCommand(&mockClient{}, testStore, "vehicle-123", "", "", output)
+
+	formatter, err := NewFormatter(FormatJSON, FormatOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+
+	update := map[string]interface{}{"batteryLevel": 42.0}
+	cmd.applyUpdate(ctx, formatter, update)
+	written := output.Len()
+	cmd.applyUpdate(ctx, formatter, map[string]interface{}{"batteryLevel": 42.0}) // repeated snapshot
+
+	if output.Len() != written {
+		t.Error("Expected duplicate update to produce no output")
+	}
+
+	history, err := testStore.GetStateHistory(ctx, "vehicle-123", time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetStateHistory failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("Expected 1 saved state, got %d", len(history))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	makeState := func(hour int, odometer, battery float64, charge model.ChargeState) *model.VehicleState {
+		state := makeTestState()
+		state.UpdatedAt = start.Add(time.Duration(hour) * time.Hour)
+		state.Odometer = odometer
+		state.BatteryLevel = battery
+		state.BatteryCapacity = 100
+		state.ChargeState = charge
+		state.ChargeLimit = 40
+		state.IsLocked = false
+		state.Doors.FrontLeft = model.ClosureStatusClosed
+		return state
+	}
+
+	// Newest first, as returned by the store
+	states := []*model.VehicleState{
+		makeState(4, 1040, 70, model.ChargeStateComplete),
+		makeState(3, 1040, 60, model.ChargeStateCharging),
+		makeState(2, 1040, 50, model.ChargeStateNotCharging),
+		makeState(1, 1020, 60, model.ChargeStateNotCharging),
+		makeState(0, 1000, 70, model.ChargeStateNotCharging),
+	}
+	states[3].Doors.FrontLeft = model.ClosureStatusOpen
+	states[4].Doors.FrontLeft = model.ClosureStatusOpen
+
+	summary := Summarize(states, nil)
+
+	if summary.Miles != 40 {
+		t.Errorf("Miles = %v, want 40", summary.Miles)
+	}
+	if summary.EnergyUsed != 20 {
+		t.Errorf("EnergyUsed = %v, want 20", summary.EnergyUsed)
+	}
+	if summary.Efficiency() != 2 {
+		t.Errorf("Efficiency = %v, want 2", summary.Efficiency())
+	}
+	if summary.Charges != 1 {
+		t.Errorf("Charges = %d, want 1 (rebuilt from states)", summary.Charges)
+	}
+	if len(summary.Issues) == 0 || summary.Issues[0] != (IssueCount{Issue: "Warning: One or more doors open", Count: 2}) {
+		t.Errorf("Issues = %+v, want open doors first with count 2", summary.Issues)
+	}
+
+	stored := []*model.ChargingSession{{EnergyAdded: 12}, {EnergyAdded: 8}}
+	if summary := Summarize(states, stored); summary.Charges != 2 || summary.EnergyAdded != 20 {
+		t.Errorf("with stored sessions: Charges = %d, EnergyAdded = %v, want 2 and 20", summary.Charges, summary.EnergyAdded)
+	}
+}
+
+func TestSummaryCommand_Run(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	state := makeTestState()
+	state.UpdatedAt = time.Now().Add(-time.Hour)
+	if err := testStore.SaveState(ctx, state); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		markdown bool
+		want     string
+	}{
+		{false, "Total miles:"},
+		{true, "| Total miles |"},
+	} {
+		output := &bytes.Buffer{}
+		cmd := NewSummaryCommand(testStore, state.VehicleID, output)
+		if err := cmd.Run(ctx, SummaryOptions{Period: 24 * time.Hour, Markdown: tt.markdown}); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !strings.Contains(output.String(), tt.want) {
+			t.Errorf("markdown=%v output missing %q:\n%s", tt.markdown, tt.want, output.String())
+		}
+	}
+
+	if err := NewSummaryCommand(nil, "v", &bytes.Buffer{}).Run(ctx, SummaryOptions{Period: time.Hour}); err == nil {
+		t.Error("Expected error without a store")
+	}
+}
+
+func TestSummaryCommand_MissingTelemetry(t *testing.T) {
+	testStore, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	// A sample without battery or range between two at 70% must not read
+	// as a drop to 0% and back
+	ctx := context.Background()
+	start := time.Now().Add(-3 * time.Hour)
+	for i, missing := range []bool{false, true, false} {
+		state := makeTestState()
+		state.UpdatedAt = start.Add(time.Duration(i) * time.Hour)
+		state.ChargeState = model.ChargeStateNotCharging
+		state.BatteryLevel, state.BatteryCapacity = 70, 100
+		if missing {
+			state.BatteryLevel, state.RangeEstimate = 0, 0
+			state.TelemetryMissing = true
+		}
+		if err := testStore.SaveState(ctx, state); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	states, err := testStore.GetStates(ctx, makeTestState().VehicleID, start.Add(-time.Minute), time.Now())
+	if err != nil {
+		t.Fatalf("GetStates failed: %v", err)
+	}
+	summary := Summarize(states, nil)
+	if summary.States != 2 || summary.EnergyUsed != 0 {
+		t.Errorf("summary = %d states, %.1f kWh used; want 2 states and no energy used", summary.States, summary.EnergyUsed)
+	}
+
+	output := &bytes.Buffer{}
+	cmd := NewSummaryCommand(testStore, makeTestState().VehicleID, output)
+	if err := cmd.Run(ctx, SummaryOptions{Period: 24 * time.Hour}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "States recorded: 2") || !strings.Contains(output.String(), "Avg efficiency:  N/A") {
+		t.Errorf("output should show no phantom usage:\n%s", output.String())
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	from := makeTestState()
+	to := makeTestState()
+	to.UpdatedAt = from.UpdatedAt.Add(3 * time.Hour)
+	to.BatteryLevel = 72.3
+	to.RangeEstimate = 215
+	to.Odometer = from.Odometer + 42.2
+	to.ChargeState = model.ChargeStateNotCharging
+	to.IsLocked = false
+	to.Doors.FrontLeft = model.ClosureStatusOpen
+	to.Windows.RearRight = model.ClosureStatusUnknown // Unknown isn't a change
+	to.Frunk = model.ClosureStatusOpen
+
+	diff := DiffStates(from, to)
+	if diff.BatteryLevel.Delta != -13.2 || diff.RangeMiles.Delta != -35 || diff.OdometerMiles.Delta != 42.2 {
+		t.Errorf("deltas = %v/%v/%v, want -13.2/-35/42.2",
+			diff.BatteryLevel.Delta, diff.RangeMiles.Delta, diff.OdometerMiles.Delta)
+	}
+	if diff.ChargeState == nil || diff.ChargeState.From != string(model.ChargeStateCharging) {
+		t.Errorf("ChargeState = %+v, want a change from charging", diff.ChargeState)
+	}
+	if diff.Locked == nil || !diff.Locked.From || diff.Locked.To {
+		t.Errorf("Locked = %+v, want locked → unlocked", diff.Locked)
+	}
+	wantClosures := []ClosureChange{
+		{Closure: "front left door", From: "closed", To: "open"},
+		{Closure: "frunk", From: "closed", To: "open"},
+	}
+	if len(diff.Closures) != len(wantClosures) {
+		t.Fatalf("Closures = %+v, want %+v", diff.Closures, wantClosures)
+	}
+	for i, want := range wantClosures {
+		if diff.Closures[i] != want {
+			t.Errorf("Closures[%d] = %+v, want %+v", i, diff.Closures[i], want)
+		}
+	}
+
+	same := DiffStates(from, from)
+	if same.ChargeState != nil || same.Locked != nil || len(same.Closures) != 0 || same.BatteryLevel.Delta != 0 {
+		t.Errorf("diff of a state with itself = %+v, want no changes", same)
+	}
+}
+
+func TestDiffCommand_Run(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	saveTestStates(t, testStore, ctx, now, 4, func(i int) float64 { return 90 - float64(i)*5 })
+
+	output := &bytes.Buffer{}
+	cmd := NewDiffCommand(testStore, "vehicle-123", output)
+	opts := DiffOptions{From: now.Add(10 * time.Minute), To: now.Add(3 * time.Hour), JSON: true}
+	if err := cmd.Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var diff DiffOutput
+	if err := json.Unmarshal(output.Bytes(), &diff); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output.String())
+	}
+	if diff.SchemaVersion != JSONSchemaVersion || !diff.From.Equal(now) || !diff.To.Equal(now.Add(3*time.Hour)) {
+		t.Errorf("diff = %+v, want states at %v and %v", diff, now, now.Add(3*time.Hour))
+	}
+	if diff.BatteryLevel.From != 90 || diff.BatteryLevel.To != 75 || diff.BatteryLevel.Delta != -15 {
+		t.Errorf("BatteryLevel = %+v, want 90 → 75 (-15)", diff.BatteryLevel)
+	}
+
+	// Times far from any state are noted in text output
+	output.Reset()
+	opts = DiffOptions{From: now.Add(-48 * time.Hour), To: now.Add(90 * time.Minute)}
+	if err := cmd.Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, want := range []string{"Battery:      90.0% → 85.0% (-5.0)", "Range:        200 mi (no change)", "Note: nearest state to --from"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, output.String())
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		cmd  *DiffCommand
+		opts DiffOptions
+	}{
+		{"no store", NewDiffCommand(nil, "vehicle-123", &bytes.Buffer{}), opts},
+		{"reversed times", cmd, DiffOptions{From: opts.To, To: opts.From}},
+		{"no states", NewDiffCommand(testStore, "other-vehicle", &bytes.Buffer{}), opts},
+	} {
+		if err := tt.cmd.Run(ctx, tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestInPlaceFormatter(t *testing.T) {
+	output := &bytes.Buffer{}
+	formatter := &inPlaceFormatter{Formatter: &TableFormatter{}}
+
+	if err := formatter.FormatState(output, makeTestState()); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	if strings.Contains(output.String(), "\x1b[") {
+		t.Error("First update should not move the cursor")
+	}
+
+	first := output.Len()
+	if err := formatter.FormatState(output, makeTestState()); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	// Header, separator and one row
+	if redraw := output.String()[first:]; !strings.HasPrefix(redraw, "\x1b[3F\x1b[J") {
+		t.Errorf("Expected redraw to move up 3 lines and clear, got %q", redraw)
+	}
+
+	// Messages printed between updates are cleared with the table
+	cmd := NewWatchCommand(&mockClient{}, nil, "vehicle-123", "", "", output)
+	cmd.inPlace = formatter
+	cmd.notify("WebSocket disconnected, reconnecting...\n")
+	second := output.Len()
+	if err := formatter.FormatState(output, makeTestState()); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	if redraw := output.String()[second:]; !strings.HasPrefix(redraw, "\x1b[4F\x1b[J") {
+		t.Errorf("Expected redraw to also clear the message line, got %q", redraw)
+	}
+}
+
+func TestWatchCommand_RefreshInPlaceRequiresTable(t *testing.T) {
+	cmd := NewWatchCommand(&mockClient{}, nil, "vehicle-123", "", "", &bytes.Buffer{})
+	err := cmd.Run(context.Background(), WatchOptions{Format: FormatJSON, RefreshInPlace: true, Interval: time.Second})
+	if err == nil || !strings.Contains(err.Error(), "table") {
+		t.Errorf("Expected table format error, got %v", err)
+	}
+}
+
+func TestExportCommand_Run_SplitByDay(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	// Two states on Jan 14 and three on Jan 15
+	ctx := context.Background()
+	start := time.Date(2025, 1, 14, 22, 0, 0, 0, time.UTC)
+	saveTestStates(t, testStore, ctx, start, 5, func(i int) float64 { return float64(80 - i) })
+
+	var buf bytes.Buffer
+	cmd := NewExportCommand(testStore, "vehicle-123", &buf)
+	opts := ExportOptions{
+		Format:     FormatCSV,
+		Since:      start,
+		Until:      start.Add(10 * time.Hour),
+		OutputPath: filepath.Join(tmpDir, "history.csv"),
+		SplitBy:    SplitDay,
+	}
+	if err := cmd.Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for name, wantRows := range map[string]int{"history-2025-01-14.csv": 2, "history-2025-01-15.csv": 3} {
+		data, err := os.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if !strings.Contains(lines[0], "BatteryLevel") || len(lines) != wantRows+1 {
+			t.Errorf("%s: got %d lines, want header + %d rows", name, len(lines), wantRows)
+		}
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("Expected report to mention %s, got %q", name, buf.String())
+		}
+	}
+
+	opts.OutputPath = ""
+	if err := cmd.Run(ctx, opts); err == nil {
+		t.Error("Expected error when splitting without an output path")
+	}
+}
+
+func TestWriteFile_FailureKeepsExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sessions.csv")
+	if err := os.WriteFile(path, []byte("previous export\n"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	err := writeFile(path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("writeFile should fail when the write does")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous export\n" {
+		t.Errorf("existing file = %q, want it untouched", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("got %d files, want the temporary file removed", len(entries))
+	}
+
+	if err := writeFile(path, func(w io.Writer) error { _, err := io.WriteString(w, "new export\n"); return err }); err != nil {
+		t.Fatalf("writeFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new export\n" {
+		t.Errorf("file = %q, want the new export", data)
+	}
+}
+
+func TestExportCommand_Run_SQLite(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	start := time.Now().Add(-10 * time.Hour).Truncate(time.Second)
+	saveTestStates(t, testStore, ctx, start, 5, func(i int) float64 { return float64(80 - i) })
+
+	var buf bytes.Buffer
+	cmd := NewExportCommand(testStore, "vehicle-123", &buf)
+	path := filepath.Join(tmpDir, "subset.db")
+	opts := ExportOptions{
+		Format:     FormatSQLite,
+		Since:      start.Add(2 * time.Hour),
+		OutputPath: path,
+		Redact:     Redaction{VIN: true},
+	}
+	if err := cmd.Run(ctx, opts); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Wrote 3 states to "+path) {
+		t.Errorf("Expected rows written report, got %q", buf.String())
+	}
+
+	subset, err := store.NewStore(path)
+	if err != nil {
+		t.Fatalf("open exported database: %v", err)
+	}
+	defer func() { _ = subset.Close() }()
+	latest, err := subset.GetLatestState(ctx, "vehicle-123")
+	if err != nil || latest == nil {
+		t.Fatalf("GetLatestState failed: %v", err)
+	}
+	if latest.VIN != "" {
+		t.Errorf("VIN should be redacted in the export, got %q", latest.VIN)
+	}
+
+	opts.OutputPath = ""
+	if err := cmd.Run(ctx, opts); err == nil {
+		t.Error("Expected error for sqlite export without an output path")
+	}
+}
+
+// lineWriter hands each write to a channel, so a test can wait for output
+// from a command running in another goroutine.
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestExportCommand_Run_Follow(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := time.Now().Add(-3 * time.Hour)
+	saveTestStates(t, testStore, ctx, now, 2, func(i int) float64 { return float64(80 - i) })
+
+	lines := make(lineWriter, 10)
+	opts := ExportOptions{Format: FormatNDJSON, Follow: true, FollowInterval: 10 * time.Millisecond}
+	done := make(chan error, 1)
+	go func() { done <- NewExportCommand(testStore, "vehicle-123", lines).Run(ctx, opts) }()
+
+	next := func() float64 {
+		t.Helper()
+		select {
+		case line := <-lines:
+			var out StatusOutput
+			if err := json.Unmarshal([]byte(line), &out); err != nil {
+				t.Fatalf("invalid ndjson line %q: %v", line, err)
+			}
+			return out.Metrics.BatteryLevel
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for output")
+			return 0
+		}
+	}
+
+	// Existing states oldest first, then each newly saved one
+	if first, second := next(), next(); first != 80 || second != 79 {
+		t.Errorf("existing states = %v, %v; want 80 then 79", first, second)
+	}
+	saveTestStates(t, testStore, context.Background(), now.Add(-time.Hour), 1, func(int) float64 { return 42 })
+	if got := next(); got != 42 {
+		t.Errorf("followed state battery = %v, want 42", got)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run returned %v after cancel, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	opts.Format = FormatCSV
+	if err := NewExportCommand(testStore, "vehicle-123", lines).Run(context.Background(), opts); err == nil {
+		t.Error("Expected error for follow with csv output")
+	}
+}
+
+func TestSplitPeriodKey(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	for period, want := range map[SplitPeriod]string{
+		SplitDay:   "2024-01-15",
+		SplitWeek:  "2024-W03",
+		SplitMonth: "2024-01",
+	} {
+		if got := period.key(ts); got != want {
+			t.Errorf("%s key = %q, want %q", period, got, want)
+		}
+	}
+	if err := SplitPeriod("year").Validate(); err == nil {
+		t.Error("Expected error for unknown split period")
+	}
+}
+
+func TestPlanCommand_Run(t *testing.T) {
+	// Mock state: 85.5% battery, 250 km (~155 mi) range
+	client := &mockClient{state: makeMockRivianState()}
+
+	var buf bytes.Buffer
+	cmd := NewPlanCommand(client, "vehicle-123", &buf)
+	if err := cmd.Run(context.Background(), PlanOptions{Distance: 100, Margin: 10}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, want := range []string{"Distance:  100 mi", "Arrival:   30% (55 mi remaining)", "✓ Reachable"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	err := cmd.Run(context.Background(), PlanOptions{Distance: 140, Margin: 10})
+	if !errors.Is(err, ErrBatteryBelow) {
+		t.Fatalf("Run() error = %v, want ErrBatteryBelow", err)
+	}
+	for _, want := range []string{"✗ Charge before leaving", "Arrival range critical"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if err := cmd.Run(context.Background(), PlanOptions{Distance: 0, Margin: 10}); err == nil {
+		t.Error("Run() should reject a zero distance")
+	}
+}
+
+// compareClient answers GetVehicleState per vehicle, failing for unknown ones
+type compareClient struct {
+	mockClient
+	states map[string]*rivian.VehicleState
+}
+
+func (c *compareClient) GetVehicleState(ctx context.Context, vehicleID string) (*rivian.VehicleState, error) {
+	if state, ok := c.states[vehicleID]; ok {
+		return state, nil
+	}
+	return nil, fmt.Errorf("vehicle %s is asleep", vehicleID)
+}
+
+func TestCompareCommand_Run(t *testing.T) {
+	tmpDir := t.TempDir()
+	testStore, err := store.NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	// The second vehicle drove 30 miles this week but is offline now
+	ctx := context.Background()
+	start := time.Now().Add(-48 * time.Hour)
+	for i := 0; i < 4; i++ {
+		state := &model.VehicleState{
+			VehicleID:       "vehicle-456",
+			UpdatedAt:       start.Add(time.Duration(i) * time.Hour),
+			BatteryLevel:    float64(70 - 5*i),
+			BatteryCapacity: 100,
+			RangeEstimate:   float64(210 - 15*i),
+			Odometer:        float64(1000 + 10*i),
+			ChargeLimit:     90,
+		}
+		if err := testStore.SaveState(ctx, state); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	client := &compareClient{states: map[string]*rivian.VehicleState{"vehicle-123": makeMockRivianState()}}
+	vehicles := []rivian.Vehicle{
+		{ID: "vehicle-123", Name: "Truck", Model: "R1T"},
+		{ID: "vehicle-456", Name: "Family", Model: "R1S"},
+	}
+
+	var buf bytes.Buffer
+	cmd := NewCompareCommand(client, testStore, vehicles, &buf)
+	if err := cmd.Run(ctx, CompareOptions{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := buf.String()
+	lines := strings.Split(output, "\n")
+	if !strings.Contains(lines[0], "Truck") || !strings.Contains(lines[0], "Family") {
+		t.Errorf("header should name both vehicles, got %q", lines[0])
+	}
+	for _, want := range []string{
+		"Miles (7d)",
+		"30.0",        // Driven by the cached vehicle
+		"2.00 mi/kWh", // 30 miles on 15 kWh
+		"55%",         // Cached battery level
+		"165 miles",   // Cached range, in the same units as status
+		"1030.0 miles",
+		"Note: R1S Family",
+		"live state unavailable, showing cached",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	// Live states are saved like status does
+	latest, err := testStore.GetLatestState(ctx, "vehicle-123")
+	if err != nil || latest == nil {
+		t.Errorf("live state should be saved, got %v, %v", latest, err)
+	}
+}
+
+func TestCompareCommand_SingleVehicle(t *testing.T) {
+	client := &compareClient{states: map[string]*rivian.VehicleState{"vehicle-123": makeMockRivianState()}}
+	vehicles := []rivian.Vehicle{{ID: "vehicle-123", Name: "Truck"}}
+
+	var buf bytes.Buffer
+	if err := NewCompareCommand(client, nil, vehicles, &buf).Run(context.Background(), CompareOptions{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Only one vehicle to compare") || !strings.Contains(output, "Truck") {
+		t.Errorf("single vehicle should be shown alone:\n%s", output)
+	}
+	if !strings.Contains(output, "state storage disabled") {
+		t.Errorf("missing history note without a store:\n%s", output)
+	}
+}
+
+func TestCompareCommand_NoState(t *testing.T) {
+	client := &compareClient{}
+	vehicles := []rivian.Vehicle{{ID: "a"}, {ID: "b"}}
+
+	err := NewCompareCommand(client, nil, vehicles, &bytes.Buffer{}).Run(context.Background(), CompareOptions{})
+	if err == nil || !strings.Contains(err.Error(), "asleep") {
+		t.Errorf("expected an error naming the failure, got %v", err)
+	}
+
+	err = NewCompareCommand(client, nil, vehicles, &bytes.Buffer{}).Run(context.Background(), CompareOptions{Offline: true})
+	if err == nil || !strings.Contains(err.Error(), "--no-store") {
+		t.Errorf("offline without a store should fail, got %v", err)
 	}
 }
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// DefaultComparePeriod is the window compare-vehicles aggregates driving over
+const DefaultComparePeriod = 7 * 24 * time.Hour
+
+// CompareOptions configures the compare-vehicles command
+type CompareOptions struct {
+	Period    time.Duration // Window for miles driven, efficiency and charges
+	Offline   bool          // Use cached states instead of live queries
+	LocalTime bool          // Show update times in the local zone
+}
+
+// CompareCommand shows vehicles side by side, one column each
+type CompareCommand struct {
+	client   rivian.Client
+	store    *store.Store
+	vehicles []rivian.Vehicle
+	output   io.Writer
+}
+
+// NewCompareCommand creates a new compare-vehicles command for the given
+// vehicles, usually two
+func NewCompareCommand(client rivian.Client, store *store.Store, vehicles []rivian.Vehicle, output io.Writer) *CompareCommand {
+	return &CompareCommand{
+		client:   client,
+		store:    store,
+		vehicles: vehicles,
+		output:   output,
+	}
+}
+
+// vehicleComparison is one column of the comparison.
+type vehicleComparison struct {
+	vehicle rivian.Vehicle
+	state   *model.VehicleState // nil when no state could be loaded
+	note    string              // why the state is missing or stale
+	summary *Summary            // nil without a store
+}
+
+// Run executes the compare-vehicles command. A vehicle whose live state
+// can't be fetched falls back to its latest stored state; only when no
+// vehicle has any state is it an error.
+func (c *CompareCommand) Run(ctx context.Context, opts CompareOptions) error {
+	if len(c.vehicles) == 0 {
+		return fmt.Errorf("no vehicles to compare")
+	}
+	if opts.Offline && c.store == nil {
+		return fmt.Errorf("offline mode requires state storage (--no-store conflicts with --offline)")
+	}
+	if opts.Period <= 0 {
+		opts.Period = DefaultComparePeriod
+	}
+
+	end := time.Now()
+	start := end.Add(-opts.Period)
+
+	columns := make([]vehicleComparison, len(c.vehicles))
+	loaded := 0
+	for i, vehicle := range c.vehicles {
+		column, err := c.compareVehicle(ctx, vehicle, opts.Offline, start, end)
+		if err != nil {
+			return err
+		}
+		if column.state != nil {
+			loaded++
+		}
+		columns[i] = column
+	}
+	if loaded == 0 {
+		return fmt.Errorf("no state available for any vehicle: %s", columns[0].note)
+	}
+
+	if len(columns) == 1 {
+		_, _ = fmt.Fprintln(c.output, "Only one vehicle to compare; showing it alone.")
+		_, _ = fmt.Fprintln(c.output)
+	}
+	return writeComparison(c.output, columns, opts)
+}
+
+// compareVehicle loads one vehicle's state and period summary. Errors are
+// only returned for store failures; a missing state is noted instead.
+func (c *CompareCommand) compareVehicle(ctx context.Context, vehicle rivian.Vehicle, offline bool, start, end time.Time) (vehicleComparison, error) {
+	column := vehicleComparison{vehicle: vehicle}
+
+	if !offline {
+		rivState, err := c.client.GetVehicleState(ctx, vehicle.ID)
+		if err == nil {
+			reducer := model.NewReducer()
+			reducer.Dispatch(model.VehicleListReceived{Vehicles: []rivian.Vehicle{vehicle}, VehicleID: vehicle.ID})
+			column.state = reducer.Dispatch(model.VehicleStateReceived{State: rivState})
+			if c.store != nil {
+				if err := c.store.SaveState(ctx, column.state); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to save state: %v\n", err)
+				}
+			}
+		} else {
+			column.note = fmt.Sprintf("live state unavailable: %v", err)
+		}
+	}
+
+	if c.store == nil {
+		return column, nil
+	}
+
+	if column.state == nil {
+		cached, err := c.store.GetLatestState(ctx, vehicle.ID)
+		if err != nil {
+			return column, fmt.Errorf("get cached state: %w", err)
+		}
+		switch {
+		case cached != nil && column.note != "":
+			column.note = "live state unavailable, showing cached"
+			column.state = cached
+		case cached != nil:
+			column.state = cached
+		case column.note == "":
+			column.note = "no cached state"
+		}
+	}
+
+	states, err := c.store.GetStates(ctx, vehicle.ID, start, end)
+	if err != nil {
+		return column, fmt.Errorf("query states: %w", err)
+	}
+	sessions, err := c.store.GetChargingSessions(ctx, vehicle.ID, start)
+	if err != nil {
+		return column, fmt.Errorf("query charging sessions: %w", err)
+	}
+	column.summary = Summarize(states, sessions)
+
+	return column, nil
+}
+
+// compareLabel names a vehicle in a column heading.
+func compareLabel(v rivian.Vehicle) string {
+	label := strings.TrimSpace(v.Description() + " " + v.Name)
+	if label == "" {
+		label = v.ID
+	}
+	return label
+}
+
+// writeComparison renders one row per metric and one column per vehicle.
+func writeComparison(w io.Writer, columns []vehicleComparison, opts CompareOptions) error {
+	days := fmt.Sprintf("%.0fd", opts.Period.Hours()/24)
+	rows := [][]string{{""}}
+	for _, col := range columns {
+		rows[0] = append(rows[0], compareLabel(col.vehicle))
+	}
+
+	metric := func(label string, value func(col vehicleComparison) string) {
+		row := []string{label}
+		for _, col := range columns {
+			row = append(row, value(col))
+		}
+		rows = append(rows, row)
+	}
+	fromState := func(format func(s *model.VehicleState) string) func(vehicleComparison) string {
+		return func(col vehicleComparison) string {
+			if col.state == nil {
+				return "N/A"
+			}
+			return format(col.state)
+		}
+	}
+	fromSummary := func(format func(s *Summary) string) func(vehicleComparison) string {
+		return func(col vehicleComparison) string {
+			if col.summary == nil || col.summary.States == 0 {
+				return "N/A"
+			}
+			return format(col.summary)
+		}
+	}
+
+	metric("Battery", fromState(func(s *model.VehicleState) string { return fmt.Sprintf("%.0f%%", s.BatteryLevel) }))
+	metric("Range", fromState(func(s *model.VehicleState) string { return formatRange(s.RangeEstimate) }))
+	metric("Charge limit", fromState(func(s *model.VehicleState) string { return fmt.Sprintf("%d%%", s.ChargeLimit) }))
+	metric("Charge state", fromState(func(s *model.VehicleState) string {
+		if s.ChargeState == "" {
+			return "N/A"
+		}
+		return string(s.ChargeState)
+	}))
+	metric("Odometer", fromState(func(s *model.VehicleState) string { return formatOdometer(s.Odometer) }))
+	metric("Miles ("+days+")", fromSummary(func(s *Summary) string { return fmt.Sprintf("%.1f", s.Miles) }))
+	metric("Efficiency ("+days+")", fromSummary(func(s *Summary) string {
+		if e := s.Efficiency(); e > 0 {
+			return fmt.Sprintf("%.2f mi/kWh", e)
+		}
+		return "N/A"
+	}))
+	metric("Charges ("+days+")", fromSummary(func(s *Summary) string { return fmt.Sprintf("%d", s.Charges) }))
+	metric("Updated", fromState(func(s *model.VehicleState) string {
+		return displayTime(s.UpdatedAt, opts.LocalTime).Format(tableTimeLayout)
+	}))
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-len([]rune(cell))))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	var notes []string
+	for _, col := range columns {
+		if col.note != "" {
+			notes = append(notes, fmt.Sprintf("%s: %s", compareLabel(col.vehicle), col.note))
+		}
+	}
+	if columns[0].summary == nil {
+		notes = append(notes, "no history to aggregate (state storage disabled)")
+	}
+	if len(notes) > 0 {
+		b.WriteString("\n")
+		for _, note := range notes {
+			fmt.Fprintf(&b, "Note: %s\n", note)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+// csvField sets one VehicleState field from a CSV value.
+type csvField func(state *model.VehicleState, value string) error
+
+// csvFields maps lower-cased CSVFormatter header names to their fields.
+var csvFields = map[string]csvField{
+	"timestamp": func(s *model.VehicleState, v string) (err error) {
+		s.UpdatedAt, err = parseCSVTime(v)
+		return err
+	},
+	"vehicleid": func(s *model.VehicleState, v string) error { s.VehicleID = csvUnsafe(v); return nil },
+	"vin":       func(s *model.VehicleState, v string) error { s.VIN = csvUnsafe(v); return nil },
+	"name":      func(s *model.VehicleState, v string) error { s.Name = csvUnsafe(v); return nil },
+	"model":     func(s *model.VehicleState, v string) error { s.Model = csvUnsafe(v); return nil },
+	"batterylevel": func(s *model.VehicleState, v string) (err error) {
+		s.BatteryLevel, err = parseCSVFloat(v)
+		return err
+	},
+	"rangeestimate": func(s *model.VehicleState, v string) (err error) {
+		s.RangeEstimate, err = parseCSVFloat(v)
+		return err
+	},
+	"rangestatus": func(s *model.VehicleState, v string) error {
+		s.RangeStatus = model.RangeStatus(strings.TrimSpace(v))
+		return nil
+	},
+	"chargestate": func(s *model.VehicleState, v string) error {
+		s.ChargeState = model.ChargeState(strings.TrimSpace(v))
+		return nil
+	},
+	"chargelimit": func(s *model.VehicleState, v string) error {
+		if v = strings.TrimSpace(v); v == "" {
+			return nil
+		}
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		s.ChargeLimit = limit
+		return nil
+	},
+	"chargingrate": func(s *model.VehicleState, v string) (err error) {
+		s.ChargingRate, err = parseCSVFloatPtr(v)
+		return err
+	},
+	"islocked": func(s *model.VehicleState, v string) (err error) {
+		s.IsLocked, err = parseCSVBool(v)
+		return err
+	},
+	"isonline": func(s *model.VehicleState, v string) (err error) {
+		s.IsOnline, err = parseCSVBool(v)
+		return err
+	},
+	"latitude": func(s *model.VehicleState, v string) error {
+		return setCSVCoordinate(s, v, func(loc *model.Location, f float64) { loc.Latitude = f })
+	},
+	"longitude": func(s *model.VehicleState, v string) error {
+		return setCSVCoordinate(s, v, func(loc *model.Location, f float64) { loc.Longitude = f })
+	},
+	"cabintemp": func(s *model.VehicleState, v string) (err error) {
+		s.CabinTemp, err = parseCSVFloatPtr(v)
+		return err
+	},
+	"exteriortemp": func(s *model.VehicleState, v string) (err error) {
+		s.ExteriorTemp, err = parseCSVFloatPtr(v)
+		return err
+	},
+	"odometer": func(s *model.VehicleState, v string) (err error) {
+		s.Odometer, err = parseCSVFloat(v)
+		return err
+	},
+	"readyscore": func(s *model.VehicleState, v string) (err error) {
+		s.ReadyScore, err = parseCSVFloatPtr(v)
+		return err
+	},
+}
+
+// csvRequiredColumns must be present for a row to identify a state.
+var csvRequiredColumns = []string{"Timestamp", "VehicleID"}
+
+// ReadCSVStates parses CSV in the layout CSVFormatter writes back into
+// states. Columns are matched by header name, ignoring case, in any order;
+// unknown columns are skipped and missing ones keep their zero value, but
+// Timestamp and VehicleID are required. Rows may be shorter than the
+// header. Text is kept as written (quoted fields may hold commas, quotes
+// and newlines); surrounding spaces are ignored elsewhere. Invalid values
+// are reported by line and column, all at once.
+func ReadCSVStates(r io.Reader) ([]*model.VehicleState, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Tolerate rows with missing trailing columns
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read CSV: no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	fields := make([]csvField, len(header))
+	found := make(map[string]bool, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // Excel adds a BOM
+		fields[i] = csvFields[key]
+		found[key] = fields[i] != nil
+	}
+	for _, name := range csvRequiredColumns {
+		if !found[strings.ToLower(name)] {
+			return nil, fmt.Errorf("read CSV: missing required column %s", name)
+		}
+	}
+
+	var states []*model.VehicleState
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Malformed quoting; csv.ParseError already names the line
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+
+		state := &model.VehicleState{}
+		rowErrs := len(errs)
+		for i, value := range record {
+			if i >= len(fields) || fields[i] == nil {
+				continue
+			}
+			if err := fields[i](state, value); err != nil {
+				line, _ := reader.FieldPos(i)
+				errs = append(errs, fmt.Errorf("line %d: %s: %w", line, header[i], err))
+			}
+		}
+		if len(errs) > rowErrs {
+			continue
+		}
+		if state.VehicleID == "" || state.UpdatedAt.IsZero() {
+			line, _ := reader.FieldPos(0)
+			errs = append(errs, fmt.Errorf("line %d: Timestamp and VehicleID must not be empty", line))
+			continue
+		}
+		states = append(states, state)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("read CSV: %w", errors.Join(errs...))
+	}
+	return states, nil
+}
+
+// csvTimeLayouts are the timestamp layouts TimeFormat can produce other than
+// unix seconds, tried in order. Custom layouts can't be recognized.
+var csvTimeLayouts = []string{time.RFC3339Nano, tableTimeLayout}
+
+// parseCSVTime parses an RFC3339, unix or "local" preset timestamp; the
+// latter has no zone and is read as local time.
+func parseCSVTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q: expected RFC3339, unix seconds or \"2006-01-02 15:04:05\"", v)
+}
+
+func parseCSVFloat(v string) (float64, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", v)
+	}
+	return f, nil
+}
+
+// parseCSVFloatPtr parses an optional number; empty means not reported.
+func parseCSVFloatPtr(v string) (*float64, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	f, err := parseCSVFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseCSVBool(v string) (bool, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q", v)
+	}
+	return b, nil
+}
+
+// setCSVCoordinate sets one coordinate of the state's location, creating
+// it on the first non-empty value.
+func setCSVCoordinate(s *model.VehicleState, v string, set func(*model.Location, float64)) error {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	f, err := parseCSVFloat(v)
+	if err != nil {
+		return err
+	}
+	if s.Location == nil {
+		s.Location = &model.Location{}
+	}
+	set(s.Location, f)
+	return nil
+}
+
+// csvUnsafe undoes csvSafe: it drops the quote prefixed to text that starts
+// like a formula.
+func csvUnsafe(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
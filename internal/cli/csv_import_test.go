@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+func TestReadCSVStates_RoundTrip(t *testing.T) {
+	full := makeTestState()
+	full.Name = `Road "Trip", 2nd` // Quotes and a comma need CSV quoting
+	full.VIN = "=HYPERLINK(1)"     // Escaped by csvSafe on export
+	exteriorTemp := -3.5
+	full.ExteriorTemp = &exteriorTemp
+
+	sparse := makeTestState()
+	sparse.UpdatedAt = full.UpdatedAt.Add(time.Hour)
+	sparse.Name = "Line one\nline two"
+	sparse.Location = nil
+	sparse.ChargingRate = nil
+	sparse.CabinTemp = nil
+	sparse.ReadyScore = nil
+	sparse.IsLocked = false
+
+	for _, timeFormat := range []TimeFormat{"", TimeFormatRFC3339, TimeFormatUnix, TimeFormatLocal} {
+		t.Run(string(timeFormat), func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := &CSVFormatter{TimeFormat: timeFormat}
+			if err := formatter.FormatStates(&buf, []*model.VehicleState{full, sparse}); err != nil {
+				t.Fatalf("FormatStates failed: %v", err)
+			}
+
+			states, err := ReadCSVStates(&buf)
+			if err != nil {
+				t.Fatalf("ReadCSVStates failed: %v", err)
+			}
+			if len(states) != 2 {
+				t.Fatalf("got %d states, want 2", len(states))
+			}
+			for i, want := range []*model.VehicleState{full, sparse} {
+				assertCSVRoundTrip(t, states[i], want)
+			}
+		})
+	}
+}
+
+// assertCSVRoundTrip compares the fields CSV export carries.
+func assertCSVRoundTrip(t *testing.T, got, want *model.VehicleState) {
+	t.Helper()
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+	for _, f := range []struct{ name, got, want string }{
+		{"VehicleID", got.VehicleID, want.VehicleID},
+		{"VIN", got.VIN, want.VIN},
+		{"Name", got.Name, want.Name},
+		{"Model", got.Model, want.Model},
+		{"RangeStatus", string(got.RangeStatus), string(want.RangeStatus)},
+		{"ChargeState", string(got.ChargeState), string(want.ChargeState)},
+	} {
+		if f.got != f.want {
+			t.Errorf("%s = %q, want %q", f.name, f.got, f.want)
+		}
+	}
+	if got.BatteryLevel != want.BatteryLevel || got.RangeEstimate != want.RangeEstimate || got.Odometer != want.Odometer {
+		t.Errorf("battery/range/odometer = %v/%v/%v, want %v/%v/%v",
+			got.BatteryLevel, got.RangeEstimate, got.Odometer, want.BatteryLevel, want.RangeEstimate, want.Odometer)
+	}
+	if got.ChargeLimit != want.ChargeLimit || got.IsLocked != want.IsLocked || got.IsOnline != want.IsOnline {
+		t.Errorf("limit/locked/online = %v/%v/%v, want %v/%v/%v",
+			got.ChargeLimit, got.IsLocked, got.IsOnline, want.ChargeLimit, want.IsLocked, want.IsOnline)
+	}
+	for _, f := range []struct {
+		name      string
+		got, want *float64
+	}{
+		{"ChargingRate", got.ChargingRate, want.ChargingRate},
+		{"CabinTemp", got.CabinTemp, want.CabinTemp},
+		{"ExteriorTemp", got.ExteriorTemp, want.ExteriorTemp},
+		{"ReadyScore", got.ReadyScore, want.ReadyScore},
+	} {
+		if (f.got == nil) != (f.want == nil) || f.got != nil && *f.got != *f.want {
+			t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+		}
+	}
+	if (got.Location == nil) != (want.Location == nil) || got.Location != nil && *got.Location != *want.Location {
+		t.Errorf("Location = %+v, want %+v", got.Location, want.Location)
+	}
+}
+
+func TestReadCSVStates_ColumnsByName(t *testing.T) {
+	// Reordered and lower-case headers, an unknown column, and no location
+	// or temperature columns; the last row is short
+	input := "batterylevel,Notes,VEHICLEID,Timestamp,IsLocked\n" +
+		`81.5,"ignored, quoted",veh-1,2024-01-15T10:00:00Z,true` + "\n" +
+		"79,,veh-1,1705316400\n"
+
+	states, err := ReadCSVStates(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadCSVStates failed: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("got %d states, want 2", len(states))
+	}
+	if states[0].BatteryLevel != 81.5 || states[0].VehicleID != "veh-1" || !states[0].IsLocked {
+		t.Errorf("first state = %+v", states[0])
+	}
+	if states[1].BatteryLevel != 79 || states[1].IsLocked || states[1].Location != nil || states[1].CabinTemp != nil {
+		t.Errorf("second state = %+v", states[1])
+	}
+	if want := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC); !states[1].UpdatedAt.Equal(want) {
+		t.Errorf("unix timestamp parsed as %v, want %v", states[1].UpdatedAt, want)
+	}
+}
+
+func TestReadCSVStates_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "empty input",
+			input: "",
+			want:  []string{"no header row"},
+		},
+		{
+			name:  "missing required column",
+			input: "Timestamp,BatteryLevel\n2024-01-15T10:00:00Z,80\n",
+			want:  []string{"missing required column VehicleID"},
+		},
+		{
+			name: "invalid values on several rows",
+			input: "Timestamp,VehicleID,BatteryLevel,IsOnline\n" +
+				"2024-01-15T10:00:00Z,veh-1,eighty,true\n" +
+				"yesterday,veh-1,80,maybe\n" +
+				"2024-01-15T12:00:00Z,,80,true\n",
+			want: []string{
+				`line 2: BatteryLevel: invalid number "eighty"`,
+				`line 3: Timestamp: invalid timestamp "yesterday"`,
+				`line 3: IsOnline: invalid boolean "maybe"`,
+				"line 4: Timestamp and VehicleID must not be empty",
+			},
+		},
+		{
+			name:  "unterminated quote",
+			input: "Timestamp,VehicleID,Name\n2024-01-15T10:00:00Z,veh-1,\"My R1T\n",
+			want:  []string{"read CSV", "extraneous or missing \" in quoted-field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states, err := ReadCSVStates(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatalf("expected an error, got %d states", len(states))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q should contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// diffGapNote is how far a matched state may be from the requested time
+// before the diff points it out.
+const diffGapNote = time.Hour
+
+// DiffOptions configures the diff command
+type DiffOptions struct {
+	From      time.Time // Compare the stored state nearest to this time...
+	To        time.Time // ...with the one nearest to this time
+	JSON      bool      // Emit a structured diff object instead of text
+	LocalTime bool      // Show state times in the local zone
+}
+
+// DiffCommand compares two stored states of one vehicle
+type DiffCommand struct {
+	store     *store.Store
+	vehicleID string
+	output    io.Writer
+}
+
+// NewDiffCommand creates a new diff command
+func NewDiffCommand(store *store.Store, vehicleID string, output io.Writer) *DiffCommand {
+	return &DiffCommand{
+		store:     store,
+		vehicleID: vehicleID,
+		output:    output,
+	}
+}
+
+// Run executes the diff command
+func (c *DiffCommand) Run(ctx context.Context, opts DiffOptions) error {
+	if c.store == nil {
+		return fmt.Errorf("store not available for diff")
+	}
+	if !opts.From.Before(opts.To) {
+		return fmt.Errorf("--from must be before --to")
+	}
+
+	from, err := c.store.GetStateNearest(ctx, c.vehicleID, opts.From)
+	if err != nil {
+		return fmt.Errorf("get state near --from: %w", err)
+	}
+	if from == nil {
+		return fmt.Errorf("no stored states for this vehicle")
+	}
+	to, err := c.store.GetStateNearest(ctx, c.vehicleID, opts.To)
+	if err != nil {
+		return fmt.Errorf("get state near --to: %w", err)
+	}
+
+	diff := DiffStates(from, to)
+	if opts.JSON {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal diff: %w", err)
+		}
+		_, err = fmt.Fprintf(c.output, "%s\n", data)
+		return err
+	}
+	return writeDiffText(c.output, diff, opts)
+}
+
+// DiffOutput is the stable JSON shape of the change between two states.
+type DiffOutput struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	VehicleID     string          `json:"vehicleId"`
+	From          time.Time       `json:"from"` // Timestamp of the earlier state
+	To            time.Time       `json:"to"`   // Timestamp of the later state
+	BatteryLevel  NumberChange    `json:"batteryLevel"`
+	RangeMiles    NumberChange    `json:"rangeMiles"`
+	OdometerMiles NumberChange    `json:"odometerMiles"`
+	ChargeState   *ValueChange    `json:"chargeState,omitempty"` // nil if unchanged
+	Locked        *LockChange     `json:"locked,omitempty"`      // nil if unchanged
+	Closures      []ClosureChange `json:"closures"`
+}
+
+// NumberChange is a metric's value in both states and the difference.
+type NumberChange struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Delta float64 `json:"delta"`
+}
+
+// ValueChange is a status that differs between the two states.
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// LockChange records the vehicle being locked or unlocked.
+type LockChange struct {
+	From bool `json:"from"`
+	To   bool `json:"to"`
+}
+
+// ClosureChange records a closure that opened or closed.
+type ClosureChange struct {
+	Closure string `json:"closure"` // e.g. "front left door"
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// DiffStates computes the field-by-field change from one state to a later
+// one. Closures whose status is unknown in either state are left out, as
+// are closures the vehicle doesn't have.
+func DiffStates(from, to *model.VehicleState) *DiffOutput {
+	diff := &DiffOutput{
+		SchemaVersion: JSONSchemaVersion,
+		VehicleID:     to.VehicleID,
+		From:          from.UpdatedAt,
+		To:            to.UpdatedAt,
+		BatteryLevel:  numberChange(from.BatteryLevel, to.BatteryLevel),
+		RangeMiles:    numberChange(from.RangeEstimate, to.RangeEstimate),
+		OdometerMiles: numberChange(from.Odometer, to.Odometer),
+		Closures:      []ClosureChange{},
+	}
+	if from.ChargeState != to.ChargeState {
+		diff.ChargeState = &ValueChange{From: string(from.ChargeState), To: string(to.ChargeState)}
+	}
+	if from.IsLocked != to.IsLocked {
+		diff.Locked = &LockChange{From: from.IsLocked, To: to.IsLocked}
+	}
+
+	closure := func(name string, before, after model.ClosureStatus) {
+		if before == after || !knownClosure(before) || !knownClosure(after) {
+			return
+		}
+		diff.Closures = append(diff.Closures, ClosureChange{Closure: name, From: string(before), To: string(after)})
+	}
+	corners := func(kind string, before, after model.Closures) {
+		closure("front left "+kind, before.FrontLeft, after.FrontLeft)
+		closure("front right "+kind, before.FrontRight, after.FrontRight)
+		closure("rear left "+kind, before.RearLeft, after.RearLeft)
+		closure("rear right "+kind, before.RearRight, after.RearRight)
+	}
+	corners("door", from.Doors, to.Doors)
+	corners("window", from.Windows, to.Windows)
+
+	profile := to.Profile()
+	if profile.HasFrunk {
+		closure("frunk", from.Frunk, to.Frunk)
+	}
+	if profile.HasLiftgate {
+		closure("liftgate", from.Liftgate, to.Liftgate)
+	}
+	before, hadTonneau := from.Tonneau()
+	after, hasTonneau := to.Tonneau()
+	if hadTonneau && hasTonneau {
+		closure("tonneau cover", before, after)
+	}
+
+	return diff
+}
+
+// numberChange rounds the delta to hide floating point noise.
+func numberChange(from, to float64) NumberChange {
+	return NumberChange{From: from, To: to, Delta: math.Round((to-from)*10) / 10}
+}
+
+func knownClosure(status model.ClosureStatus) bool {
+	return status == model.ClosureStatusOpen || status == model.ClosureStatusClosed
+}
+
+// writeDiffText renders a diff as aligned "before → after (delta)" lines.
+func writeDiffText(w io.Writer, diff *DiffOutput, opts DiffOptions) error {
+	at := func(t time.Time) string { return displayTime(t, opts.LocalTime).Format(tableTimeLayout) }
+
+	_, _ = fmt.Fprintf(w, "From %s to %s (%s)\n\n", at(diff.From), at(diff.To), model.FormatAge(diff.To.Sub(diff.From)))
+	_, _ = fmt.Fprintf(w, "Battery:      %s\n", formatNumberChange(diff.BatteryLevel, "%.1f%%"))
+	_, _ = fmt.Fprintf(w, "Range:        %s\n", formatNumberChange(diff.RangeMiles, "%.0f mi"))
+	_, _ = fmt.Fprintf(w, "Odometer:     %s\n", formatNumberChange(diff.OdometerMiles, "%.1f mi"))
+	if diff.ChargeState != nil {
+		_, _ = fmt.Fprintf(w, "Charge state: %s → %s\n", diff.ChargeState.From, diff.ChargeState.To)
+	} else {
+		_, _ = fmt.Fprintf(w, "Charge state: unchanged\n")
+	}
+	if diff.Locked != nil {
+		_, _ = fmt.Fprintf(w, "Lock:         %s → %s\n", formatLockStatus(diff.Locked.From), formatLockStatus(diff.Locked.To))
+	} else {
+		_, _ = fmt.Fprintf(w, "Lock:         unchanged\n")
+	}
+
+	if len(diff.Closures) == 0 {
+		_, _ = fmt.Fprintf(w, "Closures:     unchanged\n")
+	} else {
+		_, _ = fmt.Fprintf(w, "Closures:\n")
+		for _, change := range diff.Closures {
+			_, _ = fmt.Fprintf(w, "  %s: %s → %s\n", change.Closure, change.From, change.To)
+		}
+	}
+
+	// The nearest states may be far from what was asked for
+	var notes []string
+	if diff.From.Equal(diff.To) {
+		notes = append(notes, "both times matched the same stored state")
+	}
+	for _, gap := range []struct {
+		flag           string
+		asked, matched time.Time
+	}{{"--from", opts.From, diff.From}, {"--to", opts.To, diff.To}} {
+		if d := gap.matched.Sub(gap.asked).Abs(); d > diffGapNote {
+			notes = append(notes, fmt.Sprintf("nearest state to %s is %s away", gap.flag, model.FormatAge(d)))
+		}
+	}
+	if len(notes) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, note := range notes {
+			_, _ = fmt.Fprintf(w, "Note: %s\n", note)
+		}
+	}
+	return nil
+}
+
+// formatNumberChange renders "80.0% → 72.0% (-8.0)", or the value and
+// "(no change)" when it didn't change.
+func formatNumberChange(c NumberChange, layout string) string {
+	if c.Delta == 0 {
+		return fmt.Sprintf(layout+" (no change)", c.To)
+	}
+	return fmt.Sprintf(layout+" → "+layout+" (%+.1f)", c.From, c.To, c.Delta)
+}
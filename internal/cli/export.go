@@ -4,6 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/model"
@@ -17,6 +23,63 @@ type ExportOptions struct {
 	Since  time.Time // Start time for export
 	Until  time.Time // End time for export
 	Limit  int       // Maximum number of records
+	// MaxRows refuses --since/--until exports larger than this in formats
+	// that must buffer every state (JSON, YAML, split files); 0 = no cap.
+	// CSV and NDJSON stream and ignore it.
+	MaxRows int
+
+	TimeFormat TimeFormat // Timestamp rendering for CSV/table output
+	LocalTime  bool       // Show timestamps in the local zone (presentation only)
+	Redact     Redaction  // Hide location/VIN in the output
+	Summary    bool       // Add min/max/avg rows to table output
+
+	OutputPath string      // Write to this file instead of the command output
+	SplitBy    SplitPeriod // Write one file per period, named after OutputPath
+
+	// Follow keeps writing newly saved states after the existing ones, like
+	// tail -f, until the context is canceled or an interrupt arrives
+	// (ndjson only). FollowInterval is how often the store is checked.
+	Follow         bool
+	FollowInterval time.Duration
+
+	// Sessions writes one CSV row per charging session detected in the
+	// history instead of one row per state.
+	Sessions bool
+}
+
+// DefaultFollowInterval is how often export --follow checks for new states
+const DefaultFollowInterval = 2 * time.Second
+
+// SplitPeriod selects how export --split-by buckets states into files
+type SplitPeriod string
+
+const (
+	SplitNone  SplitPeriod = ""
+	SplitDay   SplitPeriod = "day"
+	SplitWeek  SplitPeriod = "week" // ISO week
+	SplitMonth SplitPeriod = "month"
+)
+
+// Validate rejects unknown split periods
+func (p SplitPeriod) Validate() error {
+	switch p {
+	case SplitNone, SplitDay, SplitWeek, SplitMonth:
+		return nil
+	}
+	return fmt.Errorf("invalid split period %q: expected day, week or month", string(p))
+}
+
+// key returns the file name suffix for the period containing t
+func (p SplitPeriod) key(t time.Time) string {
+	switch p {
+	case SplitWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case SplitMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
 }
 
 // ExportCommand exports historical vehicle state data
@@ -40,15 +103,58 @@ func (c *ExportCommand) Run(ctx context.Context, opts ExportOptions) error {
 	if c.store == nil {
 		return fmt.Errorf("store not available for export")
 	}
+	if err := opts.SplitBy.Validate(); err != nil {
+		return err
+	}
+	if opts.SplitBy != SplitNone && opts.OutputPath == "" {
+		return fmt.Errorf("split by %s requires an output path", opts.SplitBy)
+	}
+	if opts.Format == FormatSQLite {
+		return c.writeSQLite(ctx, opts)
+	}
+	if opts.Sessions {
+		return c.writeSessions(ctx, opts)
+	}
+
+	formatter, err := NewFormatter(opts.Format, FormatOptions{
+		Pretty:     opts.Pretty,
+		TimeFormat: opts.TimeFormat,
+		LocalTime:  opts.LocalTime,
+		Redact:     opts.Redact,
+		Summary:    opts.Summary,
+	})
+	if err != nil {
+		return fmt.Errorf("create formatter: %w", err)
+	}
+	if opts.Follow {
+		return c.follow(ctx, formatter, opts)
+	}
 
 	var states []*model.VehicleState
-	var err error
 
 	// Determine query method
 	switch {
 	case !opts.Since.IsZero() && !opts.Until.IsZero():
-		// Range query
+		// Range query: unbounded, so stream it or guard its size
+		count, err := c.store.CountStates(ctx, c.vehicleID, opts.Since, opts.Until)
+		if err != nil {
+			return fmt.Errorf("count states: %w", err)
+		}
+		if count == 0 {
+			_, _ = fmt.Fprintln(c.output, "No states found for the specified time range")
+			return nil
+		}
+
+		if stream, ok := formatter.(StreamFormatter); ok && opts.SplitBy == SplitNone {
+			return c.writeStream(ctx, stream, opts)
+		}
+		if opts.MaxRows > 0 && count > opts.MaxRows {
+			return fmt.Errorf("%d states in range exceeds the %d row limit for %s export: use csv or ndjson to stream, narrow the range, or raise the limit", count, opts.MaxRows, opts.Format)
+		}
 		states, err = c.store.GetStates(ctx, c.vehicleID, opts.Since, opts.Until)
+		if err != nil {
+			return fmt.Errorf("query states: %w", err)
+		}
 	case !opts.Since.IsZero():
 		// History query with limit
 		limit := opts.Limit
@@ -75,11 +181,237 @@ func (c *ExportCommand) Run(ctx context.Context, opts ExportOptions) error {
 		return nil
 	}
 
-	// Format and output
-	formatter, err := NewFormatter(opts.Format, opts.Pretty)
+	switch {
+	case opts.SplitBy != SplitNone:
+		return c.writeSplit(formatter, states, opts)
+	case opts.OutputPath != "":
+		if err := writeStatesFile(opts.OutputPath, formatter, states); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.output, "Wrote %d states to %s\n", len(states), opts.OutputPath)
+		return nil
+	default:
+		return formatter.FormatStates(c.output, states)
+	}
+}
+
+// writeStream writes a range export straight from the store, one state at a
+// time, to the output file or the command output.
+func (c *ExportCommand) writeStream(ctx context.Context, formatter StreamFormatter, opts ExportOptions) error {
+	written := 0
+	each := func(fn func(*model.VehicleState) error) error {
+		return c.store.StreamStates(ctx, c.vehicleID, opts.Since, opts.Until, func(state *model.VehicleState) error {
+			written++
+			return fn(state)
+		})
+	}
+
+	if opts.OutputPath == "" {
+		return formatter.FormatStream(c.output, each)
+	}
+
+	if err := writeFile(opts.OutputPath, func(w io.Writer) error { return formatter.FormatStream(w, each) }); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.output, "Wrote %d states to %s\n", written, opts.OutputPath)
+	return nil
+}
+
+// follow writes recent states oldest first, then polls the store and writes
+// each state saved afterwards until ctx is canceled or an interrupt arrives.
+// New states come from whatever shares the database, such as watch or the
+// TUI.
+func (c *ExportCommand) follow(ctx context.Context, formatter Formatter, opts ExportOptions) error {
+	switch {
+	case opts.Format != FormatNDJSON:
+		return fmt.Errorf("follow requires ndjson output, got %s", opts.Format)
+	case opts.OutputPath != "" || opts.SplitBy != SplitNone:
+		return fmt.Errorf("follow only writes to the command output")
+	case !opts.Until.IsZero():
+		return fmt.Errorf("follow cannot be combined with an end time")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Mark the newest row before reading history: a state saved in between
+	// may be written twice, but none is skipped
+	lastID, err := c.store.LastStateID(ctx, c.vehicleID)
 	if err != nil {
-		return fmt.Errorf("create formatter: %w", err)
+		return err
+	}
+
+	since, limit := opts.Since, opts.Limit
+	if since.IsZero() {
+		since = time.Now().AddDate(-1, 0, 0)
+	}
+	if limit == 0 {
+		limit = 100
+	}
+	states, err := c.store.GetStateHistory(ctx, c.vehicleID, since, limit)
+	if err != nil {
+		return fmt.Errorf("query states: %w", err)
+	}
+	// History comes newest first; write it in the order new states arrive
+	for i, j := 0, len(states)-1; i < j; i, j = i+1, j-1 {
+		states[i], states[j] = states[j], states[i]
+	}
+	if err := formatter.FormatStates(c.output, states); err != nil {
+		return err
+	}
+
+	interval := opts.FollowInterval
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			states, id, err := c.store.StatesSavedAfter(ctx, c.vehicleID, lastID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			lastID = id
+			if err := formatter.FormatStates(c.output, states); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeSQLite copies the selected states into a new database at the output
+// path. Without --since it copies all history; --until defaults to now.
+func (c *ExportCommand) writeSQLite(ctx context.Context, opts ExportOptions) error {
+	switch {
+	case opts.OutputPath == "":
+		return fmt.Errorf("sqlite export requires an output path")
+	case opts.SplitBy != SplitNone:
+		return fmt.Errorf("sqlite export cannot be split")
+	case opts.Limit > 0:
+		return fmt.Errorf("sqlite export does not support a limit: use a time range")
+	}
+
+	until := opts.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	var transform func(*model.VehicleState) *model.VehicleState
+	if opts.Redact.enabled() {
+		transform = opts.Redact.apply
+	}
+
+	written, err := c.store.ExportStates(ctx, opts.OutputPath, c.vehicleID, opts.Since, until, transform)
+	if err != nil {
+		return fmt.Errorf("export to %s: %w", opts.OutputPath, err)
+	}
+	_, _ = fmt.Fprintf(c.output, "Wrote %d states to %s\n", written, opts.OutputPath)
+	return nil
+}
+
+// writeSessions detects charging sessions in the selected history and writes
+// them as CSV. Without --since it reads the last year; --until defaults to
+// now.
+func (c *ExportCommand) writeSessions(ctx context.Context, opts ExportOptions) error {
+	switch {
+	case opts.Format != FormatCSV:
+		return fmt.Errorf("sessions export requires csv output, got %s", opts.Format)
+	case opts.SplitBy != SplitNone || opts.Follow:
+		return fmt.Errorf("sessions export cannot be split or followed")
+	case opts.Limit > 0:
+		return fmt.Errorf("sessions export does not support a limit: use a time range")
+	}
+
+	since, until := opts.Since, opts.Until
+	if since.IsZero() {
+		since = time.Now().AddDate(-1, 0, 0)
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	states, err := c.store.GetStates(ctx, c.vehicleID, since, until)
+	if err != nil {
+		return fmt.Errorf("query states: %w", err)
+	}
+	if len(states) == 0 {
+		_, _ = fmt.Fprintln(c.output, "No states found for the specified time range")
+		return nil
+	}
+
+	sessions := DetectChargingSessions(states)
+	formatter := &SessionFormatter{TimeFormat: opts.TimeFormat, LocalTime: opts.LocalTime}
+	if opts.OutputPath == "" {
+		return formatter.FormatSessions(c.output, sessions)
+	}
+
+	if err := writeFile(opts.OutputPath, func(w io.Writer) error { return formatter.FormatSessions(w, sessions) }); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.output, "Wrote %d sessions to %s\n", len(sessions), opts.OutputPath)
+	return nil
+}
+
+// writeSplit writes one file per period, oldest first, reporting each file
+// written. "history.csv" split by day becomes "history-2024-01-15.csv".
+func (c *ExportCommand) writeSplit(formatter Formatter, states []*model.VehicleState, opts ExportOptions) error {
+	buckets := make(map[string][]*model.VehicleState)
+	var keys []string
+	for _, state := range states {
+		key := opts.SplitBy.key(displayTime(state.UpdatedAt, opts.LocalTime))
+		if _, ok := buckets[key]; !ok {
+			keys = append(keys, key)
+		}
+		buckets[key] = append(buckets[key], state)
 	}
+	sort.Strings(keys)
 
-	return formatter.FormatStates(c.output, states)
+	ext := filepath.Ext(opts.OutputPath)
+	base := strings.TrimSuffix(opts.OutputPath, ext)
+	for _, key := range keys {
+		path := base + "-" + key + ext
+		if err := writeStatesFile(path, formatter, buckets[key]); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.output, "Wrote %d states to %s\n", len(buckets[key]), path)
+	}
+	return nil
+}
+
+// writeStatesFile formats states into a new file at path
+func writeStatesFile(path string, formatter Formatter, states []*model.VehicleState) error {
+	return writeFile(path, func(w io.Writer) error { return formatter.FormatStates(w, states) })
+}
+
+// writeFile writes a file through a temporary file in the same directory,
+// renamed into place once complete, so a failed export never leaves a
+// truncated file behind or clobbers an existing one.
+func writeFile(path string, write func(io.Writer) error) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("rename %s: %w", path, err)
+	}
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/model"
@@ -16,19 +17,184 @@ import (
 type OutputFormat string
 
 const (
-	FormatJSON  OutputFormat = "json"
-	FormatYAML  OutputFormat = "yaml"
-	FormatCSV   OutputFormat = "csv"
-	FormatTable OutputFormat = "table"
-	FormatText  OutputFormat = "text"
+	FormatJSON   OutputFormat = "json"
+	FormatNDJSON OutputFormat = "ndjson" // One JSON object per line; streams in export
+	FormatYAML   OutputFormat = "yaml"
+	FormatCSV    OutputFormat = "csv"
+	FormatTable  OutputFormat = "table"
+	FormatText   OutputFormat = "text"
+	FormatAuto   OutputFormat = "auto"   // table on a terminal, json when piped
+	FormatSQLite OutputFormat = "sqlite" // Export only: copies states into a new database
+	FormatXLSX   OutputFormat = "xlsx"   // Excel workbook; export refuses to write it to a terminal
 )
 
+// ResolveFormat resolves FormatAuto to a concrete format based on whether
+// output is going to a terminal. Explicit formats are returned unchanged.
+func ResolveFormat(format OutputFormat, isTerminal bool) OutputFormat {
+	if format != FormatAuto {
+		return format
+	}
+	if isTerminal {
+		return FormatTable
+	}
+	return FormatJSON
+}
+
+// TimeFormat controls how CSV and table output render timestamps: one of
+// the presets below or a custom Go time layout. Empty keeps each
+// formatter's default (RFC3339 for CSV, "2006-01-02 15:04:05" for table).
+type TimeFormat string
+
+const (
+	TimeFormatRFC3339 TimeFormat = "rfc3339"
+	TimeFormatUnix    TimeFormat = "unix"  // Seconds since the epoch
+	TimeFormatLocal   TimeFormat = "local" // Local time without a zone suffix (spreadsheet friendly)
+)
+
+const (
+	csvTimeLayout   = time.RFC3339
+	tableTimeLayout = "2006-01-02 15:04:05"
+)
+
+// Validate rejects custom layouts that contain no time elements, which
+// would otherwise print the same literal text for every row.
+func (f TimeFormat) Validate() error {
+	switch f {
+	case "", TimeFormatRFC3339, TimeFormatUnix, TimeFormatLocal:
+		return nil
+	}
+
+	layout := string(f)
+	if time.Unix(0, 0).UTC().Format(layout) == layout {
+		return fmt.Errorf("invalid time format %q: expected rfc3339, unix, local or a Go time layout such as \"2006-01-02 15:04\"", layout)
+	}
+	return nil
+}
+
+// Format renders t, falling back to defaultLayout when no format is set.
+func (f TimeFormat) Format(t time.Time, defaultLayout string) string {
+	switch f {
+	case "":
+		return t.Format(defaultLayout)
+	case TimeFormatRFC3339:
+		return t.Format(time.RFC3339)
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatLocal:
+		return t.Local().Format(tableTimeLayout)
+	default:
+		return t.Format(string(f))
+	}
+}
+
+// FormatOptions holds presentation settings passed to NewFormatter.
+type FormatOptions struct {
+	Pretty     bool       // Pretty-print JSON/YAML output
+	TimeFormat TimeFormat // Timestamp rendering for CSV/table output
+	LocalTime  bool       // Show text/table/CSV timestamps in the local zone
+	Redact     Redaction  // Fields to hide before formatting
+	Summary    bool       // Add min/max/avg rows to multi-state tables
+}
+
+// Redaction selects identifying fields to strip from output, e.g. before
+// pasting it into a bug report.
+type Redaction struct {
+	Location bool // Drop GPS coordinates
+	VIN      bool // Blank the VIN
+}
+
+// enabled reports whether anything is redacted.
+func (r Redaction) enabled() bool {
+	return r.Location || r.VIN
+}
+
+// apply returns a copy of state with the selected fields removed.
+func (r Redaction) apply(state *model.VehicleState) *model.VehicleState {
+	if state == nil {
+		return nil
+	}
+	redacted := *state
+	if r.Location {
+		redacted.Location = nil
+	}
+	if r.VIN {
+		redacted.VIN = ""
+	}
+	return &redacted
+}
+
+// redactingFormatter applies a Redaction to every state before handing it
+// to the wrapped formatter, so all output formats hide the same fields.
+type redactingFormatter struct {
+	Formatter
+	redact Redaction
+}
+
+func (f *redactingFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
+	return f.Formatter.FormatState(w, f.redact.apply(state))
+}
+
+func (f *redactingFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
+	redacted := make([]*model.VehicleState, len(states))
+	for i, state := range states {
+		redacted[i] = f.redact.apply(state)
+	}
+	return f.Formatter.FormatStates(w, redacted)
+}
+
+// redactingStreamFormatter is a redactingFormatter that keeps the wrapped
+// formatter's streaming support.
+type redactingStreamFormatter struct {
+	*redactingFormatter
+	stream StreamFormatter
+}
+
+func (f *redactingStreamFormatter) FormatStream(w io.Writer, each StateIterator) error {
+	return f.stream.FormatStream(w, func(fn func(*model.VehicleState) error) error {
+		return each(func(state *model.VehicleState) error {
+			return fn(f.redact.apply(state))
+		})
+	})
+}
+
+// displayTime converts t to the local zone when local is set. This is
+// presentation only; stored values and queries keep their original zone.
+func displayTime(t time.Time, local bool) time.Time {
+	if local {
+		return t.Local()
+	}
+	return t
+}
+
 // Formatter handles output formatting
 type Formatter interface {
 	FormatState(w io.Writer, state *model.VehicleState) error
 	FormatStates(w io.Writer, states []*model.VehicleState) error
 }
 
+// StateIterator calls fn for each state in turn, stopping at the first
+// error (e.g. a bound store.StreamStates).
+type StateIterator func(fn func(*model.VehicleState) error) error
+
+// StreamFormatter is a Formatter that can write states as they are read,
+// without holding the whole set in memory.
+type StreamFormatter interface {
+	Formatter
+	FormatStream(w io.Writer, each StateIterator) error
+}
+
+// sliceIterator iterates over states already in memory.
+func sliceIterator(states []*model.VehicleState) StateIterator {
+	return func(fn func(*model.VehicleState) error) error {
+		for _, state := range states {
+			if err := fn(state); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // JSONFormatter formats output as JSON
 type JSONFormatter struct {
 	Pretty bool
@@ -39,7 +205,7 @@ func (f *JSONFormatter) FormatState(w io.Writer, state *model.VehicleState) erro
 	if f.Pretty {
 		encoder.SetIndent("", "  ")
 	}
-	return encoder.Encode(state)
+	return encoder.Encode(NewStatusOutput(state))
 }
 
 func (f *JSONFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
@@ -47,32 +213,75 @@ func (f *JSONFormatter) FormatStates(w io.Writer, states []*model.VehicleState)
 	if f.Pretty {
 		encoder.SetIndent("", "  ")
 	}
-	return encoder.Encode(states)
+	return encoder.Encode(NewHistoryOutput(states))
+}
+
+// NDJSONFormatter formats output as newline-delimited JSON: one versioned
+// status object per line.
+type NDJSONFormatter struct{}
+
+func (f *NDJSONFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
+	return json.NewEncoder(w).Encode(NewStatusOutput(state))
+}
+
+func (f *NDJSONFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
+	return f.FormatStream(w, sliceIterator(states))
 }
 
-// YAMLFormatter formats output as YAML
-type YAMLFormatter struct{}
+func (f *NDJSONFormatter) FormatStream(w io.Writer, each StateIterator) error {
+	encoder := json.NewEncoder(w)
+	return each(func(state *model.VehicleState) error {
+		return encoder.Encode(NewStatusOutput(state))
+	})
+}
+
+// YAMLFormatter formats output as YAML. Pretty selects indented block
+// style; otherwise output is compact flow style.
+type YAMLFormatter struct {
+	Pretty bool
+}
 
 func (f *YAMLFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
-	encoder := yaml.NewEncoder(w)
-	encoder.SetIndent(2)
-	return encoder.Encode(state)
+	return f.encode(w, state)
 }
 
 func (f *YAMLFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
+	return f.encode(w, states)
+}
+
+func (f *YAMLFormatter) encode(w io.Writer, v interface{}) error {
 	encoder := yaml.NewEncoder(w)
-	encoder.SetIndent(2)
-	return encoder.Encode(states)
+	defer func() { _ = encoder.Close() }()
+
+	if f.Pretty {
+		encoder.SetIndent(2)
+		return encoder.Encode(v)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+	// Flow style on the root applies to all nested collections
+	node.Style = yaml.FlowStyle
+	return encoder.Encode(&node)
 }
 
 // CSVFormatter formats output as CSV
-type CSVFormatter struct{}
+type CSVFormatter struct {
+	TimeFormat TimeFormat
+	LocalTime  bool
+}
 
 func (f *CSVFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
 	return f.FormatStates(w, []*model.VehicleState{state})
 }
 
 func (f *CSVFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
+	return f.FormatStream(w, sliceIterator(states))
+}
+
+func (f *CSVFormatter) FormatStream(w io.Writer, each StateIterator) error {
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
@@ -92,13 +301,13 @@ func (f *CSVFormatter) FormatStates(w io.Writer, states []*model.VehicleState) e
 	}
 
 	// Write rows
-	for _, state := range states {
+	return each(func(state *model.VehicleState) error {
 		row := []string{
-			state.UpdatedAt.Format(time.RFC3339),
-			state.VehicleID,
-			state.VIN,
-			state.Name,
-			state.Model,
+			f.TimeFormat.Format(displayTime(state.UpdatedAt, f.LocalTime), csvTimeLayout),
+			csvSafe(state.VehicleID),
+			csvSafe(state.VIN),
+			csvSafe(state.Name),
+			csvSafe(state.Model),
 			formatFloat(state.BatteryLevel, 1),
 			formatFloat(state.RangeEstimate, 1),
 			string(state.RangeStatus),
@@ -114,27 +323,25 @@ func (f *CSVFormatter) FormatStates(w io.Writer, states []*model.VehicleState) e
 			formatFloat(state.Odometer, 1),
 			formatFloatPtr(state.ReadyScore, 1),
 		}
-		if err := writer.Write(row); err != nil {
-			return err
-		}
-	}
-
-	return nil
+		return writer.Write(row)
+	})
 }
 
 // TextFormatter formats output as human-readable text
-type TextFormatter struct{}
+type TextFormatter struct {
+	LocalTime bool
+}
 
 func (f *TextFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
 	_, _ = fmt.Fprintf(w, "Vehicle: %s (%s)\n", state.Name, state.Model)
 	_, _ = fmt.Fprintf(w, "VIN: %s\n", state.VIN)
 	_, _ = fmt.Fprintf(w, "Status: %s\n", formatOnlineStatus(state.IsOnline))
-	_, _ = fmt.Fprintf(w, "Updated: %s\n", state.UpdatedAt.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(w, "Updated: %s\n", displayTime(state.UpdatedAt, f.LocalTime).Format(time.RFC3339))
 	_, _ = fmt.Fprintf(w, "\n")
 
 	// Battery & Range
-	_, _ = fmt.Fprintf(w, "Battery: %.1f%% | Range: %.0f miles (%s)\n",
-		state.BatteryLevel, state.RangeEstimate, state.RangeStatus)
+	_, _ = fmt.Fprintf(w, "Battery: %.1f%% | Range: %s (%s)\n",
+		state.BatteryLevel, formatRange(state.RangeEstimate), state.RangeStatus)
 
 	// Charging
 	if state.ChargeState == model.ChargeStateCharging {
@@ -158,22 +365,23 @@ func (f *TextFormatter) FormatState(w io.Writer, state *model.VehicleState) erro
 	_, _ = fmt.Fprintf(w, "Doors: %s | Windows: %s\n",
 		formatClosures(state.Doors), formatClosures(state.Windows))
 
-	// Only show closures if known
+	// Only show closures the model has and that are known
+	profile := state.Profile()
 	closureLine := ""
-	if state.Frunk != model.ClosureStatusUnknown {
+	if profile.HasFrunk && state.Frunk != model.ClosureStatusUnknown {
 		closureLine += fmt.Sprintf("Frunk: %s", state.Frunk)
 	}
-	if state.Liftgate != model.ClosureStatusUnknown {
+	if profile.HasLiftgate && state.Liftgate != model.ClosureStatusUnknown {
 		if closureLine != "" {
 			closureLine += " | "
 		}
 		closureLine += fmt.Sprintf("Liftgate: %s", state.Liftgate)
 	}
-	if state.TonneauCover != nil && *state.TonneauCover != model.ClosureStatusUnknown {
+	if tonneau, ok := state.Tonneau(); ok {
 		if closureLine != "" {
 			closureLine += " | "
 		}
-		closureLine += fmt.Sprintf("Tonneau: %s", *state.TonneauCover)
+		closureLine += fmt.Sprintf("Tonneau: %s", tonneau)
 	}
 	if closureLine != "" {
 		_, _ = fmt.Fprintf(w, "%s\n", closureLine)
@@ -202,7 +410,7 @@ func (f *TextFormatter) FormatState(w io.Writer, state *model.VehicleState) erro
 	}
 
 	// Odometer
-	_, _ = fmt.Fprintf(w, "Odometer: %.1f miles\n", state.Odometer)
+	_, _ = fmt.Fprintf(w, "Odometer: %s\n", formatOdometer(state.Odometer))
 	_, _ = fmt.Fprintf(w, "\n")
 
 	// Ready Score
@@ -211,7 +419,7 @@ func (f *TextFormatter) FormatState(w io.Writer, state *model.VehicleState) erro
 	}
 
 	// Issues
-	issues := state.GetIssues()
+	issues := state.IssuesAt(state.UpdatedAt)
 	if len(issues) > 0 {
 		_, _ = fmt.Fprintf(w, "\nIssues:\n")
 		for _, issue := range issues {
@@ -235,7 +443,11 @@ func (f *TextFormatter) FormatStates(w io.Writer, states []*model.VehicleState)
 }
 
 // TableFormatter formats output as a compact table
-type TableFormatter struct{}
+type TableFormatter struct {
+	TimeFormat TimeFormat
+	LocalTime  bool
+	Summary    bool // Follow multi-state tables with min/max/avg rows
+}
 
 func (f *TableFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
 	return f.FormatStates(w, []*model.VehicleState{state})
@@ -247,16 +459,24 @@ func (f *TableFormatter) FormatStates(w io.Writer, states []*model.VehicleState)
 		return nil
 	}
 
+	// Size the timestamp column to the widest rendered timestamp
+	timestamps := make([]string, len(states))
+	width := len(tableTimeLayout)
+	for i, state := range states {
+		timestamps[i] = f.TimeFormat.Format(displayTime(state.UpdatedAt, f.LocalTime), tableTimeLayout)
+		width = max(width, len(timestamps[i]))
+	}
+
 	// Header
-	_, _ = fmt.Fprintf(w, "%-19s  %-8s  %-6s  %-5s  %-10s  %s\n",
-		"TIMESTAMP", "BATTERY", "RANGE", "LOCK", "CHARGING", "STATUS")
-	_, _ = fmt.Fprintf(w, "%-19s  %-8s  %-6s  %-5s  %-10s  %s\n",
-		"-------------------", "--------", "------", "-----", "----------", "------")
+	_, _ = fmt.Fprintf(w, "%-*s  %-8s  %-6s  %-5s  %-10s  %s\n",
+		width, "TIMESTAMP", "BATTERY", "RANGE", "LOCK", "CHARGING", "STATUS")
+	_, _ = fmt.Fprintf(w, "%-*s  %-8s  %-6s  %-5s  %-10s  %s\n",
+		width, strings.Repeat("-", width), "--------", "------", "-----", "----------", "------")
 
 	// Rows
-	for _, state := range states {
-		_, _ = fmt.Fprintf(w, "%-19s  %6.1f%%  %5.0fmi  %-5s  %-10s  %s\n",
-			state.UpdatedAt.Format("2006-01-02 15:04:05"),
+	for i, state := range states {
+		_, _ = fmt.Fprintf(w, "%-*s  %6.1f%%  %5.0fmi  %-5s  %-10s  %s\n",
+			width, timestamps[i],
 			state.BatteryLevel,
 			state.RangeEstimate,
 			formatLockStatusShort(state.IsLocked),
@@ -265,22 +485,91 @@ func (f *TableFormatter) FormatStates(w io.Writer, states []*model.VehicleState)
 		)
 	}
 
+	if f.Summary && len(states) > 1 {
+		writeTableSummary(w, states, width)
+	}
+
 	return nil
 }
 
+// writeTableSummary writes min/max/avg battery and range rows after a
+// separator, aligned with the table columns.
+func writeTableSummary(w io.Writer, states []*model.VehicleState, width int) {
+	stats := summarizeStates(states)
+
+	_, _ = fmt.Fprintf(w, "%-*s  %-8s  %-6s\n", width, strings.Repeat("-", width), "--------", "------")
+	for _, row := range []struct {
+		label               string
+		batteryPct, rangeMi float64
+	}{
+		{"MIN", stats.minBattery, stats.minRange},
+		{"MAX", stats.maxBattery, stats.maxRange},
+		{"AVG", stats.avgBattery, stats.avgRange},
+	} {
+		_, _ = fmt.Fprintf(w, "%-*s  %6.1f%%  %5.0fmi\n", width, row.label, row.batteryPct, row.rangeMi)
+	}
+}
+
+// stateStats are the min/max/avg battery level and range of some states.
+type stateStats struct {
+	minBattery, maxBattery, avgBattery float64
+	minRange, maxRange, avgRange       float64
+}
+
+// summarizeStates computes stateStats over states, which must not be empty.
+func summarizeStates(states []*model.VehicleState) stateStats {
+	stats := stateStats{
+		minBattery: states[0].BatteryLevel, maxBattery: states[0].BatteryLevel,
+		minRange: states[0].RangeEstimate, maxRange: states[0].RangeEstimate,
+	}
+	var sumBattery, sumRange float64
+	for _, state := range states {
+		stats.minBattery, stats.maxBattery = min(stats.minBattery, state.BatteryLevel), max(stats.maxBattery, state.BatteryLevel)
+		stats.minRange, stats.maxRange = min(stats.minRange, state.RangeEstimate), max(stats.maxRange, state.RangeEstimate)
+		sumBattery += state.BatteryLevel
+		sumRange += state.RangeEstimate
+	}
+	n := float64(len(states))
+	stats.avgBattery, stats.avgRange = sumBattery/n, sumRange/n
+	return stats
+}
+
 // NewFormatter creates a formatter for the given format
-func NewFormatter(format OutputFormat, pretty bool) (Formatter, error) {
+func NewFormatter(format OutputFormat, opts FormatOptions) (Formatter, error) {
+	formatter, err := newFormatter(format, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Redact.enabled() {
+		redacting := &redactingFormatter{Formatter: formatter, redact: opts.Redact}
+		if stream, ok := formatter.(StreamFormatter); ok {
+			return &redactingStreamFormatter{redactingFormatter: redacting, stream: stream}, nil
+		}
+		return redacting, nil
+	}
+	return formatter, nil
+}
+
+func newFormatter(format OutputFormat, opts FormatOptions) (Formatter, error) {
+	if err := opts.TimeFormat.Validate(); err != nil {
+		return nil, err
+	}
+
 	switch format {
 	case FormatJSON:
-		return &JSONFormatter{Pretty: pretty}, nil
+		return &JSONFormatter{Pretty: opts.Pretty}, nil
+	case FormatNDJSON:
+		return &NDJSONFormatter{}, nil
 	case FormatYAML:
-		return &YAMLFormatter{}, nil
+		return &YAMLFormatter{Pretty: opts.Pretty}, nil
 	case FormatCSV:
-		return &CSVFormatter{}, nil
+		return &CSVFormatter{TimeFormat: opts.TimeFormat, LocalTime: opts.LocalTime}, nil
 	case FormatText:
-		return &TextFormatter{}, nil
+		return &TextFormatter{LocalTime: opts.LocalTime}, nil
 	case FormatTable:
-		return &TableFormatter{}, nil
+		return &TableFormatter{TimeFormat: opts.TimeFormat, LocalTime: opts.LocalTime, Summary: opts.Summary}, nil
+	case FormatXLSX:
+		return &XLSXFormatter{LocalTime: opts.LocalTime}, nil
 	default:
 		return nil, fmt.Errorf("unknown format: %s", format)
 	}
@@ -288,10 +577,30 @@ func NewFormatter(format OutputFormat, pretty bool) (Formatter, error) {
 
 // Helper functions
 
+// csvSafe neutralizes free-text values that spreadsheets would evaluate as
+// formulas (CSV injection) by prefixing a single quote. Only apply it to
+// text fields: numeric columns legitimately start with "-".
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
 func formatFloat(f float64, prec int) string {
 	return strconv.FormatFloat(f, 'f', prec, 64)
 }
 
+// formatRange renders a range estimate, which the reducer keeps in miles.
+func formatRange(miles float64) string {
+	return fmt.Sprintf("%.0f miles", miles)
+}
+
+// formatOdometer renders an odometer reading in miles.
+func formatOdometer(miles float64) string {
+	return fmt.Sprintf("%.1f miles", miles)
+}
+
 func formatFloatPtr(f *float64, prec int) string {
 	if f == nil {
 		return ""
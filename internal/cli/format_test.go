@@ -2,12 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/xuri/excelize/v2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -75,17 +79,91 @@ func TestJSONFormatter_FormatState(t *testing.T) {
 	}
 
 	// Verify it's valid JSON
-	var decoded model.VehicleState
+	var decoded StatusOutput
 	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
 		t.Fatalf("Invalid JSON: %v", err)
 	}
 
 	// Verify key fields
-	if decoded.VehicleID != "vehicle-123" {
-		t.Errorf("VehicleID mismatch: got %s", decoded.VehicleID)
+	if decoded.Vehicle.ID != "vehicle-123" {
+		t.Errorf("Vehicle.ID mismatch: got %s", decoded.Vehicle.ID)
+	}
+	if decoded.Metrics.BatteryLevel != 85.5 {
+		t.Errorf("Metrics.BatteryLevel mismatch: got %v", decoded.Metrics.BatteryLevel)
+	}
+}
+
+func TestJSONFormatter_FormatState_Envelope(t *testing.T) {
+	formatter := &JSONFormatter{}
+
+	var buf bytes.Buffer
+	if err := formatter.FormatState(&buf, makeTestState()); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	for _, key := range []string{"schemaVersion", "updatedAt", "vehicle", "metrics", "closures", "tires", "issues"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("missing top-level key %q", key)
+		}
+	}
+
+	// Model field names must not leak into the output
+	if _, ok := decoded["VehicleID"]; ok {
+		t.Error("unexpected raw model field VehicleID in output")
+	}
+
+	var version int
+	if err := json.Unmarshal(decoded["schemaVersion"], &version); err != nil {
+		t.Fatalf("schemaVersion is not a number: %v", err)
+	}
+	if version != JSONSchemaVersion {
+		t.Errorf("schemaVersion = %d, want %d", version, JSONSchemaVersion)
+	}
+}
+
+func TestNewStatusOutput_Tonneau(t *testing.T) {
+	state := makeTestState()
+	tonneau := model.ClosureStatusClosed
+	state.TonneauCover = &tonneau
+
+	state.Model = "R1T"
+	if tc := NewStatusOutput(state).Closures.TonneauCover; tc == nil || *tc != "closed" {
+		t.Errorf("R1T tonneauCover = %v, want closed", tc)
+	}
+
+	state.Model = "R1S"
+	if tc := NewStatusOutput(state).Closures.TonneauCover; tc != nil {
+		t.Errorf("R1S tonneauCover = %q, want omitted", *tc)
 	}
-	if decoded.BatteryLevel != 85.5 {
-		t.Errorf("BatteryLevel mismatch: got %v", decoded.BatteryLevel)
+}
+
+func TestNewStatusOutput_StoredStateIssues(t *testing.T) {
+	// An online state taken long ago was current when it was saved
+	state := makeTestState()
+	state.UpdatedAt = time.Now().Add(-72 * time.Hour)
+	for _, issue := range NewStatusOutput(state).Issues {
+		if strings.Contains(issue, "Inconsistent data") {
+			t.Errorf("stored state reported %q", issue)
+		}
+	}
+}
+
+func TestTextFormatter_StoredStateConfidence(t *testing.T) {
+	state := makeTestState()
+	state.UpdatedAt = time.Now().Add(-72 * time.Hour)
+	state.ReportedAt = state.UpdatedAt.Add(-10 * time.Minute)
+
+	var buf bytes.Buffer
+	if err := (&TextFormatter{}).FormatState(&buf, state); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Data unavailable") {
+		t.Errorf("3-day-old stored state reported as stale:\n%s", buf.String())
 	}
 }
 
@@ -99,14 +177,17 @@ func TestJSONFormatter_FormatStates(t *testing.T) {
 		t.Fatalf("FormatStates failed: %v", err)
 	}
 
-	// Verify it's valid JSON array
-	var decoded []*model.VehicleState
+	// Verify it's a valid JSON envelope
+	var decoded HistoryOutput
 	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
 		t.Fatalf("Invalid JSON: %v", err)
 	}
 
-	if len(decoded) != 2 {
-		t.Errorf("Expected 2 states, got %d", len(decoded))
+	if decoded.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", decoded.SchemaVersion, JSONSchemaVersion)
+	}
+	if len(decoded.States) != 2 {
+		t.Errorf("Expected 2 states, got %d", len(decoded.States))
 	}
 }
 
@@ -153,6 +234,41 @@ func TestYAMLFormatter_FormatStates(t *testing.T) {
 	}
 }
 
+func TestYAMLFormatter_Styles(t *testing.T) {
+	state := makeTestState()
+
+	var pretty bytes.Buffer
+	if err := (&YAMLFormatter{Pretty: true}).FormatState(&pretty, state); err != nil {
+		t.Fatalf("FormatState (pretty) failed: %v", err)
+	}
+	if strings.HasPrefix(pretty.String(), "{") {
+		t.Errorf("Pretty YAML should use block style, got: %s", pretty.String())
+	}
+	if !strings.Contains(pretty.String(), "\nvehicleid: vehicle-123\n") && !strings.HasPrefix(pretty.String(), "vehicleid: vehicle-123\n") {
+		t.Errorf("Pretty YAML should have one key per line, got: %s", pretty.String())
+	}
+
+	var compact bytes.Buffer
+	if err := (&YAMLFormatter{Pretty: false}).FormatState(&compact, state); err != nil {
+		t.Fatalf("FormatState (compact) failed: %v", err)
+	}
+	if !strings.HasPrefix(compact.String(), "{") {
+		t.Errorf("Compact YAML should use flow style, got: %s", compact.String())
+	}
+	if lines := strings.Count(strings.TrimSpace(compact.String()), "\n"); lines != 0 {
+		t.Errorf("Compact YAML should be a single line, got %d line breaks", lines)
+	}
+
+	// Both styles must decode to the same data
+	var decoded model.VehicleState
+	if err := yaml.Unmarshal(compact.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid compact YAML: %v", err)
+	}
+	if decoded.VehicleID != "vehicle-123" || decoded.BatteryLevel != 85.5 {
+		t.Errorf("Compact YAML round-trip mismatch: %+v", decoded)
+	}
+}
+
 func TestCSVFormatter_FormatState(t *testing.T) {
 	state := makeTestState()
 	formatter := &CSVFormatter{}
@@ -201,6 +317,87 @@ func TestCSVFormatter_FormatStates(t *testing.T) {
 	}
 }
 
+func TestCSVFormatter_FormulaInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{`=HYPERLINK("http://evil.example","click")`, `'=HYPERLINK("http://evil.example","click")`},
+		{"+1+2", "'+1+2"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{`Truck, "The Beast"`, `Truck, "The Beast"`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := makeTestState()
+			state.Name = tt.name
+			cold := -5.0
+			state.ExteriorTemp = &cold
+
+			record := csvRoundTrip(t, state)
+			if got := record["Name"]; got != tt.want {
+				t.Errorf("Name = %q, want %q", got, tt.want)
+			}
+			// Numeric columns keep their sign
+			if got := record["ExteriorTemp"]; got != "-5.0" {
+				t.Errorf("ExteriorTemp = %q, want -5.0", got)
+			}
+		})
+	}
+}
+
+// csvRoundTrip formats one state as CSV and parses it back into a
+// header -> value map.
+func csvRoundTrip(t *testing.T, state *model.VehicleState) map[string]string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := (&CSVFormatter{}).FormatState(&buf, state); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected header + 1 row, got %d rows", len(rows))
+	}
+
+	record := make(map[string]string, len(rows[0]))
+	for i, column := range rows[0] {
+		record[column] = rows[1][i]
+	}
+	return record
+}
+
+func FuzzCSVFormatter_Name(f *testing.F) {
+	for _, seed := range []string{"My R1T", "=1+1", "a,b", `"quoted"`, "line\nbreak", "-"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		if !utf8.ValidString(name) || strings.ContainsRune(name, '\r') {
+			t.Skip() // encoding/csv normalizes \r\n and rejects invalid UTF-8 round trips
+		}
+
+		state := makeTestState()
+		state.Name = name
+
+		got := csvRoundTrip(t, state)["Name"]
+		if got != name && got != "'"+name {
+			t.Fatalf("Name round-tripped to %q, want %q", got, name)
+		}
+		if got != "" && strings.ContainsRune("=+-@\t", rune(got[0])) {
+			t.Fatalf("Name %q starts with a formula character", got)
+		}
+	})
+}
+
 func TestTextFormatter_FormatState(t *testing.T) {
 	state := makeTestState()
 	formatter := &TextFormatter{}
@@ -249,6 +446,75 @@ func TestTextFormatter_FormatStates(t *testing.T) {
 	}
 }
 
+func TestXLSXFormatter_FormatStates(t *testing.T) {
+	first := makeTestState()
+	second := makeTestState()
+	second.UpdatedAt = first.UpdatedAt.Add(time.Hour)
+	second.BatteryLevel = 75.5
+	second.RangeEstimate = 220
+	second.CabinTemp = nil
+
+	var buf bytes.Buffer
+	if err := (&XLSXFormatter{}).FormatStates(&buf, []*model.VehicleState{first, second}); err != nil {
+		t.Fatalf("FormatStates failed: %v", err)
+	}
+
+	book, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader failed: %v", err)
+	}
+	defer func() { _ = book.Close() }()
+
+	if got := book.GetSheetList(); len(got) != 2 || got[0] != "States" || got[1] != "Summary" {
+		t.Fatalf("sheets = %v, want [States Summary]", got)
+	}
+
+	rows, err := book.GetRows("States")
+	if err != nil {
+		t.Fatalf("GetRows failed: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "Timestamp" || rows[0][5] != "BatteryLevel" {
+		t.Fatalf("unexpected States rows: %v", rows)
+	}
+	if rows[1][0] != "2024-01-15 10:00:00" {
+		t.Errorf("timestamp shown as %q, want 2024-01-15 10:00:00", rows[1][0])
+	}
+
+	for cell, want := range map[string]string{
+		"A2": "45306.416666666664", // Excel date serial, not text
+		"F2": "85.5",
+		"F3": "75.5",
+		"P2": "72",
+		"P3": "", // Nil cabin temp stays empty
+	} {
+		got, err := book.GetCellValue("States", cell, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Fatalf("GetCellValue(%s) failed: %v", cell, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+	if typ, _ := book.GetCellType("States", "F2"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Error("battery level should be a number cell, not a string")
+	}
+
+	summary, err := book.GetRows("Summary")
+	if err != nil {
+		t.Fatalf("GetRows(Summary) failed: %v", err)
+	}
+	if summary[0][0] != "States" || summary[0][1] != "2" {
+		t.Errorf("summary count row = %v, want [States 2]", summary[0])
+	}
+	if summary[2][1] != "2024-01-15 11:00:00" {
+		t.Errorf("summary last = %q, want 2024-01-15 11:00:00", summary[2][1])
+	}
+	battery := summary[5]
+	if battery[0] != "Battery (%)" || battery[1] != "75.5" || battery[2] != "85.5" || battery[3] != "80.5" {
+		t.Errorf("battery summary row = %v, want [Battery (%%) 75.5 85.5 80.5]", battery)
+	}
+}
+
 func TestTableFormatter_FormatState(t *testing.T) {
 	state := makeTestState()
 	formatter := &TableFormatter{}
@@ -297,6 +563,38 @@ func TestTableFormatter_FormatStates(t *testing.T) {
 	}
 }
 
+func TestTableFormatter_Summary(t *testing.T) {
+	low, high := makeTestState(), makeTestState()
+	low.BatteryLevel, low.RangeEstimate = 40, 120
+	high.BatteryLevel, high.RangeEstimate = 80, 240
+	formatter := &TableFormatter{Summary: true}
+
+	var buf bytes.Buffer
+	if err := formatter.FormatStates(&buf, []*model.VehicleState{low, high}); err != nil {
+		t.Fatalf("FormatStates failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	// header + separator + 2 rows + separator + min/max/avg
+	if len(lines) != 8 {
+		t.Fatalf("Expected 8 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"MIN   40.0%    120mi", "MAX   80.0%    240mi", "AVG   60.0%    180mi"} {
+		if got := strings.Join(strings.Fields(lines[5+i]), " "); got != strings.Join(strings.Fields(want), " ") {
+			t.Errorf("summary row %d = %q, want %q", i, lines[5+i], want)
+		}
+	}
+
+	// A single state has nothing to summarize
+	buf.Reset()
+	if err := formatter.FormatState(&buf, low); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "MIN") {
+		t.Errorf("single-state table should not have a summary:\n%s", buf.String())
+	}
+}
+
 func TestTableFormatter_EmptyStates(t *testing.T) {
 	formatter := &TableFormatter{}
 
@@ -318,6 +616,7 @@ func TestNewFormatter(t *testing.T) {
 		wantErr bool
 	}{
 		{FormatJSON, false},
+		{FormatNDJSON, false},
 		{FormatYAML, false},
 		{FormatCSV, false},
 		{FormatText, false},
@@ -327,7 +626,7 @@ func TestNewFormatter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.format), func(t *testing.T) {
-			formatter, err := NewFormatter(tt.format, true)
+			formatter, err := NewFormatter(tt.format, FormatOptions{Pretty: true})
 
 			if tt.wantErr {
 				if err == nil {
@@ -347,6 +646,128 @@ func TestNewFormatter(t *testing.T) {
 	}
 }
 
+func TestTimeFormat(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		format  TimeFormat
+		want    string
+		wantErr bool
+	}{
+		{name: "default", format: "", want: "2024-01-15T10:00:00Z"},
+		{name: "rfc3339", format: TimeFormatRFC3339, want: "2024-01-15T10:00:00Z"},
+		{name: "unix", format: TimeFormatUnix, want: "1705312800"},
+		{name: "local", format: TimeFormatLocal, want: ts.Local().Format("2006-01-02 15:04:05")},
+		{name: "custom layout", format: "02/01/2006 15:04", want: "15/01/2024 10:00"},
+		{name: "layout without time elements", format: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.format.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := NewFormatter(FormatCSV, FormatOptions{TimeFormat: tt.format}); err == nil {
+					t.Error("NewFormatter() should reject an invalid time format")
+				}
+				return
+			}
+
+			if got := tt.format.Format(ts, time.RFC3339); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFormat_CSVAndTable(t *testing.T) {
+	state := makeTestState()
+
+	var csvBuf bytes.Buffer
+	if err := (&CSVFormatter{TimeFormat: TimeFormatUnix}).FormatState(&csvBuf, state); err != nil {
+		t.Fatalf("CSV FormatState failed: %v", err)
+	}
+	if !strings.Contains(csvBuf.String(), "\n1705312800,vehicle-123,") {
+		t.Errorf("CSV should use unix timestamps, got: %s", csvBuf.String())
+	}
+
+	var tableBuf bytes.Buffer
+	if err := (&TableFormatter{TimeFormat: TimeFormatRFC3339}).FormatState(&tableBuf, state); err != nil {
+		t.Fatalf("Table FormatState failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(tableBuf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header, separator and one row, got: %s", tableBuf.String())
+	}
+	// Columns stay aligned when timestamps are wider than the default
+	if strings.Index(lines[0], "BATTERY") != len("2024-01-15T10:00:00Z")+2 {
+		t.Errorf("table columns misaligned:\n%s", tableBuf.String())
+	}
+	if !strings.HasPrefix(lines[2], "2024-01-15T10:00:00Z") {
+		t.Errorf("table should use RFC3339 timestamps, got: %s", lines[2])
+	}
+}
+
+func TestLocalTime(t *testing.T) {
+	origLocal := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	defer func() { time.Local = origLocal }()
+
+	state := makeTestState()
+	tests := []struct {
+		name string
+		f    Formatter
+		want string
+	}{
+		{name: "text", f: &TextFormatter{LocalTime: true}, want: "Updated: 2024-01-15T19:00:00+09:00"},
+		{name: "csv", f: &CSVFormatter{LocalTime: true}, want: "\n2024-01-15T19:00:00+09:00,vehicle-123,"},
+		{name: "table", f: &TableFormatter{LocalTime: true}, want: "2024-01-15 19:00:00"},
+		{name: "text utc", f: &TextFormatter{}, want: "Updated: 2024-01-15T10:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.f.FormatState(&buf, state); err != nil {
+				t.Fatalf("FormatState failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output missing %q, got:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+
+	if state.UpdatedAt.Location() != time.UTC {
+		t.Error("formatting should not modify the state's UpdatedAt")
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     OutputFormat
+		isTerminal bool
+		want       OutputFormat
+	}{
+		{"auto on terminal", FormatAuto, true, FormatTable},
+		{"auto when piped", FormatAuto, false, FormatJSON},
+		{"explicit json on terminal", FormatJSON, true, FormatJSON},
+		{"explicit text when piped", FormatText, false, FormatText},
+		{"explicit csv on terminal", FormatCSV, true, FormatCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveFormat(tt.format, tt.isTerminal); got != tt.want {
+				t.Errorf("ResolveFormat(%q, %v) = %q, want %q", tt.format, tt.isTerminal, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFormatHelpers(t *testing.T) {
 	t.Run("formatFloat", func(t *testing.T) {
 		result := formatFloat(123.456, 2)
@@ -420,3 +841,104 @@ func TestFormatHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestNewFormatter_Redaction(t *testing.T) {
+	formats := []OutputFormat{FormatJSON, FormatNDJSON, FormatYAML, FormatCSV, FormatText, FormatTable}
+
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			state := makeTestState()
+			formatter, err := NewFormatter(format, FormatOptions{Redact: Redaction{Location: true, VIN: true}})
+			if err != nil {
+				t.Fatalf("NewFormatter failed: %v", err)
+			}
+
+			var single, multi bytes.Buffer
+			if err := formatter.FormatState(&single, state); err != nil {
+				t.Fatalf("FormatState failed: %v", err)
+			}
+			if err := formatter.FormatStates(&multi, []*model.VehicleState{state}); err != nil {
+				t.Fatalf("FormatStates failed: %v", err)
+			}
+
+			outputs := []string{single.String(), multi.String()}
+			if stream, ok := formatter.(StreamFormatter); ok {
+				var streamed bytes.Buffer
+				if err := stream.FormatStream(&streamed, sliceIterator([]*model.VehicleState{state})); err != nil {
+					t.Fatalf("FormatStream failed: %v", err)
+				}
+				outputs = append(outputs, streamed.String())
+			}
+
+			for _, out := range outputs {
+				if strings.Contains(out, "37.77") || strings.Contains(out, "122.41") {
+					t.Errorf("output should not contain coordinates:\n%s", out)
+				}
+				if strings.Contains(out, "VIN123456") {
+					t.Errorf("output should not contain the VIN:\n%s", out)
+				}
+			}
+
+			// The caller's state is left intact
+			if state.Location == nil || state.VIN != "VIN123456" {
+				t.Error("redaction should not modify the original state")
+			}
+		})
+	}
+
+	// Off by default
+	formatter, err := NewFormatter(FormatJSON, FormatOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := formatter.FormatState(&buf, makeTestState()); err != nil {
+		t.Fatalf("FormatState failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "VIN123456") || !strings.Contains(buf.String(), "37.7749") {
+		t.Errorf("unredacted output should keep VIN and location:\n%s", buf.String())
+	}
+}
+
+func TestNDJSONFormatter_FormatStates(t *testing.T) {
+	first, second := makeTestState(), makeTestState()
+	second.BatteryLevel = 42
+
+	var buf bytes.Buffer
+	formatter := &NDJSONFormatter{}
+	if err := formatter.FormatStates(&buf, []*model.VehicleState{first, second}); err != nil {
+		t.Fatalf("FormatStates failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	var status StatusOutput
+	if err := json.Unmarshal([]byte(lines[1]), &status); err != nil {
+		t.Fatalf("Line is not a JSON object: %v", err)
+	}
+	if status.SchemaVersion != JSONSchemaVersion || status.Metrics.BatteryLevel != 42 {
+		t.Errorf("Unexpected second line: %s", lines[1])
+	}
+}
+
+func TestCSVFormatter_FormatStreamStopsOnError(t *testing.T) {
+	errStop := errors.New("stop")
+	each := func(fn func(*model.VehicleState) error) error {
+		if err := fn(makeTestState()); err != nil {
+			return err
+		}
+		return errStop
+	}
+
+	var buf bytes.Buffer
+	formatter := &CSVFormatter{}
+	if err := formatter.FormatStream(&buf, each); !errors.Is(err, errStop) {
+		t.Errorf("FormatStream error = %v, want %v", err, errStop)
+	}
+	// Rows written before the error are still flushed
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("Expected header and one row, got %d lines", got)
+	}
+}
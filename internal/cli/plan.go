@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+// PlanOptions configures the plan command
+type PlanOptions struct {
+	Distance float64 // Trip distance in miles
+	Margin   float64 // Minimum battery % to arrive with
+}
+
+// PlanCommand checks whether a trip is possible on the current charge
+type PlanCommand struct {
+	client    rivian.Client
+	vehicleID string
+	output    io.Writer
+}
+
+// NewPlanCommand creates a new plan command
+func NewPlanCommand(client rivian.Client, vehicleID string, output io.Writer) *PlanCommand {
+	return &PlanCommand{
+		client:    client,
+		vehicleID: vehicleID,
+		output:    output,
+	}
+}
+
+// Run executes the plan command. It returns ErrBatteryBelow after printing
+// the plan when the destination can't be reached with the margin.
+func (c *PlanCommand) Run(ctx context.Context, opts PlanOptions) error {
+	if opts.Distance <= 0 {
+		return fmt.Errorf("distance must be positive")
+	}
+	if opts.Margin < 0 || opts.Margin >= 100 {
+		return fmt.Errorf("margin must be between 0 and 100")
+	}
+
+	rivState, err := c.client.GetVehicleState(ctx, c.vehicleID)
+	if err != nil {
+		return fmt.Errorf("get vehicle state: %w", err)
+	}
+	state := model.NewReducer().Dispatch(model.VehicleStateReceived{State: rivState})
+
+	if state.BatteryLevel <= 0 || state.RangeEstimate <= 0 {
+		return fmt.Errorf("vehicle reports no battery or range data")
+	}
+
+	ok, arrivalPct := model.CanReach(state, opts.Distance, opts.Margin)
+	if err := writePlan(c.output, state, opts, ok, arrivalPct); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: arriving with %.0f%% < %.0f%% margin", ErrBatteryBelow, arrivalPct, opts.Margin)
+	}
+	return nil
+}
+
+func writePlan(w io.Writer, state *model.VehicleState, opts PlanOptions, ok bool, arrivalPct float64) error {
+	arrivalMiles := state.RangeEstimate - opts.Distance
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Distance:  %.0f mi\n", opts.Distance)
+	fmt.Fprintf(&b, "Current:   %.0f%% (%.0f mi range)\n", state.BatteryLevel, state.RangeEstimate)
+	if arrivalMiles > 0 {
+		fmt.Fprintf(&b, "Arrival:   %.0f%% (%.0f mi remaining)\n", arrivalPct, arrivalMiles)
+	} else {
+		fmt.Fprintf(&b, "Arrival:   out of range (%.0f mi short)\n", -arrivalMiles)
+	}
+
+	if ok {
+		fmt.Fprintf(&b, "Result:    ✓ Reachable with a %.0f%% margin\n", opts.Margin)
+	} else {
+		fmt.Fprintf(&b, "Result:    ✗ Charge before leaving (need %.0f%% margin)\n", opts.Margin)
+	}
+
+	// Warn with the same range thresholds as the status view
+	if arrivalMiles > 0 {
+		switch model.DetermineRangeStatus(arrivalMiles) {
+		case model.RangeStatusCritical:
+			b.WriteString("Warning:   Arrival range critical (< 25 miles)\n")
+		case model.RangeStatusLow:
+			b.WriteString("Warning:   Arrival range low (< 50 miles)\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
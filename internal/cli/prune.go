@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// PruneOptions configures the prune command
+type PruneOptions struct {
+	OlderThan time.Duration // Delete states older than this
+	Vacuum    bool          // Run VACUUM afterwards to shrink the file
+	DryRun    bool          // Only count what would be deleted
+}
+
+// PruneCommand deletes old states from the local database
+type PruneCommand struct {
+	store  *store.Store
+	output io.Writer
+}
+
+// NewPruneCommand creates a new prune command
+func NewPruneCommand(store *store.Store, output io.Writer) *PruneCommand {
+	return &PruneCommand{
+		store:  store,
+		output: output,
+	}
+}
+
+// Run executes the prune command
+func (c *PruneCommand) Run(ctx context.Context, opts PruneOptions) error {
+	if c.store == nil {
+		return fmt.Errorf("store not available for prune")
+	}
+	if opts.OlderThan <= 0 {
+		return fmt.Errorf("older-than must be positive")
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	if opts.DryRun {
+		count, err := c.store.CountStatesBefore(ctx, cutoff)
+		if err != nil {
+			return fmt.Errorf("count old states: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.output, "Would delete %d states older than %s (dry run, nothing deleted)\n", count, cutoff.Format(time.RFC3339))
+		return nil
+	}
+
+	deleted, err := c.store.DeleteOldStates(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("delete old states: %w", err)
+	}
+	_, _ = fmt.Fprintf(c.output, "Deleted %d states older than %s\n", deleted, cutoff.Format(time.RFC3339))
+
+	if !opts.Vacuum {
+		return nil
+	}
+
+	before, err := c.store.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("get stats: %w", err)
+	}
+	if err := c.store.Vacuum(ctx); err != nil {
+		return err
+	}
+	after, err := c.store.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("get stats: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(c.output, "Vacuumed database: %s -> %s (reclaimed %s)\n",
+		formatBytes(before.DatabaseSize),
+		formatBytes(after.DatabaseSize),
+		formatBytes(before.DatabaseSize-after.DatabaseSize),
+	)
+	return nil
+}
+
+// formatBytes formats a byte count with a binary unit suffix
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
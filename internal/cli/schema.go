@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+// JSONSchemaVersion is the version of the JSON output envelope.
+// Bump it whenever a field is removed or changes meaning; adding new
+// optional fields does not require a bump.
+const JSONSchemaVersion = 1
+
+// StatusOutput is the stable JSON shape for a single vehicle snapshot.
+// It is intentionally decoupled from model.VehicleState so internal
+// refactors don't break consumers.
+type StatusOutput struct {
+	SchemaVersion int `json:"schemaVersion"`
+	SnapshotOutput
+}
+
+// HistoryOutput is the stable JSON shape for a list of snapshots (export).
+type HistoryOutput struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	States        []SnapshotOutput `json:"states"`
+}
+
+// SnapshotOutput holds one vehicle snapshot.
+type SnapshotOutput struct {
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Vehicle   VehicleOutput   `json:"vehicle"`
+	Metrics   MetricsOutput   `json:"metrics"`
+	Closures  ClosuresOutput  `json:"closures"`
+	Tires     TiresOutput     `json:"tires"`
+	Location  *LocationOutput `json:"location,omitempty"`
+	Issues    []string        `json:"issues"`
+}
+
+// VehicleOutput identifies the vehicle.
+type VehicleOutput struct {
+	ID    string `json:"id"`
+	VIN   string `json:"vin"`
+	Name  string `json:"name"`
+	Model string `json:"model"`
+}
+
+// MetricsOutput holds battery, charging and status metrics.
+// Units are encoded in the field names.
+type MetricsOutput struct {
+	BatteryLevel       float64    `json:"batteryLevel"`
+	BatteryCapacityKWh float64    `json:"batteryCapacityKWh"`
+	RangeMiles         float64    `json:"rangeMiles"`
+	RangeStatus        string     `json:"rangeStatus"`
+	ChargeState        string     `json:"chargeState"`
+	ChargeLimit        int        `json:"chargeLimit"`
+	ChargingRateKW     *float64   `json:"chargingRateKW"`
+	ChargeCompleteAt   *time.Time `json:"chargeCompleteAt"`
+	IsLocked           bool       `json:"isLocked"`
+	IsOnline           bool       `json:"isOnline"`
+	OdometerMiles      float64    `json:"odometerMiles"`
+	CabinTempF         *float64   `json:"cabinTempF"`
+	ExteriorTempF      *float64   `json:"exteriorTempF"`
+	ReadyScore         *float64   `json:"readyScore"`
+}
+
+// ClosuresOutput holds door/window/closure statuses.
+type ClosuresOutput struct {
+	Doors        CornersOutput `json:"doors"`
+	Windows      CornersOutput `json:"windows"`
+	Frunk        string        `json:"frunk"`
+	Liftgate     string        `json:"liftgate"`
+	TonneauCover *string       `json:"tonneauCover,omitempty"`
+}
+
+// CornersOutput holds one status per corner of the vehicle.
+type CornersOutput struct {
+	FrontLeft  string `json:"frontLeft"`
+	FrontRight string `json:"frontRight"`
+	RearLeft   string `json:"rearLeft"`
+	RearRight  string `json:"rearRight"`
+}
+
+// TiresOutput holds per-tire status and pressure.
+type TiresOutput struct {
+	FrontLeft  TireOutput `json:"frontLeft"`
+	FrontRight TireOutput `json:"frontRight"`
+	RearLeft   TireOutput `json:"rearLeft"`
+	RearRight  TireOutput `json:"rearRight"`
+}
+
+// TireOutput holds a single tire reading. PSI is omitted when unavailable.
+type TireOutput struct {
+	Status string   `json:"status"`
+	PSI    *float64 `json:"psi,omitempty"`
+}
+
+// LocationOutput holds GPS coordinates.
+type LocationOutput struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// NewStatusOutput builds the versioned JSON envelope for a single state.
+func NewStatusOutput(state *model.VehicleState) StatusOutput {
+	return StatusOutput{
+		SchemaVersion:  JSONSchemaVersion,
+		SnapshotOutput: newSnapshotOutput(state),
+	}
+}
+
+// NewHistoryOutput builds the versioned JSON envelope for multiple states.
+func NewHistoryOutput(states []*model.VehicleState) HistoryOutput {
+	out := HistoryOutput{
+		SchemaVersion: JSONSchemaVersion,
+		States:        make([]SnapshotOutput, 0, len(states)),
+	}
+	for _, state := range states {
+		out.States = append(out.States, newSnapshotOutput(state))
+	}
+	return out
+}
+
+func newSnapshotOutput(state *model.VehicleState) SnapshotOutput {
+	out := SnapshotOutput{
+		UpdatedAt: state.UpdatedAt,
+		Vehicle: VehicleOutput{
+			ID:    state.VehicleID,
+			VIN:   state.VIN,
+			Name:  state.Name,
+			Model: state.Model,
+		},
+		Metrics: MetricsOutput{
+			BatteryLevel:       state.BatteryLevel,
+			BatteryCapacityKWh: state.BatteryCapacity,
+			RangeMiles:         state.RangeEstimate,
+			RangeStatus:        string(state.RangeStatus),
+			ChargeState:        string(state.ChargeState),
+			ChargeLimit:        state.ChargeLimit,
+			ChargingRateKW:     state.ChargingRate,
+			ChargeCompleteAt:   state.TimeToCharge,
+			IsLocked:           state.IsLocked,
+			IsOnline:           state.IsOnline,
+			OdometerMiles:      state.Odometer,
+			CabinTempF:         state.CabinTemp,
+			ExteriorTempF:      state.ExteriorTemp,
+			ReadyScore:         state.ReadyScore,
+		},
+		Closures: ClosuresOutput{
+			Doors:    newCornersOutput(state.Doors),
+			Windows:  newCornersOutput(state.Windows),
+			Frunk:    string(state.Frunk),
+			Liftgate: string(state.Liftgate),
+		},
+		Tires: TiresOutput{
+			FrontLeft:  newTireOutput(state.TirePressures.FrontLeftStatus, state.TirePressures.FrontLeft),
+			FrontRight: newTireOutput(state.TirePressures.FrontRightStatus, state.TirePressures.FrontRight),
+			RearLeft:   newTireOutput(state.TirePressures.RearLeftStatus, state.TirePressures.RearLeft),
+			RearRight:  newTireOutput(state.TirePressures.RearRightStatus, state.TirePressures.RearRight),
+		},
+		Issues: state.IssuesAt(state.UpdatedAt),
+	}
+
+	if tonneau, ok := state.Tonneau(); ok {
+		tc := string(tonneau)
+		out.Closures.TonneauCover = &tc
+	}
+
+	if state.Location != nil {
+		out.Location = &LocationOutput{
+			Latitude:  state.Location.Latitude,
+			Longitude: state.Location.Longitude,
+		}
+	}
+
+	// Always emit an array, never null
+	if out.Issues == nil {
+		out.Issues = []string{}
+	}
+
+	return out
+}
+
+func newCornersOutput(c model.Closures) CornersOutput {
+	return CornersOutput{
+		FrontLeft:  string(c.FrontLeft),
+		FrontRight: string(c.FrontRight),
+		RearLeft:   string(c.RearLeft),
+		RearRight:  string(c.RearRight),
+	}
+}
+
+func newTireOutput(status model.TirePressureStatus, psi float64) TireOutput {
+	out := TireOutput{Status: string(status)}
+	if out.Status == "" {
+		out.Status = string(model.TirePressureStatusUnknown)
+	}
+	if psi > 0 {
+		out.PSI = &psi
+	}
+	return out
+}
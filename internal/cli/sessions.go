@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+// DetectChargingSessions groups consecutive charging states into sessions,
+// oldest first. States may come in any order. A session ends at the first
+// state that isn't charging; one still charging at the end of the history
+// ends at its last state.
+func DetectChargingSessions(states []*model.VehicleState) []*model.ChargingSession {
+	ordered := make([]*model.VehicleState, len(states))
+	copy(ordered, states)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].UpdatedAt.Before(ordered[j].UpdatedAt)
+	})
+
+	tracker := model.NewChargingSessionTracker()
+	var sessions []*model.ChargingSession
+	for _, state := range ordered {
+		if session := tracker.Observe(state); session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	if session := tracker.Flush(); session != nil {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// SessionFormatter writes charging sessions as CSV, one row per session
+type SessionFormatter struct {
+	TimeFormat TimeFormat
+	LocalTime  bool
+}
+
+// FormatSessions writes a header and one row per session. Rates are left
+// empty when the vehicle reported none during the session.
+func (f *SessionFormatter) FormatSessions(w io.Writer, sessions []*model.ChargingSession) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"StartTime", "EndTime",
+		"StartLevel", "EndLevel",
+		"EnergyAddedKWh", "AvgRateKW", "PeakRateKW",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		row := []string{
+			f.TimeFormat.Format(displayTime(session.StartTime, f.LocalTime), csvTimeLayout),
+			f.TimeFormat.Format(displayTime(session.EndTime, f.LocalTime), csvTimeLayout),
+			formatFloat(session.StartLevel, 1),
+			formatFloat(session.EndLevel, 1),
+			formatFloat(session.EnergyAdded, 1),
+			formatRate(session.AvgRateKW),
+			formatRate(session.PeakRateKW),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatRate(kw float64) string {
+	if kw <= 0 {
+		return ""
+	}
+	return formatFloat(kw, 1)
+}
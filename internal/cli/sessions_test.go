@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// chargingCurve returns a day of states every 30 minutes: an evening
+// session from 40% to 55% at 11 kW, a drive, and a DC fast charge still in
+// progress at the end. Newest first, as the store returns them.
+func chargingCurve(start time.Time) []*model.VehicleState {
+	steps := []struct {
+		charge model.ChargeState
+		level  float64
+		rate   float64
+	}{
+		{model.ChargeStateNotCharging, 40, 0},
+		{model.ChargeStateCharging, 40, 11},
+		{model.ChargeStateCharging, 45, 11},
+		{model.ChargeStateCharging, 50, 11},
+		{model.ChargeStateComplete, 55, 0},
+		{model.ChargeStateNotCharging, 55, 0},
+		{model.ChargeStateNotCharging, 30, 0},
+		{model.ChargeStateCharging, 30, 150},
+		{model.ChargeStateCharging, 50, 190},
+		{model.ChargeStateCharging, 70, 110},
+	}
+
+	states := make([]*model.VehicleState, len(steps))
+	for i, step := range steps {
+		state := &model.VehicleState{
+			VehicleID:       "vehicle-123",
+			UpdatedAt:       start.Add(time.Duration(i) * 30 * time.Minute),
+			ChargeState:     step.charge,
+			BatteryLevel:    step.level,
+			BatteryCapacity: 135,
+		}
+		if step.rate > 0 {
+			rate := step.rate
+			state.ChargingRate = &rate
+		}
+		states[len(steps)-1-i] = state
+	}
+	return states
+}
+
+func TestDetectChargingSessions(t *testing.T) {
+	start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+	sessions := DetectChargingSessions(chargingCurve(start))
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+
+	home, fast := sessions[0], sessions[1]
+	if !home.StartTime.Equal(start.Add(30*time.Minute)) || !home.EndTime.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("home session %v to %v, want 18:30 to 20:00", home.StartTime, home.EndTime)
+	}
+	if home.StartLevel != 40 || home.EndLevel != 55 || home.EnergyAdded != 20.25 || home.AvgRateKW != 11 || home.PeakRateKW != 11 {
+		t.Errorf("home session = %+v, want 40%% → 55%%, 20.25 kWh at 11 kW", home)
+	}
+
+	// Still charging at the end of the history
+	if !fast.EndTime.Equal(start.Add(4*time.Hour + 30*time.Minute)) {
+		t.Errorf("fast session ends at %v, want the last state", fast.EndTime)
+	}
+	if fast.EnergyAdded != 54 || fast.AvgRateKW != 150 || fast.PeakRateKW != 190 {
+		t.Errorf("fast session = %+v, want 54 kWh, avg 150 kW, peak 190 kW", fast)
+	}
+
+	if got := DetectChargingSessions(nil); len(got) != 0 {
+		t.Errorf("DetectChargingSessions(nil) = %+v, want none", got)
+	}
+}
+
+func TestSessionFormatter(t *testing.T) {
+	start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+	sessions := DetectChargingSessions(chargingCurve(start))
+	sessions[0].AvgRateKW, sessions[0].PeakRateKW = 0, 0 // No rates reported
+
+	var buf bytes.Buffer
+	formatter := &SessionFormatter{TimeFormat: TimeFormatUnix}
+	if err := formatter.FormatSessions(&buf, sessions); err != nil {
+		t.Fatalf("FormatSessions failed: %v", err)
+	}
+
+	want := "StartTime,EndTime,StartLevel,EndLevel,EnergyAddedKWh,AvgRateKW,PeakRateKW\n" +
+		"1705343400,1705348800,40.0,55.0,20.2,,\n" +
+		"1705354200,1705357800,30.0,70.0,54.0,150.0,190.0\n"
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestExportCommand_Sessions(t *testing.T) {
+	testStore, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = testStore.Close() }()
+
+	ctx := context.Background()
+	start := time.Now().Add(-6 * time.Hour).Truncate(time.Second)
+	for _, state := range chargingCurve(start) {
+		if err := testStore.SaveState(ctx, state); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	output := &bytes.Buffer{}
+	cmd := NewExportCommand(testStore, "vehicle-123", output)
+	if err := cmd.Run(ctx, ExportOptions{Format: FormatCSV, Sessions: true}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "StartTime,") {
+		t.Errorf("want a header and two sessions, got:\n%s", output.String())
+	}
+
+	if err := cmd.Run(ctx, ExportOptions{Format: FormatJSON, Sessions: true}); err == nil {
+		t.Error("Expected an error for sessions in JSON")
+	}
+	if err := cmd.Run(ctx, ExportOptions{Format: FormatCSV, Sessions: true, Limit: 10}); err == nil {
+		t.Error("Expected an error for sessions with a limit")
+	}
+}
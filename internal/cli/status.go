@@ -2,9 +2,12 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 	"github.com/pfrederiksen/rivian-ls/internal/rivian"
@@ -16,8 +19,25 @@ type StatusOptions struct {
 	Format  OutputFormat
 	Pretty  bool
 	Offline bool // Use cached state instead of live query
+
+	TimeFormat TimeFormat // Timestamp rendering for CSV/table output
+	LocalTime  bool       // Show timestamps in the local zone (presentation only)
+	Redact     Redaction  // Hide location/VIN in the output
+
+	// Condition checks for scripts, evaluated after the status is printed
+	ExitOnIssues bool    // Fail with ErrVehicleIssues on any warning or critical issue
+	ExitBelow    float64 // Fail with ErrBatteryBelow when battery % < ExitBelow (0 = off)
+
+	ReadyBy time.Time // Report whether charging reaches the limit by then (zero = off)
 }
 
+// Condition errors returned by StatusCommand.Run after the status has been
+// printed, so callers can map them to distinct exit codes.
+var (
+	ErrBatteryBelow  = errors.New("battery below threshold")
+	ErrVehicleIssues = errors.New("vehicle has issues")
+)
+
 // StatusCommand displays current vehicle state
 type StatusCommand struct {
 	client      rivian.Client
@@ -71,21 +91,19 @@ func (c *StatusCommand) Run(ctx context.Context, opts StatusOptions) error {
 			return fmt.Errorf("get vehicle state: %w", err)
 		}
 
-		state = model.FromRivianVehicleState(rivState)
-
-		// Enrich with vehicle identity (not in GetVehicleState API)
-		if c.vehicleName != "" {
-			state.Name = c.vehicleName
-		}
-		if c.vehicleVIN != "" {
-			state.VIN = c.vehicleVIN
-		}
-		if c.vehicleModel != "" {
-			state.Model = c.vehicleModel
-		}
-
-		// Update derived metrics
-		state.UpdateReadyScore()
+		// Go through the reducer like the TUI, so identity merging and
+		// derived metrics match
+		reducer := model.NewReducer()
+		reducer.Dispatch(model.VehicleListReceived{
+			Vehicles: []rivian.Vehicle{{
+				ID:    c.vehicleID,
+				VIN:   c.vehicleVIN,
+				Name:  c.vehicleName,
+				Model: c.vehicleModel,
+			}},
+			VehicleID: c.vehicleID,
+		})
+		state = reducer.Dispatch(model.VehicleStateReceived{State: rivState})
 
 		// Save to store for offline use
 		if c.store != nil {
@@ -94,13 +112,90 @@ func (c *StatusCommand) Run(ctx context.Context, opts StatusOptions) error {
 				_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to save state: %v\n", err)
 			}
 		}
+
+		if warning := model.RangeUnitWarning(state); warning != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
 	}
 
 	// Format and output
-	formatter, err := NewFormatter(opts.Format, opts.Pretty)
+	formatter, err := NewFormatter(opts.Format, FormatOptions{
+		Pretty:     opts.Pretty,
+		TimeFormat: opts.TimeFormat,
+		LocalTime:  opts.LocalTime,
+		Redact:     opts.Redact,
+	})
 	if err != nil {
 		return fmt.Errorf("create formatter: %w", err)
 	}
 
-	return formatter.FormatState(c.output, state)
+	if err := formatter.FormatState(c.output, state); err != nil {
+		return err
+	}
+
+	if opts.Offline {
+		if err := c.writeDataAge(ctx, opts); err != nil {
+			return err
+		}
+	}
+
+	if !opts.ReadyBy.IsZero() {
+		// Keep machine-readable output parseable
+		w := c.output
+		if opts.Format != FormatText {
+			w = os.Stderr
+		}
+		if err := writeReadyBy(w, state, opts.ReadyBy); err != nil {
+			return err
+		}
+	}
+
+	return checkConditions(state, opts)
+}
+
+// writeDataAge reports how long ago the cached state was synced. Like the
+// ready-by line, it goes to stderr for machine-readable formats.
+func (c *StatusCommand) writeDataAge(ctx context.Context, opts StatusOptions) error {
+	synced, err := c.store.GetLastSyncTime(ctx, c.vehicleID)
+	if err != nil {
+		return fmt.Errorf("get last sync time: %w", err)
+	}
+	w := c.output
+	if opts.Format != FormatText {
+		w = os.Stderr
+	}
+	_, err = fmt.Fprintf(w, "Data age: %s (as of %s)\n", model.FormatAge(time.Since(synced)), displayTime(synced, opts.LocalTime).Format(time.RFC3339))
+	return err
+}
+
+// writeReadyBy reports whether charging will reach the charge limit by target.
+func writeReadyBy(w io.Writer, state *model.VehicleState, target time.Time) error {
+	_, err := fmt.Fprintf(w, "Ready by %s: %s\n", target.Format("Mon 3:04 PM"), model.ReadyBySummary(state, target))
+	return err
+}
+
+// checkConditions reports the first failed condition check. The battery
+// threshold takes precedence over general issues.
+func checkConditions(state *model.VehicleState, opts StatusOptions) error {
+	if opts.ExitBelow > 0 && state.BatteryLevel < opts.ExitBelow {
+		return fmt.Errorf("%w: %.1f%% < %.0f%%", ErrBatteryBelow, state.BatteryLevel, opts.ExitBelow)
+	}
+	if opts.ExitOnIssues {
+		if issues := actionableIssues(state.GetIssueList()); len(issues) > 0 {
+			return fmt.Errorf("%w: %s", ErrVehicleIssues, strings.Join(issues, "; "))
+		}
+	}
+	return nil
+}
+
+// actionableIssues drops informational issues, such as an unlocked
+// vehicle, keeping the text of warning and critical ones.
+func actionableIssues(issues []model.Issue) []string {
+	var actionable []string
+	for _, issue := range issues {
+		if issue.Severity >= model.IssueWarning {
+			actionable = append(actionable, issue.Text)
+		}
+	}
+	return actionable
 }
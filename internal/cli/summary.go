@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// maxSummaryIssues is how many of the most common issues a summary lists.
+const maxSummaryIssues = 5
+
+// SummaryOptions configures the summary command
+type SummaryOptions struct {
+	Period   time.Duration // How far back to summarize
+	Markdown bool          // Render as Markdown instead of plain text
+}
+
+// Summary aggregates stored history over a period. It is computed locally
+// and never leaves the machine.
+type Summary struct {
+	Start time.Time
+	End   time.Time
+
+	States      int     // Number of stored states in the period
+	Miles       float64 // Odometer distance covered
+	Charges     int     // Completed charging sessions
+	EnergyAdded float64 // kWh added across those sessions
+	EnergyUsed  float64 // kWh consumed while not charging
+	Issues      []IssueCount
+}
+
+// IssueCount is an issue and the number of states that reported it.
+type IssueCount struct {
+	Issue string
+	Count int
+}
+
+// Efficiency returns the average driving efficiency in mi/kWh, or 0 when
+// no energy use was recorded.
+func (s *Summary) Efficiency() float64 {
+	if s.EnergyUsed <= 0 {
+		return 0
+	}
+	return s.Miles / s.EnergyUsed
+}
+
+// SummaryCommand prints a report of stored history for one vehicle
+type SummaryCommand struct {
+	store     *store.Store
+	vehicleID string
+	output    io.Writer
+}
+
+// NewSummaryCommand creates a new summary command
+func NewSummaryCommand(store *store.Store, vehicleID string, output io.Writer) *SummaryCommand {
+	return &SummaryCommand{
+		store:     store,
+		vehicleID: vehicleID,
+		output:    output,
+	}
+}
+
+// Run executes the summary command
+func (c *SummaryCommand) Run(ctx context.Context, opts SummaryOptions) error {
+	if c.store == nil {
+		return fmt.Errorf("store not available for summary")
+	}
+	if opts.Period <= 0 {
+		return fmt.Errorf("period must be positive")
+	}
+
+	end := time.Now()
+	start := end.Add(-opts.Period)
+
+	states, err := c.store.GetStates(ctx, c.vehicleID, start, end)
+	if err != nil {
+		return fmt.Errorf("query states: %w", err)
+	}
+	sessions, err := c.store.GetChargingSessions(ctx, c.vehicleID, start)
+	if err != nil {
+		return fmt.Errorf("query charging sessions: %w", err)
+	}
+
+	summary := Summarize(states, sessions)
+	summary.Start, summary.End = start, end
+
+	if opts.Markdown {
+		return writeSummaryMarkdown(c.output, summary)
+	}
+	return writeSummaryText(c.output, summary)
+}
+
+// Summarize aggregates states (newest first, as returned by the store) and
+// stored charging sessions. When no sessions were stored, they are rebuilt
+// from the states themselves.
+func Summarize(states []*model.VehicleState, sessions []*model.ChargingSession) *Summary {
+	summary := &Summary{States: len(states)}
+
+	tracker := model.NewChargingSessionTracker()
+	var replayed []*model.ChargingSession
+	issueCounts := make(map[string]int)
+
+	var prev *model.VehicleState
+	for i := len(states) - 1; i >= 0; i-- {
+		state := states[i]
+
+		if session := tracker.Observe(state); session != nil {
+			replayed = append(replayed, session)
+		}
+
+		for _, issue := range state.IssuesAt(state.UpdatedAt) {
+			// Info-level issues are too noisy to be worth ranking
+			if !strings.HasPrefix(issue, "Info:") {
+				issueCounts[issue]++
+			}
+		}
+
+		if prev != nil {
+			if prev.Odometer > 0 && state.Odometer > prev.Odometer {
+				summary.Miles += state.Odometer - prev.Odometer
+			}
+			drop := prev.BatteryLevel - state.BatteryLevel
+			if drop > 0 && !prev.IsCharging() && !state.IsCharging() && state.BatteryCapacity > 0 {
+				summary.EnergyUsed += drop / 100 * state.BatteryCapacity
+			}
+		}
+		prev = state
+	}
+
+	if len(sessions) == 0 {
+		sessions = replayed
+	}
+	summary.Charges = len(sessions)
+	for _, session := range sessions {
+		summary.EnergyAdded += session.EnergyAdded
+	}
+
+	for issue, count := range issueCounts {
+		summary.Issues = append(summary.Issues, IssueCount{Issue: issue, Count: count})
+	}
+	sort.Slice(summary.Issues, func(i, j int) bool {
+		if summary.Issues[i].Count != summary.Issues[j].Count {
+			return summary.Issues[i].Count > summary.Issues[j].Count
+		}
+		return summary.Issues[i].Issue < summary.Issues[j].Issue
+	})
+	if len(summary.Issues) > maxSummaryIssues {
+		summary.Issues = summary.Issues[:maxSummaryIssues]
+	}
+
+	return summary
+}
+
+// summaryRows returns the label/value pairs shared by both renderings
+func summaryRows(s *Summary) [][2]string {
+	efficiency := "N/A"
+	if e := s.Efficiency(); e > 0 {
+		efficiency = fmt.Sprintf("%.2f mi/kWh", e)
+	}
+	return [][2]string{
+		{"Total miles", fmt.Sprintf("%.1f", s.Miles)},
+		{"Charges", fmt.Sprintf("%d", s.Charges)},
+		{"Energy added", fmt.Sprintf("%.1f kWh", s.EnergyAdded)},
+		{"Avg efficiency", efficiency},
+		{"States recorded", fmt.Sprintf("%d", s.States)},
+	}
+}
+
+func writeSummaryText(w io.Writer, s *Summary) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary: %s to %s\n\n", s.Start.Format("2006-01-02"), s.End.Format("2006-01-02"))
+	for _, row := range summaryRows(s) {
+		fmt.Fprintf(&b, "%-16s %s\n", row[0]+":", row[1])
+	}
+
+	b.WriteString("\nMost common issues:\n")
+	if len(s.Issues) == 0 {
+		b.WriteString("  None\n")
+	}
+	for _, issue := range s.Issues {
+		fmt.Fprintf(&b, "  %s (%d)\n", issue.Issue, issue.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSummaryMarkdown(w io.Writer, s *Summary) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Summary: %s to %s\n\n", s.Start.Format("2006-01-02"), s.End.Format("2006-01-02"))
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	for _, row := range summaryRows(s) {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], row[1])
+	}
+
+	b.WriteString("\n## Most common issues\n\n")
+	if len(s.Issues) == 0 {
+		b.WriteString("None\n")
+	}
+	for _, issue := range s.Issues {
+		fmt.Fprintf(&b, "- %s (%d)\n", issue.Issue, issue.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -1,11 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,6 +24,57 @@ type WatchOptions struct {
 	Format   OutputFormat
 	Pretty   bool
 	Interval time.Duration // Polling interval (0 = use WebSocket)
+
+	TimeFormat TimeFormat // Timestamp rendering for CSV/table output
+	LocalTime  bool       // Show timestamps in the local zone (presentation only)
+	Redact     Redaction  // Hide location/VIN in the output
+
+	// RefreshInPlace redraws the table over the previous update instead of
+	// appending. Table format only; callers should enable it only on a TTY.
+	RefreshInPlace bool
+
+	// Adaptive polling: poll at FastInterval while the vehicle is active
+	// (charging or moving) and back off to SlowInterval once it is idle.
+	// Overrides Interval and WebSocket mode.
+	Adaptive     bool
+	FastInterval time.Duration
+	SlowInterval time.Duration
+
+	// ReadyBy reports with each update whether charging reaches the limit
+	// by this time of day, an offset from midnight (nil = off)
+	ReadyBy *time.Duration
+}
+
+// Default adaptive polling bounds
+const (
+	DefaultFastInterval = 1 * time.Minute
+	DefaultSlowInterval = 30 * time.Minute
+)
+
+// errWebSocketLost means the WebSocket died mid-session and could not be
+// re-established.
+var errWebSocketLost = errors.New("websocket connection lost")
+
+// adaptiveIdlePolls is how many consecutive idle polls it takes before
+// backing off to the slow interval.
+const adaptiveIdlePolls = 3
+
+// pollFields are the state fields polling fetches between full fetches:
+// the ones that change while the vehicle is driven or charged.
+var pollFields = []rivian.StateField{
+	rivian.StateFieldLocation, rivian.StateFieldBattery, rivian.StateFieldRange,
+	rivian.StateFieldCharging, rivian.StateFieldOdometer, rivian.StateFieldClimate,
+	rivian.StateFieldDoors,
+}
+
+// fullStatePolls is how many polls apart the full state is fetched, so
+// windows, closures and tires don't go stale.
+const fullStatePolls = 10
+
+// stateFieldsClient fetches part of the vehicle state, as
+// *rivian.HTTPClient does.
+type stateFieldsClient interface {
+	GetVehicleStateFields(ctx context.Context, vehicleID string, fields []rivian.StateField) (*rivian.VehicleState, error)
 }
 
 // WatchCommand streams real-time vehicle state updates
@@ -29,6 +85,18 @@ type WatchCommand struct {
 	csrfToken string
 	appSessID string
 	output    io.Writer
+	sessions  *model.ChargingSessionTracker
+	reducer   *model.Reducer
+	prev      *model.VehicleState // Last recorded state, for change alerts
+	logger    *slog.Logger        // WebSocket events; never nil
+
+	polled *rivian.VehicleState // Last fetched state, merged onto by partial polls
+	polls  int                  // Polls since the last full fetch
+
+	inPlace *inPlaceFormatter // Set with RefreshInPlace; counts lines from notify
+
+	readyBy    *time.Duration // From WatchOptions.ReadyBy
+	textOutput bool           // Ready-by lines go to output rather than stderr
 }
 
 // NewWatchCommand creates a new watch command
@@ -40,15 +108,40 @@ func NewWatchCommand(client rivian.Client, store *store.Store, vehicleID, csrfTo
 		csrfToken: csrfToken,
 		appSessID: appSessID,
 		output:    output,
+		sessions:  model.NewChargingSessionTracker(),
+		reducer:   model.NewReducer(),
+		logger:    slog.New(slog.DiscardHandler),
 	}
 }
 
+// SetLogger records WebSocket connects, disconnects, reconnects and
+// subscription errors in WebSocket mode. nil turns logging off.
+func (c *WatchCommand) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	c.logger = logger
+}
+
 // Run executes the watch command
 func (c *WatchCommand) Run(ctx context.Context, opts WatchOptions) error {
-	formatter, err := NewFormatter(opts.Format, opts.Pretty)
+	formatter, err := NewFormatter(opts.Format, FormatOptions{
+		Pretty:     opts.Pretty,
+		TimeFormat: opts.TimeFormat,
+		LocalTime:  opts.LocalTime,
+		Redact:     opts.Redact,
+	})
 	if err != nil {
 		return fmt.Errorf("create formatter: %w", err)
 	}
+	c.readyBy, c.textOutput = opts.ReadyBy, opts.Format == FormatText
+	if opts.RefreshInPlace {
+		if opts.Format != FormatTable {
+			return fmt.Errorf("refresh in place requires table format, got %q", opts.Format)
+		}
+		c.inPlace = &inPlaceFormatter{Formatter: formatter}
+		formatter = c.inPlace
+	}
 
 	// Set up signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(ctx)
@@ -63,6 +156,20 @@ func (c *WatchCommand) Run(ctx context.Context, opts WatchOptions) error {
 		cancel()
 	}()
 
+	if opts.Adaptive {
+		fast, slow := opts.FastInterval, opts.SlowInterval
+		if fast <= 0 {
+			fast = DefaultFastInterval
+		}
+		if slow <= 0 {
+			slow = DefaultSlowInterval
+		}
+		if slow < fast {
+			return fmt.Errorf("slow interval %s is shorter than fast interval %s", slow, fast)
+		}
+		return c.runAdaptivePolling(ctx, formatter, newAdaptiveInterval(fast, slow))
+	}
+
 	if opts.Interval > 0 {
 		// Polling mode
 		return c.runPolling(ctx, formatter, opts.Interval)
@@ -72,8 +179,13 @@ func (c *WatchCommand) Run(ctx context.Context, opts WatchOptions) error {
 	wsErr := c.runWebSocket(ctx, formatter)
 	if wsErr != nil {
 		// WebSocket failed - fall back to polling mode
-		_, _ = fmt.Fprintln(os.Stderr, "\nWebSocket connection failed (this is a known Rivian API limitation).")
-		_, _ = fmt.Fprintln(os.Stderr, "Falling back to polling mode (30-second intervals)...")
+		c.logger.Warn("live updates unavailable, polling", "error", wsErr, "poll_interval", 30*time.Second)
+		if errors.Is(wsErr, errWebSocketLost) {
+			c.notify("\nWebSocket connection lost and could not be re-established: %v\n", wsErr)
+		} else {
+			c.notify("\nWebSocket connection failed (this is a known Rivian API limitation).\n")
+		}
+		c.notify("Falling back to polling mode (30-second intervals)...\n")
 		return c.runPolling(ctx, formatter, 30*time.Second)
 	}
 	return nil
@@ -85,7 +197,7 @@ func (c *WatchCommand) runPolling(ctx context.Context, formatter Formatter, inte
 	defer ticker.Stop()
 
 	// Get initial state
-	if err := c.fetchAndOutput(ctx, formatter); err != nil {
+	if _, err := c.fetchAndOutput(ctx, formatter); err != nil {
 		return err
 	}
 
@@ -95,14 +207,72 @@ func (c *WatchCommand) runPolling(ctx context.Context, formatter Formatter, inte
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			if err := c.fetchAndOutput(ctx, formatter); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Error fetching state: %v\n", err)
+			if _, err := c.fetchAndOutput(ctx, formatter); err != nil {
+				c.notify("Error fetching state: %v\n", err)
 				// Continue polling despite errors
 			}
 		}
 	}
 }
 
+// runAdaptivePolling polls with an interval that follows vehicle activity
+func (c *WatchCommand) runAdaptivePolling(ctx context.Context, formatter Formatter, schedule *adaptiveInterval) error {
+	// Get initial state
+	state, err := c.fetchAndOutput(ctx, formatter)
+	if err != nil {
+		return err
+	}
+	interval := schedule.Next(state)
+
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+			state, err := c.fetchAndOutput(ctx, formatter)
+			if err != nil {
+				c.notify("Error fetching state: %v\n", err)
+				// Keep the current interval and continue polling despite errors
+				continue
+			}
+			interval = schedule.Next(state)
+		}
+	}
+}
+
+// adaptiveInterval picks the next polling interval from consecutive state diffs
+type adaptiveInterval struct {
+	fast, slow time.Duration
+	prev       *model.VehicleState
+	idlePolls  int
+}
+
+func newAdaptiveInterval(fast, slow time.Duration) *adaptiveInterval {
+	return &adaptiveInterval{fast: fast, slow: slow}
+}
+
+// Next records state and returns how long to wait before the next poll.
+// Charging or a changing odometer means active; after adaptiveIdlePolls
+// consecutive inactive polls the slow interval is used.
+func (a *adaptiveInterval) Next(state *model.VehicleState) time.Duration {
+	active := state.IsCharging() ||
+		(a.prev != nil && state.Odometer != a.prev.Odometer)
+	a.prev = state
+
+	if active {
+		a.idlePolls = 0
+		return a.fast
+	}
+
+	a.idlePolls++
+	if a.idlePolls >= adaptiveIdlePolls {
+		return a.slow
+	}
+	return a.fast
+}
+
 // runWebSocket implements WebSocket-based updates
 func (c *WatchCommand) runWebSocket(ctx context.Context, formatter Formatter) error {
 	// Get credentials from HTTP client
@@ -118,6 +288,21 @@ func (c *WatchCommand) runWebSocket(ctx context.Context, formatter Formatter) er
 
 	// Create WebSocket client
 	wsClient := rivian.NewWebSocketClient(creds, c.csrfToken, c.appSessID)
+	wsClient.SetTLSConfig(httpClient.TLSConfig())
+	wsClient.SetLogger(c.logger.With("vehicle_id", c.vehicleID))
+
+	// Report reconnects so mode switches aren't silent
+	var gaveUpErr error
+	wsClient.SetEventHandler(func(event rivian.ConnectionEvent, err error) {
+		switch event {
+		case rivian.EventDisconnected:
+			c.notify("WebSocket disconnected, reconnecting...\n")
+		case rivian.EventReconnected:
+			c.notify("WebSocket reconnected, resuming live updates\n")
+		case rivian.EventGaveUp:
+			gaveUpErr = err
+		}
+	})
 
 	// Connect
 	if err := wsClient.Connect(ctx); err != nil {
@@ -132,54 +317,67 @@ func (c *WatchCommand) runWebSocket(ctx context.Context, formatter Formatter) er
 	}
 	defer func() { _ = subscription.Close() }()
 
-	_, _ = fmt.Fprintln(os.Stderr, "Watching for updates... (Press Ctrl+C to stop)")
+	c.notify("Watching for updates... (Press Ctrl+C to stop)\n")
 
 	// Get initial state via HTTP
-	if err := c.fetchAndOutput(ctx, formatter); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to get initial state: %v\n", err)
+	if _, err := c.fetchAndOutput(ctx, formatter); err != nil {
+		c.notify("Warning: Failed to get initial state: %v\n", err)
 	}
 
-	// Process updates
-	reducer := model.NewReducer()
-
+	// Process updates on top of the initial state
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 
-		case update := <-subscription.Updates():
-			if update == nil {
-				continue
+		case <-wsClient.Done():
+			if ctx.Err() != nil {
+				return nil
 			}
-
-			// Convert WebSocket update to partial state update
-			updates := extractVehicleStateUpdates(update)
-			if len(updates) == 0 {
-				continue
+			if gaveUpErr != nil {
+				return fmt.Errorf("%w: %v", errWebSocketLost, gaveUpErr)
 			}
+			return errWebSocketLost
 
-			// Apply partial update
-			event := model.PartialStateUpdate{
-				VehicleID: c.vehicleID,
-				Updates:   updates,
-			}
-			state := reducer.Dispatch(event)
+		case <-subscription.Done():
+			return fmt.Errorf("%w: %v", errWebSocketLost, subscription.Err())
 
-			// Output updated state
-			if err := formatter.FormatState(c.output, state); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Error formatting state: %v\n", err)
+		case update, ok := <-subscription.Updates():
+			if !ok {
+				return errWebSocketLost
 			}
-
-			// Save to store
-			if c.store != nil {
-				if err := c.store.SaveState(ctx, state); err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "Warning: Failed to save state: %v\n", err)
-				}
+			if update == nil {
+				continue
 			}
+
+			c.applyUpdate(ctx, formatter, extractVehicleStateUpdates(update))
 		}
 	}
 }
 
+// applyUpdate applies one WebSocket update, then outputs and records the
+// result. Updates that repeat the current values are dropped.
+func (c *WatchCommand) applyUpdate(ctx context.Context, formatter Formatter, updates map[string]interface{}) {
+	if len(updates) == 0 {
+		return
+	}
+
+	state, changed := c.reducer.DispatchPartial(model.PartialStateUpdate{
+		VehicleID: c.vehicleID,
+		Updates:   updates,
+	})
+	if !changed {
+		return
+	}
+
+	// Output updated state
+	if err := c.outputState(formatter, state); err != nil {
+		c.notify("Error formatting state: %v\n", err)
+	}
+
+	c.record(ctx, state)
+}
+
 // extractVehicleStateUpdates parses WebSocket update payload into field updates
 func extractVehicleStateUpdates(update map[string]interface{}) map[string]interface{} {
 	updates := make(map[string]interface{})
@@ -231,22 +429,142 @@ func extractTimestampedBool(vState map[string]interface{}, field, key string, up
 	}
 }
 
-// fetchAndOutput fetches current state and outputs it
-func (c *WatchCommand) fetchAndOutput(ctx context.Context, formatter Formatter) error {
-	rivState, err := c.client.GetVehicleState(ctx, c.vehicleID)
+// fetchAndOutput fetches current state, outputs it and returns it
+func (c *WatchCommand) fetchAndOutput(ctx context.Context, formatter Formatter) (*model.VehicleState, error) {
+	rivState, err := c.fetchState(ctx)
 	if err != nil {
+		return nil, err
+	}
+
+	state := c.reducer.Dispatch(model.VehicleStateReceived{State: rivState})
+
+	c.record(ctx, state)
+
+	return state, c.outputState(formatter, state)
+}
+
+// outputState formats a state, followed by the ready-by verdict when
+// enabled. Like status, the verdict goes to stderr for machine-readable
+// formats.
+func (c *WatchCommand) outputState(formatter Formatter, state *model.VehicleState) error {
+	if err := formatter.FormatState(c.output, state); err != nil {
 		return err
 	}
+	if c.readyBy == nil {
+		return nil
+	}
 
-	state := model.FromRivianVehicleState(rivState)
-	state.UpdateReadyScore()
+	target := model.NextClockTime(time.Now(), *c.readyBy)
+	if c.textOutput {
+		return writeReadyBy(c.output, state, target)
+	}
+	var line strings.Builder
+	_ = writeReadyBy(&line, state, target)
+	c.notify("%s", line.String())
+	return nil
+}
 
-	// Save to store
-	if c.store != nil {
-		if err := c.store.SaveState(ctx, state); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to save state: %v\n", err)
+// fetchState fetches the vehicle state. Clients that can fetch part of it
+// only fetch pollFields, merged onto the last state, between full fetches
+// every fullStatePolls polls.
+func (c *WatchCommand) fetchState(ctx context.Context) (*rivian.VehicleState, error) {
+	partial, ok := c.client.(stateFieldsClient)
+	c.polls++
+	if !ok || c.polled == nil || c.polls >= fullStatePolls {
+		state, err := c.client.GetVehicleState(ctx, c.vehicleID)
+		if err != nil {
+			return nil, err
 		}
+		c.polled, c.polls = state, 0
+		return state, nil
+	}
+
+	state, err := partial.GetVehicleStateFields(ctx, c.vehicleID, pollFields)
+	if err != nil {
+		return nil, err
+	}
+	c.polled = rivian.MergeStateFields(c.polled, state, pollFields)
+	return c.polled, nil
+}
+
+// record saves a state to the store, finalizes the charging session it
+// ends, if any, and reports a charge limit change since the previous state
+func (c *WatchCommand) record(ctx context.Context, state *model.VehicleState) {
+	session := c.sessions.Observe(state)
+
+	prev := c.prev
+	if prev == nil && c.store != nil {
+		// Also catch a change made while watch wasn't running
+		prev, _ = c.store.GetLatestState(ctx, c.vehicleID)
+	}
+	if change, ok := model.DetectChargeLimitChange(prev, state); ok {
+		c.notify("%s\n", change)
+	}
+	c.prev = state
+
+	if c.store == nil {
+		return
+	}
+
+	if err := c.store.SaveState(ctx, state); err != nil {
+		c.notify("Warning: Failed to save state: %v\n", err)
+	}
+
+	if session != nil {
+		if err := c.store.SaveChargingSession(ctx, session); err != nil {
+			c.notify("Warning: Failed to save charging session: %v\n", err)
+			return
+		}
+		c.notify("Charging session saved: +%.0f%% (%.1f kWh) in %s\n",
+			session.SOCDelta(), session.EnergyAdded, session.Duration().Round(time.Minute))
+	}
+}
+
+// notify prints a message on stderr. With in-place refresh, the next
+// redraw clears it along with the previous table.
+func (c *WatchCommand) notify(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	_, _ = fmt.Fprint(os.Stderr, msg)
+	if c.inPlace != nil {
+		c.inPlace.addLines(strings.Count(msg, "\n"))
+	}
+}
+
+// inPlaceFormatter redraws each state over the previous one using ANSI
+// cursor control, so a terminal shows one compact table instead of an
+// ever-growing log.
+type inPlaceFormatter struct {
+	Formatter
+
+	mu    sync.Mutex // Messages can come from WebSocket event callbacks
+	lines int        // Lines written since the previous update started
+}
+
+// addLines counts lines printed below the table, e.g. on stderr, so the
+// next redraw clears them too. Before the first table there is nothing to
+// redraw over.
+func (f *inPlaceFormatter) addLines(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lines > 0 {
+		f.lines += n
+	}
+}
+
+func (f *inPlaceFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
+	var buf bytes.Buffer
+	if err := f.Formatter.FormatState(&buf, state); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lines > 0 {
+		// Move to the start of the previous output and clear to the end of screen
+		_, _ = fmt.Fprintf(w, "\x1b[%dF\x1b[J", f.lines)
 	}
+	f.lines = bytes.Count(buf.Bytes(), []byte("\n"))
 
-	return formatter.FormatState(c.output, state)
+	_, err := w.Write(buf.Bytes())
+	return err
 }
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheet names in XLSX exports
+const (
+	xlsxStatesSheet  = "States"
+	xlsxSummarySheet = "Summary"
+)
+
+// xlsxTimeFormat is the number format for timestamp cells
+const xlsxTimeFormat = "yyyy-mm-dd hh:mm:ss"
+
+// XLSXFormatter writes an Excel workbook with a "States" sheet (one row per
+// state, numbers and timestamps as typed cells) and a "Summary" sheet. A
+// workbook is a zip archive, so it is built in memory and can't stream.
+type XLSXFormatter struct {
+	LocalTime bool // Timestamps in the local zone instead of UTC (Excel dates have no zone)
+}
+
+func (f *XLSXFormatter) FormatState(w io.Writer, state *model.VehicleState) error {
+	return f.FormatStates(w, []*model.VehicleState{state})
+}
+
+func (f *XLSXFormatter) FormatStates(w io.Writer, states []*model.VehicleState) error {
+	book := excelize.NewFile()
+	defer func() { _ = book.Close() }()
+
+	timeStyle, err := book.NewStyle(&excelize.Style{CustomNumFmt: stringPtr(xlsxTimeFormat)})
+	if err != nil {
+		return fmt.Errorf("create time style: %w", err)
+	}
+	headerStyle, err := book.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("create header style: %w", err)
+	}
+
+	if err := book.SetSheetName("Sheet1", xlsxStatesSheet); err != nil {
+		return err
+	}
+	if err := f.writeStates(book, states, timeStyle, headerStyle); err != nil {
+		return fmt.Errorf("write %s sheet: %w", xlsxStatesSheet, err)
+	}
+
+	if _, err := book.NewSheet(xlsxSummarySheet); err != nil {
+		return err
+	}
+	if err := f.writeSummary(book, states, timeStyle, headerStyle); err != nil {
+		return fmt.Errorf("write %s sheet: %w", xlsxSummarySheet, err)
+	}
+
+	_, err = book.WriteTo(w)
+	return err
+}
+
+// writeStates fills the States sheet with the same columns as CSV output.
+func (f *XLSXFormatter) writeStates(book *excelize.File, states []*model.VehicleState, timeStyle, headerStyle int) error {
+	header := []interface{}{
+		"Timestamp", "VehicleID", "VIN", "Name", "Model",
+		"BatteryLevel", "RangeEstimate", "RangeStatus",
+		"ChargeState", "ChargeLimit", "ChargingRate",
+		"IsLocked", "IsOnline",
+		"Latitude", "Longitude",
+		"CabinTemp", "ExteriorTemp",
+		"Odometer",
+		"ReadyScore",
+	}
+	if err := book.SetSheetRow(xlsxStatesSheet, "A1", &header); err != nil {
+		return err
+	}
+	if err := book.SetRowStyle(xlsxStatesSheet, 1, 1, headerStyle); err != nil {
+		return err
+	}
+
+	for i, state := range states {
+		var lat, lon interface{}
+		if state.Location != nil {
+			lat, lon = state.Location.Latitude, state.Location.Longitude
+		}
+		row := []interface{}{
+			displayTime(state.UpdatedAt, f.LocalTime),
+			state.VehicleID,
+			state.VIN,
+			state.Name,
+			state.Model,
+			state.BatteryLevel,
+			state.RangeEstimate,
+			string(state.RangeStatus),
+			string(state.ChargeState),
+			state.ChargeLimit,
+			floatCell(state.ChargingRate),
+			state.IsLocked,
+			state.IsOnline,
+			lat,
+			lon,
+			floatCell(state.CabinTemp),
+			floatCell(state.ExteriorTemp),
+			state.Odometer,
+			floatCell(state.ReadyScore),
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := book.SetSheetRow(xlsxStatesSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	if err := book.SetColStyle(xlsxStatesSheet, "A", timeStyle); err != nil {
+		return err
+	}
+	if err := book.SetColWidth(xlsxStatesSheet, "A", "A", 20); err != nil {
+		return err
+	}
+	return book.SetPanes(xlsxStatesSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// writeSummary fills the Summary sheet with the time span covered and the
+// min/max/avg battery level and range.
+func (f *XLSXFormatter) writeSummary(book *excelize.File, states []*model.VehicleState, timeStyle, headerStyle int) error {
+	rows := [][]interface{}{
+		{"States", len(states)},
+	}
+	if len(states) > 0 {
+		first, last := states[0].UpdatedAt, states[0].UpdatedAt
+		for _, state := range states {
+			first, last = minTime(first, state.UpdatedAt), maxTime(last, state.UpdatedAt)
+		}
+		stats := summarizeStates(states)
+		rows = append(rows,
+			[]interface{}{"First", displayTime(first, f.LocalTime)},
+			[]interface{}{"Last", displayTime(last, f.LocalTime)},
+			nil,
+			[]interface{}{"Metric", "Min", "Max", "Avg"},
+			[]interface{}{"Battery (%)", stats.minBattery, stats.maxBattery, stats.avgBattery},
+			[]interface{}{"Range (mi)", stats.minRange, stats.maxRange, stats.avgRange},
+		)
+	}
+
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := book.SetSheetRow(xlsxSummarySheet, cell, &row); err != nil {
+			return err
+		}
+		if row[0] == "Metric" {
+			if err := book.SetRowStyle(xlsxSummarySheet, i+1, i+1, headerStyle); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(states) > 0 {
+		if err := book.SetCellStyle(xlsxSummarySheet, "B2", "B3", timeStyle); err != nil {
+			return err
+		}
+	}
+	return book.SetColWidth(xlsxSummarySheet, "A", "B", 20)
+}
+
+// floatCell returns the value for an optional number, or nil for an empty
+// cell.
+func floatCell(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
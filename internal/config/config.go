@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -19,18 +23,41 @@ type Config struct {
 	DBPath      string `yaml:"db_path"`
 	TokenCache  string `yaml:"token_cache"`
 	DisableStore bool   `yaml:"disable_store"`
+	Retention    string `yaml:"retention"` // e.g. "180d"; empty or "0" keeps history forever
 
 	// Vehicle selection
 	Vehicle int `yaml:"vehicle"` // 0-based index
 
 	// Polling
-	PollInterval time.Duration `yaml:"poll_interval"`
+	PollInterval  time.Duration `yaml:"poll_interval"`
+	StateCacheTTL time.Duration `yaml:"state_cache_ttl"` // 0 disables the API response cache
+
+	// API
+	QueryOverrides string `yaml:"query_overrides"` // GraphQL file replacing built-in queries
+	RangeUnit      string `yaml:"range_unit"`      // Unit of the API's range estimate: "km" or "mi"
+
+	// Charging
+	ReadyBy         string  `yaml:"ready_by"`         // e.g. "07:00"; empty turns the ready-by check off
+	BatteryCapacity float64 `yaml:"battery_capacity"` // Usable pack capacity in kWh; 0 estimates it
 
 	// Output
-	Quiet   bool `yaml:"quiet"`
-	Verbose bool `yaml:"verbose"`
+	Format  string `yaml:"format"` // Default --format for status and watch; empty = text
+	Quiet   bool   `yaml:"quiet"`
+	Verbose bool   `yaml:"verbose"`
+
+	// Display
+	Units string `yaml:"units"` // TUI temperatures: "imperial" (°F) or "metric" (°C)
+	Theme string `yaml:"theme"` // "dark" or "light"
 }
 
+// Supported display settings
+const (
+	UnitsImperial = "imperial"
+	UnitsMetric   = "metric"
+	ThemeDark     = "dark"
+	ThemeLight    = "light"
+)
+
 // Load loads configuration from multiple sources in priority order:
 // 1. Environment variables
 // 2. Config file (~/.config/rivian-ls/config.yaml)
@@ -38,8 +65,34 @@ type Config struct {
 //
 // Note: CLI flags are applied separately by the caller and take highest precedence
 func Load() (*Config, error) {
-	cfg := &Config{
-		// Defaults
+	cfg := defaults()
+
+	// Load from config file if it exists
+	if err := cfg.loadFromFile(); err != nil {
+		// Non-fatal: config file is optional
+		_ = err
+	}
+
+	// Override with environment variables
+	cfg.loadFromEnv()
+
+	return cfg, nil
+}
+
+// LoadFile loads the defaults and the config file only, without environment
+// variables, so that saving it back doesn't persist them. Unlike Load, an
+// unreadable or invalid config file is an error.
+func LoadFile() (*Config, error) {
+	cfg := defaults()
+	if err := cfg.loadFromFile(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// defaults returns the built-in configuration
+func defaults() *Config {
+	return &Config{
 		DBPath:       defaultDBPath(),
 		TokenCache:   defaultTokenCachePath(),
 		Vehicle:      0,
@@ -47,18 +100,125 @@ func Load() (*Config, error) {
 		Quiet:        false,
 		Verbose:      false,
 		DisableStore: false,
+		Units:        UnitsImperial,
+		Theme:        ThemeDark,
 	}
+}
 
-	// Load from config file if it exists
-	if err := cfg.loadFromFile(); err != nil {
-		// Non-fatal: config file is optional
-		_ = err
+// MaxBatteryCapacity bounds the battery_capacity setting in kWh; the
+// largest Rivian packs are around 150 kWh.
+const MaxBatteryCapacity = 250
+
+// Formats accepted by the format setting
+var Formats = []string{"text", "json", "yaml", "csv", "table"}
+
+// validators check settings with a fixed set of values or a format of
+// their own
+var validators = map[string]func(*Config) error{
+	"vehicle": func(c *Config) error {
+		if c.Vehicle < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		return nil
+	},
+	"battery_capacity": func(c *Config) error {
+		if c.BatteryCapacity < 0 || c.BatteryCapacity > MaxBatteryCapacity {
+			return fmt.Errorf("must be 0 (estimate) up to %d kWh", MaxBatteryCapacity)
+		}
+		return nil
+	},
+	"format":     oneOf(func(c *Config) string { return c.Format }, append([]string{""}, Formats...)...),
+	"range_unit": oneOf(func(c *Config) string { return c.RangeUnit }, "", "km", "mi"),
+	"units":      oneOf(func(c *Config) string { return c.Units }, UnitsImperial, UnitsMetric),
+	"theme":      oneOf(func(c *Config) string { return c.Theme }, ThemeDark, ThemeLight),
+	"retention": func(c *Config) error {
+		_, err := ParseRetentionSetting(c.Retention)
+		return err
+	},
+	"ready_by": func(c *Config) error {
+		_, err := ParseReadyBy(c.ReadyBy)
+		return err
+	},
+}
+
+func oneOf(get func(*Config) string, allowed ...string) func(*Config) error {
+	return func(c *Config) error {
+		if !slices.Contains(allowed, get(c)) {
+			return fmt.Errorf("must be one of %s", strings.Join(slices.DeleteFunc(slices.Clone(allowed), func(s string) bool { return s == "" }), ", "))
+		}
+		return nil
 	}
+}
 
-	// Override with environment variables
-	cfg.loadFromEnv()
+// Keys returns the config file keys Set accepts, in file order.
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("yaml"); key != "password" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
 
-	return cfg, nil
+// Set sets the option with the given config file key from its text form,
+// e.g. Set("poll_interval", "1m"). The password can't be set: it is never
+// stored in the config file.
+func (c *Config) Set(key, value string) error {
+	if key == "password" {
+		return fmt.Errorf("the password is never stored in the config file; enter it when prompted or set RIVIAN_PASSWORD")
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	var field reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("yaml") == key {
+			field = v.Field(i)
+		}
+	}
+	if !field.IsValid() {
+		return fmt.Errorf("unknown key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+	}
+	old := reflect.New(field.Type()).Elem()
+	old.Set(field)
+
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", key, value)
+		}
+		field.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", key, value)
+		}
+		field.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", key, value)
+		}
+		field.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration %q, e.g. 30s or 5m", key, value)
+		}
+		field.SetInt(int64(d))
+	}
+
+	if validate, ok := validators[key]; ok {
+		if err := validate(c); err != nil {
+			field.Set(old)
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
 }
 
 // loadFromFile loads configuration from ~/.config/rivian-ls/config.yaml
@@ -81,6 +241,49 @@ func (c *Config) loadFromFile() error {
 	return nil
 }
 
+// Path returns the path of the config file.
+func Path() string {
+	return getConfigPath()
+}
+
+// Exists reports whether a config file is present.
+func Exists() bool {
+	path := getConfigPath()
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Save writes the configuration to the config file, creating its directory
+// if needed. The password is never written to disk.
+func (c *Config) Save() error {
+	path := getConfigPath()
+	if path == "" {
+		return fmt.Errorf("could not determine config path")
+	}
+
+	out := *c
+	out.Password = ""
+
+	data, err := yaml.Marshal(&out)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	// 0600: the file contains the account email
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+
+	return nil
+}
+
 // loadFromEnv loads configuration from environment variables
 func (c *Config) loadFromEnv() {
 	if email := os.Getenv("RIVIAN_EMAIL"); email != "" {
@@ -91,6 +294,11 @@ func (c *Config) loadFromEnv() {
 		c.Password = password
 	}
 
+	if dbPath := os.Getenv("RIVIAN_DB"); dbPath != "" {
+		c.DBPath = dbPath
+	}
+
+	// RIVIAN_DB_PATH is the older name and wins when both are set
 	if dbPath := os.Getenv("RIVIAN_DB_PATH"); dbPath != "" {
 		c.DBPath = dbPath
 	}
@@ -103,6 +311,38 @@ func (c *Config) loadFromEnv() {
 		c.DisableStore = true
 	}
 
+	if retention := os.Getenv("RIVIAN_RETENTION"); retention != "" {
+		c.Retention = retention
+	}
+
+	if overrides := os.Getenv("RIVIAN_QUERY_OVERRIDES"); overrides != "" {
+		c.QueryOverrides = overrides
+	}
+
+	if rangeUnit := os.Getenv("RIVIAN_RANGE_UNIT"); rangeUnit != "" {
+		c.RangeUnit = rangeUnit
+	}
+
+	if readyBy := os.Getenv("RIVIAN_READY_BY"); readyBy != "" {
+		c.ReadyBy = readyBy
+	}
+
+	if capacity := os.Getenv("RIVIAN_BATTERY_CAPACITY"); capacity != "" {
+		if kWh, err := strconv.ParseFloat(capacity, 64); err == nil {
+			c.BatteryCapacity = kWh
+		}
+	}
+
+	// Checked like the format setting; others, such as "auto", would break
+	// watch, so they are ignored
+	if format := os.Getenv("RIVIAN_FORMAT"); format != "" {
+		prev := c.Format
+		c.Format = format
+		if validators["format"](c) != nil {
+			c.Format = prev
+		}
+	}
+
 	if os.Getenv("RIVIAN_QUIET") == "true" {
 		c.Quiet = true
 	}
@@ -116,6 +356,12 @@ func (c *Config) loadFromEnv() {
 			c.PollInterval = duration
 		}
 	}
+
+	if ttl := os.Getenv("RIVIAN_STATE_CACHE_TTL"); ttl != "" {
+		if duration, err := time.ParseDuration(ttl); err == nil {
+			c.StateCacheTTL = duration
+		}
+	}
 }
 
 // getConfigPath returns the path to the config file
@@ -134,6 +380,12 @@ func getConfigPath() string {
 	return filepath.Join(home, ".config", "rivian-ls", "config.yaml")
 }
 
+// LegacyDBPaths returns database locations used by older versions, relative
+// to the working directory, that may still hold history.
+func LegacyDBPaths() []string {
+	return []string{"test-cli.db", "rivian-ls.db"}
+}
+
 // defaultDBPath returns the default database path
 func defaultDBPath() string {
 	home, err := os.UserHomeDir()
@@ -153,3 +405,50 @@ func defaultTokenCachePath() string {
 
 	return filepath.Join(home, ".local", "share", "rivian-ls", "credentials.json")
 }
+
+// ParseRetention parses a duration, additionally accepting a day suffix ("30d").
+func ParseRetention(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid day count %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// ParseReadyBy parses a time of day such as "07:00" or "7am" into its
+// offset from midnight. Empty means the ready-by check is off and returns nil.
+func ParseReadyBy(s string) (*time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{"15:04", "3:04pm", "3pm"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+			return &clock, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a time of day like 07:00 or 7am", s)
+}
+
+// ParseRetentionSetting parses the retention setting. Empty or zero means
+// history is kept forever and is returned as 0.
+func ParseRetentionSetting(s string) (time.Duration, error) {
+	switch strings.TrimSpace(s) {
+	case "", "0", "0d", "0s":
+		return 0, nil
+	}
+	return ParseRetention(strings.TrimSpace(s))
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -66,6 +68,33 @@ func TestLoadFromEnv(t *testing.T) {
 	}
 }
 
+func TestLoadFromEnv_DBAndFormat(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("RIVIAN_DB", "/env/state.db")
+	t.Setenv("RIVIAN_FORMAT", "json")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.DBPath != "/env/state.db" || cfg.Format != "json" {
+		t.Errorf("Load() = db %s, format %s; want values from RIVIAN_DB and RIVIAN_FORMAT", cfg.DBPath, cfg.Format)
+	}
+
+	t.Setenv("RIVIAN_DB_PATH", "/env/other.db")
+	if cfg, _ := Load(); cfg.DBPath != "/env/other.db" {
+		t.Errorf("RIVIAN_DB_PATH should win over RIVIAN_DB, got %s", cfg.DBPath)
+	}
+
+	// Values the format setting rejects are ignored
+	for _, format := range []string{"auto", "xml"} {
+		t.Setenv("RIVIAN_FORMAT", format)
+		if cfg, _ := Load(); cfg.Format != "" {
+			t.Errorf("RIVIAN_FORMAT=%s gave format %q, want it ignored", format, cfg.Format)
+		}
+	}
+}
+
 func TestLoadFromFile(t *testing.T) {
 	// Create a temporary config file
 	tmpDir := t.TempDir()
@@ -105,3 +134,260 @@ verbose: true
 		t.Error("Expected verbose to be true")
 	}
 }
+
+func TestSave(t *testing.T) {
+	tmpDir := t.TempDir()
+	_ = os.Setenv("XDG_CONFIG_HOME", tmpDir)
+	defer func() { _ = os.Unsetenv("XDG_CONFIG_HOME") }()
+
+	if Exists() {
+		t.Fatal("Exists() = true before Save")
+	}
+
+	cfg := &Config{
+		Email:    "saved@example.com",
+		Password: "secret",
+		DBPath:   "/tmp/state.db",
+		Vehicle:  1,
+		Units:    UnitsMetric,
+		Theme:    ThemeLight,
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	if !Exists() {
+		t.Error("Exists() = false after Save")
+	}
+	if Path() != filepath.Join(tmpDir, "rivian-ls", "config.yaml") {
+		t.Errorf("Path() = %s", Path())
+	}
+
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("Save() must not write the password")
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.Email != "saved@example.com" || loaded.Vehicle != 1 || loaded.Units != UnitsMetric || loaded.Theme != ThemeLight {
+		t.Errorf("Loaded config mismatch: %+v", loaded)
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	// Missing file yields empty preferences
+	if prefs := LoadPreferences(); *prefs != (Preferences{}) {
+		t.Errorf("LoadPreferences() without a file = %+v, want empty", prefs)
+	}
+
+	saved := &Preferences{View: "charts", ChartMetric: "range", TimeRange: "7d", VehicleID: "v2", TempUnit: "celsius"}
+	if err := saved.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if PreferencesPath() != filepath.Join(tmpDir, "rivian-ls", "preferences.yaml") {
+		t.Errorf("PreferencesPath() = %s", PreferencesPath())
+	}
+	if got := LoadPreferences(); *got != *saved {
+		t.Errorf("LoadPreferences() = %+v, want %+v", got, saved)
+	}
+
+	// A corrupt file is ignored
+	if err := os.WriteFile(PreferencesPath(), []byte("view: [unclosed"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if prefs := LoadPreferences(); *prefs != (Preferences{}) {
+		t.Errorf("LoadPreferences() with a corrupt file = %+v, want empty", prefs)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "rivian-ls"), 0750); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configContent := `email: file@example.com
+db_path: /file/state.db
+format: json
+`
+	if err := os.WriteFile(Path(), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("RIVIAN_EMAIL", "env@example.com")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	// env > file > default
+	if cfg.Email != "env@example.com" || cfg.DBPath != "/file/state.db" || cfg.Format != "json" || cfg.PollInterval != 30*time.Second {
+		t.Errorf("Load() = email %s, db %s, format %s, poll %v", cfg.Email, cfg.DBPath, cfg.Format, cfg.PollInterval)
+	}
+
+	// LoadFile leaves the environment out
+	fileCfg, err := LoadFile()
+	if err != nil {
+		t.Fatalf("LoadFile() failed: %v", err)
+	}
+	if fileCfg.Email != "file@example.com" || fileCfg.Format != "json" {
+		t.Errorf("LoadFile() = email %s, format %s", fileCfg.Email, fileCfg.Format)
+	}
+
+	// Unlike Load, LoadFile reports a broken file
+	if err := os.WriteFile(Path(), []byte("email: [unclosed"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if _, err := LoadFile(); err == nil {
+		t.Error("LoadFile() with an invalid file should fail")
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := &Config{Units: UnitsImperial, Theme: ThemeDark}
+
+	for _, kv := range [][2]string{
+		{"email", "set@example.com"},
+		{"vehicle", "2"},
+		{"format", "json"},
+		{"poll_interval", "1m"},
+		{"disable_store", "true"},
+		{"theme", "light"},
+		{"battery_capacity", "135"},
+		{"retention", "180d"},
+		{"ready_by", "7am"},
+	} {
+		if err := cfg.Set(kv[0], kv[1]); err != nil {
+			t.Errorf("Set(%q, %q) failed: %v", kv[0], kv[1], err)
+		}
+	}
+	if cfg.Email != "set@example.com" || cfg.Vehicle != 2 || cfg.Format != "json" || cfg.PollInterval != time.Minute || !cfg.DisableStore || cfg.Theme != ThemeLight || cfg.BatteryCapacity != 135 ||
+		cfg.Retention != "180d" || cfg.ReadyBy != "7am" {
+		t.Errorf("Set produced %+v", cfg)
+	}
+
+	for _, tt := range []struct{ key, value, want string }{
+		{"password", "secret", "never stored"},
+		{"colour", "red", `unknown key "colour"`},
+		{"vehicle", "two", "invalid integer"},
+		{"vehicle", "-1", "must not be negative"},
+		{"poll_interval", "soon", "invalid duration"},
+		{"quiet", "maybe", "invalid boolean"},
+		{"format", "xml", "must be one of text, json"},
+		{"battery_capacity", "big", "invalid number"},
+		{"battery_capacity", "-1", "must be 0 (estimate) up to 250 kWh"},
+		{"battery_capacity", "300", "must be 0 (estimate) up to 250 kWh"},
+		{"retention", "forever", "retention: "},
+		{"ready_by", "25:00", "not a time of day"},
+	} {
+		err := cfg.Set(tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Set(%q, %q) error = %v, want %q", tt.key, tt.value, err, tt.want)
+		}
+	}
+	// A rejected value leaves the setting unchanged
+	if cfg.Vehicle != 2 || cfg.Format != "json" || cfg.Retention != "180d" || cfg.ReadyBy != "7am" {
+		t.Errorf("rejected values changed the config: vehicle %d, format %s, retention %s, ready_by %s", cfg.Vehicle, cfg.Format, cfg.Retention, cfg.ReadyBy)
+	}
+	if slices.Contains(Keys(), "password") || !slices.Contains(Keys(), "poll_interval") {
+		t.Errorf("Keys() = %v", Keys())
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRetention(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRetention(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRetention(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReadyBy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		off     bool
+		wantErr bool
+	}{
+		{input: "", off: true},
+		{input: "07:00", want: 7 * time.Hour},
+		{input: "22:30", want: 22*time.Hour + 30*time.Minute},
+		{input: "7am", want: 7 * time.Hour},
+		{input: " 6:45PM ", want: 18*time.Hour + 45*time.Minute},
+		{input: "25:00", wantErr: true},
+		{input: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseReadyBy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReadyBy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.off {
+				t.Fatalf("ParseReadyBy(%q) = %v, want off=%v", tt.input, got, tt.off)
+			}
+			if got != nil && *got != tt.want {
+				t.Errorf("ParseReadyBy(%q) = %v, want %v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetentionSetting(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"0d", 0, false},
+		{"180d", 180 * 24 * time.Hour, false},
+		{" 48h ", 48 * time.Hour, false},
+		{"-1d", 0, true},
+		{"forever", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRetentionSetting(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRetentionSetting(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRetentionSetting(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Preferences holds TUI state remembered between runs. Unlike Config it is
+// written by the application, not the user.
+type Preferences struct {
+	View        string `yaml:"view,omitempty"`
+	ChartMetric string `yaml:"chart_metric,omitempty"`
+	TimeRange   string `yaml:"time_range,omitempty"`
+	VehicleID   string `yaml:"vehicle_id,omitempty"`
+	TempUnit    string `yaml:"temp_unit,omitempty"` // "fahrenheit" or "celsius"
+}
+
+// PreferencesPath returns the path of the preferences file, next to the
+// config file.
+func PreferencesPath() string {
+	path := getConfigPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "preferences.yaml")
+}
+
+// LoadPreferences reads the preferences file. It is best-effort: a missing
+// or unreadable file yields empty preferences, so callers use defaults.
+func LoadPreferences() *Preferences {
+	prefs := &Preferences{}
+
+	path := PreferencesPath()
+	if path == "" {
+		return prefs
+	}
+
+	// #nosec G304 -- path is from XDG_CONFIG_HOME or ~/.config, not user input
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	if err := yaml.Unmarshal(data, prefs); err != nil {
+		return &Preferences{}
+	}
+
+	return prefs
+}
+
+// Save writes the preferences file, creating its directory if needed.
+func (p *Preferences) Save() error {
+	path := PreferencesPath()
+	if path == "" {
+		return fmt.Errorf("could not determine preferences path")
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal preferences: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("write preferences file: %w", err)
+	}
+
+	return nil
+}
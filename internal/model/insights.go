@@ -1,6 +1,12 @@
 package model
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
 
 // CalculateReadyScore computes a 0-100 "readiness to drive" score.
 //
@@ -72,6 +78,59 @@ func (v *VehicleState) UpdateReadyScore() {
 	v.ReadyScore = v.CalculateReadyScore()
 }
 
+// CanReach estimates whether the vehicle can drive distanceMiles and
+// still arrive with at least marginPct battery. Consumption is derived
+// from the current range estimate per battery percent. arrivalPct may be
+// negative when the destination is out of range; ok is false when the
+// state has no usable battery or range data.
+func CanReach(state *VehicleState, distanceMiles, marginPct float64) (ok bool, arrivalPct float64) {
+	if state == nil || state.BatteryLevel <= 0 || state.RangeEstimate <= 0 {
+		return false, 0
+	}
+
+	milesPerPct := state.RangeEstimate / state.BatteryLevel
+	arrivalPct = state.BatteryLevel - distanceMiles/milesPerPct
+	return arrivalPct >= marginPct, arrivalPct
+}
+
+// minEfficiencyMiles is the least driving RecentEfficiency needs, so a short
+// trip doesn't skew projections.
+const minEfficiencyMiles = 20.0
+
+// RecentEfficiency returns the average driving efficiency in mi/kWh over
+// history (newest first), counting odometer distance against battery drops
+// while not charging. It returns 0 when history covers too little driving.
+func RecentEfficiency(history []*VehicleState) float64 {
+	var miles, energy float64
+	for i := 0; i+1 < len(history); i++ {
+		curr, prev := history[i], history[i+1]
+		if curr == nil || prev == nil || curr.IsCharging() || prev.IsCharging() {
+			continue
+		}
+		if prev.Odometer > 0 && curr.Odometer > prev.Odometer {
+			miles += curr.Odometer - prev.Odometer
+		}
+		if drop := prev.BatteryLevel - curr.BatteryLevel; drop > 0 && curr.BatteryCapacity > 0 {
+			energy += drop / 100 * curr.BatteryCapacity
+		}
+	}
+
+	if miles < minEfficiencyMiles || energy <= 0 {
+		return 0
+	}
+	return miles / energy
+}
+
+// ProjectedRange estimates the miles left on the current charge at the given
+// efficiency (mi/kWh), e.g. from RecentEfficiency. It returns 0 when the
+// efficiency or battery capacity is unknown.
+func ProjectedRange(state *VehicleState, recentEfficiency float64) float64 {
+	if state == nil || recentEfficiency <= 0 || state.BatteryCapacity <= 0 {
+		return 0
+	}
+	return state.BatteryLevel / 100 * state.BatteryCapacity * recentEfficiency
+}
+
 // NeedsCharge returns true if battery is below the charge limit.
 func (v *VehicleState) NeedsCharge() bool {
 	return v.BatteryLevel < float64(v.ChargeLimit)
@@ -82,8 +141,47 @@ func (v *VehicleState) IsCharging() bool {
 	return v.ChargeState == ChargeStateCharging
 }
 
+// DataConfidence describes how far a state's values reflect the vehicle
+// as it is now.
+type DataConfidence string
+
+const (
+	DataConfidenceHigh        DataConfidence = "high"        // Complete, recently reported telemetry
+	DataConfidenceStale       DataConfidence = "stale"       // Complete, but not reported for days (deep sleep)
+	DataConfidenceUnavailable DataConfidence = "unavailable" // No telemetry (in service or deep sleep)
+)
+
+// deepSleepThreshold is how long a vehicle can go without reporting before
+// its values are treated as stale rather than merely parked.
+const deepSleepThreshold = 48 * time.Hour
+
+// DataConfidence classifies the state's data. A vehicle at a service
+// center or in deep sleep often comes back without battery or range
+// readings, which would otherwise read as an empty battery with doors in an
+// unknown state; one asleep for days keeps serving its last values.
+func (v *VehicleState) DataConfidence() DataConfidence {
+	return v.DataConfidenceAt(time.Now())
+}
+
+// DataConfidenceAt classifies the state's data as of the given time; for a
+// stored state, its own UpdatedAt.
+func (v *VehicleState) DataConfidenceAt(now time.Time) DataConfidence {
+	if v.TelemetryMissing {
+		return DataConfidenceUnavailable
+	}
+	if !v.ReportedAt.IsZero() && now.Sub(v.ReportedAt) > deepSleepThreshold {
+		return DataConfidenceStale
+	}
+	return DataConfidenceHigh
+}
+
 // HasCriticalIssues returns true if any critical issues are detected.
+// Data that isn't current never counts as critical.
 func (v *VehicleState) HasCriticalIssues() bool {
+	if v.DataConfidence() != DataConfidenceHigh {
+		return false
+	}
+
 	// Critical: Low range
 	if v.RangeStatus == RangeStatusCritical {
 		return true
@@ -97,48 +195,164 @@ func (v *VehicleState) HasCriticalIssues() bool {
 	return false
 }
 
-// GetIssues returns a list of current issues/warnings.
+// IssueSeverity ranks an issue.
+type IssueSeverity int
+
+const (
+	IssueInfo IssueSeverity = iota
+	IssueWarning
+	IssueCritical
+)
+
+// Issue is a current issue with its severity. Text is the message as
+// GetIssues lists it, e.g. "Warning: Frunk open".
+type Issue struct {
+	Severity IssueSeverity
+	Text     string
+}
+
+// issueTexts returns the messages of issues.
+func issueTexts(issues []Issue) []string {
+	if issues == nil {
+		return nil
+	}
+	texts := make([]string, len(issues))
+	for i, issue := range issues {
+		texts[i] = issue.Text
+	}
+	return texts
+}
+
+// GetIssues returns a list of current issues/warnings. Without
+// telemetry, the issues missing values would raise are replaced by a single
+// "data unavailable" note; with stale telemetry, critical issues are
+// downgraded to warnings and being offline is expected.
 func (v *VehicleState) GetIssues() []string {
-	var issues []string
+	return v.IssuesAt(time.Now())
+}
+
+// GetIssueList returns the issues of GetIssues with their severities.
+func (v *VehicleState) GetIssueList() []Issue {
+	return v.IssueListAt(time.Now())
+}
+
+// IssuesAt returns the issues as they stood at the given time. Evaluate a
+// stored state at its own UpdatedAt, so history isn't judged stale against
+// the wall clock.
+func (v *VehicleState) IssuesAt(now time.Time) []string {
+	return issueTexts(v.IssueListAt(now))
+}
+
+// IssueListAt returns the issues of IssuesAt with their severities.
+func (v *VehicleState) IssueListAt(now time.Time) []Issue {
+	switch v.DataConfidenceAt(now) {
+	case DataConfidenceUnavailable:
+		return []Issue{{IssueInfo, "Info: Data unavailable: no battery or range reported (vehicle may be in service or deep sleep)"}}
+
+	case DataConfidenceStale:
+		age := now.Sub(v.ReportedAt)
+		issues := []Issue{{IssueInfo, fmt.Sprintf("Info: Data unavailable: no report for %s (vehicle may be in deep sleep); values are as of %s",
+			formatOpenDuration(age.Truncate(time.Hour)), v.ReportedAt.Local().Format("Jan 2 15:04"))}}
+		for _, issue := range v.currentIssues(now) {
+			if issue == offlineIssue {
+				continue
+			}
+			if issue.Severity == IssueCritical {
+				issue = Issue{IssueWarning, strings.Replace(issue.Text, "Critical: ", "Warning: ", 1)}
+			}
+			issues = append(issues, issue)
+		}
+		return issues
+	}
+
+	return v.currentIssues(now)
+}
+
+// offlineIssue is raised for a vehicle that isn't online.
+var offlineIssue = Issue{IssueWarning, "Warning: Vehicle offline"}
+
+// currentIssues returns the issues of the state taken at face value.
+func (v *VehicleState) currentIssues(now time.Time) []Issue {
+	var issues []Issue
 
 	// Range warnings
 	switch v.RangeStatus {
 	case RangeStatusCritical:
-		issues = append(issues, "Critical: Range below 25 miles")
+		issues = append(issues, Issue{IssueCritical, "Critical: Range below 25 miles"})
 	case RangeStatusLow:
-		issues = append(issues, "Warning: Low range (< 50 miles)")
+		issues = append(issues, Issue{IssueWarning, "Warning: Low range (< 50 miles)"})
 	}
 
 	// Battery below charge limit
 	if v.NeedsCharge() && !v.IsCharging() {
-		issues = append(issues, "Battery below charge limit - connect to charger")
+		issues = append(issues, Issue{IssueWarning, "Battery below charge limit - connect to charger"})
 	}
 
 	// Closure warnings
 	if v.Doors.AnyOpen() {
-		issues = append(issues, "Warning: One or more doors open")
+		issues = append(issues, Issue{IssueWarning, "Warning: One or more doors open"})
 	}
 	if v.Windows.AnyOpen() {
-		issues = append(issues, "Warning: One or more windows open")
+		issues = append(issues, Issue{IssueWarning, "Warning: One or more windows open"})
 	}
-	if v.Frunk == ClosureStatusOpen {
-		issues = append(issues, "Warning: Frunk open")
+	profile := v.Profile()
+	if profile.HasFrunk && v.Frunk == ClosureStatusOpen {
+		issues = append(issues, Issue{IssueWarning, "Warning: Frunk open"})
 	}
-	if v.Liftgate == ClosureStatusOpen {
-		issues = append(issues, "Warning: Liftgate open")
+	if profile.HasLiftgate && v.Liftgate == ClosureStatusOpen {
+		issues = append(issues, Issue{IssueWarning, "Warning: Liftgate open"})
 	}
-	if v.TonneauCover != nil && *v.TonneauCover == ClosureStatusOpen {
-		issues = append(issues, "Warning: Tonneau cover open")
+	if tonneau, ok := v.Tonneau(); ok && tonneau == ClosureStatusOpen {
+		issues = append(issues, Issue{IssueWarning, "Warning: Tonneau cover open"})
 	}
 
 	// Lock status
 	if !v.IsLocked && v.Doors.AllClosed() && v.Windows.AllClosed() {
-		issues = append(issues, "Info: Vehicle unlocked")
+		issues = append(issues, Issue{IssueInfo, "Info: Vehicle unlocked"})
 	}
 
 	// Offline warning
 	if !v.IsOnline {
-		issues = append(issues, "Warning: Vehicle offline")
+		issues = append(issues, offlineIssue)
+	}
+
+	issues = append(issues, v.inconsistencyIssues(now)...)
+
+	return issues
+}
+
+// Thresholds for flagging contradictory data.
+const (
+	staleOnlineThreshold    = 24 * time.Hour
+	chargeCompleteTolerance = 2.0 // percentage points below the limit still counted as complete
+)
+
+// InconsistencyIssues returns issues for state combinations that should be
+// impossible, which usually indicate stale or buggy API data.
+func (v *VehicleState) InconsistencyIssues(now time.Time) []string {
+	return issueTexts(v.inconsistencyIssues(now))
+}
+
+// inconsistencyIssues returns the issues of InconsistencyIssues with their
+// severities.
+func (v *VehicleState) inconsistencyIssues(now time.Time) []Issue {
+	var issues []Issue
+
+	if v.IsLocked && v.Doors.AnyOpen() {
+		issues = append(issues, Issue{IssueCritical, "Critical: Inconsistent data: locked with a door open"})
+	}
+
+	if v.ChargeState == ChargeStateDisconnected && v.ChargingRate != nil && *v.ChargingRate > 0 {
+		issues = append(issues, Issue{IssueInfo, fmt.Sprintf("Info: Inconsistent data: disconnected but charging at %.1f kW", *v.ChargingRate)})
+	}
+
+	if v.ChargeState == ChargeStateComplete && v.ChargeLimit > 0 &&
+		v.BatteryLevel < float64(v.ChargeLimit)-chargeCompleteTolerance {
+		issues = append(issues, Issue{IssueInfo, fmt.Sprintf("Info: Inconsistent data: charge complete but battery %.0f%% below %d%% limit", v.BatteryLevel, v.ChargeLimit)})
+	}
+
+	if v.IsOnline && !v.UpdatedAt.IsZero() && now.Sub(v.UpdatedAt) > staleOnlineThreshold {
+		issues = append(issues, Issue{IssueInfo, fmt.Sprintf("Info: Inconsistent data: online but last update %s ago", now.Sub(v.UpdatedAt).Round(time.Hour))})
 	}
 
 	return issues
@@ -159,3 +373,479 @@ func (v *VehicleState) EstimatedChargeTime() *float64 {
 
 	return &hours
 }
+
+// WillCompleteBy reports whether charging to the charge limit will finish by
+// target, along with the average charging rate in kW needed to get there in
+// time. A battery already at its limit is on track and needs no charging.
+// Otherwise the vehicle must be charging with an estimated finish (or,
+// lacking one, a current rate) that meets target. requiredRateKW is 0 when
+// the battery capacity or charge limit is unknown, or target has passed.
+func WillCompleteBy(state *VehicleState, target time.Time) (ok bool, requiredRateKW float64) {
+	return willCompleteBy(state, target, time.Now())
+}
+
+func willCompleteBy(state *VehicleState, target, now time.Time) (bool, float64) {
+	if state == nil {
+		return false, 0
+	}
+	if state.ChargeLimit > 0 && state.BatteryLevel >= float64(state.ChargeLimit) {
+		return true, 0
+	}
+
+	var required float64
+	hours := target.Sub(now).Hours()
+	if state.ChargeLimit > 0 && state.BatteryCapacity > 0 && hours > 0 {
+		neededKWh := (float64(state.ChargeLimit) - state.BatteryLevel) / 100 * state.BatteryCapacity
+		required = neededKWh / hours
+	}
+
+	if !state.IsCharging() || hours <= 0 {
+		return false, required
+	}
+	if state.TimeToCharge != nil {
+		return !state.TimeToCharge.After(target), required
+	}
+	if state.ChargingRate != nil && required > 0 {
+		return *state.ChargingRate >= required, required
+	}
+	return false, required
+}
+
+// ReadyBySummary describes the WillCompleteBy verdict in one line, e.g.
+// "✓ on track (needs 6.8 kW)".
+func ReadyBySummary(state *VehicleState, target time.Time) string {
+	ok, rate := WillCompleteBy(state, target)
+	switch {
+	case state == nil:
+		return "? no vehicle state"
+	case state.ChargeLimit > 0 && state.BatteryLevel >= float64(state.ChargeLimit):
+		return fmt.Sprintf("✓ already at the %d%% charge limit", state.ChargeLimit)
+	case ok && rate > 0:
+		return fmt.Sprintf("✓ on track (needs %.1f kW)", rate)
+	case ok:
+		return "✓ on track"
+	case rate == 0:
+		return "? can't estimate (battery capacity or charge limit unknown)"
+	case !state.IsCharging():
+		return fmt.Sprintf("✗ not charging (needs %.1f kW)", rate)
+	case state.ChargingRate != nil && *state.ChargingRate > 0:
+		return fmt.Sprintf("✗ won't finish in time (needs %.1f kW, charging at %.1f kW)", rate, *state.ChargingRate)
+	default:
+		return fmt.Sprintf("✗ won't finish in time (needs %.1f kW)", rate)
+	}
+}
+
+// NextClockTime returns the first time at or after now that falls on the
+// given time of day (e.g. 7h for 07:00) in now's location. The time is
+// read off the wall clock, so it is right on days with a DST change too.
+func NextClockTime(now time.Time, clock time.Duration) time.Time {
+	y, m, d := now.Date()
+	hour, min, sec := int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second)
+	next := time.Date(y, m, d, hour, min, sec, 0, now.Location())
+	if next.Before(now) {
+		next = time.Date(y, m, d+1, hour, min, sec, 0, now.Location())
+	}
+	return next
+}
+
+// Slow-leak detection thresholds. A tire must read "low" for several
+// consecutive snapshots spanning a meaningful period, after previously
+// reading OK, before we flag it. This filters out transient readings such as
+// cold-morning pressure dips or a single bad sensor sample.
+const (
+	slowLeakMinLowReadings = 3
+	slowLeakMinDuration    = 6 * time.Hour
+)
+
+// TirePressureTrendIssues inspects tire status history and returns
+// "possible slow leak" warnings for tires that have degraded from OK to low
+// and stayed low. History must be ordered newest first (as returned by
+// store.GetStateHistory).
+func TirePressureTrendIssues(history []*VehicleState) []string {
+	tires := []struct {
+		label  string
+		status func(TirePressures) TirePressureStatus
+	}{
+		{"front left", func(t TirePressures) TirePressureStatus { return t.FrontLeftStatus }},
+		{"front right", func(t TirePressures) TirePressureStatus { return t.FrontRightStatus }},
+		{"rear left", func(t TirePressures) TirePressureStatus { return t.RearLeftStatus }},
+		{"rear right", func(t TirePressures) TirePressureStatus { return t.RearRightStatus }},
+	}
+
+	var issues []string
+	for _, tire := range tires {
+		if isTireTrendingLow(history, tire.status) {
+			issues = append(issues, fmt.Sprintf("Warning: Possible slow leak: %s trending low", tire.label))
+		}
+	}
+	return issues
+}
+
+// isTireTrendingLow reports whether the most recent readings for one tire are
+// consistently low after an earlier OK reading.
+func isTireTrendingLow(history []*VehicleState, status func(TirePressures) TirePressureStatus) bool {
+	lowCount := 0
+	var newestLow, oldestLow time.Time
+
+	for _, state := range history {
+		if state == nil {
+			continue
+		}
+
+		switch status(state.TirePressures) {
+		case TirePressureStatusLow:
+			if lowCount == 0 {
+				newestLow = state.UpdatedAt
+			}
+			oldestLow = state.UpdatedAt
+			lowCount++
+		case TirePressureStatusOK:
+			// First OK reading ends the low streak - it must be long enough
+			return lowCount >= slowLeakMinLowReadings &&
+				newestLow.Sub(oldestLow) >= slowLeakMinDuration
+		default:
+			// Skip unknown/missing readings rather than breaking the streak
+			continue
+		}
+	}
+
+	// Never saw an OK reading - could be a faulty sensor, not a leak
+	return false
+}
+
+const (
+	tempBucketWidth         = 10.0 // °F
+	tempBucketMinSamples    = 3
+	rangeProjectionMinLevel = 20.0 // % - below this, projecting to 100% amplifies noise
+)
+
+// TempRangePoint summarizes projected full-charge range within one
+// exterior temperature bucket.
+type TempRangePoint struct {
+	TempLow      float64 // Bucket lower bound (°F, inclusive)
+	TempHigh     float64 // Bucket upper bound (°F, exclusive)
+	Samples      int
+	AvgFullRange float64 // Average range projected to 100% charge (miles)
+	RangeLoss    float64 // Percent below the best bucket (0 for the best)
+}
+
+// RangeTemperatureCorrelation buckets history by exterior temperature and
+// reports the average projected full-charge range per bucket, sorted from
+// coldest to warmest. States without an exterior temperature or with too
+// little charge to project reliably are skipped, as are buckets with fewer
+// than tempBucketMinSamples states.
+func RangeTemperatureCorrelation(history []*VehicleState) []TempRangePoint {
+	type bucket struct {
+		sum   float64
+		count int
+	}
+	buckets := make(map[int]*bucket)
+
+	for _, s := range history {
+		if s == nil || s.ExteriorTemp == nil {
+			continue
+		}
+		if s.BatteryLevel < rangeProjectionMinLevel || s.RangeEstimate <= 0 {
+			continue
+		}
+
+		key := int(math.Floor(*s.ExteriorTemp / tempBucketWidth))
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.sum += s.RangeEstimate / (s.BatteryLevel / 100)
+		b.count++
+	}
+
+	var points []TempRangePoint
+	best := 0.0
+	for key, b := range buckets {
+		if b.count < tempBucketMinSamples {
+			continue
+		}
+		avg := b.sum / float64(b.count)
+		best = math.Max(best, avg)
+		points = append(points, TempRangePoint{
+			TempLow:      float64(key) * tempBucketWidth,
+			TempHigh:     float64(key+1) * tempBucketWidth,
+			Samples:      b.count,
+			AvgFullRange: avg,
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].TempLow < points[j].TempLow })
+
+	for i := range points {
+		points[i].RangeLoss = (best - points[i].AvgFullRange) / best * 100
+	}
+
+	return points
+}
+
+// CapacitySampleMinLevel is the lowest charge level CapacitySamples uses.
+// Projecting range to a full pack amplifies noise at lower levels.
+const CapacitySampleMinLevel = 80.0
+
+// CapacitySample is an estimated full-pack capacity at an odometer reading.
+type CapacitySample struct {
+	Odometer float64 // miles
+	Capacity float64 // kWh
+}
+
+// CapacitySamples estimates full-pack capacity for each high-charge state in
+// history, sorted by odometer. The estimate comes from the range projected to
+// 100% (see estimateBatteryCapacity), so a shrinking pack shows up as a
+// falling series even when the API reports a fixed capacity.
+func CapacitySamples(history []*VehicleState) []CapacitySample {
+	var samples []CapacitySample
+	for _, s := range history {
+		if s == nil || s.Odometer <= 0 || s.BatteryLevel < CapacitySampleMinLevel {
+			continue
+		}
+		if capacity := estimateBatteryCapacity(s.Model, s.BatteryLevel, s.RangeEstimate); capacity > 0 {
+			samples = append(samples, CapacitySample{Odometer: s.Odometer, Capacity: capacity})
+		}
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Odometer < samples[j].Odometer })
+	return samples
+}
+
+// CapacityTrend fits a least-squares line through samples and returns the
+// capacity change per 10,000 miles as a percentage of the average capacity.
+// ok is false when the samples cover no distance.
+func CapacityTrend(samples []CapacitySample) (pctPer10k float64, ok bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.Odometer
+		sumY += s.Capacity
+	}
+	n := float64(len(samples))
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX float64
+	for _, s := range samples {
+		dx := s.Odometer - meanX
+		cov += dx * (s.Capacity - meanY)
+		varX += dx * dx
+	}
+	if varX == 0 || meanY == 0 {
+		return 0, false
+	}
+
+	slope := cov / varX // kWh per mile
+	return slope * 10000 / meanY * 100, true
+}
+
+// SinceLastCharge reports miles driven and battery percentage used since the
+// vehicle was last on charge (charging or charge complete). History must be
+// ordered newest first (as returned by store.GetStateHistory). ok is false
+// when no charge is found in history, the vehicle is still on charge, or
+// the odometer went backwards.
+func SinceLastCharge(history []*VehicleState) (miles, batteryUsed float64, ok bool) {
+	var latest *VehicleState
+	for _, s := range history {
+		if s == nil {
+			continue
+		}
+		if latest == nil {
+			latest = s
+		}
+		if !s.IsCharging() && s.ChargeState != ChargeStateComplete {
+			continue
+		}
+
+		// The newest state is still on charge: nothing driven yet
+		if s == latest {
+			return 0, 0, false
+		}
+
+		miles = latest.Odometer - s.Odometer
+		if miles < 0 {
+			return 0, 0, false
+		}
+		return miles, s.BatteryLevel - latest.BatteryLevel, true
+	}
+
+	return 0, 0, false
+}
+
+// closureAccessor names one closure and reads its status from a state.
+type closureAccessor struct {
+	label  string
+	status func(*VehicleState) ClosureStatus
+}
+
+// closureAccessors returns the closures tracked for open durations, limited
+// to those the vehicle's body style has.
+func closureAccessors(profile Profile) []closureAccessor {
+	accessors := []closureAccessor{
+		{"Front-left door", func(s *VehicleState) ClosureStatus { return s.Doors.FrontLeft }},
+		{"Front-right door", func(s *VehicleState) ClosureStatus { return s.Doors.FrontRight }},
+		{"Rear-left door", func(s *VehicleState) ClosureStatus { return s.Doors.RearLeft }},
+		{"Rear-right door", func(s *VehicleState) ClosureStatus { return s.Doors.RearRight }},
+		{"Front-left window", func(s *VehicleState) ClosureStatus { return s.Windows.FrontLeft }},
+		{"Front-right window", func(s *VehicleState) ClosureStatus { return s.Windows.FrontRight }},
+		{"Rear-left window", func(s *VehicleState) ClosureStatus { return s.Windows.RearLeft }},
+		{"Rear-right window", func(s *VehicleState) ClosureStatus { return s.Windows.RearRight }},
+	}
+	if profile.HasFrunk {
+		accessors = append(accessors, closureAccessor{"Frunk", func(s *VehicleState) ClosureStatus { return s.Frunk }})
+	}
+	if profile.HasLiftgate {
+		accessors = append(accessors, closureAccessor{"Liftgate", func(s *VehicleState) ClosureStatus { return s.Liftgate }})
+	}
+	if profile.HasTonneau {
+		accessors = append(accessors, closureAccessor{"Tonneau cover", func(s *VehicleState) ClosureStatus {
+			if s.TonneauCover == nil {
+				return ClosureStatusUnknown
+			}
+			return *s.TonneauCover
+		}})
+	}
+	return accessors
+}
+
+// ClosureOpenDurations returns how long each currently open closure has been
+// open, keyed by a label such as "Front-left window". The opening time is the
+// oldest state in the unbroken run of "open" readings, so durations are a
+// lower bound limited by snapshot frequency. Unknown readings don't break a
+// run. History must be ordered newest first (as returned by
+// store.GetStateHistory).
+func ClosureOpenDurations(history []*VehicleState, now time.Time) map[string]time.Duration {
+	var latest *VehicleState
+	for _, s := range history {
+		if s != nil {
+			latest = s
+			break
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	durations := make(map[string]time.Duration)
+	for _, closure := range closureAccessors(latest.Profile()) {
+		if closure.status(latest) != ClosureStatusOpen {
+			continue
+		}
+
+		openedAt := latest.UpdatedAt
+		for _, s := range history {
+			if s == nil {
+				continue
+			}
+			status := closure.status(s)
+			if status == ClosureStatusClosed {
+				break
+			}
+			if status == ClosureStatusOpen {
+				openedAt = s.UpdatedAt
+			}
+		}
+
+		durations[closure.label] = max(now.Sub(openedAt), 0)
+	}
+
+	return durations
+}
+
+// ClosureOpenIssues returns warnings such as "Front-left window open for 3h"
+// for every closure that is currently open, sorted by label.
+func ClosureOpenIssues(history []*VehicleState, now time.Time) []string {
+	durations := ClosureOpenDurations(history, now)
+
+	labels := make([]string, 0, len(durations))
+	for label := range durations {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	issues := make([]string, 0, len(labels))
+	for _, label := range labels {
+		issues = append(issues, fmt.Sprintf("Warning: %s open for %s", label, formatOpenDuration(durations[label])))
+	}
+	return issues
+}
+
+// ChargeLimitChange is a change of the charge limit between two consecutive
+// snapshots, e.g. from the Rivian app or a charging schedule.
+type ChargeLimitChange struct {
+	From, To int
+	At       time.Time // When the new limit was first seen
+}
+
+// String returns e.g. "Charge limit changed from 80% to 70%".
+func (c ChargeLimitChange) String() string {
+	return fmt.Sprintf("Charge limit changed from %d%% to %d%%", c.From, c.To)
+}
+
+// Issue returns the change as an info issue.
+func (c ChargeLimitChange) Issue() string {
+	return "Info: " + c.String()
+}
+
+// DetectChargeLimitChange reports whether the charge limit differs between
+// prev and cur. A limit of 0 is unknown and never counts as a change.
+func DetectChargeLimitChange(prev, cur *VehicleState) (ChargeLimitChange, bool) {
+	if prev == nil || cur == nil || prev.ChargeLimit == 0 || cur.ChargeLimit == 0 || prev.ChargeLimit == cur.ChargeLimit {
+		return ChargeLimitChange{}, false
+	}
+	return ChargeLimitChange{From: prev.ChargeLimit, To: cur.ChargeLimit, At: cur.UpdatedAt}, true
+}
+
+// LastChargeLimitChange returns the most recent charge limit change in
+// history, which must be ordered newest first. States with an unknown
+// limit are skipped rather than treated as changes.
+func LastChargeLimitChange(history []*VehicleState) (ChargeLimitChange, bool) {
+	var newer *VehicleState
+	for _, s := range history {
+		if s == nil || s.ChargeLimit == 0 {
+			continue
+		}
+		if change, ok := DetectChargeLimitChange(s, newer); ok {
+			return change, true
+		}
+		newer = s
+	}
+	return ChargeLimitChange{}, false
+}
+
+// FormatAge renders how old data is compactly: "<1m", "25m", "3h 12m" or,
+// from a day on, "2d 4h".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < 24*time.Hour:
+		return formatOpenDuration(d)
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d.Hours()) % 24
+	if hours == 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
+
+// formatOpenDuration renders a duration compactly: "3h", "2h 15m" or "25m".
+func formatOpenDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -220,6 +222,8 @@ func TestHasCriticalIssues(t *testing.T) {
 }
 
 func TestGetIssues(t *testing.T) {
+	tonneauOpen := ClosureStatusOpen
+
 	tests := []struct {
 		name       string
 		state      *VehicleState
@@ -268,6 +272,29 @@ func TestGetIssues(t *testing.T) {
 			wantCount: 1,
 			wantAny:   "doors open",
 		},
+		{
+			name: "tonneau open on R1T",
+			state: &VehicleState{
+				Model:        "R1T",
+				IsOnline:     true,
+				IsLocked:     true,
+				RangeStatus:  RangeStatusNormal,
+				TonneauCover: &tonneauOpen,
+			},
+			wantCount: 1,
+			wantAny:   "Tonneau",
+		},
+		{
+			name: "tonneau ignored on R1S",
+			state: &VehicleState{
+				Model:        "R1S",
+				IsOnline:     true,
+				IsLocked:     true,
+				RangeStatus:  RangeStatusNormal,
+				TonneauCover: &tonneauOpen,
+			},
+			wantCount: 0,
+		},
 		{
 			name: "offline",
 			state: &VehicleState{
@@ -377,6 +404,57 @@ func TestEstimatedChargeTime(t *testing.T) {
 
 // Helper functions
 
+func TestTirePressureTrendIssues(t *testing.T) {
+	now := time.Now()
+
+	// makeHistory builds newest-first history with the given rear-left statuses,
+	// spaced the given interval apart.
+	makeHistory := func(interval time.Duration, statuses ...TirePressureStatus) []*VehicleState {
+		history := make([]*VehicleState, 0, len(statuses))
+		for i, status := range statuses {
+			history = append(history, &VehicleState{
+				UpdatedAt: now.Add(-time.Duration(i) * interval),
+				TirePressures: TirePressures{
+					FrontLeftStatus:  TirePressureStatusOK,
+					FrontRightStatus: TirePressureStatusOK,
+					RearLeftStatus:   status,
+					RearRightStatus:  TirePressureStatusOK,
+				},
+			})
+		}
+		return history
+	}
+
+	low, ok, unknown := TirePressureStatusLow, TirePressureStatusOK, TirePressureStatusUnknown
+
+	tests := []struct {
+		name    string
+		history []*VehicleState
+		want    int
+	}{
+		{"empty history", nil, 0},
+		{"all OK", makeHistory(4*time.Hour, ok, ok, ok, ok), 0},
+		{"degraded and stayed low", makeHistory(4*time.Hour, low, low, low, ok, ok), 1},
+		{"unknown readings do not break streak", makeHistory(4*time.Hour, low, unknown, low, low, ok), 1},
+		{"single transient low reading", makeHistory(4*time.Hour, low, ok, ok, ok), 0},
+		{"low streak too short in time", makeHistory(time.Minute, low, low, low, ok), 0},
+		{"always low (likely sensor fault)", makeHistory(4*time.Hour, low, low, low, low), 0},
+		{"recovered after being low", makeHistory(4*time.Hour, ok, low, low, low, ok), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := TirePressureTrendIssues(tt.history)
+			if len(issues) != tt.want {
+				t.Fatalf("TirePressureTrendIssues() returned %d issues, want %d: %v", len(issues), tt.want, issues)
+			}
+			if tt.want > 0 && !containsIgnoreCase(issues[0], "slow leak: rear left") {
+				t.Errorf("Unexpected issue text: %q", issues[0])
+			}
+		})
+	}
+}
+
 func float64Ptr(v float64) *float64 {
 	return &v
 }
@@ -404,3 +482,714 @@ func toLower(s string) string {
 	}
 	return string(result)
 }
+
+func TestRangeTemperatureCorrelation(t *testing.T) {
+	temp := func(f float64) *float64 { return &f }
+
+	var history []*VehicleState
+	// Cold bucket (20-30°F): 80% -> 160mi projects to 200mi
+	for i := 0; i < 3; i++ {
+		history = append(history, &VehicleState{ExteriorTemp: temp(25), BatteryLevel: 80, RangeEstimate: 160})
+	}
+	// Mild bucket (70-80°F): 50% -> 150mi projects to 300mi
+	for i := 0; i < 4; i++ {
+		history = append(history, &VehicleState{ExteriorTemp: temp(72), BatteryLevel: 50, RangeEstimate: 150})
+	}
+	// Too few samples for the 40-50°F bucket
+	history = append(history, &VehicleState{ExteriorTemp: temp(45), BatteryLevel: 80, RangeEstimate: 200})
+	// Skipped: no exterior temp, and battery too low to project
+	history = append(history, &VehicleState{BatteryLevel: 80, RangeEstimate: 100})
+	history = append(history, &VehicleState{ExteriorTemp: temp(25), BatteryLevel: 5, RangeEstimate: 1})
+
+	points := RangeTemperatureCorrelation(history)
+	if len(points) != 2 {
+		t.Fatalf("len(points) = %d, want 2: %+v", len(points), points)
+	}
+
+	cold, mild := points[0], points[1]
+	if cold.TempLow != 20 || cold.TempHigh != 30 || cold.Samples != 3 {
+		t.Errorf("cold bucket = %+v, want 20-30°F with 3 samples", cold)
+	}
+	if cold.AvgFullRange != 200 {
+		t.Errorf("cold AvgFullRange = %v, want 200", cold.AvgFullRange)
+	}
+	if math.Abs(cold.RangeLoss-33.33) > 0.01 {
+		t.Errorf("cold RangeLoss = %v, want ~33.33", cold.RangeLoss)
+	}
+	if mild.AvgFullRange != 300 || mild.RangeLoss != 0 {
+		t.Errorf("mild bucket = %+v, want 300mi with no loss", mild)
+	}
+
+	if got := RangeTemperatureCorrelation(nil); len(got) != 0 {
+		t.Errorf("RangeTemperatureCorrelation(nil) = %v, want empty", got)
+	}
+}
+
+func TestInconsistencyIssues(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	rate := 7.2
+	openDoor := Closures{ClosureStatusOpen, ClosureStatusClosed, ClosureStatusClosed, ClosureStatusClosed}
+
+	tests := []struct {
+		name    string
+		state   *VehicleState
+		wantAny string // empty means no issues expected
+	}{
+		{
+			name:    "consistent state",
+			state:   &VehicleState{IsOnline: true, IsLocked: true, ChargeState: ChargeStateNotCharging, UpdatedAt: now.Add(-time.Hour)},
+			wantAny: "",
+		},
+		{
+			name:    "locked with door open",
+			state:   &VehicleState{IsLocked: true, Doors: openDoor},
+			wantAny: "Critical: Inconsistent data: locked with a door open",
+		},
+		{
+			name:    "disconnected but charging",
+			state:   &VehicleState{ChargeState: ChargeStateDisconnected, ChargingRate: &rate},
+			wantAny: "disconnected but charging at 7.2 kW",
+		},
+		{
+			name:    "complete but below limit",
+			state:   &VehicleState{ChargeState: ChargeStateComplete, BatteryLevel: 60, ChargeLimit: 80},
+			wantAny: "charge complete but battery 60% below 80% limit",
+		},
+		{
+			name:    "complete within tolerance",
+			state:   &VehicleState{ChargeState: ChargeStateComplete, BatteryLevel: 79, ChargeLimit: 80},
+			wantAny: "",
+		},
+		{
+			name:    "online but stale",
+			state:   &VehicleState{IsOnline: true, UpdatedAt: now.Add(-48 * time.Hour)},
+			wantAny: "online but last update 48h0m0s ago",
+		},
+		{
+			name:    "online without timestamp is not flagged",
+			state:   &VehicleState{IsOnline: true},
+			wantAny: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := tt.state.InconsistencyIssues(now)
+
+			if tt.wantAny == "" {
+				if len(issues) != 0 {
+					t.Errorf("InconsistencyIssues() = %v, want none", issues)
+				}
+				return
+			}
+
+			found := false
+			for _, issue := range issues {
+				if containsIgnoreCase(issue, tt.wantAny) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("InconsistencyIssues() = %v, want one containing %q", issues, tt.wantAny)
+			}
+		})
+	}
+
+	// A stored state is judged as of when it was taken
+	stored := &VehicleState{IsOnline: true, IsLocked: true, ChargeState: ChargeStateNotCharging, UpdatedAt: now.Add(-72 * time.Hour)}
+	for _, issue := range stored.IssuesAt(stored.UpdatedAt) {
+		if strings.Contains(issue, "Inconsistent data") {
+			t.Errorf("IssuesAt(UpdatedAt) = %q for a stored state", issue)
+		}
+	}
+}
+
+func TestDataConfidence(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	closedDoors := Closures{ClosureStatusClosed, ClosureStatusClosed, ClosureStatusClosed, ClosureStatusClosed}
+
+	tests := []struct {
+		name       string
+		state      *VehicleState
+		want       DataConfidence
+		wantIssues []string // Substrings, in order; nil means exactly none
+	}{
+		{
+			name: "awake",
+			state: &VehicleState{IsOnline: true, IsLocked: true, BatteryLevel: 80, ChargeLimit: 80,
+				RangeEstimate: 220, RangeStatus: RangeStatusNormal, Doors: closedDoors, Windows: closedDoors,
+				ReportedAt: now.Add(-time.Hour)},
+			want: DataConfidenceHigh,
+		},
+		{
+			name: "in service, nothing reported",
+			state: &VehicleState{IsOnline: false, TelemetryMissing: true, ChargeLimit: 80,
+				RangeStatus: RangeStatusUnknown},
+			want:       DataConfidenceUnavailable,
+			wantIssues: []string{"Info: Data unavailable: no battery or range reported"},
+		},
+		{
+			name: "deep sleep, last values days old",
+			state: &VehicleState{IsOnline: false, IsLocked: true, BatteryLevel: 12, ChargeLimit: 80,
+				RangeEstimate: 20, RangeStatus: RangeStatusCritical, Doors: closedDoors, Windows: closedDoors,
+				ReportedAt: now.Add(-72 * time.Hour)},
+			want: DataConfidenceStale,
+			wantIssues: []string{
+				"Info: Data unavailable: no report for 72h",
+				"Warning: Range below 25 miles",
+				"Battery below charge limit",
+			},
+		},
+		{
+			name: "no report time is not stale",
+			state: &VehicleState{IsOnline: true, BatteryLevel: 80, ChargeLimit: 80, RangeEstimate: 220,
+				RangeStatus: RangeStatusNormal, Doors: closedDoors, Windows: closedDoors, IsLocked: true},
+			want: DataConfidenceHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.DataConfidenceAt(now); got != tt.want {
+				t.Errorf("DataConfidenceAt() = %q, want %q", got, tt.want)
+			}
+
+			issues := tt.state.IssuesAt(now)
+			if len(issues) != len(tt.wantIssues) {
+				t.Fatalf("IssuesAt() = %q, want %d matching %q", issues, len(tt.wantIssues), tt.wantIssues)
+			}
+			for i, want := range tt.wantIssues {
+				if !strings.Contains(issues[i], want) {
+					t.Errorf("IssuesAt()[%d] = %q, want it to contain %q", i, issues[i], want)
+				}
+			}
+			for _, issue := range issues {
+				if strings.HasPrefix(issue, "Critical:") {
+					t.Errorf("%s: reported %q as critical", tt.want, issue)
+				}
+			}
+		})
+	}
+}
+
+func TestIssueListAt_Severity(t *testing.T) {
+	now := time.Now()
+	closed := Closures{ClosureStatusClosed, ClosureStatusClosed, ClosureStatusClosed, ClosureStatusClosed}
+	state := &VehicleState{IsOnline: true, BatteryLevel: 80, ChargeLimit: 80, RangeEstimate: 20,
+		RangeStatus: RangeStatusCritical, Doors: closed, Windows: closed, ReportedAt: now.Add(-time.Hour)}
+
+	want := []IssueSeverity{IssueCritical, IssueInfo} // Critical range, unlocked
+	issues := state.IssueListAt(now)
+	if len(issues) != len(want) {
+		t.Fatalf("IssueListAt() = %+v, want %d issues", issues, len(want))
+	}
+	for i, issue := range issues {
+		if issue.Severity != want[i] {
+			t.Errorf("IssueListAt()[%d] = %+v, want severity %d", i, issue, want[i])
+		}
+	}
+
+	// Stale critical issues are downgraded along with their text
+	state.ReportedAt = now.Add(-72 * time.Hour)
+	for _, issue := range state.IssueListAt(now) {
+		if issue.Severity == IssueCritical || strings.HasPrefix(issue.Text, "Critical:") {
+			t.Errorf("stale state reported %+v as critical", issue)
+		}
+	}
+}
+
+func TestDataConfidenceAt_StoredState(t *testing.T) {
+	// Reported shortly before it was saved three days ago
+	saved := time.Now().Add(-72 * time.Hour)
+	state := &VehicleState{IsOnline: true, IsLocked: true, BatteryLevel: 80, ChargeLimit: 80,
+		RangeEstimate: 220, RangeStatus: RangeStatusNormal, UpdatedAt: saved, ReportedAt: saved.Add(-10 * time.Minute)}
+
+	if got := state.DataConfidenceAt(state.UpdatedAt); got != DataConfidenceHigh {
+		t.Errorf("DataConfidenceAt(UpdatedAt) = %q, want %q", got, DataConfidenceHigh)
+	}
+	for _, issue := range state.IssuesAt(state.UpdatedAt) {
+		if strings.Contains(issue, "Data unavailable") {
+			t.Errorf("IssuesAt(UpdatedAt) = %q, want no confidence note", issue)
+		}
+	}
+	if got := state.DataConfidence(); got != DataConfidenceStale {
+		t.Errorf("DataConfidence() = %q, want %q judged against now", got, DataConfidenceStale)
+	}
+}
+
+func TestWillCompleteBy(t *testing.T) {
+	now := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+	target := now.Add(8 * time.Hour)
+	early := now.Add(5 * time.Hour)
+	late := now.Add(9 * time.Hour)
+
+	tests := []struct {
+		name     string
+		state    *VehicleState
+		target   time.Time
+		wantOK   bool
+		wantRate float64
+	}{
+		{
+			// 40% of 135 kWh = 54 kWh over 8h
+			name: "charging fast enough",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateCharging, ChargingRate: float64Ptr(11),
+			},
+			target: target, wantOK: true, wantRate: 6.75,
+		},
+		{
+			name: "charging too slowly",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateCharging, ChargingRate: float64Ptr(3),
+			},
+			target: target, wantOK: false, wantRate: 6.75,
+		},
+		{
+			name: "estimated finish before target",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateCharging, TimeToCharge: &early,
+			},
+			target: target, wantOK: true, wantRate: 6.75,
+		},
+		{
+			name: "estimated finish after target",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateCharging, TimeToCharge: &late, ChargingRate: float64Ptr(11),
+			},
+			target: target, wantOK: false, wantRate: 6.75,
+		},
+		{
+			name: "not charging",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateNotCharging,
+			},
+			target: target, wantOK: false, wantRate: 6.75,
+		},
+		{
+			name: "already complete",
+			state: &VehicleState{
+				BatteryLevel: 80, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateComplete,
+			},
+			target: target, wantOK: true, wantRate: 0,
+		},
+		{
+			name: "target passed",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80, BatteryCapacity: 135,
+				ChargeState: ChargeStateCharging, ChargingRate: float64Ptr(11),
+			},
+			target: now.Add(-time.Hour), wantOK: false, wantRate: 0,
+		},
+		{
+			name: "capacity unknown",
+			state: &VehicleState{
+				BatteryLevel: 40, ChargeLimit: 80,
+				ChargeState: ChargeStateCharging, ChargingRate: float64Ptr(11),
+			},
+			target: target, wantOK: false, wantRate: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rate := willCompleteBy(tt.state, tt.target, now)
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(rate-tt.wantRate) > 0.01 {
+				t.Errorf("requiredRateKW = %.2f, want %.2f", rate, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestNextClockTime(t *testing.T) {
+	now := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+
+	if got, want := NextClockTime(now, 7*time.Hour), time.Date(2024, 1, 16, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextClockTime(07:00) = %v, want %v", got, want)
+	}
+	if got, want := NextClockTime(now, 23*time.Hour), time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextClockTime(23:00) = %v, want %v", got, want)
+	}
+}
+
+func TestNextClockTime_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Clocks go forward on March 10 and back on November 3, 2024
+	for _, day := range []time.Time{
+		time.Date(2024, time.March, 10, 0, 0, 0, 0, loc),
+		time.Date(2024, time.November, 3, 0, 0, 0, 0, loc),
+	} {
+		now := day.Add(30 * time.Minute)
+		want := time.Date(day.Year(), day.Month(), day.Day(), 7, 0, 0, 0, loc)
+		if got := NextClockTime(now, 7*time.Hour); !got.Equal(want) {
+			t.Errorf("NextClockTime(%v, 07:00) = %v, want %v", now, got, want)
+		}
+	}
+}
+
+func TestSinceLastCharge(t *testing.T) {
+	now := time.Now()
+	state := func(hoursAgo int, charge ChargeState, odometer, battery float64) *VehicleState {
+		return &VehicleState{
+			UpdatedAt:    now.Add(-time.Duration(hoursAgo) * time.Hour),
+			ChargeState:  charge,
+			Odometer:     odometer,
+			BatteryLevel: battery,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		history     []*VehicleState
+		wantMiles   float64
+		wantBattery float64
+		wantOK      bool
+	}{
+		{
+			name: "driven since charge complete",
+			history: []*VehicleState{
+				state(0, ChargeStateDisconnected, 1120, 62),
+				state(2, ChargeStateDisconnected, 1060, 72),
+				state(5, ChargeStateComplete, 1000, 80),
+				state(6, ChargeStateComplete, 1000, 80),
+				state(8, ChargeStateCharging, 1000, 50),
+			},
+			wantMiles: 120, wantBattery: 18, wantOK: true,
+		},
+		{
+			name: "unplugged mid-charge",
+			history: []*VehicleState{
+				state(0, ChargeStateDisconnected, 1030, 65),
+				state(3, ChargeStateCharging, 1000, 70),
+			},
+			wantMiles: 30, wantBattery: 5, wantOK: true,
+		},
+		{
+			name: "still charging",
+			history: []*VehicleState{
+				state(0, ChargeStateCharging, 1000, 60),
+				state(1, ChargeStateDisconnected, 990, 55),
+			},
+		},
+		{
+			name: "no charge in history",
+			history: []*VehicleState{
+				state(0, ChargeStateDisconnected, 1000, 60),
+				state(1, ChargeStateDisconnected, 990, 62),
+			},
+		},
+		{
+			name: "odometer went backwards",
+			history: []*VehicleState{
+				state(0, ChargeStateDisconnected, 900, 60),
+				state(1, ChargeStateComplete, 1000, 80),
+			},
+		},
+		{name: "empty history"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			miles, used, ok := SinceLastCharge(tt.history)
+			if ok != tt.wantOK || miles != tt.wantMiles || used != tt.wantBattery {
+				t.Errorf("SinceLastCharge() = (%v, %v, %v), want (%v, %v, %v)",
+					miles, used, ok, tt.wantMiles, tt.wantBattery, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestClosureOpenDurations(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	open, closed, unknown := ClosureStatusOpen, ClosureStatusClosed, ClosureStatusUnknown
+
+	// makeHistory builds hourly newest-first history with the given
+	// front-left window statuses.
+	makeHistory := func(statuses ...ClosureStatus) []*VehicleState {
+		history := make([]*VehicleState, 0, len(statuses))
+		for i, status := range statuses {
+			history = append(history, &VehicleState{
+				Model:     "R1T",
+				UpdatedAt: now.Add(-time.Duration(i) * time.Hour),
+				Windows:   Closures{FrontLeft: status, FrontRight: closed, RearLeft: closed, RearRight: closed},
+				Doors:     Closures{FrontLeft: closed, FrontRight: closed, RearLeft: closed, RearRight: closed},
+			})
+		}
+		return history
+	}
+
+	tests := []struct {
+		name    string
+		history []*VehicleState
+		want    time.Duration
+		wantOK  bool
+	}{
+		{"empty history", nil, 0, false},
+		{"closed now", makeHistory(closed, open, open), 0, false},
+		{"opened three hours ago", makeHistory(open, open, open, open, closed), 3 * time.Hour, true},
+		{"unknown readings don't break the run", makeHistory(open, unknown, open, closed), 2 * time.Hour, true},
+		{"open for all of history", makeHistory(open, open), time.Hour, true},
+		{"just opened", makeHistory(open, closed), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			durations := ClosureOpenDurations(tt.history, now)
+			got, ok := durations["Front-left window"]
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Front-left window = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+			if len(durations) > 1 {
+				t.Errorf("only the front-left window is open, got %v", durations)
+			}
+		})
+	}
+}
+
+func TestClosureOpenIssues(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tonneau := ClosureStatusOpen
+	history := []*VehicleState{
+		{Model: "R1T", UpdatedAt: now.Add(-15 * time.Minute), Frunk: ClosureStatusOpen, TonneauCover: &tonneau,
+			Windows: Closures{RearRight: ClosureStatusOpen}},
+		{Model: "R1T", UpdatedAt: now.Add(-3 * time.Hour), Frunk: ClosureStatusClosed, TonneauCover: &tonneau,
+			Windows: Closures{RearRight: ClosureStatusOpen}},
+		{Model: "R1T", UpdatedAt: now.Add(-4 * time.Hour), TonneauCover: &tonneau},
+	}
+
+	want := []string{
+		"Warning: Frunk open for 15m",
+		"Warning: Rear-right window open for 3h",
+		"Warning: Tonneau cover open for 4h",
+	}
+	got := ClosureOpenIssues(history, now)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ClosureOpenIssues() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectChargeLimitChange(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		prev, cur *VehicleState
+		want      string
+	}{
+		{"changed", &VehicleState{ChargeLimit: 80}, &VehicleState{ChargeLimit: 70, UpdatedAt: now}, "Charge limit changed from 80% to 70%"},
+		{"unchanged", &VehicleState{ChargeLimit: 80}, &VehicleState{ChargeLimit: 80}, ""},
+		{"previous unknown", &VehicleState{}, &VehicleState{ChargeLimit: 70}, ""},
+		{"current unknown", &VehicleState{ChargeLimit: 80}, &VehicleState{}, ""},
+		{"no previous", nil, &VehicleState{ChargeLimit: 70}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, ok := DetectChargeLimitChange(tt.prev, tt.cur)
+			if ok != (tt.want != "") {
+				t.Fatalf("DetectChargeLimitChange() ok = %v, want %v", ok, tt.want != "")
+			}
+			if ok && (change.String() != tt.want || !change.At.Equal(now)) {
+				t.Errorf("DetectChargeLimitChange() = %q at %v, want %q at %v", change, change.At, tt.want, now)
+			}
+		})
+	}
+}
+
+func TestLastChargeLimitChange(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	history := []*VehicleState{
+		{ChargeLimit: 70, UpdatedAt: now},
+		{ChargeLimit: 0, UpdatedAt: now.Add(-time.Hour)}, // unknown, skipped
+		{ChargeLimit: 70, UpdatedAt: now.Add(-2 * time.Hour)},
+		{ChargeLimit: 80, UpdatedAt: now.Add(-3 * time.Hour)},
+		{ChargeLimit: 90, UpdatedAt: now.Add(-4 * time.Hour)},
+	}
+
+	change, ok := LastChargeLimitChange(history)
+	if !ok {
+		t.Fatal("LastChargeLimitChange() found no change")
+	}
+	if change.From != 80 || change.To != 70 || !change.At.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("LastChargeLimitChange() = %+v, want 80%% -> 70%% two hours ago", change)
+	}
+	if got := change.Issue(); got != "Info: Charge limit changed from 80% to 70%" {
+		t.Errorf("Issue() = %q", got)
+	}
+
+	if _, ok := LastChargeLimitChange(history[:3]); ok {
+		t.Error("LastChargeLimitChange() should find no change in a steady history")
+	}
+}
+
+func TestCanReach(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       *VehicleState
+		distance    float64
+		margin      float64
+		wantOK      bool
+		wantArrival float64
+	}{
+		{
+			name:        "reachable with margin",
+			state:       &VehicleState{BatteryLevel: 80, RangeEstimate: 240},
+			distance:    180,
+			margin:      10,
+			wantOK:      true,
+			wantArrival: 20, // 3 mi per %, 60% used
+		},
+		{
+			name:        "reachable but inside margin",
+			state:       &VehicleState{BatteryLevel: 80, RangeEstimate: 240},
+			distance:    225,
+			margin:      10,
+			wantOK:      false,
+			wantArrival: 5,
+		},
+		{
+			name:        "exactly at margin",
+			state:       &VehicleState{BatteryLevel: 50, RangeEstimate: 100},
+			distance:    80,
+			margin:      10,
+			wantOK:      true,
+			wantArrival: 10,
+		},
+		{
+			name:        "out of range",
+			state:       &VehicleState{BatteryLevel: 50, RangeEstimate: 100},
+			distance:    150,
+			margin:      0,
+			wantOK:      false,
+			wantArrival: -25,
+		},
+		{
+			name:     "no range data",
+			state:    &VehicleState{BatteryLevel: 50},
+			distance: 10,
+			wantOK:   false,
+		},
+		{
+			name:     "nil state",
+			distance: 10,
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, arrival := CanReach(tt.state, tt.distance, tt.margin)
+			if ok != tt.wantOK {
+				t.Errorf("CanReach() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(arrival-tt.wantArrival) > 1e-9 {
+				t.Errorf("CanReach() arrivalPct = %v, want %v", arrival, tt.wantArrival)
+			}
+		})
+	}
+}
+
+func TestRecentEfficiency(t *testing.T) {
+	history := []*VehicleState{
+		{Odometer: 1080, BatteryLevel: 60, BatteryCapacity: 100},
+		{Odometer: 1040, BatteryLevel: 70, BatteryCapacity: 100, ChargeState: ChargeStateCharging},
+		{Odometer: 1040, BatteryLevel: 65, BatteryCapacity: 100},
+		{Odometer: 1000, BatteryLevel: 75, BatteryCapacity: 100},
+	}
+	// Only the 1000→1040 leg counts: 40 mi on 10 kWh
+	if got := RecentEfficiency(history); got != 4 {
+		t.Errorf("RecentEfficiency = %.2f, want 4", got)
+	}
+
+	short := []*VehicleState{
+		{Odometer: 1010, BatteryLevel: 70, BatteryCapacity: 100},
+		{Odometer: 1000, BatteryLevel: 72, BatteryCapacity: 100},
+	}
+	if got := RecentEfficiency(short); got != 0 {
+		t.Errorf("RecentEfficiency with short history = %.2f, want 0", got)
+	}
+	if got := RecentEfficiency(nil); got != 0 {
+		t.Errorf("RecentEfficiency(nil) = %.2f, want 0", got)
+	}
+}
+
+func TestProjectedRange(t *testing.T) {
+	state := &VehicleState{BatteryLevel: 50, BatteryCapacity: 120}
+	if got := ProjectedRange(state, 3); got != 180 {
+		t.Errorf("ProjectedRange = %.1f, want 180", got)
+	}
+	if got := ProjectedRange(state, 0); got != 0 {
+		t.Errorf("ProjectedRange without efficiency = %.1f, want 0", got)
+	}
+	if got := ProjectedRange(&VehicleState{BatteryLevel: 50}, 3); got != 0 {
+		t.Errorf("ProjectedRange without capacity = %.1f, want 0", got)
+	}
+	if got := ProjectedRange(nil, 3); got != 0 {
+		t.Errorf("ProjectedRange(nil) = %.1f, want 0", got)
+	}
+}
+
+func TestCapacitySamples(t *testing.T) {
+	history := []*VehicleState{
+		{Model: "R1T", BatteryLevel: 90, RangeEstimate: 252, Odometer: 20000},
+		{Model: "R1T", BatteryLevel: 40, RangeEstimate: 120, Odometer: 15000}, // Too low to project
+		{Model: "R1T", BatteryLevel: 90, RangeEstimate: 270, Odometer: 10000},
+		{Model: "R1T", BatteryLevel: 95, RangeEstimate: 280}, // No odometer
+	}
+
+	samples := CapacitySamples(history)
+	if len(samples) != 2 {
+		t.Fatalf("CapacitySamples returned %d samples, want 2", len(samples))
+	}
+	// Sorted by odometer; 270 mi at 90% = 300 mi full at 2.0 mi/kWh
+	if samples[0].Odometer != 10000 || samples[0].Capacity != 150 {
+		t.Errorf("first sample = %+v, want {10000 150}", samples[0])
+	}
+	if samples[1].Odometer != 20000 || samples[1].Capacity != 140 {
+		t.Errorf("second sample = %+v, want {20000 140}", samples[1])
+	}
+
+	// 10 kWh lost over 10k mi against a 145 kWh average
+	trend, ok := CapacityTrend(samples)
+	if !ok {
+		t.Fatal("CapacityTrend should succeed with two samples")
+	}
+	if want := -10.0 / 145 * 100; math.Abs(trend-want) > 0.01 {
+		t.Errorf("CapacityTrend = %.2f, want %.2f", trend, want)
+	}
+
+	if _, ok := CapacityTrend(samples[:1]); ok {
+		t.Error("CapacityTrend should fail with a single sample")
+	}
+	if _, ok := CapacityTrend([]CapacitySample{{Odometer: 5, Capacity: 100}, {Odometer: 5, Capacity: 90}}); ok {
+		t.Error("CapacityTrend should fail when samples cover no distance")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		20 * time.Second:                           "<1m",
+		25 * time.Minute:                           "25m",
+		3*time.Hour + 12*time.Minute:               "3h 12m",
+		2 * time.Hour:                              "2h",
+		2*24*time.Hour + 4*time.Hour + time.Minute: "2d 4h",
+		3 * 24 * time.Hour:                         "3d",
+	} {
+		if got := FormatAge(d); got != want {
+			t.Errorf("FormatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import "strings"
+
+// Profile declares which closures and features a vehicle model has.
+// Views and insights consult it so closures a model doesn't have are
+// neither rendered nor warned about.
+type Profile struct {
+	Model       string
+	HasFrunk    bool
+	HasLiftgate bool
+	HasTonneau  bool
+}
+
+// VehicleProfile returns the profile for a vehicle model name (e.g. "R1T").
+// Unknown models get a permissive default that shows every closure the
+// API reports.
+func VehicleProfile(modelName string) Profile {
+	name := strings.ToUpper(strings.TrimSpace(modelName))
+
+	switch {
+	case strings.HasPrefix(name, "R1T"):
+		return Profile{Model: "R1T", HasFrunk: true, HasLiftgate: true, HasTonneau: true}
+	case strings.HasPrefix(name, "R1S"):
+		return Profile{Model: "R1S", HasFrunk: true, HasLiftgate: true}
+	default:
+		return Profile{Model: modelName, HasFrunk: true, HasLiftgate: true, HasTonneau: true}
+	}
+}
+
+// Profile returns the vehicle profile for this state's model.
+func (v *VehicleState) Profile() Profile {
+	return VehicleProfile(v.Model)
+}
+
+// Tonneau returns the tonneau cover status and true only when the model has
+// a tonneau and the API reported a known status for it. Views and insights
+// go through it so an R1S never shows a tonneau line.
+func (v *VehicleState) Tonneau() (ClosureStatus, bool) {
+	if !v.Profile().HasTonneau || v.TonneauCover == nil {
+		return ClosureStatusUnknown, false
+	}
+	switch *v.TonneauCover {
+	case ClosureStatusUnknown, "":
+		return ClosureStatusUnknown, false
+	}
+	return *v.TonneauCover, true
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/rivian"
@@ -9,6 +11,8 @@ import (
 // Event represents an event that can update the vehicle state.
 type Event interface {
 	// ApplyTo applies this event to the given state, returning updated state.
+	// Implementations must not modify current; they return a new value (or
+	// current itself when nothing applies).
 	ApplyTo(current *VehicleState) *VehicleState
 }
 
@@ -23,18 +27,20 @@ func (e VehicleListReceived) ApplyTo(current *VehicleState) *VehicleState {
 	// Find our vehicle in the list
 	for _, v := range e.Vehicles {
 		if v.ID == e.VehicleID {
-			if current == nil {
-				current = &VehicleState{}
+			// Make a copy to avoid mutation
+			var updated VehicleState
+			if current != nil {
+				updated = *current
 			}
 
 			// Update identity fields
-			current.VehicleID = v.ID
-			current.VIN = v.VIN
-			current.Name = v.Name
-			current.Model = v.Model
-			current.UpdatedAt = time.Now()
+			updated.VehicleID = v.ID
+			updated.VIN = v.VIN
+			updated.Name = v.Name
+			updated.Model = v.Model
+			updated.UpdatedAt = time.Now()
 
-			return current
+			return &updated
 		}
 	}
 
@@ -42,6 +48,42 @@ func (e VehicleListReceived) ApplyTo(current *VehicleState) *VehicleState {
 	return current
 }
 
+// VehicleMetadataUpdated is emitted when the vehicle list is re-fetched
+// (e.g. after a vehicle is renamed). Unlike VehicleListReceived it only
+// merges identity fields and never touches telemetry or timestamps.
+type VehicleMetadataUpdated struct {
+	Vehicle rivian.Vehicle
+}
+
+// ApplyTo merges updated identity fields onto the current state.
+func (e VehicleMetadataUpdated) ApplyTo(current *VehicleState) *VehicleState {
+	if current == nil {
+		return nil // Nothing to enrich yet - identity arrives with the first state
+	}
+
+	// Ignore metadata for a different vehicle
+	if current.VehicleID != "" && current.VehicleID != e.Vehicle.ID {
+		return current
+	}
+
+	// Make a copy to avoid mutation
+	updated := *current
+	updated.VehicleID = e.Vehicle.ID
+
+	// Only overwrite fields the API actually returned
+	if e.Vehicle.VIN != "" {
+		updated.VIN = e.Vehicle.VIN
+	}
+	if e.Vehicle.Name != "" {
+		updated.Name = e.Vehicle.Name
+	}
+	if e.Vehicle.Model != "" {
+		updated.Model = e.Vehicle.Model
+	}
+
+	return &updated
+}
+
 // VehicleStateReceived is emitted when we receive vehicle state from the API.
 type VehicleStateReceived struct {
 	State *rivian.VehicleState
@@ -67,11 +109,40 @@ func (e VehicleStateReceived) ApplyTo(current *VehicleState) *VehicleState {
 		if newState.Model == "" {
 			newState.Model = current.Model
 		}
+
+		// Software versions come from a separate query
+		newState.SoftwareVersion = current.SoftwareVersion
+		newState.AvailableUpdate = current.AvailableUpdate
 	}
 
 	return newState
 }
 
+// SoftwareInfoReceived is emitted when OTA software versions are fetched.
+type SoftwareInfoReceived struct {
+	Info *rivian.SoftwareInfo
+}
+
+// ApplyTo merges the software versions into the current state.
+func (e SoftwareInfoReceived) ApplyTo(current *VehicleState) *VehicleState {
+	if e.Info == nil || current == nil {
+		return current
+	}
+	if current.VehicleID != "" && current.VehicleID != e.Info.VehicleID {
+		return current
+	}
+
+	// Make a copy to avoid mutation
+	updated := *current
+	updated.SoftwareVersion = e.Info.CurrentVersion
+	updated.AvailableUpdate = ""
+	if e.Info.UpdateAvailable() {
+		updated.AvailableUpdate = e.Info.AvailableVersion
+	}
+
+	return &updated
+}
+
 // PartialStateUpdate represents a partial update (e.g., from WebSocket).
 type PartialStateUpdate struct {
 	VehicleID string
@@ -89,6 +160,8 @@ func (e PartialStateUpdate) ApplyTo(current *VehicleState) *VehicleState {
 	// Make a copy to avoid mutation
 	updated := *current
 	updated.UpdatedAt = time.Now()
+	updated.ReportedAt = updated.UpdatedAt // Live updates mean the vehicle is awake
+	updated.Raw = nil                      // No longer what the API returned
 
 	// Apply updates
 	for field, value := range e.Updates {
@@ -96,11 +169,14 @@ func (e PartialStateUpdate) ApplyTo(current *VehicleState) *VehicleState {
 		case "batteryLevel":
 			if v, ok := value.(float64); ok {
 				updated.BatteryLevel = v
+				updated.TelemetryMissing = false
 			}
 		case "rangeEstimate":
 			if v, ok := value.(float64); ok {
-				updated.RangeEstimate = v
-				updated.RangeStatus = DetermineRangeStatus(v)
+				// Partial updates carry the API's unit, like full states
+				updated.RangeEstimate = apiRangeToMiles(v)
+				updated.RangeStatus = DetermineRangeStatus(updated.RangeEstimate)
+				updated.TelemetryMissing = false
 			}
 		case "chargeState":
 			if v, ok := value.(string); ok {
@@ -121,8 +197,39 @@ func (e PartialStateUpdate) ApplyTo(current *VehicleState) *VehicleState {
 	return &updated
 }
 
-// Reducer processes events and produces new state.
+// changes reports whether applying the update would change any field of
+// current other than the timestamps and raw response it always resets.
+func (e PartialStateUpdate) changes(current *VehicleState) bool {
+	if current == nil {
+		return true
+	}
+	next := e.ApplyTo(current)
+	next.UpdatedAt = current.UpdatedAt
+	next.ReportedAt = current.ReportedAt
+	next.Raw = current.Raw
+	return !reflect.DeepEqual(next, current)
+}
+
+// Reducer processes events and produces new state. It is the single place
+// where API data becomes a VehicleState, shared by the TUI and the status,
+// watch and plan commands.
+//
+// Each event owns a slice of the state:
+//   - VehicleListReceived sets identity (ID, VIN, name, model), keeping telemetry.
+//   - VehicleStateReceived replaces telemetry with a full snapshot, keeping
+//     identity fields the snapshot lacks and the software versions.
+//   - VehicleMetadataUpdated merges non-empty identity fields only.
+//   - SoftwareInfoReceived sets the software versions only.
+//   - PartialStateUpdate overwrites just the fields it carries.
+//
+// Events may arrive in any order: identity survives a later snapshot and a
+// snapshot survives a later identity update. A partial update before any
+// snapshot starts from an empty state.
+//
+// A Reducer is safe for concurrent use, e.g. by a live subscription loop
+// and an HTTP refresh.
 type Reducer struct {
+	mu           sync.Mutex
 	currentState *VehicleState
 }
 
@@ -132,13 +239,40 @@ func NewReducer() *Reducer {
 }
 
 // Dispatch processes an event and updates the state.
+// Derived metrics are recomputed after every event, so every consumer of the
+// reducer sees the same ReadyScore. States returned by earlier calls are not
+// modified by later events.
 func (r *Reducer) Dispatch(event Event) *VehicleState {
-	r.currentState = event.ApplyTo(r.currentState)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dispatch(event)
+}
+
+func (r *Reducer) dispatch(event Event) *VehicleState {
+	next := event.ApplyTo(r.currentState)
+	if next != nil && next != r.currentState {
+		next.UpdateReadyScore()
+	}
+	r.currentState = next
 	return r.currentState
 }
 
+// DispatchPartial applies a partial update unless it repeats values already
+// in the current state (e.g. keep-alive snapshots). changed is false when the
+// update was skipped, so callers can avoid redundant saves and re-renders.
+func (r *Reducer) DispatchPartial(e PartialStateUpdate) (state *VehicleState, changed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !e.changes(r.currentState) {
+		return r.currentState, false
+	}
+	return r.dispatch(e), true
+}
+
 // GetState returns the current state (read-only).
 func (r *Reducer) GetState() *VehicleState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if r.currentState == nil {
 		return nil
 	}
@@ -149,5 +283,7 @@ func (r *Reducer) GetState() *VehicleState {
 
 // Reset clears the current state.
 func (r *Reducer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.currentState = nil
 }
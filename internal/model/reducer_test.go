@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -140,6 +141,9 @@ func TestReducer_PartialStateUpdate(t *testing.T) {
 		BatteryLevel:  80.0,
 		RangeEstimate: 200.0,
 		ChargeState:   ChargeStateNotCharging,
+		Raw:           []byte(`{"batteryLevel":{"value":80}}`),
+
+		TelemetryMissing: true,
 	}
 
 	event := PartialStateUpdate{
@@ -156,9 +160,9 @@ func TestReducer_PartialStateUpdate(t *testing.T) {
 	if state.BatteryLevel != 85.5 {
 		t.Errorf("BatteryLevel = %v, want %v", state.BatteryLevel, 85.5)
 	}
-	// PartialStateUpdate sets values directly (no conversion needed)
-	if state.RangeEstimate != 250.0 {
-		t.Errorf("RangeEstimate = %v, want %v", state.RangeEstimate, 250.0)
+	// The range arrives in the API's unit, kilometers by default
+	if state.RangeEstimate != 250.0/1.60934 {
+		t.Errorf("RangeEstimate = %v, want %v", state.RangeEstimate, 250.0/1.60934)
 	}
 	if state.ChargeState != ChargeStateCharging {
 		t.Errorf("ChargeState = %v, want %v", state.ChargeState, ChargeStateCharging)
@@ -166,6 +170,133 @@ func TestReducer_PartialStateUpdate(t *testing.T) {
 	if state.RangeStatus != RangeStatusNormal {
 		t.Errorf("RangeStatus should be updated to %v, got %v", RangeStatusNormal, state.RangeStatus)
 	}
+	if state.Raw != nil {
+		t.Errorf("Raw should be cleared once the state no longer matches it, got %s", state.Raw)
+	}
+	if state.TelemetryMissing {
+		t.Error("TelemetryMissing should be cleared by a battery or range update")
+	}
+}
+
+func TestReducer_PartialStateUpdate_RangeUnit(t *testing.T) {
+	t.Cleanup(func() { apiRangeUnit = DistanceKilometers })
+
+	update := PartialStateUpdate{
+		VehicleID: "vehicle-1",
+		Updates:   map[string]interface{}{"rangeEstimate": 400.0},
+	}
+
+	if err := SetAPIRangeUnit(DistanceKilometers); err != nil {
+		t.Fatalf("SetAPIRangeUnit(km) error = %v", err)
+	}
+	reducer := NewReducer()
+	reducer.currentState = &VehicleState{VehicleID: "vehicle-1"}
+	if got := reducer.Dispatch(update).RangeEstimate; math.Abs(got-248.55) > 0.01 {
+		t.Errorf("km: RangeEstimate = %.2f, want 248.55", got)
+	}
+
+	if err := SetAPIRangeUnit(DistanceMiles); err != nil {
+		t.Fatalf("SetAPIRangeUnit(mi) error = %v", err)
+	}
+	reducer = NewReducer()
+	reducer.currentState = &VehicleState{VehicleID: "vehicle-1"}
+	if got := reducer.Dispatch(update).RangeEstimate; got != 400 {
+		t.Errorf("mi: RangeEstimate = %.2f, want 400", got)
+	}
+}
+
+func TestReducer_VehicleMetadataUpdated(t *testing.T) {
+	reducer := NewReducer()
+
+	updatedAt := time.Now().Add(-time.Hour)
+	reducer.currentState = &VehicleState{
+		VehicleID:     "vehicle-1",
+		VIN:           "VIN123",
+		Name:          "Old Name",
+		Model:         "R1T",
+		UpdatedAt:     updatedAt,
+		BatteryLevel:  72.5,
+		RangeEstimate: 210.0,
+	}
+
+	state := reducer.Dispatch(VehicleMetadataUpdated{
+		Vehicle: rivian.Vehicle{ID: "vehicle-1", Name: "Adventure Rig", Model: "R1T"},
+	})
+
+	if state.Name != "Adventure Rig" {
+		t.Errorf("Name = %v, want %v", state.Name, "Adventure Rig")
+	}
+	// Empty fields from the API must not clobber existing identity
+	if state.VIN != "VIN123" {
+		t.Errorf("VIN = %v, want %v", state.VIN, "VIN123")
+	}
+	// Telemetry and timestamps must be preserved
+	if state.BatteryLevel != 72.5 {
+		t.Errorf("BatteryLevel = %v, want %v", state.BatteryLevel, 72.5)
+	}
+	if state.RangeEstimate != 210.0 {
+		t.Errorf("RangeEstimate = %v, want %v", state.RangeEstimate, 210.0)
+	}
+	if !state.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("UpdatedAt changed: got %v, want %v", state.UpdatedAt, updatedAt)
+	}
+}
+
+func TestReducer_VehicleMetadataUpdated_OtherVehicle(t *testing.T) {
+	reducer := NewReducer()
+	reducer.currentState = &VehicleState{VehicleID: "vehicle-1", Name: "Mine"}
+
+	state := reducer.Dispatch(VehicleMetadataUpdated{
+		Vehicle: rivian.Vehicle{ID: "vehicle-2", Name: "Someone Else"},
+	})
+
+	if state.Name != "Mine" {
+		t.Errorf("State should be unchanged, got Name = %v", state.Name)
+	}
+}
+
+func TestReducer_VehicleMetadataUpdated_NoState(t *testing.T) {
+	reducer := NewReducer()
+
+	state := reducer.Dispatch(VehicleMetadataUpdated{
+		Vehicle: rivian.Vehicle{ID: "vehicle-1", Name: "New"},
+	})
+
+	if state != nil {
+		t.Errorf("Expected nil state when nothing has been fetched yet, got %+v", state)
+	}
+}
+
+func TestReducer_SoftwareInfoReceived(t *testing.T) {
+	reducer := NewReducer()
+	reducer.currentState = &VehicleState{VehicleID: "vehicle-1", BatteryLevel: 72.5}
+
+	state := reducer.Dispatch(SoftwareInfoReceived{
+		Info: &rivian.SoftwareInfo{VehicleID: "vehicle-1", CurrentVersion: "2024.10.0", AvailableVersion: "2024.12.1"},
+	})
+	if state.SoftwareVersion != "2024.10.0" || state.AvailableUpdate != "2024.12.1" {
+		t.Errorf("software = %q/%q, want 2024.10.0/2024.12.1", state.SoftwareVersion, state.AvailableUpdate)
+	}
+
+	// Versions survive a later full state refresh
+	state = reducer.Dispatch(VehicleStateReceived{State: &rivian.VehicleState{VehicleID: "vehicle-1"}})
+	if state.SoftwareVersion != "2024.10.0" {
+		t.Errorf("SoftwareVersion = %q after state refresh, want it preserved", state.SoftwareVersion)
+	}
+
+	// An "available" version equal to the installed one is not an update
+	state = reducer.Dispatch(SoftwareInfoReceived{
+		Info: &rivian.SoftwareInfo{VehicleID: "vehicle-1", CurrentVersion: "2024.12.1", AvailableVersion: "2024.12.1"},
+	})
+	if state.AvailableUpdate != "" {
+		t.Errorf("AvailableUpdate = %q, want empty when up to date", state.AvailableUpdate)
+	}
+
+	// Other vehicles are ignored
+	state = reducer.Dispatch(SoftwareInfoReceived{Info: &rivian.SoftwareInfo{VehicleID: "vehicle-2", CurrentVersion: "1.0"}})
+	if state.SoftwareVersion != "2024.12.1" {
+		t.Errorf("SoftwareVersion = %q, want other vehicle's info ignored", state.SoftwareVersion)
+	}
 }
 
 func TestReducer_GetState(t *testing.T) {
@@ -274,3 +405,171 @@ func TestReducer_MultipleEvents(t *testing.T) {
 		t.Errorf("CabinTemp = %v, want %v (converted from 72°C)", state.CabinTemp, expectedTemp)
 	}
 }
+
+func TestReducer_DispatchPartial_Duplicate(t *testing.T) {
+	reducer := NewReducer()
+	reducer.currentState = &VehicleState{
+		VehicleID:    "vehicle-1",
+		BatteryLevel: 80.0,
+		ChargeState:  ChargeStateNotCharging,
+		RangeStatus:  RangeStatusNormal,
+	}
+
+	event := PartialStateUpdate{
+		VehicleID: "vehicle-1",
+		Updates:   map[string]interface{}{"batteryLevel": 85.0},
+	}
+
+	state, changed := reducer.DispatchPartial(event)
+	if !changed {
+		t.Fatal("Expected first update to change state")
+	}
+	if state.BatteryLevel != 85.0 {
+		t.Errorf("BatteryLevel = %v, want 85", state.BatteryLevel)
+	}
+
+	again, changed := reducer.DispatchPartial(event)
+	if changed {
+		t.Error("Expected identical update to be skipped")
+	}
+	if again != state {
+		t.Error("Expected skipped update to return the current state")
+	}
+}
+
+func TestReducer_DispatchComputesReadyScore(t *testing.T) {
+	reducer := NewReducer()
+
+	state := reducer.Dispatch(VehicleStateReceived{State: &rivian.VehicleState{
+		VehicleID:     "vehicle-1",
+		BatteryLevel:  80,
+		RangeEstimate: 400,
+		IsOnline:      true,
+	}})
+	if state.ReadyScore == nil {
+		t.Fatal("Expected ReadyScore to be computed on dispatch")
+	}
+	initial := *state.ReadyScore
+
+	state = reducer.Dispatch(PartialStateUpdate{
+		VehicleID: "vehicle-1",
+		Updates:   map[string]interface{}{"batteryLevel": 20.0},
+	})
+	if state.ReadyScore == nil || *state.ReadyScore >= initial {
+		t.Errorf("Expected ReadyScore to drop after a battery update, got %v (was %v)", state.ReadyScore, initial)
+	}
+}
+
+func TestReducer_EventOrdering(t *testing.T) {
+	list := VehicleListReceived{
+		Vehicles:  []rivian.Vehicle{{ID: "vehicle-1", VIN: "VIN123", Name: "My R1T", Model: "R1T"}},
+		VehicleID: "vehicle-1",
+	}
+	snapshot := VehicleStateReceived{
+		State: &rivian.VehicleState{VehicleID: "vehicle-1", BatteryLevel: 80, RangeEstimate: 400},
+	}
+	software := SoftwareInfoReceived{
+		Info: &rivian.SoftwareInfo{VehicleID: "vehicle-1", CurrentVersion: "2024.10.0"},
+	}
+
+	orders := map[string][]Event{
+		"list, state, software": {list, snapshot, software},
+		"state, list, software": {snapshot, list, software},
+		"software, state, list": {software, snapshot, list},
+		"list, software, state": {list, software, snapshot},
+	}
+
+	for name, events := range orders {
+		t.Run(name, func(t *testing.T) {
+			reducer := NewReducer()
+			var state *VehicleState
+			for _, e := range events {
+				state = reducer.Dispatch(e)
+			}
+
+			if state.VIN != "VIN123" || state.Name != "My R1T" || state.Model != "R1T" {
+				t.Errorf("identity = %q/%q/%q, want VIN123/My R1T/R1T", state.VIN, state.Name, state.Model)
+			}
+			if state.BatteryLevel != 80 {
+				t.Errorf("BatteryLevel = %v, want 80", state.BatteryLevel)
+			}
+			if state.ReadyScore == nil && state.IsOnline {
+				t.Error("ReadyScore should be computed")
+			}
+		})
+	}
+
+	// Software info arriving before any state has nothing to attach to
+	reducer := NewReducer()
+	reducer.Dispatch(software)
+	reducer.Dispatch(list)
+	if state := reducer.Dispatch(software); state.SoftwareVersion != "2024.10.0" {
+		t.Errorf("SoftwareVersion = %q, want 2024.10.0", state.SoftwareVersion)
+	}
+	if state := reducer.Dispatch(snapshot); state.SoftwareVersion != "2024.10.0" {
+		t.Errorf("snapshot dropped SoftwareVersion, got %q", state.SoftwareVersion)
+	}
+}
+
+func TestReducer_PartialMerge(t *testing.T) {
+	reducer := NewReducer()
+	reducer.Dispatch(VehicleListReceived{
+		Vehicles:  []rivian.Vehicle{{ID: "vehicle-1", VIN: "VIN123", Name: "My R1T", Model: "R1T"}},
+		VehicleID: "vehicle-1",
+	})
+	before := reducer.Dispatch(VehicleStateReceived{
+		State: &rivian.VehicleState{
+			VehicleID:     "vehicle-1",
+			BatteryLevel:  80,
+			RangeEstimate: 400,
+			ChargeState:   "not_charging",
+			IsLocked:      true,
+		},
+	})
+
+	state := reducer.Dispatch(PartialStateUpdate{
+		VehicleID: "vehicle-1",
+		Updates: map[string]interface{}{
+			"rangeEstimate": 64.0,
+			"isLocked":      "yes", // wrong type, ignored
+			"unknownField":  1.0,   // not mapped, ignored
+		},
+	})
+
+	if state.RangeEstimate != 64/1.60934 || state.RangeStatus != RangeStatusLow {
+		t.Errorf("range = %v (%s), want 64 km (low)", state.RangeEstimate, state.RangeStatus)
+	}
+	if !state.IsLocked {
+		t.Error("IsLocked should keep its value when the update has the wrong type")
+	}
+	if state.BatteryLevel != 80 || state.ChargeState != ChargeStateNotCharging {
+		t.Errorf("untouched fields changed: battery %v, charge %q", state.BatteryLevel, state.ChargeState)
+	}
+	if state.VIN != "VIN123" || state.Name != "My R1T" {
+		t.Errorf("identity lost: VIN %q, name %q", state.VIN, state.Name)
+	}
+
+	// Earlier states are snapshots and don't see later events
+	if before.RangeEstimate != 400/1.60934 {
+		t.Errorf("previous state was mutated: RangeEstimate = %v", before.RangeEstimate)
+	}
+}
+
+func TestReducer_VehicleListReceived_DoesNotMutate(t *testing.T) {
+	reducer := NewReducer()
+	before := reducer.Dispatch(VehicleStateReceived{
+		State: &rivian.VehicleState{VehicleID: "vehicle-1", BatteryLevel: 80},
+	})
+
+	after := reducer.Dispatch(VehicleListReceived{
+		Vehicles:  []rivian.Vehicle{{ID: "vehicle-1", Name: "Renamed"}},
+		VehicleID: "vehicle-1",
+	})
+
+	if before.Name != "" {
+		t.Errorf("previous state was mutated: Name = %q", before.Name)
+	}
+	if after.Name != "Renamed" || after.BatteryLevel != 80 {
+		t.Errorf("after = %q/%v, want Renamed/80", after.Name, after.BatteryLevel)
+	}
+}
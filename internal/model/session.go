@@ -0,0 +1,116 @@
+package model
+
+import "time"
+
+// ChargingSession summarizes one completed charging session.
+type ChargingSession struct {
+	VehicleID   string
+	StartTime   time.Time
+	EndTime     time.Time // When charging stopped (complete, unplugged, ...)
+	StartLevel  float64   // Battery % at start
+	EndLevel    float64   // Battery % at end
+	EnergyAdded float64   // kWh, from SOC delta and pack capacity
+	AvgRateKW   float64   // Mean of reported charging rates
+	PeakRateKW  float64
+}
+
+// SOCDelta returns the battery percentage gained during the session.
+func (s *ChargingSession) SOCDelta() float64 {
+	return s.EndLevel - s.StartLevel
+}
+
+// Duration returns how long the session lasted.
+func (s *ChargingSession) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// ChargingSessionTracker turns a stream of states into charging sessions.
+// Feed every observed state to Observe; it returns the finished session when
+// ChargeState transitions away from charging.
+type ChargingSessionTracker struct {
+	active    *ChargingSession
+	capacity  float64
+	rateSum   float64
+	rateCount int
+}
+
+// NewChargingSessionTracker creates a tracker with no active session.
+func NewChargingSessionTracker() *ChargingSessionTracker {
+	return &ChargingSessionTracker{}
+}
+
+// Observe records a state and returns the completed session, if this state
+// ended one. During a session, states older than the latest one seen are
+// ignored so out-of-order updates can't end it early.
+func (t *ChargingSessionTracker) Observe(state *VehicleState) *ChargingSession {
+	if state == nil {
+		return nil
+	}
+
+	if t.active != nil && state.UpdatedAt.Before(t.active.EndTime) {
+		return nil
+	}
+
+	if state.IsCharging() {
+		if t.active == nil {
+			t.active = &ChargingSession{
+				VehicleID:  state.VehicleID,
+				StartTime:  state.UpdatedAt,
+				StartLevel: state.BatteryLevel,
+			}
+			t.rateSum, t.rateCount = 0, 0
+		}
+
+		t.active.EndTime = state.UpdatedAt
+		t.active.EndLevel = state.BatteryLevel
+		if state.BatteryCapacity > 0 {
+			t.capacity = state.BatteryCapacity
+		}
+		if state.ChargingRate != nil && *state.ChargingRate > 0 {
+			t.rateSum += *state.ChargingRate
+			t.rateCount++
+			if *state.ChargingRate > t.active.PeakRateKW {
+				t.active.PeakRateKW = *state.ChargingRate
+			}
+		}
+		return nil
+	}
+
+	if t.active == nil {
+		return nil
+	}
+
+	// Charging stopped: this state marks the end of the session
+	t.active.EndTime = state.UpdatedAt
+	if state.BatteryLevel > 0 {
+		t.active.EndLevel = state.BatteryLevel
+	}
+	if state.BatteryCapacity > 0 {
+		t.capacity = state.BatteryCapacity
+	}
+	return t.finish()
+}
+
+// Flush ends a session still in progress at the last charging state seen
+// and returns it, or nil when no session is active. Use it at the end of a
+// history that stops while the vehicle is charging.
+func (t *ChargingSessionTracker) Flush() *ChargingSession {
+	if t.active == nil {
+		return nil
+	}
+	return t.finish()
+}
+
+// finish fills in the derived totals of the active session and clears it.
+func (t *ChargingSessionTracker) finish() *ChargingSession {
+	session := t.active
+	t.active = nil
+
+	if delta := session.SOCDelta(); delta > 0 && t.capacity > 0 {
+		session.EnergyAdded = delta / 100 * t.capacity
+	}
+	if t.rateCount > 0 {
+		session.AvgRateKW = t.rateSum / float64(t.rateCount)
+	}
+	return session
+}
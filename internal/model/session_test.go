@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChargingSessionTracker(t *testing.T) {
+	start := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	state := func(offset time.Duration, charge ChargeState, level float64, rate *float64) *VehicleState {
+		return &VehicleState{
+			VehicleID:       "vehicle-123",
+			UpdatedAt:       start.Add(offset),
+			ChargeState:     charge,
+			BatteryLevel:    level,
+			BatteryCapacity: 135,
+			ChargingRate:    rate,
+		}
+	}
+
+	tracker := NewChargingSessionTracker()
+	steps := []*VehicleState{
+		state(-time.Hour, ChargeStateNotCharging, 40, nil),
+		state(0, ChargeStateCharging, 40, float64Ptr(7)),
+		state(time.Hour, ChargeStateCharging, 50, float64Ptr(11)),
+		state(30*time.Minute, ChargeStateNotCharging, 45, nil), // out of order, ignored
+	}
+	for _, s := range steps {
+		if got := tracker.Observe(s); got != nil {
+			t.Fatalf("Observe() returned session %+v before charging stopped", got)
+		}
+	}
+
+	session := tracker.Observe(state(3*time.Hour, ChargeStateComplete, 80, nil))
+	if session == nil {
+		t.Fatal("Observe() should finalize the session when charging stops")
+	}
+
+	if !session.StartTime.Equal(start) || session.Duration() != 3*time.Hour {
+		t.Errorf("session time = %v for %v, want %v for 3h", session.StartTime, session.Duration(), start)
+	}
+	if session.SOCDelta() != 40 {
+		t.Errorf("SOCDelta() = %v, want 40", session.SOCDelta())
+	}
+	if session.EnergyAdded != 54 {
+		t.Errorf("EnergyAdded = %v, want 54", session.EnergyAdded)
+	}
+	if session.AvgRateKW != 9 || session.PeakRateKW != 11 {
+		t.Errorf("rates avg=%v peak=%v, want 9 and 11", session.AvgRateKW, session.PeakRateKW)
+	}
+
+	// Not charging with no active session produces nothing
+	if got := tracker.Observe(state(4*time.Hour, ChargeStateComplete, 80, nil)); got != nil {
+		t.Errorf("Observe() = %+v, want nil without an active session", got)
+	}
+	if got := tracker.Observe(nil); got != nil {
+		t.Errorf("Observe(nil) = %+v, want nil", got)
+	}
+
+	// Flush ends a session still charging at the last state seen
+	if got := tracker.Flush(); got != nil {
+		t.Errorf("Flush() = %+v, want nil without an active session", got)
+	}
+	tracker.Observe(state(5*time.Hour, ChargeStateCharging, 60, float64Ptr(50)))
+	tracker.Observe(state(6*time.Hour, ChargeStateCharging, 70, nil))
+	flushed := tracker.Flush()
+	if flushed == nil || !flushed.EndTime.Equal(start.Add(6*time.Hour)) || flushed.EnergyAdded != 13.5 || flushed.AvgRateKW != 50 {
+		t.Errorf("Flush() = %+v, want a session ending at 6h with 13.5 kWh at 50 kW", flushed)
+	}
+	if got := tracker.Flush(); got != nil {
+		t.Errorf("second Flush() = %+v, want nil", got)
+	}
+}
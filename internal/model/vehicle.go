@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/rivian"
@@ -18,6 +20,14 @@ type VehicleState struct {
 	// Timestamp of last update
 	UpdatedAt time.Time
 
+	// When the vehicle itself last reported telemetry (zero if unknown).
+	// Differs from UpdatedAt while it sleeps and the API serves old values.
+	ReportedAt time.Time
+
+	// The API returned no battery level or range, as for a vehicle in
+	// service or deep sleep; zero values then mean "not reported"
+	TelemetryMissing bool
+
 	// Battery & Charging
 	BatteryLevel    float64   // Percentage (0-100)
 	BatteryCapacity float64   // kWh (total capacity)
@@ -49,9 +59,17 @@ type VehicleState struct {
 	// Tires
 	TirePressures TirePressures
 
+	// Software (from a separate query, so may be empty)
+	SoftwareVersion string // Installed OTA version
+	AvailableUpdate string // Pending OTA version ("" when up to date)
+
 	// Derived Metrics (calculated by insights.go)
 	ReadyScore  *float64 // 0-100 score of "readiness to drive"
 	RangeStatus RangeStatus
+
+	// Raw API response this state was parsed from (nil unless fetched over
+	// HTTP); stored separately, only with --save-raw
+	Raw json.RawMessage `json:"-"`
 }
 
 // Location represents GPS coordinates.
@@ -131,7 +149,7 @@ type TirePressures struct {
 	RearLeft   float64 // PSI (0 if not available)
 	RearRight  float64 // PSI (0 if not available)
 
-	// Status from API (API doesn't provide PSI, only status)
+	// Status from API (reported even when the pressures aren't)
 	FrontLeftStatus  TirePressureStatus
 	FrontRightStatus TirePressureStatus
 	RearLeftStatus   TirePressureStatus
@@ -195,9 +213,10 @@ func FromRivianVehicleState(v *rivian.VehicleState) *VehicleState {
 	state := &VehicleState{
 		VehicleID:       v.VehicleID,
 		UpdatedAt:       v.UpdatedAt,
+		ReportedAt:      v.ReportedAt,
 		BatteryLevel:    v.BatteryLevel,
 		BatteryCapacity: v.BatteryCapacity,
-		RangeEstimate:   kilometersToMiles(v.RangeEstimate),
+		RangeEstimate:   apiRangeToMiles(v.RangeEstimate),
 		ChargeState:     ChargeState(v.ChargeState),
 		ChargeLimit:     v.ChargeLimit,
 		ChargingRate:    v.ChargingRate,
@@ -211,11 +230,12 @@ func FromRivianVehicleState(v *rivian.VehicleState) *VehicleState {
 		Windows:         closuresFromRivian(v.Windows),
 		Frunk:           ClosureStatus(v.Frunk),
 		Liftgate:        ClosureStatus(v.Liftgate),
+		Raw:             v.Raw,
 		TirePressures: TirePressures{
-			FrontLeft:        v.TirePressures.FrontLeft,
-			FrontRight:       v.TirePressures.FrontRight,
-			RearLeft:         v.TirePressures.RearLeft,
-			RearRight:        v.TirePressures.RearRight,
+			FrontLeft:        barToPSI(v.TirePressures.FrontLeft),
+			FrontRight:       barToPSI(v.TirePressures.FrontRight),
+			RearLeft:         barToPSI(v.TirePressures.RearLeft),
+			RearRight:        barToPSI(v.TirePressures.RearRight),
 			FrontLeftStatus:  TirePressureStatus(v.TirePressures.FrontLeftStatus),
 			FrontRightStatus: TirePressureStatus(v.TirePressures.FrontRightStatus),
 			RearLeftStatus:   TirePressureStatus(v.TirePressures.RearLeftStatus),
@@ -239,11 +259,18 @@ func FromRivianVehicleState(v *rivian.VehicleState) *VehicleState {
 		state.TonneauCover = &cs
 	}
 
-	// Calculate derived metrics
+	// Calculate derived metrics; a range that was never reported isn't critical
+	state.TelemetryMissing = v.TelemetryMissing
 	state.RangeStatus = DetermineRangeStatus(state.RangeEstimate)
+	if state.TelemetryMissing {
+		state.RangeStatus = RangeStatusUnknown
+	}
 
-	// Estimate battery capacity if not provided by API
-	if state.BatteryCapacity == 0 && state.BatteryLevel > 0 && state.RangeEstimate > 0 {
+	// A configured pack capacity beats anything else; otherwise estimate
+	// it if the API didn't provide one
+	if kWh := batteryCapacities[state.VehicleID]; kWh > 0 {
+		state.BatteryCapacity = kWh
+	} else if state.BatteryCapacity == 0 && state.BatteryLevel > 0 && state.RangeEstimate > 0 {
 		state.BatteryCapacity = estimateBatteryCapacity(state.Model, state.BatteryLevel, state.RangeEstimate)
 	}
 
@@ -264,10 +291,13 @@ func estimateBatteryCapacity(model string, batteryPercent, rangeMiles float64) f
 	// R1T: ~2.0 mi/kWh average
 	// R1S: ~2.1 mi/kWh average (slightly more efficient due to aerodynamics)
 	var efficiency float64
-	if model == "R1S" {
+	switch VehicleProfile(model).Model {
+	case "R1T":
+		efficiency = 2.0
+	case "R1S":
 		efficiency = 2.1
-	} else {
-		efficiency = 2.0 // R1T or unknown
+	default:
+		efficiency = 2.05 // Unknown model: split the difference
 	}
 
 	// Calculate capacity: range / efficiency
@@ -293,12 +323,95 @@ func metersToMiles(meters float64) float64 {
 	return meters / 1609.34
 }
 
+// barToPSI converts a tire pressure in bar to PSI; 0 (not reported) stays 0.
+func barToPSI(bar float64) float64 {
+	return bar * 14.5038
+}
+
 // kilometersToMiles converts kilometers to miles.
-// Rivian API returns range estimate in kilometers, we display in miles.
 func kilometersToMiles(km float64) float64 {
 	return km / 1.60934
 }
 
+// DistanceUnit is the unit the API reports the range estimate
+// (distanceToEmpty) in.
+type DistanceUnit string
+
+const (
+	DistanceKilometers DistanceUnit = "km"
+	DistanceMiles      DistanceUnit = "mi"
+)
+
+// apiRangeUnit is the one place the range unit assumption lives. The API
+// has always reported kilometers, but accounts with a miles regional
+// setting may not; SetAPIRangeUnit overrides it.
+var apiRangeUnit = DistanceKilometers
+
+// SetAPIRangeUnit sets the unit the API's range estimate is read in.
+// Call it before any state is converted.
+func SetAPIRangeUnit(unit DistanceUnit) error {
+	switch unit {
+	case DistanceKilometers, DistanceMiles:
+		apiRangeUnit = unit
+		return nil
+	}
+	return fmt.Errorf("invalid range unit %q: expected km or mi", string(unit))
+}
+
+// batteryCapacities holds the usable pack capacity in kWh set by the user,
+// by vehicle ID. Vehicles without one have it estimated from range and
+// charge level (see estimateBatteryCapacity), which is rough, especially at
+// low charge.
+var batteryCapacities = map[string]float64{}
+
+// SetBatteryCapacity sets the vehicle's pack capacity in kWh used for
+// energy figures instead of the estimate; 0 goes back to estimating. Call
+// it before any state is converted.
+func SetBatteryCapacity(vehicleID string, kWh float64) {
+	if kWh <= 0 {
+		delete(batteryCapacities, vehicleID)
+		return
+	}
+	batteryCapacities[vehicleID] = kWh
+}
+
+// apiRangeToMiles converts a range estimate from the API to miles.
+func apiRangeToMiles(v float64) float64 {
+	if apiRangeUnit == DistanceMiles {
+		return v
+	}
+	return kilometersToMiles(v)
+}
+
+// Bounds on the range a full battery can plausibly give, in miles. Rivian
+// rates 260-420 mi and cold weather or towing can cut that by up to 40%,
+// while miles misread as kilometers show 62% of it. The bounds only catch
+// the clear cases. Below rangeCheckMinLevel the estimate is too coarse to
+// judge.
+const (
+	minPlausibleFullRange = 150.0
+	maxPlausibleFullRange = 500.0
+	rangeCheckMinLevel    = 20.0
+)
+
+// RangeUnitWarning returns a warning when the range estimate is implausible
+// for the battery level, which usually means the API reports range in a
+// different unit than apiRangeUnit; otherwise it returns "".
+func RangeUnitWarning(state *VehicleState) string {
+	if state == nil || state.BatteryLevel < rangeCheckMinLevel || state.RangeEstimate <= 0 {
+		return ""
+	}
+
+	fullRange := state.RangeEstimate / (state.BatteryLevel / 100)
+	switch {
+	case fullRange < minPlausibleFullRange && apiRangeUnit == DistanceKilometers:
+		return fmt.Sprintf("range %.0f mi looks low for %.0f%% battery; if the API reports miles for your account, use --range-unit mi", state.RangeEstimate, state.BatteryLevel)
+	case fullRange > maxPlausibleFullRange && apiRangeUnit == DistanceMiles:
+		return fmt.Sprintf("range %.0f mi looks high for %.0f%% battery; if the API reports kilometers for your account, use --range-unit km", state.RangeEstimate, state.BatteryLevel)
+	}
+	return ""
+}
+
 // celsiusToFahrenheit converts temperature from Celsius to Fahrenheit.
 // Rivian API returns temperatures in Celsius, we display in Fahrenheit.
 func celsiusToFahrenheit(celsius *float64) *float64 {
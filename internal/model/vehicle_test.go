@@ -1,6 +1,8 @@
 package model
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -185,6 +187,7 @@ func TestFromRivianVehicleState(t *testing.T) {
 	lat := 37.7749
 	lon := -122.4194
 	cabinTemp := 72.0
+	exteriorTemp := -5.0
 	chargingRate := 11.5
 
 	rivState := &rivian.VehicleState{
@@ -200,6 +203,7 @@ func TestFromRivianVehicleState(t *testing.T) {
 		IsOnline:        true,
 		Odometer:        12345.6,
 		CabinTemp:       &cabinTemp,
+		ExteriorTemp:    &exteriorTemp,
 		Doors: rivian.ClosureState{
 			FrontLeft:  rivian.ClosureStatusClosed,
 			FrontRight: rivian.ClosureStatusClosed,
@@ -215,10 +219,10 @@ func TestFromRivianVehicleState(t *testing.T) {
 		Frunk:    rivian.ClosureStatusClosed,
 		Liftgate: rivian.ClosureStatusClosed,
 		TirePressures: rivian.TirePressures{
-			FrontLeft:  42.0,
-			FrontRight: 41.5,
-			RearLeft:   42.0,
-			RearRight:  41.5,
+			FrontLeft:  2.9, // Bar
+			FrontRight: 2.85,
+			RearLeft:   2.9,
+			RearRight:  2.85,
 		},
 		Latitude:  &lat,
 		Longitude: &lon,
@@ -258,4 +262,186 @@ func TestFromRivianVehicleState(t *testing.T) {
 	if state.RangeStatus != RangeStatusNormal {
 		t.Errorf("RangeStatus = %v, want %v", state.RangeStatus, RangeStatusNormal)
 	}
+	// Tire pressures should be converted from bar to PSI
+	if got := state.TirePressures.FrontLeft; math.Abs(got-42.06) > 0.01 {
+		t.Errorf("TirePressures.FrontLeft = %v, want 42.06 PSI (converted from 2.9 bar)", got)
+	}
+	// Exterior temperature should be converted from Celsius to Fahrenheit
+	if state.ExteriorTemp == nil || *state.ExteriorTemp != 23 {
+		t.Errorf("ExteriorTemp = %v, want 23°F (converted from -5°C)", state.ExteriorTemp)
+	}
+}
+
+func TestVehicleProfile(t *testing.T) {
+	tests := []struct {
+		model       string
+		wantModel   string
+		wantTonneau bool
+	}{
+		{"R1T", "R1T", true},
+		{"r1t", "R1T", true},
+		{"R1S", "R1S", false},
+		{"", "", true},
+		{"Future Model", "Future Model", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p := VehicleProfile(tt.model)
+			if p.Model != tt.wantModel {
+				t.Errorf("Model = %q, want %q", p.Model, tt.wantModel)
+			}
+			if p.HasTonneau != tt.wantTonneau {
+				t.Errorf("HasTonneau = %v, want %v", p.HasTonneau, tt.wantTonneau)
+			}
+			if !p.HasFrunk || !p.HasLiftgate {
+				t.Errorf("expected frunk and liftgate for %q", tt.model)
+			}
+		})
+	}
+}
+
+func TestVehicleStateTonneau(t *testing.T) {
+	open := ClosureStatusOpen
+	unknown := ClosureStatusUnknown
+
+	tests := []struct {
+		name    string
+		model   string
+		tonneau *ClosureStatus
+		wantOK  bool
+	}{
+		{"R1T reported", "R1T", &open, true},
+		{"R1T not reported", "R1T", nil, false},
+		{"R1T unknown", "R1T", &unknown, false},
+		{"R1S reported", "R1S", &open, false},
+		{"R1S not reported", "R1S", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &VehicleState{Model: tt.model, TonneauCover: tt.tonneau}
+			status, ok := state.Tonneau()
+			if ok != tt.wantOK {
+				t.Fatalf("Tonneau() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && status != *tt.tonneau {
+				t.Errorf("Tonneau() = %v, want %v", status, *tt.tonneau)
+			}
+
+			issues := strings.Join(state.GetIssues(), "; ")
+			if hasIssue := strings.Contains(issues, "Tonneau"); hasIssue != tt.wantOK {
+				t.Errorf("GetIssues() = %q, tonneau warning = %v, want %v", issues, hasIssue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEstimateBatteryCapacity(t *testing.T) {
+	// 50% with 102.5 miles left is 205 miles at 100%
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"R1T", 102.5},
+		{"R1S", 205 / 2.1},
+		{"", 100},
+		{"Unknown", 100},
+	}
+
+	for _, tt := range tests {
+		got := estimateBatteryCapacity(tt.model, 50, 102.5)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("estimateBatteryCapacity(%q) = %.2f, want %.2f", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestSetAPIRangeUnit(t *testing.T) {
+	t.Cleanup(func() { apiRangeUnit = DistanceKilometers })
+
+	rivState := &rivian.VehicleState{RangeEstimate: 250}
+	if got := FromRivianVehicleState(rivState).RangeEstimate; math.Abs(got-155.34) > 0.01 {
+		t.Errorf("km: RangeEstimate = %.2f, want 155.34", got)
+	}
+
+	if err := SetAPIRangeUnit(DistanceMiles); err != nil {
+		t.Fatalf("SetAPIRangeUnit(mi) error = %v", err)
+	}
+	if got := FromRivianVehicleState(rivState).RangeEstimate; got != 250 {
+		t.Errorf("mi: RangeEstimate = %.2f, want 250", got)
+	}
+
+	if err := SetAPIRangeUnit("miles"); err == nil {
+		t.Error("SetAPIRangeUnit(miles) should fail")
+	}
+	if apiRangeUnit != DistanceMiles {
+		t.Errorf("invalid unit changed apiRangeUnit to %q", apiRangeUnit)
+	}
+}
+
+func TestSetBatteryCapacity(t *testing.T) {
+	t.Cleanup(func() { SetBatteryCapacity("vehicle-1", 0) })
+
+	// 100 miles at 40% estimates 250 miles / 2.05 mi/kWh = 122 kWh
+	rivState := &rivian.VehicleState{VehicleID: "vehicle-1", BatteryLevel: 40, RangeEstimate: 100 * 1.60934}
+	estimated := FromRivianVehicleState(rivState).BatteryCapacity
+	if math.Abs(estimated-122) > 0.5 {
+		t.Fatalf("estimated BatteryCapacity = %.1f, want about 122", estimated)
+	}
+
+	SetBatteryCapacity("vehicle-1", 135)
+	if got := FromRivianVehicleState(rivState).BatteryCapacity; got != 135 {
+		t.Errorf("configured BatteryCapacity = %.1f, want 135 instead of the estimate", got)
+	}
+	// It also wins over a capacity from the API
+	rivState.BatteryCapacity = 128.9
+	if got := FromRivianVehicleState(rivState).BatteryCapacity; got != 135 {
+		t.Errorf("BatteryCapacity = %.1f, want the configured 135 over the API's", got)
+	}
+
+	// Other vehicles on the account keep the estimate
+	other := *rivState
+	other.VehicleID, other.BatteryCapacity = "vehicle-2", 0
+	if got := FromRivianVehicleState(&other).BatteryCapacity; got != estimated {
+		t.Errorf("other vehicle's BatteryCapacity = %.1f, want the estimate %.1f", got, estimated)
+	}
+
+	// Zero goes back to estimating
+	rivState.BatteryCapacity = 0
+	SetBatteryCapacity("vehicle-1", 0)
+	if got := FromRivianVehicleState(rivState).BatteryCapacity; got != estimated {
+		t.Errorf("BatteryCapacity after reset = %.1f, want the estimate %.1f", got, estimated)
+	}
+}
+
+func TestRangeUnitWarning(t *testing.T) {
+	t.Cleanup(func() { apiRangeUnit = DistanceKilometers })
+
+	tests := []struct {
+		name    string
+		unit    DistanceUnit
+		battery float64
+		miles   float64
+		want    string
+	}{
+		{"plausible", DistanceKilometers, 80, 240, ""},
+		{"miles read as km", DistanceKilometers, 80, 100, "--range-unit mi"},
+		{"km read as miles", DistanceMiles, 80, 500, "--range-unit km"},
+		{"low battery skipped", DistanceKilometers, 10, 5, ""},
+		{"low range already miles", DistanceMiles, 80, 100, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiRangeUnit = tt.unit
+			got := RangeUnitWarning(&VehicleState{BatteryLevel: tt.battery, RangeEstimate: tt.miles})
+			if tt.want == "" && got != "" {
+				t.Errorf("RangeUnitWarning() = %q, want none", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("RangeUnitWarning() = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
 }
@@ -2,6 +2,7 @@ package rivian
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -110,9 +111,47 @@ func (c *HTTPClient) CreateSession(ctx context.Context) error {
 	return nil
 }
 
+// CreateSessionWithRefresh creates a session like CreateSession, but when the
+// API rejects the current credentials it refreshes the access token and
+// retries once. refreshed reports whether a token refresh happened. Other
+// errors (e.g. network failures) are returned as-is without retrying.
+func (c *HTTPClient) CreateSessionWithRefresh(ctx context.Context) (refreshed bool, err error) {
+	err = c.CreateSession(ctx)
+	if err == nil || !errors.Is(err, ErrUnauthorized) {
+		return false, err
+	}
+
+	if refreshErr := c.RefreshToken(ctx); refreshErr != nil {
+		return false, fmt.Errorf("%w (token refresh failed: %v)", err, refreshErr)
+	}
+
+	if err := c.CreateSession(ctx); err != nil {
+		return true, fmt.Errorf("after token refresh: %w", err)
+	}
+
+	return true, nil
+}
+
+// ResetAuthState clears any half-finished login: the pending email, OTP
+// token and CSRF session. Call it before restarting the login flow (e.g.
+// with a corrected email) so nothing from the abandoned attempt is reused.
+// Credentials are left untouched.
+func (c *HTTPClient) ResetAuthState() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.email = ""
+	c.otpToken = ""
+	c.csrfToken = ""
+	c.appSessionID = ""
+}
+
 // Authenticate performs login with email and password.
 // Returns OTPRequiredError if MFA is enabled.
 func (c *HTTPClient) Authenticate(ctx context.Context, email, password string) error {
+	// A new login supersedes any pending OTP challenge
+	c.ResetAuthState()
+
 	// Step 1: Get CSRF token and app session
 	if err := c.CreateSession(ctx); err != nil {
 		return err
@@ -161,7 +200,9 @@ func (c *HTTPClient) Authenticate(ctx context.Context, email, password string) e
 	return nil
 }
 
-// SubmitOTP submits a one-time password for MFA authentication.
+// SubmitOTP submits a one-time password for MFA authentication. Errors
+// tell the caller what to do next: an *OTPNetworkError means the same code
+// can be submitted again, ErrOTPRejected means a new code is needed.
 func (c *HTTPClient) SubmitOTP(ctx context.Context, code string) error {
 	c.mu.RLock()
 	otpToken := c.otpToken
@@ -184,6 +225,16 @@ func (c *HTTPClient) SubmitOTP(ctx context.Context, code string) error {
 
 	var resp loginWithOTPResponse
 	if err := c.doGraphQL(ctx, loginWithOTPMutation, variables, &resp); err != nil {
+		var transportErr *transportError
+		var apiErr *APIError
+		switch {
+		case ctx.Err() != nil:
+			return fmt.Errorf("submit OTP: %w", err)
+		case errors.As(err, &transportErr):
+			return &OTPNetworkError{Err: err}
+		case errors.As(err, &apiErr), errors.Is(err, ErrUnauthorized):
+			return fmt.Errorf("submit OTP: %w: %w", ErrOTPRejected, err)
+		}
 		return fmt.Errorf("submit OTP: %w", err)
 	}
 
@@ -228,7 +279,13 @@ func (c *HTTPClient) RefreshToken(ctx context.Context) error {
 		ExpiresAt:    time.Now().Add(24 * time.Hour),
 		UserID:       c.credentials.UserID, // Preserve user ID
 	}
+	refreshed := *c.credentials
+	onRefresh := c.onRefresh
 	c.mu.Unlock()
 
+	if onRefresh != nil {
+		onRefresh(&refreshed)
+	}
+
 	return nil
 }
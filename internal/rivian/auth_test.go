@@ -1,8 +1,10 @@
 package rivian
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -186,6 +188,88 @@ func TestAuthenticate_OTPRequired(t *testing.T) {
 	client.mu.RUnlock()
 }
 
+func TestAuthenticate_NewEmailDropsStaleOTP(t *testing.T) {
+	otpSubmitted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		var response map[string]interface{}
+		switch {
+		case strings.Contains(req.Query, "CreateCSRFToken"):
+			response = map[string]interface{}{
+				"data": map[string]interface{}{
+					"createCsrfToken": map[string]interface{}{
+						"csrfToken":       "test-csrf-token",
+						"appSessionToken": "test-app-session",
+					},
+				},
+			}
+		case strings.Contains(req.Query, "LoginWithOTP"):
+			otpSubmitted = true
+			response = map[string]interface{}{"errors": []map[string]interface{}{{"message": "invalid otp"}}}
+		case req.Variables["email"] == "typo@example.com":
+			// First attempt triggers an OTP challenge
+			response = map[string]interface{}{
+				"data": map[string]interface{}{
+					"login": map[string]interface{}{
+						"__typename": "MobileMFALoginResponse",
+						"otpToken":   "stale-otp-token",
+					},
+				},
+			}
+		default:
+			// Corrected email fails to log in
+			response = map[string]interface{}{"errors": []map[string]interface{}{{"message": "bad credentials"}}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatalf("Failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(WithBaseURL(server.URL))
+	var otpErr *OTPRequiredError
+	if err := client.Authenticate(context.Background(), "typo@example.com", "password"); !errors.As(err, &otpErr) {
+		t.Fatalf("Expected OTPRequiredError, got %v", err)
+	}
+	if err := client.Authenticate(context.Background(), "user@example.com", "password"); err == nil {
+		t.Fatal("Expected second Authenticate to fail")
+	}
+
+	// The OTP challenge from the first email must not be reused
+	err := client.SubmitOTP(context.Background(), "123456")
+	if err == nil || !strings.Contains(err.Error(), "no OTP session active") {
+		t.Errorf("SubmitOTP error = %v, want no OTP session active", err)
+	}
+	if otpSubmitted {
+		t.Error("SubmitOTP should not send the stale OTP token")
+	}
+}
+
+func TestResetAuthState(t *testing.T) {
+	creds := &Credentials{AccessToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	client := NewHTTPClient(WithCredentials(creds))
+	client.email = "user@example.com"
+	client.otpToken = "otp-token"
+	client.csrfToken = "csrf"
+	client.appSessionID = "session"
+
+	client.ResetAuthState()
+
+	if client.email != "" || client.otpToken != "" || client.csrfToken != "" || client.appSessionID != "" {
+		t.Errorf("ResetAuthState left pending state: email=%q otp=%q csrf=%q session=%q",
+			client.email, client.otpToken, client.csrfToken, client.appSessionID)
+	}
+	if !client.IsAuthenticated() {
+		t.Error("ResetAuthState should keep existing credentials")
+	}
+}
+
 func TestSubmitOTP_Success(t *testing.T) {
 	callCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -308,6 +392,128 @@ func TestSubmitOTP_NoSession(t *testing.T) {
 	}
 }
 
+// newOTPServer starts a server whose Login asks for an OTP and whose
+// LoginWithOTP is answered by submit, with the 1-based submission count.
+func newOTPServer(t *testing.T, submit func(w http.ResponseWriter, r *http.Request, call int)) *httptest.Server {
+	t.Helper()
+	submissions := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		var data map[string]interface{}
+		switch operationName(req.Query) {
+		case "CreateCSRFToken":
+			data = map[string]interface{}{"createCsrfToken": map[string]interface{}{"csrfToken": "csrf", "appSessionToken": "session"}}
+		case "Login":
+			data = map[string]interface{}{"login": map[string]interface{}{"__typename": "MobileMFALoginResponse", "otpToken": "otp-token-123"}}
+		case "LoginWithOTP":
+			submissions++
+			submit(w, r, submissions)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dropConnection closes the connection without answering, like a network
+// failure mid-request.
+func dropConnection(t *testing.T, w http.ResponseWriter) {
+	t.Helper()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatalf("Hijack failed: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func writeOTPLogin(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"loginWithOTP": map[string]interface{}{"userSessionToken": "user-session", "refreshToken": "refresh"},
+		},
+	})
+}
+
+func TestSubmitOTP_NetworkErrorKeepsChallenge(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fail func(t *testing.T, w http.ResponseWriter)
+	}{
+		{"connection dropped", dropConnection},
+		{"gateway error", func(t *testing.T, w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOTPServer(t, func(w http.ResponseWriter, r *http.Request, call int) {
+				if call == 1 {
+					tt.fail(t, w)
+					return
+				}
+				writeOTPLogin(w)
+			})
+			client := NewHTTPClient(WithBaseURL(server.URL))
+			_ = client.Authenticate(context.Background(), "test@example.com", "password")
+
+			err := client.SubmitOTP(context.Background(), "123456")
+			var netErr *OTPNetworkError
+			if !errors.As(err, &netErr) {
+				t.Fatalf("SubmitOTP error = %v, want *OTPNetworkError", err)
+			}
+			if errors.Is(err, ErrOTPRejected) {
+				t.Error("a network error should not count as a rejected code")
+			}
+
+			// The challenge is kept, so the same code can be submitted again
+			if err := client.SubmitOTP(context.Background(), "123456"); err != nil {
+				t.Fatalf("retrying SubmitOTP failed: %v", err)
+			}
+			if !client.IsAuthenticated() {
+				t.Error("client should be authenticated after the retry")
+			}
+		})
+	}
+}
+
+func TestSubmitOTP_Rejected(t *testing.T) {
+	server := newOTPServer(t, func(w http.ResponseWriter, r *http.Request, call int) {
+		w.Header().Set("Content-Type", "application/json")
+		if call == 1 {
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"Invalid OTP code"}]}`))
+			return
+		}
+		writeOTPLogin(w)
+	})
+	client := NewHTTPClient(WithBaseURL(server.URL))
+	_ = client.Authenticate(context.Background(), "test@example.com", "password")
+
+	err := client.SubmitOTP(context.Background(), "000000")
+	if !errors.Is(err, ErrOTPRejected) {
+		t.Fatalf("SubmitOTP error = %v, want ErrOTPRejected", err)
+	}
+	var netErr *OTPNetworkError
+	if errors.As(err, &netErr) {
+		t.Error("a rejected code should not be reported as a network error")
+	}
+	if !strings.Contains(err.Error(), "Invalid OTP code") {
+		t.Errorf("error should include the API message, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("client should not be authenticated after a rejected code")
+	}
+
+	// A corrected code is submitted against the same challenge
+	if err := client.SubmitOTP(context.Background(), "123456"); err != nil {
+		t.Fatalf("SubmitOTP with a new code failed: %v", err)
+	}
+}
+
 func TestRefreshToken_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req graphqlRequest
@@ -347,11 +553,16 @@ func TestRefreshToken_Success(t *testing.T) {
 			UserID:       "test-user-id",
 		}),
 	)
+	var saved *Credentials
+	client.SetRefreshHandler(func(creds *Credentials) { saved = creds })
 
 	err := client.RefreshToken(context.Background())
 	if err != nil {
 		t.Fatalf("RefreshToken failed: %v", err)
 	}
+	if saved == nil || saved.AccessToken != "new-access-token" || saved.RefreshToken != "new-refresh-token" {
+		t.Errorf("refresh handler got %+v, want the new tokens", saved)
+	}
 
 	creds := client.GetCredentials()
 	if creds.AccessToken != "new-access-token" {
@@ -366,6 +577,129 @@ func TestRefreshToken_Success(t *testing.T) {
 	}
 }
 
+func TestCreateSessionWithRefresh(t *testing.T) {
+	tests := []struct {
+		name          string
+		csrfStatus    []int // status per CreateCSRFToken call; 0 means UNAUTHENTICATED graphql error
+		refreshStatus int
+		wantRefreshed bool
+		wantErr       bool
+		wantAuthErr   bool
+		wantCSRFCalls int
+	}{
+		{
+			name:          "success without refresh",
+			csrfStatus:    []int{http.StatusOK},
+			wantCSRFCalls: 1,
+		},
+		{
+			name:          "expired token refreshed and retried",
+			csrfStatus:    []int{0, http.StatusOK},
+			refreshStatus: http.StatusOK,
+			wantRefreshed: true,
+			wantCSRFCalls: 2,
+		},
+		{
+			name:          "refresh rejected",
+			csrfStatus:    []int{http.StatusUnauthorized},
+			refreshStatus: http.StatusUnauthorized,
+			wantErr:       true,
+			wantAuthErr:   true,
+			wantCSRFCalls: 1,
+		},
+		{
+			name:          "server error is not retried",
+			csrfStatus:    []int{http.StatusBadGateway},
+			wantErr:       true,
+			wantCSRFCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csrfCalls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req graphqlRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+
+				var response map[string]interface{}
+				switch {
+				case strings.Contains(req.Query, "CreateCSRFToken"):
+					status := tt.csrfStatus[csrfCalls]
+					csrfCalls++
+					if status == 0 {
+						response = map[string]interface{}{
+							"data": nil,
+							"errors": []map[string]interface{}{
+								{"message": "session expired", "extensions": map[string]interface{}{"code": "UNAUTHENTICATED"}},
+							},
+						}
+						break
+					}
+					if status != http.StatusOK {
+						w.WriteHeader(status)
+						return
+					}
+					response = map[string]interface{}{
+						"data": map[string]interface{}{
+							"createCsrfToken": map[string]interface{}{
+								"csrfToken":       "csrf",
+								"appSessionToken": "app-session",
+							},
+						},
+					}
+				case strings.Contains(req.Query, "RefreshAccessToken"):
+					if tt.refreshStatus != http.StatusOK {
+						w.WriteHeader(tt.refreshStatus)
+						return
+					}
+					response = map[string]interface{}{
+						"data": map[string]interface{}{
+							"refreshAccessToken": map[string]interface{}{
+								"accessToken":  "new-access-token",
+								"refreshToken": "new-refresh-token",
+							},
+						},
+					}
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(response); err != nil {
+					t.Fatalf("Failed to encode response: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(
+				WithBaseURL(server.URL),
+				WithCredentials(&Credentials{
+					AccessToken:  "old-access-token",
+					RefreshToken: "old-refresh-token",
+				}),
+			)
+
+			refreshed, err := client.CreateSessionWithRefresh(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateSessionWithRefresh() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrUnauthorized); got != tt.wantAuthErr {
+				t.Errorf("errors.Is(err, ErrUnauthorized) = %v, want %v (err: %v)", got, tt.wantAuthErr, err)
+			}
+			if refreshed != tt.wantRefreshed {
+				t.Errorf("refreshed = %v, want %v", refreshed, tt.wantRefreshed)
+			}
+			if csrfCalls != tt.wantCSRFCalls {
+				t.Errorf("CreateCSRFToken calls = %d, want %d", csrfCalls, tt.wantCSRFCalls)
+			}
+			if tt.wantRefreshed && client.GetCSRFToken() != "csrf" {
+				t.Errorf("GetCSRFToken() = %q, want %q", client.GetCSRFToken(), "csrf")
+			}
+		})
+	}
+}
+
 func TestIsAuthenticated(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -416,3 +750,36 @@ func TestIsAuthenticated(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticate_DebugLoggingRedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "CreateCSRFToken") {
+			_, _ = w.Write([]byte(`{"data":{"createCsrfToken":{"csrfToken":"secret-csrf","appSessionToken":"secret-app"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"login":{"__typename":"MobileLoginResponse","accessToken":"secret-access","refreshToken":"secret-refresh","userSessionToken":"secret-session"}}}`))
+	}))
+	defer server.Close()
+
+	var logBuf bytes.Buffer
+	client := NewHTTPClient(WithBaseURL(server.URL), WithDebugLogging(&logBuf))
+	if err := client.Authenticate(context.Background(), "test@example.com", "hunter2"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	logs := logBuf.String()
+	for _, want := range []string{"op=CreateCSRFToken", "op=Login", "<-- 200", "latency="} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("debug log missing %q:\n%s", want, logs)
+		}
+	}
+	for _, secret := range []string{"hunter2", "secret-csrf", "secret-app", "secret-access", "secret-refresh", "secret-session"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("debug log leaked %q:\n%s", secret, logs)
+		}
+	}
+}
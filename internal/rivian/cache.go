@@ -0,0 +1,70 @@
+package rivian
+
+import (
+	"sync"
+	"time"
+)
+
+// stateCache is a short-lived, per-vehicle cache of GetVehicleState results
+// used to collapse bursts of identical calls (e.g. repeated TUI refreshes).
+type stateCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedState
+}
+
+type cachedState struct {
+	state     *VehicleState
+	fetchedAt time.Time
+}
+
+// WithStateCacheTTL caches GetVehicleState results per vehicle for ttl, so
+// calls within that window are served without hitting the API. A ttl of 0
+// (the default) disables the cache.
+func WithStateCacheTTL(ttl time.Duration) Option {
+	return func(c *HTTPClient) {
+		if ttl <= 0 {
+			c.stateCache = nil
+			return
+		}
+		c.stateCache = &stateCache{
+			ttl:     ttl,
+			now:     time.Now,
+			entries: make(map[string]cachedState),
+		}
+	}
+}
+
+// get returns a copy of the cached state for vehicleID if it hasn't expired.
+func (sc *stateCache) get(vehicleID string) (*VehicleState, bool) {
+	if sc == nil {
+		return nil, false
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry, ok := sc.entries[vehicleID]
+	if !ok || sc.now().Sub(entry.fetchedAt) >= sc.ttl {
+		return nil, false
+	}
+
+	// Callers may modify the result, so never hand out the cached pointer
+	state := *entry.state
+	return &state, true
+}
+
+// put stores a copy of state for vehicleID.
+func (sc *stateCache) put(vehicleID string, state *VehicleState) {
+	if sc == nil || state == nil {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	cached := *state
+	sc.entries[vehicleID] = cachedState{state: &cached, fetchedAt: sc.now()}
+}
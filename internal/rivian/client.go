@@ -2,6 +2,10 @@ package rivian
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,25 +26,57 @@ type Client interface {
 	GetVehicles(ctx context.Context) ([]Vehicle, error)
 
 	// GetVehicleState retrieves the current state of a specific vehicle.
+	// It reads the telemetry Rivian's cloud last received and never wakes
+	// the vehicle, so polling it does not keep the vehicle awake.
 	GetVehicleState(ctx context.Context, vehicleID string) (*VehicleState, error)
 
+	// GetVehicleSoftwareInfo retrieves the installed and available OTA
+	// software versions of a specific vehicle.
+	GetVehicleSoftwareInfo(ctx context.Context, vehicleID string) (*SoftwareInfo, error)
+
 	// IsAuthenticated returns true if the client has valid credentials.
 	IsAuthenticated() bool
 }
 
+// ModelUnknown labels vehicles whose model could not be determined.
+const ModelUnknown = "Unknown"
+
 // Vehicle represents a Rivian vehicle.
 type Vehicle struct {
-	ID    string
-	VIN   string
-	Name  string
-	Model string
-	Year  int
+	ID         string
+	VIN        string
+	Name       string
+	Model      string
+	Year       int    // Model year (0 if unknown)
+	Trim       string // e.g. "Adventure" ("" if unknown)
+	DriveTrain string // e.g. "Quad-Motor AWD" ("" if unknown)
+}
+
+// Description returns the year, model and trim, e.g. "2024 R1T Adventure",
+// omitting whatever is unknown.
+func (v Vehicle) Description() string {
+	var parts []string
+	if v.Year > 0 {
+		parts = append(parts, strconv.Itoa(v.Year))
+	}
+	if v.Model != "" {
+		parts = append(parts, v.Model)
+	}
+	if v.Trim != "" {
+		parts = append(parts, v.Trim)
+	}
+	return strings.Join(parts, " ")
 }
 
 // VehicleState represents the current state of a vehicle.
 type VehicleState struct {
-	VehicleID string
-	UpdatedAt time.Time
+	VehicleID  string
+	UpdatedAt  time.Time
+	ReportedAt time.Time // Newest timestamp on the battery, range, odometer and location readings (zero when none)
+
+	// TelemetryMissing is set when the response had neither a battery level
+	// nor a range, as for a vehicle in service or deep sleep
+	TelemetryMissing bool
 
 	// Battery and charging
 	BatteryLevel    float64 // Percentage (0-100)
@@ -73,6 +109,22 @@ type VehicleState struct {
 	// Location (if available)
 	Latitude  *float64
 	Longitude *float64
+
+	// Raw is the vehicleState object exactly as the API returned it
+	Raw json.RawMessage
+}
+
+// SoftwareInfo describes a vehicle's OTA software versions.
+type SoftwareInfo struct {
+	VehicleID        string
+	CurrentVersion   string // Installed version, e.g. "2024.03.1"
+	AvailableVersion string // Pending OTA update ("" when none is offered)
+}
+
+// UpdateAvailable reports whether an OTA update newer than the installed
+// version is offered.
+func (s *SoftwareInfo) UpdateAvailable() bool {
+	return s.AvailableVersion != "" && s.AvailableVersion != s.CurrentVersion
 }
 
 // ChargeState represents the vehicle's charging status.
@@ -106,12 +158,12 @@ const (
 
 // TirePressures represents tire pressure readings for all four tires.
 type TirePressures struct {
-	FrontLeft  float64 // PSI (0 if not available)
-	FrontRight float64 // PSI (0 if not available)
-	RearLeft   float64 // PSI (0 if not available)
-	RearRight  float64 // PSI (0 if not available)
+	FrontLeft  float64 // Bar (0 if not available)
+	FrontRight float64 // Bar (0 if not available)
+	RearLeft   float64 // Bar (0 if not available)
+	RearRight  float64 // Bar (0 if not available)
 
-	// Status from API (Rivian doesn't expose PSI values, only status)
+	// Status from API (always reported, unlike the pressures)
 	FrontLeftStatus  string
 	FrontRightStatus string
 	RearLeftStatus   string
@@ -136,3 +188,43 @@ type OTPRequiredError struct {
 func (e *OTPRequiredError) Error() string {
 	return "OTP/MFA code required for authentication"
 }
+
+// ErrUnauthorized is matched (via errors.Is) by errors caused by missing,
+// expired or rejected credentials, as opposed to network failures.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrOTPRejected is matched (via errors.Is) by SubmitOTP errors where the
+// API answered and refused the code, e.g. a mistyped or expired code. The
+// code was not accepted, so ask for it again.
+var ErrOTPRejected = errors.New("OTP code rejected")
+
+// OTPNetworkError is returned by SubmitOTP when the code may never have
+// reached the API, or its answer was lost. The OTP challenge is kept, so
+// submitting the same code again is safe while it is still valid; if it
+// was consumed after all, the retry fails with ErrOTPRejected.
+type OTPNetworkError struct {
+	Err error
+}
+
+func (e *OTPNetworkError) Error() string {
+	return "submit OTP: network error: " + e.Err.Error()
+}
+
+func (e *OTPNetworkError) Unwrap() error {
+	return e.Err
+}
+
+// APIError is returned when the GraphQL API responds with errors and no data.
+type APIError struct {
+	Messages        []string
+	Unauthenticated bool // at least one error had code UNAUTHENTICATED
+}
+
+func (e *APIError) Error() string {
+	return "graphql error: " + strings.Join(e.Messages, "; ")
+}
+
+// Is reports whether the error matches ErrUnauthorized.
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnauthorized && e.Unauthenticated
+}
@@ -0,0 +1,100 @@
+package rivian
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// maxDebugBodyLen caps how much of a response body is written to the debug log.
+const maxDebugBodyLen = 2048
+
+// sensitiveKeys lists JSON keys and GraphQL variables whose values must
+// never appear in debug output.
+var sensitiveKeys = map[string]bool{
+	"password":         true,
+	"otpCode":          true,
+	"otpToken":         true,
+	"accessToken":      true,
+	"refreshToken":     true,
+	"userSessionToken": true,
+	"appSessionToken":  true,
+	"csrfToken":        true,
+}
+
+var (
+	operationNameRe  = regexp.MustCompile(`(?:query|mutation|subscription)\s+(\w+)`)
+	sensitiveValueRe = regexp.MustCompile(`"(password|otpCode|otpToken|accessToken|refreshToken|userSessionToken|appSessionToken|csrfToken)"\s*:\s*"[^"]*"`)
+)
+
+// WithDebugLogging logs every GraphQL request and response to w with
+// secrets redacted. Pass os.Stderr to keep stdout output clean.
+func WithDebugLogging(w io.Writer) Option {
+	return func(c *HTTPClient) {
+		c.debugLog = w
+	}
+}
+
+// operationName extracts the operation name from a GraphQL document.
+func operationName(query string) string {
+	if m := operationNameRe.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return "anonymous"
+}
+
+// redactVariables returns a copy of variables with sensitive values masked.
+func redactVariables(variables map[string]interface{}) map[string]interface{} {
+	if variables == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(variables))
+	for k, v := range variables {
+		if sensitiveKeys[k] {
+			out[k] = redacted
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// redactBody masks sensitive values in a JSON body and truncates it.
+func redactBody(body []byte) string {
+	s := sensitiveValueRe.ReplaceAllString(string(body), `"$1":"`+redacted+`"`)
+	if len(s) > maxDebugBodyLen {
+		s = s[:maxDebugBodyLen] + "...(truncated)"
+	}
+	return strings.TrimSpace(s)
+}
+
+// logRequest writes a debug line for an outgoing GraphQL request.
+func (c *HTTPClient) logRequest(op string, variables map[string]interface{}, hasSession bool) {
+	if c.debugLog == nil {
+		return
+	}
+
+	vars, _ := json.Marshal(redactVariables(variables))
+	session := "none"
+	if hasSession {
+		session = redacted
+	}
+	_, _ = fmt.Fprintf(c.debugLog, "[debug] --> POST %s op=%s u-sess=%s variables=%s\n",
+		GraphQLEndpoint, op, session, vars)
+}
+
+// logResponse writes a debug line for a GraphQL response.
+func (c *HTTPClient) logResponse(op string, status int, latency time.Duration, body []byte) {
+	if c.debugLog == nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(c.debugLog, "[debug] <-- %d op=%s latency=%s body=%s\n",
+		status, op, latency.Round(time.Millisecond), redactBody(body))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -30,9 +31,16 @@ type HTTPClient struct {
 	baseURL    string
 	httpClient *http.Client
 	userAgent  string
+	debugLog   io.Writer         // nil disables debug logging
+	warnLog    io.Writer         // nil discards non-fatal warnings
+	stateCache *stateCache       // nil disables GetVehicleState caching
+	pins       map[string]bool   // Pinned SHA-256 certificate fingerprints (nil = no pinning)
+	queries    map[string]string // Operation name -> replacement query (nil = built-in queries)
+	retry      RetryPolicy       // How queries are retried after transient failures
 
 	mu             sync.RWMutex
 	credentials    *Credentials
+	onRefresh      func(*Credentials) // Called after a successful token refresh
 	csrfToken      string // CSRF token for requests
 	appSessionID   string // App session ID (a-sess header)
 	otpToken       string // OTP token for MFA flow
@@ -44,6 +52,7 @@ func NewHTTPClient(opts ...Option) *HTTPClient {
 	client := &HTTPClient{
 		baseURL:   BaseURL,
 		userAgent: UserAgent,
+		retry:     DefaultRetryPolicy,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -52,6 +61,7 @@ func NewHTTPClient(opts ...Option) *HTTPClient {
 	for _, opt := range opts {
 		opt(client)
 	}
+	client.applyPinning()
 
 	return client
 }
@@ -73,6 +83,14 @@ func WithHTTPClient(httpClient *http.Client) Option {
 	}
 }
 
+// WithWarningLog sets where non-fatal warnings (e.g. GraphQL partial
+// errors) are written.
+func WithWarningLog(w io.Writer) Option {
+	return func(c *HTTPClient) {
+		c.warnLog = w
+	}
+}
+
 // WithCredentials initializes the client with existing credentials.
 func WithCredentials(creds *Credentials) Option {
 	return func(c *HTTPClient) {
@@ -122,6 +140,14 @@ func (c *HTTPClient) SetCredentials(creds *Credentials) {
 	c.credentials = &credsCopy
 }
 
+// SetRefreshHandler registers fn to receive a copy of the credentials after
+// each successful token refresh, e.g. to persist them.
+func (c *HTTPClient) SetRefreshHandler(fn func(*Credentials)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRefresh = fn
+}
+
 // GetCSRFToken returns the current CSRF token.
 func (c *HTTPClient) GetCSRFToken() string {
 	c.mu.RLock()
@@ -150,12 +176,48 @@ type graphqlResponse struct {
 
 // graphqlError represents a GraphQL error.
 type graphqlError struct {
-	Message string `json:"message"`
-	Path    []string `json:"path,omitempty"`
+	Message    string        `json:"message"`
+	Path       []interface{} `json:"path,omitempty"` // field names and list indices
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
 }
 
-// doGraphQL executes a GraphQL query.
-func (c *HTTPClient) doGraphQL(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+// transportError marks a request that failed before the API answered it:
+// the connection failed, the response was cut off, or a server or gateway
+// error (5xx) came back instead of a GraphQL response.
+type transportError struct {
+	err    error
+	status int // HTTP status of a 5xx response, 0 when there was none
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+// unauthenticatedCode is the GraphQL error code for missing or expired credentials.
+const unauthenticatedCode = "UNAUTHENTICATED"
+
+// String formats the error with its path, e.g. "not found (at currentUser.vehicles.0)".
+func (e graphqlError) String() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return fmt.Sprintf("%s (at %s)", e.Message, strings.Join(parts, "."))
+}
+
+// hasData reports whether the response carries a non-null data payload.
+func (r *graphqlResponse) hasData() bool {
+	data := strings.TrimSpace(string(r.Data))
+	return data != "" && data != "null"
+}
+
+// doGraphQLOnce sends a GraphQL request once.
+func (c *HTTPClient) doGraphQLOnce(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
 	reqBody := graphqlRequest{
 		Query:     query,
 		Variables: variables,
@@ -183,29 +245,62 @@ func (c *HTTPClient) doGraphQL(ctx context.Context, query string, variables map[
 	if c.csrfToken != "" {
 		req.Header.Set("csrf-token", c.csrfToken)
 	}
-	if c.credentials != nil && c.credentials.AccessToken != "" {
+	hasSession := c.credentials != nil && c.credentials.AccessToken != ""
+	if hasSession {
 		req.Header.Set("u-sess", c.credentials.AccessToken)
 	}
 	c.mu.RUnlock()
 
+	op := operationName(query)
+	c.logRequest(op, variables, hasSession)
+	start := time.Now()
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("execute request: %w", err)
+		if c.debugLog != nil {
+			_, _ = fmt.Fprintf(c.debugLog, "[debug] <-- error op=%s latency=%s: %v\n", op, time.Since(start).Round(time.Millisecond), err)
+		}
+		return fmt.Errorf("execute request: %w", &transportError{err: err})
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", &transportError{err: err})
+	}
+	c.logResponse(op, resp.StatusCode, time.Since(start), respBody)
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("unexpected status %d: %s: %w", resp.StatusCode, string(respBody), ErrUnauthorized)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &transportError{err: fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody)), status: resp.StatusCode}
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	var gqlResp graphqlResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
 		return fmt.Errorf("decode response: %w", err)
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return fmt.Errorf("graphql error: %s", gqlResp.Errors[0].Message)
+		messages := make([]string, len(gqlResp.Errors))
+		unauthenticated := false
+		for i, e := range gqlResp.Errors {
+			messages[i] = e.String()
+			unauthenticated = unauthenticated || e.Extensions.Code == unauthenticatedCode
+		}
+
+		if !gqlResp.hasData() {
+			return &APIError{Messages: messages, Unauthenticated: unauthenticated}
+		}
+
+		// Partial success: keep the data, but don't hide the errors
+		if c.warnLog != nil {
+			_, _ = fmt.Fprintf(c.warnLog, "Warning: %s returned partial data: %s\n", op, strings.Join(messages, "; "))
+		}
 	}
 
 	if result != nil {
@@ -0,0 +1,171 @@
+package rivian
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// overridableQueries lists the built-in queries a user may replace, by
+// operation name, and the variables each replacement must declare because
+// the client always sends them.
+var overridableQueries = map[string][]string{
+	"GetVehicles":     nil,
+	"GetVehicleState": {"vehicleID"},
+}
+
+var definitionRe = regexp.MustCompile(`^(query|mutation|subscription|fragment)\b\s*(\w*)`)
+
+// WithQueryOverrides replaces built-in GraphQL queries with the given ones,
+// keyed by operation name (see LoadQueryOverrides). Operations without an
+// override keep the built-in query.
+func WithQueryOverrides(overrides map[string]string) Option {
+	return func(c *HTTPClient) {
+		c.queries = overrides
+	}
+}
+
+// query returns the override for builtin's operation, or builtin itself.
+func (c *HTTPClient) query(builtin string) string {
+	if q, ok := c.queries[operationName(builtin)]; ok {
+		return q
+	}
+	return builtin
+}
+
+// LoadQueryOverrides reads a GraphQL document of replacement queries, e.g.
+// a patched "query GetVehicleState($vehicleID: String!) { ... }", so users
+// can follow an API schema change before a release. Only GetVehicles and
+// GetVehicleState can be replaced. The document is checked for balanced
+// brackets, terminated strings and named queries, not against the schema.
+func LoadQueryOverrides(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read query overrides: %w", err)
+	}
+	overrides, err := parseQueryOverrides(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// parseQueryOverrides splits doc into its top-level operations and
+// validates each as an override.
+func parseQueryOverrides(doc string) (map[string]string, error) {
+	ops, err := splitDefinitions(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no queries found")
+	}
+
+	overrides := make(map[string]string, len(ops))
+	for _, op := range ops {
+		m := definitionRe.FindStringSubmatch(op)
+		if m[1] != "query" || m[2] == "" {
+			return nil, fmt.Errorf("only named queries can be overridden, found %s %s", m[1], m[2])
+		}
+		name := m[2]
+		required, ok := overridableQueries[name]
+		if !ok {
+			return nil, fmt.Errorf("query %s cannot be overridden (supported: %s)", name, strings.Join(overridableNames(), ", "))
+		}
+		if _, dup := overrides[name]; dup {
+			return nil, fmt.Errorf("query %s is defined more than once", name)
+		}
+		for _, v := range required {
+			if !regexp.MustCompile(`\$` + v + `\b`).MatchString(op) {
+				return nil, fmt.Errorf("query %s must declare $%s", name, v)
+			}
+		}
+		overrides[name] = op
+	}
+	return overrides, nil
+}
+
+// splitDefinitions returns the top-level definitions of a GraphQL document,
+// skipping comments and strings, and reports unbalanced brackets and
+// unterminated strings by line.
+func splitDefinitions(doc string) ([]string, error) {
+	closing := map[byte]byte{'{': '}', '(': ')', '[': ']'}
+	var defs []string
+	var stack []byte
+	start := -1
+
+	for i := 0; i < len(doc); i++ {
+		ch := doc[i]
+		switch {
+		case ch == '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(doc[i:], `"""`):
+			end := strings.Index(doc[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated block string", lineOf(doc, i))
+			}
+			i += end + 5
+		case ch == '"':
+			j := i + 1
+			for ; j < len(doc) && doc[j] != '"' && doc[j] != '\n'; j++ {
+				if doc[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(doc) || doc[j] != '"' {
+				return nil, fmt.Errorf("line %d: unterminated string", lineOf(doc, i))
+			}
+			i = j
+		case ch == '{' || ch == '(' || ch == '[':
+			if start < 0 {
+				return nil, fmt.Errorf("line %d: %q outside a named definition", lineOf(doc, i), ch)
+			}
+			stack = append(stack, closing[ch])
+		case ch == '}' || ch == ')' || ch == ']':
+			if len(stack) == 0 || stack[len(stack)-1] != ch {
+				return nil, fmt.Errorf("line %d: unexpected %q", lineOf(doc, i), ch)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 && ch == '}' {
+				defs = append(defs, doc[start:i+1])
+				start = -1
+			}
+		case start < 0 && !strings.ContainsRune(" \t\r\n,", rune(ch)):
+			if !definitionRe.MatchString(doc[i:]) {
+				return nil, fmt.Errorf("line %d: expected query, mutation, subscription or fragment", lineOf(doc, i))
+			}
+			start = i
+		}
+	}
+
+	if len(stack) > 0 {
+		missing := make([]byte, len(stack))
+		for i, ch := range stack {
+			missing[len(stack)-1-i] = ch
+		}
+		return nil, fmt.Errorf("unexpected end of document (missing %q)", string(missing))
+	}
+	if start >= 0 {
+		return nil, fmt.Errorf("line %d: definition has no selection set", lineOf(doc, start))
+	}
+	return defs, nil
+}
+
+// lineOf returns the 1-based line number of offset i in doc.
+func lineOf(doc string, i int) int {
+	return strings.Count(doc[:i], "\n") + 1
+}
+
+// overridableNames returns the supported override names, sorted.
+func overridableNames() []string {
+	names := make([]string, 0, len(overridableQueries))
+	for name := range overridableQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,121 @@
+package rivian
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrCertificateMismatch means the server presented no certificate matching
+// the fingerprints pinned with WithPinnedCert.
+var ErrCertificateMismatch = errors.New("certificate does not match pinned fingerprints")
+
+// WithPinnedCert restricts connections to servers whose certificate chain
+// includes a certificate with one of the given SHA-256 fingerprints, as
+// printed by `openssl x509 -noout -fingerprint -sha256` (colons and case
+// are ignored). Normal certificate verification still applies. The same
+// pins cover the WebSocket connection via TLSConfig. Off by default.
+// Invalid fingerprints match no certificate, so connections fail rather
+// than go unpinned; check them first with ValidateFingerprint.
+func WithPinnedCert(fingerprints ...string) Option {
+	return func(c *HTTPClient) {
+		c.pins = nil
+		for _, fp := range fingerprints {
+			if strings.TrimSpace(fp) == "" {
+				continue
+			}
+			normalized, err := normalizeFingerprint(fp)
+			if err != nil {
+				normalized = fp
+			}
+			if c.pins == nil {
+				c.pins = make(map[string]bool)
+			}
+			c.pins[normalized] = true
+		}
+	}
+}
+
+// ValidateFingerprint reports whether fp is a SHA-256 fingerprint
+// WithPinnedCert accepts.
+func ValidateFingerprint(fp string) error {
+	_, err := normalizeFingerprint(fp)
+	return err
+}
+
+// TLSConfig returns the TLS configuration enforcing the pinned
+// certificates, or nil when pinning is off. Pass it to
+// WebSocketClient.SetTLSConfig so both transports share the same pins.
+func (c *HTTPClient) TLSConfig() *tls.Config {
+	if len(c.pins) == 0 {
+		return nil
+	}
+	return c.pinnedTLSConfig(nil)
+}
+
+// pinnedTLSConfig returns a copy of base that also checks the pins.
+func (c *HTTPClient) pinnedTLSConfig(base *tls.Config) *tls.Config {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	cfg.VerifyPeerCertificate = c.verifyPinned
+	return cfg
+}
+
+// verifyPinned runs after standard verification and accepts the
+// connection if any presented certificate matches a pin.
+func (c *HTTPClient) verifyPinned(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	for _, raw := range rawCerts {
+		sum := sha256.Sum256(raw)
+		if c.pins[hex.EncodeToString(sum[:])] {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (%d certificates presented)", ErrCertificateMismatch, len(rawCerts))
+}
+
+// applyPinning installs the pinned TLS config on a copy of the HTTP client's
+// transport, keeping any existing TLS settings (e.g. custom root CAs).
+func (c *HTTPClient) applyPinning() {
+	if len(c.pins) == 0 {
+		return
+	}
+
+	var transport *http.Transport
+	switch t := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		// Unknown RoundTripper: replace it rather than silently skip pinning
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.TLSClientConfig = c.pinnedTLSConfig(transport.TLSClientConfig)
+
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+}
+
+// normalizeFingerprint lowercases a hex fingerprint and strips separators
+// and any "sha256 Fingerprint=" prefix copied from openssl. What remains
+// must be the 64 hex digits of a SHA-256 sum.
+func normalizeFingerprint(fp string) (string, error) {
+	raw := fp
+	if _, after, ok := strings.Cut(fp, "="); ok {
+		fp = after
+	}
+	fp = strings.ToLower(strings.TrimSpace(fp))
+	fp = strings.NewReplacer(":", "", " ", "").Replace(fp)
+	if _, err := hex.DecodeString(fp); err != nil || len(fp) != 2*sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q: expected %d hex digits", raw, 2*sha256.Size)
+	}
+	return fp, nil
+}
@@ -0,0 +1,98 @@
+package rivian
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how queries are retried after transient failures:
+// network errors and 502/503/504 responses from the gateway. Mutations
+// (login, OTP, token refresh) are never retried, and neither are GraphQL
+// errors, including authentication failures.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts including the first; 1 or less disables retries
+	BaseDelay   time.Duration // Wait before the first retry; doubles on each one
+	MaxDelay    time.Duration // Cap on a single wait (0 = no cap)
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy says otherwise.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// WithRetryPolicy sets how queries are retried after transient failures.
+// A zero policy disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *HTTPClient) {
+		c.retry = policy
+	}
+}
+
+// delay returns the wait before the given retry (1-based): the base delay
+// doubled for each earlier retry, capped, with up to half of it taken off
+// at random so clients failing together don't retry together.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay << (retry - 1)
+	if d <= 0 || p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - time.Duration(rand.Int64N(int64(d)/2+1))
+}
+
+// retryable reports whether a failed request may succeed if sent again.
+// Certificate failures won't, even though no response came back.
+func retryable(err error) bool {
+	var transportErr *transportError
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(err, &transportErr) || errors.Is(err, ErrCertificateMismatch) || errors.As(err, &certErr) {
+		return false
+	}
+	switch transportErr.status {
+	case 0, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isQuery reports whether a GraphQL document is a query, which is safe to
+// send again, rather than a mutation.
+func isQuery(query string) bool {
+	return !strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// doGraphQL executes a GraphQL request, retrying queries according to the
+// client's retry policy.
+func (c *HTTPClient) doGraphQL(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	attempts := c.retry.MaxAttempts
+	if !isQuery(query) || attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.doGraphQLOnce(ctx, query, variables, result)
+		if err == nil || attempt >= attempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		wait := c.retry.delay(attempt)
+		if c.warnLog != nil {
+			_, _ = fmt.Fprintf(c.warnLog, "Warning: %s failed (%v), retrying in %s (%d/%d)\n", operationName(query), err, wait.Round(time.Millisecond), attempt, attempts-1)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
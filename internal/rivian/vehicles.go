@@ -2,8 +2,12 @@ package rivian
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -20,6 +24,18 @@ const (
 					vehicle {
 						__typename
 						model
+						modelYear
+						mobileConfiguration {
+							__typename
+							trimOption {
+								__typename
+								optionName
+							}
+							driveSystemOption {
+								__typename
+								optionName
+							}
+						}
 					}
 				}
 			}
@@ -71,6 +87,11 @@ const (
 					timeStamp
 					value
 				}
+				cabinClimateExteriorTemperature {
+					__typename
+					timeStamp
+					value
+				}
 				doorFrontLeftLocked {
 					__typename
 					timeStamp
@@ -181,11 +202,49 @@ const (
 					timeStamp
 					value
 				}
+				tirePressureFrontLeft {
+					__typename
+					timeStamp
+					value
+				}
+				tirePressureFrontRight {
+					__typename
+					timeStamp
+					value
+				}
+				tirePressureRearLeft {
+					__typename
+					timeStamp
+					value
+				}
+				tirePressureRearRight {
+					__typename
+					timeStamp
+					value
+				}
 			}
 		}
 	`
 )
 
+const getVehicleSoftwareInfoQuery = `
+	query GetVehicleSoftwareInfo($vehicleID: String!) {
+		vehicleState(id: $vehicleID) {
+			__typename
+			otaCurrentVersion {
+				__typename
+				timeStamp
+				value
+			}
+			otaAvailableVersion {
+				__typename
+				timeStamp
+				value
+			}
+		}
+	}
+`
+
 // vehiclesResponse represents the response from GetVehicles query.
 type vehiclesResponse struct {
 	CurrentUser struct {
@@ -196,13 +255,23 @@ type vehiclesResponse struct {
 			VIN      string `json:"vin"`
 			Name     string `json:"name"`
 			Vehicle  struct {
-				Typename string `json:"__typename"`
-				Model    string `json:"model"`
+				Typename            string `json:"__typename"`
+				Model               string `json:"model"`
+				ModelYear           int    `json:"modelYear"`
+				MobileConfiguration struct {
+					TrimOption        vehicleOption `json:"trimOption"`
+					DriveSystemOption vehicleOption `json:"driveSystemOption"`
+				} `json:"mobileConfiguration"`
 			} `json:"vehicle"`
 		} `json:"vehicles"`
 	} `json:"currentUser"`
 }
 
+// vehicleOption is a configured option (trim, drive system, ...) of a vehicle.
+type vehicleOption struct {
+	OptionName string `json:"optionName"`
+}
+
 // timestampedValue represents a value with a timestamp from the Rivian API.
 type timestampedValue[T any] struct {
 	Typename  string `json:"__typename"`
@@ -229,6 +298,7 @@ type vehicleStateData struct {
 	TimeToEndOfCharge               *timestampedValue[int64]      `json:"timeToEndOfCharge"`
 	VehicleMileage                  *timestampedValue[float64]    `json:"vehicleMileage"`
 	CabinClimateInteriorTemperature *timestampedValue[float64]    `json:"cabinClimateInteriorTemperature"`
+	CabinClimateExteriorTemperature *timestampedValue[float64]    `json:"cabinClimateExteriorTemperature"`
 	DoorFrontLeftLocked             *timestampedValue[string]     `json:"doorFrontLeftLocked"`
 	DoorFrontLeftClosed             *timestampedValue[string]     `json:"doorFrontLeftClosed"`
 	DoorFrontRightLocked            *timestampedValue[string]     `json:"doorFrontRightLocked"`
@@ -251,54 +321,333 @@ type vehicleStateData struct {
 	TirePressureStatusFrontRight    *timestampedValue[string]     `json:"tirePressureStatusFrontRight"`
 	TirePressureStatusRearLeft      *timestampedValue[string]     `json:"tirePressureStatusRearLeft"`
 	TirePressureStatusRearRight     *timestampedValue[string]     `json:"tirePressureStatusRearRight"`
+	TirePressureFrontLeft           *timestampedValue[float64]    `json:"tirePressureFrontLeft"`
+	TirePressureFrontRight          *timestampedValue[float64]    `json:"tirePressureFrontRight"`
+	TirePressureRearLeft            *timestampedValue[float64]    `json:"tirePressureRearLeft"`
+	TirePressureRearRight           *timestampedValue[float64]    `json:"tirePressureRearRight"`
+}
+
+// softwareInfoResponse represents the response from GetVehicleSoftwareInfo query.
+type softwareInfoResponse struct {
+	VehicleState struct {
+		Typename            string                    `json:"__typename"`
+		OTACurrentVersion   *timestampedValue[string] `json:"otaCurrentVersion"`
+		OTAAvailableVersion *timestampedValue[string] `json:"otaAvailableVersion"`
+	} `json:"vehicleState"`
 }
 
 // vehicleStateResponse represents the response from GetVehicleState query.
+// The state is kept raw so it can be stored alongside the parsed state.
 type vehicleStateResponse struct {
-	VehicleState vehicleStateData `json:"vehicleState"`
+	VehicleState json.RawMessage `json:"vehicleState"`
 }
 
+// ErrVehiclesUnavailable is returned by GetVehicles when the account lists
+// vehicles but none of them could be read, typically a permissions problem.
+var ErrVehiclesUnavailable = errors.New("vehicles listed but not readable")
+
+// NoVehiclesHint explains an empty vehicle list: the login worked, but no
+// vehicles are shared with this account.
+const NoVehiclesHint = `Signed in, but no vehicles are registered to this account.
+Vehicles may be under a different Rivian account: if you are a shared driver, the owner
+must invite you from the Rivian app, or sign in with the account that shows the vehicle
+there. Newly delivered vehicles can take a while to appear.`
+
 // GetVehicles retrieves the list of vehicles for the authenticated user.
+// An account with no vehicles returns an empty list, not an error.
 func (c *HTTPClient) GetVehicles(ctx context.Context) ([]Vehicle, error) {
 	if !c.IsAuthenticated() {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
 	var resp vehiclesResponse
-	if err := c.doGraphQL(ctx, getVehiclesQuery, nil, &resp); err != nil {
+	if err := c.doGraphQL(ctx, c.query(getVehiclesQuery), nil, &resp); err != nil {
 		return nil, fmt.Errorf("get vehicles: %w", err)
 	}
 
-	vehicles := make([]Vehicle, len(resp.CurrentUser.Vehicles))
-	for i, v := range resp.CurrentUser.Vehicles {
-		vehicles[i] = Vehicle{
-			ID:    v.ID,
-			VIN:   v.VIN,
-			Name:  v.Name,
-			Model: v.Vehicle.Model,
-			Year:  0, // modelYear not available in this query
+	listed := len(resp.CurrentUser.Vehicles)
+	vehicles := make([]Vehicle, 0, listed)
+	for _, v := range resp.CurrentUser.Vehicles {
+		// Entries nulled out by a partial error have no ID
+		if v.ID == "" {
+			continue
 		}
+		vehicles = append(vehicles, Vehicle{
+			ID:         v.ID,
+			VIN:        v.VIN,
+			Name:       v.Name,
+			Model:      vehicleModel(v.Vehicle.Model, v.VIN),
+			Year:       v.Vehicle.ModelYear,
+			Trim:       v.Vehicle.MobileConfiguration.TrimOption.OptionName,
+			DriveTrain: v.Vehicle.MobileConfiguration.DriveSystemOption.OptionName,
+		})
+	}
+
+	if listed > 0 && len(vehicles) == 0 {
+		return nil, fmt.Errorf("get vehicles: %w: %d vehicle(s) could not be read; check the account's access in the Rivian app", ErrVehiclesUnavailable, listed)
 	}
 
 	return vehicles, nil
 }
 
+// vehicleModel returns the reported model, falling back to the one encoded
+// in the VIN and then to ModelUnknown, since the API sometimes omits it.
+func vehicleModel(reported, vin string) string {
+	if model := strings.TrimSpace(reported); model != "" {
+		return model
+	}
+	if model := modelFromVIN(vin); model != "" {
+		return model
+	}
+	return ModelUnknown
+}
+
+// modelFromVIN decodes the model from a Rivian VIN: the manufacturer code
+// (7FC for trucks, 7PD for SUVs) and the body type at position 4.
+func modelFromVIN(vin string) string {
+	vin = strings.ToUpper(vin)
+	if len(vin) != 17 {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(vin, "7FC") && vin[3] == 'T':
+		return "R1T"
+	case strings.HasPrefix(vin, "7PD") && vin[3] == 'S':
+		return "R1S"
+	}
+	return ""
+}
+
 // GetVehicleState retrieves the current state of a specific vehicle.
+// vehicleState is a read of cloud-cached telemetry, not a vehicle command:
+// a sleeping vehicle is not woken and its values are as of its last report
+// (see the per-field timestamps). Waking requires a signed vehicle command,
+// which this client does not send.
 func (c *HTTPClient) GetVehicleState(ctx context.Context, vehicleID string) (*VehicleState, error) {
 	if !c.IsAuthenticated() {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
+	if state, ok := c.stateCache.get(vehicleID); ok {
+		return state, nil
+	}
+
 	variables := map[string]interface{}{
 		"vehicleID": vehicleID,
 	}
 
 	var resp vehicleStateResponse
-	if err := c.doGraphQL(ctx, getVehicleStateQuery, variables, &resp); err != nil {
+	if err := c.doGraphQL(ctx, c.query(getVehicleStateQuery), variables, &resp); err != nil {
 		return nil, fmt.Errorf("get vehicle state: %w", err)
 	}
 
-	return parseVehicleState(vehicleID, resp.VehicleState), nil
+	var data vehicleStateData
+	if err := json.Unmarshal(resp.VehicleState, &data); err != nil {
+		return nil, fmt.Errorf("get vehicle state: decode state: %w", err)
+	}
+
+	state := parseVehicleState(vehicleID, data)
+	state.Raw = resp.VehicleState
+	c.stateCache.put(vehicleID, state)
+	return state, nil
+}
+
+// StateField selects a group of vehicleState fields for
+// GetVehicleStateFields.
+type StateField string
+
+const (
+	StateFieldLocation StateField = "location"
+	StateFieldBattery  StateField = "battery"
+	StateFieldRange    StateField = "range"
+	StateFieldCharging StateField = "charging" // Charger state, charge limit and time to end of charge
+	StateFieldOdometer StateField = "odometer"
+	StateFieldClimate  StateField = "climate" // Cabin and exterior temperature
+	StateFieldDoors    StateField = "doors"   // Door locks and closed states; also sets IsLocked
+	StateFieldWindows  StateField = "windows"
+	StateFieldClosures StateField = "closures" // Frunk, liftgate and tonneau cover
+	StateFieldTires    StateField = "tires"    // Tire pressures and statuses
+)
+
+// stateFields lists the vehicleState fields each StateField queries, in the
+// order getVehicleStateQuery asks for them.
+var stateFields = []struct {
+	field  StateField
+	fields []string
+}{
+	{StateFieldLocation, []string{"gnssLocation"}},
+	{StateFieldBattery, []string{"batteryLevel"}},
+	{StateFieldRange, []string{"distanceToEmpty"}},
+	{StateFieldCharging, []string{"chargerState", "batteryLimit", "timeToEndOfCharge"}},
+	{StateFieldOdometer, []string{"vehicleMileage"}},
+	{StateFieldClimate, []string{"cabinClimateInteriorTemperature", "cabinClimateExteriorTemperature"}},
+	{StateFieldDoors, []string{
+		"doorFrontLeftLocked", "doorFrontLeftClosed", "doorFrontRightLocked", "doorFrontRightClosed",
+		"doorRearLeftLocked", "doorRearLeftClosed", "doorRearRightLocked", "doorRearRightClosed",
+	}},
+	{StateFieldWindows, []string{"windowFrontLeftClosed", "windowFrontRightClosed", "windowRearLeftClosed", "windowRearRightClosed"}},
+	{StateFieldClosures, []string{
+		"closureFrunkLocked", "closureFrunkClosed", "closureLiftgateLocked", "closureLiftgateClosed",
+		"closureTonneauLocked", "closureTonneauClosed",
+	}},
+	{StateFieldTires, []string{
+		"tirePressureStatusFrontLeft", "tirePressureStatusFrontRight", "tirePressureStatusRearLeft", "tirePressureStatusRearRight",
+		"tirePressureFrontLeft", "tirePressureFrontRight", "tirePressureRearLeft", "tirePressureRearRight",
+	}},
+}
+
+// vehicleStateFieldsQuery builds a GetVehicleState query that selects only
+// the requested fields.
+func vehicleStateFieldsQuery(fields []StateField) (string, error) {
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no state fields requested")
+	}
+	want := make(map[StateField]bool, len(fields))
+	for _, f := range fields {
+		known := false
+		for _, group := range stateFields {
+			known = known || group.field == f
+		}
+		if !known {
+			return "", fmt.Errorf("unknown state field %q", f)
+		}
+		want[f] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("query GetVehicleState($vehicleID: String!) {\n\tvehicleState(id: $vehicleID) {\n\t\t__typename\n")
+	for _, group := range stateFields {
+		if !want[group.field] {
+			continue
+		}
+		for _, name := range group.fields {
+			if name == "gnssLocation" {
+				fmt.Fprintf(&b, "\t\t%s {\n\t\t\t__typename\n\t\t\tlatitude\n\t\t\tlongitude\n\t\t\ttimeStamp\n\t\t}\n", name)
+				continue
+			}
+			fmt.Fprintf(&b, "\t\t%s {\n\t\t\t__typename\n\t\t\ttimeStamp\n\t\t\tvalue\n\t\t}\n", name)
+		}
+	}
+	b.WriteString("\t}\n}\n")
+	return b.String(), nil
+}
+
+// GetVehicleStateFields is GetVehicleState for a subset of the state, for
+// callers that poll often but only need a few values. Fields that weren't
+// requested keep their zero value (closures are unknown, IsLocked false
+// without StateFieldDoors). A cached full state is returned as is, but a
+// partial one is never cached. A GetVehicleState query override replaces
+// the generated query.
+func (c *HTTPClient) GetVehicleStateFields(ctx context.Context, vehicleID string, fields []StateField) (*VehicleState, error) {
+	if !c.IsAuthenticated() {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	query, err := vehicleStateFieldsQuery(fields)
+	if err != nil {
+		return nil, fmt.Errorf("get vehicle state: %w", err)
+	}
+
+	if state, ok := c.stateCache.get(vehicleID); ok {
+		return state, nil
+	}
+
+	variables := map[string]interface{}{
+		"vehicleID": vehicleID,
+	}
+
+	var resp vehicleStateResponse
+	if err := c.doGraphQL(ctx, c.query(query), variables, &resp); err != nil {
+		return nil, fmt.Errorf("get vehicle state: %w", err)
+	}
+
+	var data vehicleStateData
+	if err := json.Unmarshal(resp.VehicleState, &data); err != nil {
+		return nil, fmt.Errorf("get vehicle state: decode state: %w", err)
+	}
+
+	state := parseVehicleState(vehicleID, data)
+	state.Raw = resp.VehicleState
+	if !slices.Contains(fields, StateFieldBattery) && !slices.Contains(fields, StateFieldRange) {
+		// Missing telemetry can't be told apart from not asking for it
+		state.TelemetryMissing = false
+	}
+	return state, nil
+}
+
+// MergeStateFields returns a copy of base with the requested fields taken
+// from partial, as returned by GetVehicleStateFields. Raw is dropped since
+// the merged state matches neither response.
+func MergeStateFields(base, partial *VehicleState, fields []StateField) *VehicleState {
+	merged := *base
+	merged.UpdatedAt = partial.UpdatedAt
+	merged.IsOnline = partial.IsOnline
+	if partial.ReportedAt.After(merged.ReportedAt) {
+		merged.ReportedAt = partial.ReportedAt
+	}
+	merged.Raw = nil
+
+	for _, field := range fields {
+		switch field {
+		case StateFieldLocation:
+			merged.Latitude, merged.Longitude = partial.Latitude, partial.Longitude
+		case StateFieldBattery:
+			merged.BatteryLevel = partial.BatteryLevel
+		case StateFieldRange:
+			merged.RangeEstimate = partial.RangeEstimate
+		case StateFieldCharging:
+			merged.ChargeState = partial.ChargeState
+			merged.ChargeLimit = partial.ChargeLimit
+			merged.ChargingRate = partial.ChargingRate
+			merged.ChargingTimeLeft = partial.ChargingTimeLeft
+		case StateFieldOdometer:
+			merged.Odometer = partial.Odometer
+		case StateFieldClimate:
+			merged.CabinTemp, merged.ExteriorTemp = partial.CabinTemp, partial.ExteriorTemp
+		case StateFieldDoors:
+			merged.Doors = partial.Doors
+			merged.IsLocked = partial.IsLocked
+		case StateFieldWindows:
+			merged.Windows = partial.Windows
+		case StateFieldClosures:
+			merged.Frunk = partial.Frunk
+			merged.Liftgate = partial.Liftgate
+			merged.TonneauCover = partial.TonneauCover
+		case StateFieldTires:
+			merged.TirePressures = partial.TirePressures
+		}
+	}
+	if slices.Contains(fields, StateFieldBattery) || slices.Contains(fields, StateFieldRange) {
+		merged.TelemetryMissing = partial.TelemetryMissing
+	}
+	return &merged
+}
+
+// GetVehicleSoftwareInfo retrieves the installed and available OTA software
+// versions of a specific vehicle.
+func (c *HTTPClient) GetVehicleSoftwareInfo(ctx context.Context, vehicleID string) (*SoftwareInfo, error) {
+	if !c.IsAuthenticated() {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	variables := map[string]interface{}{
+		"vehicleID": vehicleID,
+	}
+
+	var resp softwareInfoResponse
+	if err := c.doGraphQL(ctx, getVehicleSoftwareInfoQuery, variables, &resp); err != nil {
+		return nil, fmt.Errorf("get vehicle software info: %w", err)
+	}
+
+	info := &SoftwareInfo{VehicleID: vehicleID}
+	if v := resp.VehicleState.OTACurrentVersion; v != nil {
+		info.CurrentVersion = v.Value
+	}
+	if v := resp.VehicleState.OTAAvailableVersion; v != nil {
+		info.AvailableVersion = v.Value
+	}
+
+	return info, nil
 }
 
 // parseVehicleState converts the API response to our domain model.
@@ -335,6 +684,10 @@ func parseVehicleState(vehicleID string, apiState vehicleStateData) *VehicleStat
 		temp := apiState.CabinClimateInteriorTemperature.Value
 		state.CabinTemp = &temp
 	}
+	if apiState.CabinClimateExteriorTemperature != nil {
+		temp := apiState.CabinClimateExteriorTemperature.Value
+		state.ExteriorTemp = &temp
+	}
 
 	// Determine if locked (all doors locked)
 	state.IsLocked = areAllDoorsLocked(apiState)
@@ -362,13 +715,26 @@ func parseVehicleState(vehicleID string, apiState vehicleStateData) *VehicleStat
 	state.Frunk = parseClosureStatusFromTimestamped(apiState.ClosureFrunkClosed)
 	state.Liftgate = parseClosureStatusFromTimestamped(apiState.ClosureLiftgateClosed)
 
-	// Tonneau cover
-	if apiState.ClosureTonneauClosed != nil {
+	// Tonneau cover: only trucks report a value; other models send null or ""
+	if apiState.ClosureTonneauClosed != nil && apiState.ClosureTonneauClosed.Value != "" {
 		cs := parseClosureStatusFromTimestamped(apiState.ClosureTonneauClosed)
 		state.TonneauCover = &cs
 	}
 
-	// Tire pressures - API only provides status ("OK"/"low"), not actual PSI values
+	// Tire pressures: status ("OK"/"low") and, where the vehicle reports
+	// them, pressures in bar
+	if apiState.TirePressureFrontLeft != nil {
+		state.TirePressures.FrontLeft = apiState.TirePressureFrontLeft.Value
+	}
+	if apiState.TirePressureFrontRight != nil {
+		state.TirePressures.FrontRight = apiState.TirePressureFrontRight.Value
+	}
+	if apiState.TirePressureRearLeft != nil {
+		state.TirePressures.RearLeft = apiState.TirePressureRearLeft.Value
+	}
+	if apiState.TirePressureRearRight != nil {
+		state.TirePressures.RearRight = apiState.TirePressureRearRight.Value
+	}
 	if apiState.TirePressureStatusFrontLeft != nil {
 		state.TirePressures.FrontLeftStatus = apiState.TirePressureStatusFrontLeft.Value
 	}
@@ -390,9 +756,39 @@ func parseVehicleState(vehicleID string, apiState vehicleStateData) *VehicleStat
 		state.Longitude = &lon
 	}
 
+	// When the vehicle last reported, as opposed to when we asked
+	var stamps []string
+	if apiState.BatteryLevel != nil {
+		stamps = append(stamps, apiState.BatteryLevel.TimeStamp)
+	}
+	if apiState.DistanceToEmpty != nil {
+		stamps = append(stamps, apiState.DistanceToEmpty.TimeStamp)
+	}
+	if apiState.VehicleMileage != nil {
+		stamps = append(stamps, apiState.VehicleMileage.TimeStamp)
+	}
+	if apiState.GNSSLocation != nil {
+		stamps = append(stamps, apiState.GNSSLocation.TimeStamp)
+	}
+	state.ReportedAt = latestTimestamp(stamps)
+	state.TelemetryMissing = apiState.BatteryLevel == nil && apiState.DistanceToEmpty == nil
+
 	return state
 }
 
+// latestTimestamp returns the newest of the API's ISO 8601 timestamps,
+// skipping any that don't parse; zero when none do.
+func latestTimestamp(stamps []string) time.Time {
+	var latest time.Time
+	for _, s := range stamps {
+		t, err := time.Parse(time.RFC3339, s)
+		if err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
 // areAllDoorsLocked checks if all four doors are locked
 func areAllDoorsLocked(apiState vehicleStateData) bool {
 	isDoorLocked := func(door *timestampedValue[string]) bool {
@@ -1,10 +1,16 @@
 package rivian
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -40,6 +46,12 @@ func TestGetVehicles_Success(t *testing.T) {
 							"vehicle": map[string]interface{}{
 								"__typename": "Vehicle",
 								"model":      "R1T",
+								"modelYear":  2024,
+								"mobileConfiguration": map[string]interface{}{
+									"__typename":        "MobileConfiguration",
+									"trimOption":        map[string]interface{}{"optionName": "Adventure"},
+									"driveSystemOption": map[string]interface{}{"optionName": "Quad-Motor AWD"},
+								},
 							},
 						},
 						{
@@ -94,6 +106,17 @@ func TestGetVehicles_Success(t *testing.T) {
 	if vehicles[0].Model != "R1T" {
 		t.Errorf("Expected model R1T, got %s", vehicles[0].Model)
 	}
+	if vehicles[0].Year != 2024 || vehicles[0].Trim != "Adventure" || vehicles[0].DriveTrain != "Quad-Motor AWD" {
+		t.Errorf("Expected 2024 Adventure Quad-Motor AWD, got %d %q %q", vehicles[0].Year, vehicles[0].Trim, vehicles[0].DriveTrain)
+	}
+	if got := vehicles[0].Description(); got != "2024 R1T Adventure" {
+		t.Errorf("Description() = %q, want %q", got, "2024 R1T Adventure")
+	}
+
+	// Missing configuration leaves the fields empty
+	if vehicles[1].Year != 0 || vehicles[1].Trim != "" || vehicles[1].Description() != "R1S" {
+		t.Errorf("Expected bare R1S, got %+v", vehicles[1])
+	}
 }
 
 func TestGetVehicles_NotAuthenticated(t *testing.T) {
@@ -109,6 +132,117 @@ func TestGetVehicles_NotAuthenticated(t *testing.T) {
 	}
 }
 
+func TestGetVehicles_PartialErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": {"currentUser": {"vehicles": [
+				{"id": "vehicle-1", "vin": "VIN123", "name": "My R1T", "vehicle": {"model": "R1T"}},
+				null
+			]}},
+			"errors": [{"message": "vehicle unavailable", "path": ["currentUser", "vehicles", 1]}]
+		}`))
+	}))
+	defer server.Close()
+
+	var warnBuf bytes.Buffer
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithWarningLog(&warnBuf),
+		WithCredentials(&Credentials{
+			AccessToken: "test-token",
+			ExpiresAt:   time.Now().Add(1 * time.Hour),
+		}),
+	)
+
+	vehicles, err := client.GetVehicles(context.Background())
+	if err != nil {
+		t.Fatalf("GetVehicles failed on partial data: %v", err)
+	}
+	if len(vehicles) != 1 || vehicles[0].ID != "vehicle-1" {
+		t.Errorf("Expected only vehicle-1 from partial data, got %+v", vehicles)
+	}
+
+	warning := warnBuf.String()
+	if !strings.Contains(warning, "vehicle unavailable (at currentUser.vehicles.1)") {
+		t.Errorf("Expected partial error warning, got: %q", warning)
+	}
+}
+
+func TestGetVehicles_Empty(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{
+			name: "no vehicles on account",
+			body: `{"data": {"currentUser": {"vehicles": []}}}`,
+		},
+		{
+			name: "every vehicle unreadable",
+			body: `{
+				"data": {"currentUser": {"vehicles": [null]}},
+				"errors": [{"message": "forbidden", "path": ["currentUser", "vehicles", 0]}]
+			}`,
+			wantErr: ErrVehiclesUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(
+				WithBaseURL(server.URL),
+				WithWarningLog(io.Discard),
+				WithCredentials(&Credentials{
+					AccessToken: "test-token",
+					ExpiresAt:   time.Now().Add(1 * time.Hour),
+				}),
+			)
+
+			vehicles, err := client.GetVehicles(context.Background())
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("GetVehicles() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(vehicles) != 0 {
+				t.Errorf("Expected no vehicles, got %+v", vehicles)
+			}
+		})
+	}
+}
+
+func TestGetVehicles_ErrorsWithoutData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": null, "errors": [{"message": "first"}, {"message": "second"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithCredentials(&Credentials{
+			AccessToken: "test-token",
+			ExpiresAt:   time.Now().Add(1 * time.Hour),
+		}),
+	)
+
+	_, err := client.GetVehicles(context.Background())
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got: %v", err)
+	}
+	if len(apiErr.Messages) != 2 {
+		t.Errorf("Expected 2 error messages, got %v", apiErr.Messages)
+	}
+}
+
 func TestGetVehicleState_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req graphqlRequest
@@ -167,6 +301,11 @@ func TestGetVehicleState_Success(t *testing.T) {
 						"timeStamp":  timestamp,
 						"value":      72.0,
 					},
+					"cabinClimateExteriorTemperature": map[string]interface{}{
+						"__typename": "CabinClimateExteriorTemperature",
+						"timeStamp":  timestamp,
+						"value":      -4.5,
+					},
 					"doorFrontLeftLocked": map[string]interface{}{
 						"__typename": "DoorStatus",
 						"timeStamp":  timestamp,
@@ -217,6 +356,11 @@ func TestGetVehicleState_Success(t *testing.T) {
 						"timeStamp":  timestamp,
 						"value":      "normal",
 					},
+					"tirePressureFrontLeft": map[string]interface{}{
+						"__typename": "TirePressure",
+						"timeStamp":  timestamp,
+						"value":      2.9,
+					},
 				},
 			},
 		}
@@ -265,6 +409,9 @@ func TestGetVehicleState_Success(t *testing.T) {
 	if state.CabinTemp == nil || *state.CabinTemp != 72.0 {
 		t.Errorf("Expected cabin temp 72.0, got %v", state.CabinTemp)
 	}
+	if state.ExteriorTemp == nil || *state.ExteriorTemp != -4.5 {
+		t.Errorf("Expected exterior temp -4.5, got %v", state.ExteriorTemp)
+	}
 	if state.Doors.FrontLeft != ClosureStatusClosed {
 		t.Errorf("Expected front left door closed, got %s", state.Doors.FrontLeft)
 	}
@@ -277,6 +424,16 @@ func TestGetVehicleState_Success(t *testing.T) {
 	if state.Longitude == nil || *state.Longitude != -122.4194 {
 		t.Errorf("Expected longitude -122.4194, got %v", state.Longitude)
 	}
+	if state.TirePressures.FrontLeft != 2.9 || state.TirePressures.FrontLeftStatus != "normal" {
+		t.Errorf("Expected front left tire at 2.9 bar with status normal, got %v %q",
+			state.TirePressures.FrontLeft, state.TirePressures.FrontLeftStatus)
+	}
+	if state.TirePressures.RearRight != 0 {
+		t.Errorf("Expected unreported rear right pressure to stay 0, got %v", state.TirePressures.RearRight)
+	}
+	if !strings.Contains(string(state.Raw), `"vehicleMileage":{"__typename":"VehicleMileage"`) {
+		t.Errorf("Expected the raw vehicleState object, got %s", state.Raw)
+	}
 }
 
 func TestParseChargeState(t *testing.T) {
@@ -332,3 +489,597 @@ func TestParseClosureStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVehicleState_ReportedAt(t *testing.T) {
+	state := parseVehicleState("vehicle-1", vehicleStateData{
+		BatteryLevel:   &timestampedValue[float64]{TimeStamp: "2024-01-15T10:30:00.000Z", Value: 80},
+		VehicleMileage: &timestampedValue[float64]{TimeStamp: "2024-01-15T11:45:00.000Z", Value: 1000},
+		GNSSLocation:   &gnssLocation{TimeStamp: "not a time"},
+	})
+	if want := time.Date(2024, 1, 15, 11, 45, 0, 0, time.UTC); !state.ReportedAt.Equal(want) {
+		t.Errorf("ReportedAt = %v, want the newest reading %v", state.ReportedAt, want)
+	}
+	if state.TelemetryMissing {
+		t.Error("TelemetryMissing set although a battery level was reported")
+	}
+
+	// A vehicle in service may come back with nothing but closures
+	state = parseVehicleState("vehicle-1", vehicleStateData{
+		DoorFrontLeftClosed: &timestampedValue[string]{Value: "closed"},
+	})
+	if !state.TelemetryMissing || !state.ReportedAt.IsZero() {
+		t.Errorf("TelemetryMissing = %v, ReportedAt = %v; want true and zero", state.TelemetryMissing, state.ReportedAt)
+	}
+}
+
+func TestParseVehicleState_ExteriorTemp(t *testing.T) {
+	state := parseVehicleState("vehicle-1", vehicleStateData{
+		BatteryLevel: &timestampedValue[float64]{Value: 80},
+	})
+	if state.ExteriorTemp != nil {
+		t.Errorf("ExteriorTemp = %v, want nil when not reported", *state.ExteriorTemp)
+	}
+
+	// 0°C is a real reading, not a missing one
+	state = parseVehicleState("vehicle-1", vehicleStateData{
+		CabinClimateExteriorTemperature: &timestampedValue[float64]{Value: 0},
+	})
+	if state.ExteriorTemp == nil || *state.ExteriorTemp != 0 {
+		t.Errorf("ExteriorTemp = %v, want 0", state.ExteriorTemp)
+	}
+}
+
+func TestParseVehicleState_Tonneau(t *testing.T) {
+	closed := ClosureStatusClosed
+	tests := []struct {
+		name    string
+		tonneau *timestampedValue[string]
+		want    *ClosureStatus
+	}{
+		{"not reported", nil, nil},
+		{"empty value", &timestampedValue[string]{Value: ""}, nil},
+		{"closed", &timestampedValue[string]{Value: "closed"}, &closed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := parseVehicleState("vehicle-1", vehicleStateData{ClosureTonneauClosed: tt.tonneau})
+			switch {
+			case tt.want == nil && state.TonneauCover != nil:
+				t.Errorf("TonneauCover = %v, want nil", *state.TonneauCover)
+			case tt.want != nil && (state.TonneauCover == nil || *state.TonneauCover != *tt.want):
+				t.Errorf("TonneauCover = %v, want %v", state.TonneauCover, *tt.want)
+			}
+		})
+	}
+}
+
+func TestGetVehicleState_StateCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"vehicleState": map[string]interface{}{
+					"batteryLevel": map[string]interface{}{"value": 70.0 + float64(requests)},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	newClient := func(opts ...Option) *HTTPClient {
+		return NewHTTPClient(append([]Option{
+			WithBaseURL(server.URL),
+			WithCredentials(&Credentials{
+				AccessToken: "test-token",
+				ExpiresAt:   time.Now().Add(1 * time.Hour),
+			}),
+		}, opts...)...)
+	}
+	ctx := context.Background()
+
+	// Off by default: every call hits the server
+	client := newClient()
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetVehicleState(ctx, "vehicle-1"); err != nil {
+			t.Fatalf("GetVehicleState failed: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Fatalf("without a cache, requests = %d, want 2", requests)
+	}
+
+	requests = 0
+	client = newClient(WithStateCacheTTL(10 * time.Second))
+	now := time.Now()
+	client.stateCache.now = func() time.Time { return now }
+
+	first, err := client.GetVehicleState(ctx, "vehicle-1")
+	if err != nil {
+		t.Fatalf("GetVehicleState failed: %v", err)
+	}
+	first.BatteryLevel = 0 // Callers mutating a result must not affect the cache
+
+	second, err := client.GetVehicleState(ctx, "vehicle-1")
+	if err != nil {
+		t.Fatalf("GetVehicleState failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("second call within the TTL hit the server (%d requests)", requests)
+	}
+	if second.BatteryLevel != 71 {
+		t.Errorf("cached BatteryLevel = %v, want 71", second.BatteryLevel)
+	}
+
+	// Other vehicles are cached separately
+	if _, err := client.GetVehicleState(ctx, "vehicle-2"); err != nil {
+		t.Fatalf("GetVehicleState failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("different vehicle should miss the cache (%d requests)", requests)
+	}
+
+	// After the TTL, the next call refetches
+	client.stateCache.now = func() time.Time { return now.Add(10 * time.Second) }
+	if _, err := client.GetVehicleState(ctx, "vehicle-1"); err != nil {
+		t.Fatalf("GetVehicleState failed: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("call after the TTL should refetch (%d requests)", requests)
+	}
+}
+
+func TestVehicleStateFieldsQuery(t *testing.T) {
+	query, err := vehicleStateFieldsQuery([]StateField{StateFieldBattery, StateFieldRange, StateFieldBattery})
+	if err != nil {
+		t.Fatalf("vehicleStateFieldsQuery failed: %v", err)
+	}
+	if operationName(query) != "GetVehicleState" || strings.Count(query, "batteryLevel {") != 1 || !strings.Contains(query, "distanceToEmpty {") {
+		t.Errorf("query should select batteryLevel once and distanceToEmpty:\n%s", query)
+	}
+	for _, unwanted := range []string{"gnssLocation", "chargerState", "vehicleMileage", "doorFrontLeftLocked", "tirePressureFrontLeft"} {
+		if strings.Contains(query, unwanted) {
+			t.Errorf("query should not select %s:\n%s", unwanted, query)
+		}
+	}
+	if _, err := parseQueryOverrides(query); err != nil {
+		t.Errorf("generated query is malformed: %v", err)
+	}
+
+	// Every field group together asks for exactly what the full query does
+	var all []StateField
+	for _, group := range stateFields {
+		all = append(all, group.field)
+	}
+	full, err := vehicleStateFieldsQuery(all)
+	if err != nil {
+		t.Fatalf("vehicleStateFieldsQuery failed: %v", err)
+	}
+	if got, want := strings.Fields(full), strings.Fields(getVehicleStateQuery); !slices.Equal(got, want) {
+		t.Errorf("all fields query differs from getVehicleStateQuery:\n%s", full)
+	}
+
+	for _, fields := range [][]StateField{nil, {StateFieldBattery, "speed"}} {
+		if _, err := vehicleStateFieldsQuery(fields); err == nil {
+			t.Errorf("vehicleStateFieldsQuery(%v) should fail", fields)
+		}
+	}
+}
+
+func TestGetVehicleStateFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !strings.Contains(req.Query, "vehicleMileage") || strings.Contains(req.Query, "doorFrontLeftClosed") {
+			t.Errorf("unexpected query:\n%s", req.Query)
+		}
+		vehicleState := map[string]interface{}{
+			"__typename":     "VehicleState",
+			"vehicleMileage": map[string]interface{}{"timeStamp": "2024-01-15T10:30:00Z", "value": 12000.0},
+		}
+		if strings.Contains(req.Query, "batteryLevel") {
+			vehicleState["batteryLevel"] = map[string]interface{}{"timeStamp": "2024-01-15T10:30:00Z", "value": 64.5}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"vehicleState": vehicleState},
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithCredentials(&Credentials{AccessToken: "test-token", ExpiresAt: time.Now().Add(time.Hour)}),
+		WithStateCacheTTL(time.Minute),
+	)
+	ctx := context.Background()
+
+	state, err := client.GetVehicleStateFields(ctx, "vehicle-1", []StateField{StateFieldBattery, StateFieldOdometer})
+	if err != nil {
+		t.Fatalf("GetVehicleStateFields failed: %v", err)
+	}
+	if state.BatteryLevel != 64.5 || state.Odometer != 12000 || state.TelemetryMissing {
+		t.Errorf("state = %+v, want battery 64.5, odometer 12000 and telemetry present", state)
+	}
+	if state.Doors.FrontLeft != ClosureStatusUnknown || state.Latitude != nil {
+		t.Errorf("fields not requested should stay unset, got doors %+v location %v", state.Doors, state.Latitude)
+	}
+	if _, ok := client.stateCache.get("vehicle-1"); ok {
+		t.Error("a partial state must not be cached")
+	}
+
+	odometer, err := client.GetVehicleStateFields(ctx, "vehicle-1", []StateField{StateFieldOdometer})
+	if err != nil {
+		t.Fatalf("GetVehicleStateFields failed: %v", err)
+	}
+	if odometer.TelemetryMissing {
+		t.Error("TelemetryMissing should not be set when battery and range weren't requested")
+	}
+
+	if _, err := client.GetVehicleStateFields(ctx, "vehicle-1", nil); err == nil {
+		t.Error("Expected an error without fields")
+	}
+}
+
+func TestMergeStateFields(t *testing.T) {
+	lat, lon := 37.0, -122.0
+	base := &VehicleState{
+		VehicleID:     "vehicle-1",
+		BatteryLevel:  80,
+		Odometer:      12000,
+		IsLocked:      true,
+		Windows:       ClosureState{FrontLeft: ClosureStatusOpen},
+		TirePressures: TirePressures{FrontLeft: 42},
+		Latitude:      &lat,
+		Longitude:     &lon,
+		Raw:           []byte(`{}`),
+	}
+	partial := &VehicleState{VehicleID: "vehicle-1", UpdatedAt: time.Now(), IsOnline: true, BatteryLevel: 79, Odometer: 12010}
+
+	merged := MergeStateFields(base, partial, []StateField{StateFieldBattery, StateFieldOdometer, StateFieldLocation})
+	if merged.BatteryLevel != 79 || merged.Odometer != 12010 || merged.Latitude != nil {
+		t.Errorf("requested fields = battery %v odometer %v location %v, want the partial values", merged.BatteryLevel, merged.Odometer, merged.Latitude)
+	}
+	if !merged.IsLocked || merged.Windows.FrontLeft != ClosureStatusOpen || merged.TirePressures.FrontLeft != 42 {
+		t.Errorf("fields not requested should keep the base values, got %+v", merged)
+	}
+	if !merged.UpdatedAt.Equal(partial.UpdatedAt) || merged.Raw != nil {
+		t.Errorf("UpdatedAt = %v, Raw = %s; want the partial's time and no raw response", merged.UpdatedAt, merged.Raw)
+	}
+	if base.BatteryLevel != 80 {
+		t.Error("MergeStateFields must not modify base")
+	}
+}
+
+func TestGetVehicleState_RetriesTransientFailures(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name     string
+		fail     func(t *testing.T, w http.ResponseWriter) // Answers the first two requests
+		wantErr  bool
+		requests int
+	}{
+		{"gateway unavailable", func(t *testing.T, w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }, false, 3},
+		{"gateway timeout", func(t *testing.T, w http.ResponseWriter) { w.WriteHeader(http.StatusGatewayTimeout) }, false, 3},
+		{"connection dropped", dropConnection, false, 3},
+		{"server error", func(t *testing.T, w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) }, true, 1},
+		{"unauthenticated", func(t *testing.T, w http.ResponseWriter) {
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"expired","extensions":{"code":"UNAUTHENTICATED"}}]}`))
+		}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= 2 {
+					tt.fail(t, w)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": map[string]interface{}{
+						"vehicleState": map[string]interface{}{
+							"batteryLevel": map[string]interface{}{"value": 80.0},
+						},
+					},
+				})
+			}))
+			defer server.Close()
+
+			var warnings strings.Builder
+			client := NewHTTPClient(
+				WithBaseURL(server.URL),
+				WithCredentials(&Credentials{AccessToken: "test-token", ExpiresAt: time.Now().Add(time.Hour)}),
+				WithRetryPolicy(policy),
+				WithWarningLog(&warnings),
+			)
+
+			state, err := client.GetVehicleState(context.Background(), "vehicle-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVehicleState error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.requests {
+				t.Errorf("requests = %d, want %d", requests, tt.requests)
+			}
+			if !tt.wantErr && (state.BatteryLevel != 80 || strings.Count(warnings.String(), "retrying") != 2) {
+				t.Errorf("battery %v after retries, warnings:\n%s", state.BatteryLevel, warnings.String())
+			}
+		})
+	}
+}
+
+func TestDoGraphQL_RetryLimits(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// Queries give up after MaxAttempts
+	client := NewHTTPClient(WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}))
+	if err := client.doGraphQL(context.Background(), getVehiclesQuery, nil, nil); err == nil {
+		t.Fatal("expected an error once the retries are used up")
+	}
+	if requests != 4 {
+		t.Errorf("query requests = %d, want 4", requests)
+	}
+
+	// Mutations are sent once: a login may have gone through
+	requests = 0
+	if err := client.Authenticate(context.Background(), "test@example.com", "password"); err == nil {
+		t.Fatal("expected Authenticate to fail")
+	}
+	if requests != 1 {
+		t.Errorf("mutation requests = %d, want 1", requests)
+	}
+
+	// A zero policy turns retries off
+	requests = 0
+	client = NewHTTPClient(WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{}))
+	_ = client.doGraphQL(context.Background(), getVehiclesQuery, nil, nil)
+	if requests != 1 {
+		t.Errorf("requests without retries = %d, want 1", requests)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := p.delay(retry); d < want/2 || d > want {
+				t.Errorf("delay(%d) = %v, want between %v and %v", retry, d, want/2, want)
+			}
+		}
+	}
+}
+
+func TestGetVehicleSoftwareInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !strings.Contains(req.Query, "query GetVehicleSoftwareInfo") {
+			t.Error("Expected GetVehicleSoftwareInfo query")
+		}
+		if vehicleID, ok := req.Variables["vehicleID"].(string); !ok || vehicleID != "vehicle-1" {
+			t.Errorf("Expected vehicleID variable 'vehicle-1', got %v", req.Variables["vehicleID"])
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"vehicleState": map[string]interface{}{
+					"__typename": "VehicleState",
+					"otaCurrentVersion": map[string]interface{}{
+						"__typename": "OTACurrentVersion",
+						"timeStamp":  "2024-01-15T10:30:00.000Z",
+						"value":      "2024.10.0",
+					},
+					"otaAvailableVersion": map[string]interface{}{
+						"__typename": "OTAAvailableVersion",
+						"timeStamp":  "2024-01-15T10:30:00.000Z",
+						"value":      "2024.12.1",
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithCredentials(&Credentials{
+			AccessToken: "test-token",
+			ExpiresAt:   time.Now().Add(1 * time.Hour),
+		}),
+	)
+
+	info, err := client.GetVehicleSoftwareInfo(context.Background(), "vehicle-1")
+	if err != nil {
+		t.Fatalf("GetVehicleSoftwareInfo failed: %v", err)
+	}
+	if info.VehicleID != "vehicle-1" || info.CurrentVersion != "2024.10.0" || info.AvailableVersion != "2024.12.1" {
+		t.Errorf("GetVehicleSoftwareInfo() = %+v", info)
+	}
+	if !info.UpdateAvailable() {
+		t.Error("UpdateAvailable() = false, want true")
+	}
+}
+
+func TestGetVehicleSoftwareInfo_NotAuthenticated(t *testing.T) {
+	client := NewHTTPClient()
+	if _, err := client.GetVehicleSoftwareInfo(context.Background(), "vehicle-1"); err == nil {
+		t.Error("expected error when not authenticated")
+	}
+}
+
+func TestVehicleModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported string
+		vin      string
+		want     string
+	}{
+		{"reported model wins", "R1S", "7FCTGAAL0NN000001", "R1S"},
+		{"R1T from VIN", "", "7FCTGAAL0NN000001", "R1T"},
+		{"R1S from VIN", " ", "7pdsgaba1pn000002", "R1S"},
+		{"unrecognized VIN", "", "1HGCM82633A004352", ModelUnknown},
+		{"short VIN", "", "VIN123", ModelUnknown},
+		{"no VIN", "", "", ModelUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vehicleModel(tt.reported, tt.vin); got != tt.want {
+				t.Errorf("vehicleModel(%q, %q) = %q, want %q", tt.reported, tt.vin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPinnedCert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"currentUser":{"vehicles":[]}}}`))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	fingerprint := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	newClient := func(opts ...Option) *HTTPClient {
+		return NewHTTPClient(append([]Option{
+			WithBaseURL(server.URL),
+			WithHTTPClient(server.Client()),
+			WithCredentials(&Credentials{AccessToken: "test-token", ExpiresAt: time.Now().Add(time.Hour)}),
+		}, opts...)...)
+	}
+
+	if newClient().TLSConfig() != nil {
+		t.Error("Expected no TLS config without pins")
+	}
+
+	// openssl style, with colons and prefix
+	var colons []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, fingerprint[i:i+2])
+	}
+	pinned := newClient(WithPinnedCert("sha256 Fingerprint=" + strings.Join(colons, ":")))
+	if pinned.TLSConfig() == nil {
+		t.Fatal("Expected a TLS config for the WebSocket dialer")
+	}
+	if _, err := pinned.GetVehicles(context.Background()); err != nil {
+		t.Fatalf("GetVehicles with matching pin failed: %v", err)
+	}
+
+	mismatched := newClient(WithPinnedCert(strings.Repeat("00", sha256.Size)))
+	_, err := mismatched.GetVehicles(context.Background())
+	if !errors.Is(err, ErrCertificateMismatch) {
+		t.Errorf("Expected ErrCertificateMismatch, got %v", err)
+	}
+
+	// A truncated pin keeps pinning on and matches nothing
+	truncated := newClient(WithPinnedCert(fingerprint[:62]))
+	if _, err := truncated.GetVehicles(context.Background()); !errors.Is(err, ErrCertificateMismatch) {
+		t.Errorf("Expected ErrCertificateMismatch for an invalid pin, got %v", err)
+	}
+}
+
+func TestValidateFingerprint(t *testing.T) {
+	valid := strings.Repeat("ab", sha256.Size)
+	for _, fp := range []string{valid, strings.ToUpper(valid), "sha256 Fingerprint=" + strings.Repeat("AB:", sha256.Size-1) + "AB"} {
+		if err := ValidateFingerprint(fp); err != nil {
+			t.Errorf("ValidateFingerprint(%q) = %v, want nil", fp, err)
+		}
+	}
+	for _, fp := range []string{"", valid[:62], valid + "ab", strings.Repeat("zz", sha256.Size)} {
+		if err := ValidateFingerprint(fp); err == nil {
+			t.Errorf("ValidateFingerprint(%q) should fail", fp)
+		}
+	}
+}
+
+func TestParseQueryOverrides(t *testing.T) {
+	valid := `
+		# Patched for a renamed field
+		query GetVehicleState($vehicleID: String!) {
+			vehicleState(id: $vehicleID) {
+				batteryLevel(unit: "percent") { value }
+			}
+		}
+
+		query GetVehicles { currentUser { vehicles { id vin name } } }
+	`
+	overrides, err := parseQueryOverrides(valid)
+	if err != nil {
+		t.Fatalf("parseQueryOverrides failed: %v", err)
+	}
+	if len(overrides) != 2 || !strings.Contains(overrides["GetVehicleState"], `batteryLevel(unit: "percent")`) ||
+		!strings.HasPrefix(overrides["GetVehicles"], "query GetVehicles") {
+		t.Errorf("unexpected overrides: %q", overrides)
+	}
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "empty", doc: "  # nothing\n", want: "no queries"},
+		{name: "unbalanced", doc: "query GetVehicles { currentUser { id }", want: `missing "}"`},
+		{name: "mismatched", doc: "query GetVehicles { currentUser ( id } }", want: "unexpected"},
+		{name: "unterminated string", doc: "query GetVehicles { a(x: \"oops) }", want: "line 1: unterminated string"},
+		{name: "anonymous", doc: "{ currentUser { id } }", want: "outside a named definition"},
+		{name: "mutation", doc: "mutation GetVehicles { a }", want: "only named queries"},
+		{name: "unknown", doc: "query Other { a }", want: "cannot be overridden"},
+		{name: "missing variable", doc: "query GetVehicleState { vehicleState { id } }", want: "$vehicleID"},
+		{name: "duplicate", doc: "query GetVehicles { a } query GetVehicles { b }", want: "more than once"},
+		{name: "garbage", doc: "not graphql", want: "expected query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseQueryOverrides(tt.doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseQueryOverrides() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetVehicles_QueryOverride(t *testing.T) {
+	override := "query GetVehicles { currentUser { vehicles { id patched: vin } } }"
+
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotQuery = req.Query
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"currentUser": {"vehicles": [{"id": "vehicle-1"}]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithQueryOverrides(map[string]string{"GetVehicles": override}),
+		WithCredentials(&Credentials{
+			AccessToken: "test-token",
+			ExpiresAt:   time.Now().Add(1 * time.Hour),
+		}),
+	)
+
+	if _, err := client.GetVehicles(context.Background()); err != nil {
+		t.Fatalf("GetVehicles failed: %v", err)
+	}
+	if gotQuery != override {
+		t.Errorf("sent query %q, want the override", gotQuery)
+	}
+	// Operations without an override keep the built-in query
+	if got := client.query(getVehicleStateQuery); got != getVehicleStateQuery {
+		t.Error("GetVehicleState should use the built-in query")
+	}
+}
@@ -1,8 +1,14 @@
 package rivian
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,18 +37,99 @@ type WebSocketMessage struct {
 	Payload map[string]interface{} `json:"payload,omitempty"`
 }
 
+// UnmarshalJSON also accepts a payload that is a list of GraphQL errors,
+// which some servers send in "error" messages instead of a single error
+// object; the list is kept under Payload["errors"].
+func (m *WebSocketMessage) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID      string          `json:"id"`
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.ID, m.Type, m.Payload = raw.ID, raw.Type, nil
+	payload := bytes.TrimSpace(raw.Payload)
+	switch {
+	case len(payload) == 0 || bytes.Equal(payload, []byte("null")):
+	case payload[0] == '[':
+		var errs []interface{}
+		if err := json.Unmarshal(payload, &errs); err != nil {
+			return err
+		}
+		m.Payload = map[string]interface{}{"errors": errs}
+	default:
+		return json.Unmarshal(payload, &m.Payload)
+	}
+	return nil
+}
+
 // SubscriptionCallback is called when a subscription message is received
 type SubscriptionCallback func(data map[string]interface{})
 
+// ErrSubscriptionEnded is matched (via errors.Is) when the server ends a
+// subscription with a "complete" or "error" message.
+var ErrSubscriptionEnded = errors.New("subscription ended by server")
+
+// SubscriptionEndHandler is called once when the server ends a
+// subscription. err wraps ErrSubscriptionEnded and, for "error" messages,
+// includes the server's message; for "complete" it is ErrSubscriptionEnded
+// itself.
+type SubscriptionEndHandler func(err error)
+
+// ConnectionEvent describes a change in WebSocket connection health.
+type ConnectionEvent int
+
+const (
+	// EventDisconnected means the connection dropped and reconnection started.
+	EventDisconnected ConnectionEvent = iota
+	// EventReconnected means the connection and all subscriptions were restored.
+	EventReconnected
+	// EventGaveUp means reconnection failed permanently; Done is closed
+	// after the handler returns.
+	EventGaveUp
+)
+
+// ConnectionEventHandler is notified of connection health changes. err is
+// the last reconnect error for EventGaveUp and nil otherwise.
+type ConnectionEventHandler func(event ConnectionEvent, err error)
+
+// subscription is an active subscription: the query and variables its
+// start message is rebuilt from on reconnect, and where its data goes.
+type subscription struct {
+	query     string
+	variables map[string]interface{}
+	callback  SubscriptionCallback
+}
+
+// startMessage builds the "start" message that subscribes as id.
+func (s subscription) startMessage(id string) WebSocketMessage {
+	return WebSocketMessage{
+		ID:   id,
+		Type: "start",
+		Payload: map[string]interface{}{
+			"query":     s.query,
+			"variables": s.variables,
+		},
+	}
+}
+
 // WebSocketClient manages WebSocket connections for real-time updates
 type WebSocketClient struct {
 	mu             sync.RWMutex
 	conn           *websocket.Conn
+	url            string
 	credentials    *Credentials
 	csrfToken      string
 	appSessionID   string
-	subscriptions  map[string]SubscriptionCallback // subscription ID -> callback
-	reconnectCount int
+	subscriptions  map[string]subscription // subscription ID -> subscription, replayed on reconnect
+	endHandlers    map[string]SubscriptionEndHandler
+	reconnectDelay time.Duration
+	onEvent        ConnectionEventHandler
+	tlsConfig      *tls.Config  // nil uses the default TLS settings
+	logger         *slog.Logger // never nil; discards by default
 	closeSignal    chan struct{}
 	closed         bool
 }
@@ -50,14 +137,62 @@ type WebSocketClient struct {
 // NewWebSocketClient creates a new WebSocket client
 func NewWebSocketClient(credentials *Credentials, csrfToken, appSessionID string) *WebSocketClient {
 	return &WebSocketClient{
-		credentials:   credentials,
-		csrfToken:     csrfToken,
-		appSessionID:  appSessionID,
-		subscriptions: make(map[string]SubscriptionCallback),
-		closeSignal:   make(chan struct{}),
+		url:            WebSocketURL,
+		credentials:    credentials,
+		csrfToken:      csrfToken,
+		appSessionID:   appSessionID,
+		subscriptions:  make(map[string]subscription),
+		endHandlers:    make(map[string]SubscriptionEndHandler),
+		reconnectDelay: ReconnectDelay,
+		logger:         slog.New(slog.DiscardHandler),
+		closeSignal:    make(chan struct{}),
 	}
 }
 
+// SetLogger sets the logger for connection attempts, disconnects,
+// reconnects and subscription errors. nil turns logging off again.
+func (c *WebSocketClient) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = logger
+}
+
+// SetEventHandler registers a handler for connection health changes.
+// The handler runs on the client's internal goroutines and must not block.
+func (c *WebSocketClient) SetEventHandler(handler ConnectionEventHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvent = handler
+}
+
+// OnSubscriptionEnd registers a handler for when the server ends
+// subscription id. It is not called after Unsubscribe. The handler runs on
+// the client's message loop and must not block.
+func (c *WebSocketClient) OnSubscriptionEnd(id string, handler SubscriptionEndHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endHandlers[id] = handler
+}
+
+// SetTLSConfig sets the TLS configuration used when dialing, e.g.
+// HTTPClient.TLSConfig to apply certificate pinning.
+func (c *WebSocketClient) SetTLSConfig(cfg *tls.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tlsConfig = cfg
+}
+
+// Done returns a channel that is closed once the client is closed or has
+// permanently given up reconnecting.
+func (c *WebSocketClient) Done() <-chan struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closeSignal
+}
+
 // Connect establishes the WebSocket connection
 func (c *WebSocketClient) Connect(ctx context.Context) error {
 	c.mu.Lock()
@@ -76,6 +211,7 @@ func (c *WebSocketClient) connectUnlocked(ctx context.Context) error {
 	dialer := websocket.Dialer{
 		ReadBufferSize:  ReadBufferSize,
 		WriteBufferSize: WriteBufferSize,
+		TLSClientConfig: c.tlsConfig,
 	}
 
 	headers := make(map[string][]string)
@@ -93,14 +229,15 @@ func (c *WebSocketClient) connectUnlocked(ctx context.Context) error {
 	}
 
 	// Connect
-	conn, _, err := dialer.DialContext(ctx, WebSocketURL, headers)
+	c.logger.Debug("websocket connecting", "url", c.url)
+	conn, _, err := dialer.DialContext(ctx, c.url, headers)
 	if err != nil {
+		c.logger.Warn("websocket connect failed", "error", err)
 		return fmt.Errorf("dial websocket: %w", err)
 	}
 
 	c.conn = conn
 	c.closed = false
-	c.reconnectCount = 0
 
 	// Send connection_init message
 	initMsg := WebSocketMessage{
@@ -113,8 +250,10 @@ func (c *WebSocketClient) connectUnlocked(ctx context.Context) error {
 	if err := c.writeMessage(initMsg); err != nil {
 		_ = conn.Close()
 		c.conn = nil
+		c.logger.Warn("websocket connect failed", "error", err)
 		return fmt.Errorf("send connection_init: %w", err)
 	}
+	c.logger.Info("websocket connected")
 
 	// Start message handler
 	go c.messageLoop()
@@ -134,39 +273,37 @@ func (c *WebSocketClient) Subscribe(ctx context.Context, id, query string, varia
 		return fmt.Errorf("not connected")
 	}
 
-	// Store callback
-	c.subscriptions[id] = callback
+	// Store the subscription so it can be replayed after a reconnect
+	sub := subscription{query: query, variables: variables, callback: callback}
+	c.subscriptions[id] = sub
 
 	// Send start message
-	msg := WebSocketMessage{
-		ID:   id,
-		Type: "start",
-		Payload: map[string]interface{}{
-			"query":     query,
-			"variables": variables,
-		},
-	}
-
-	if err := c.writeMessage(msg); err != nil {
+	if err := c.writeMessage(sub.startMessage(id)); err != nil {
 		delete(c.subscriptions, id)
+		delete(c.endHandlers, id)
+		c.logger.Warn("subscription start failed", "id", id, "error", err)
 		return fmt.Errorf("send start: %w", err)
 	}
+	c.logger.Info("subscription started", "id", id)
 
 	return nil
 }
 
-// Unsubscribe stops a subscription
+// Unsubscribe stops a subscription. While disconnected it is only
+// forgotten, so a reconnect doesn't start it again.
 func (c *WebSocketClient) Unsubscribe(id string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// Remove the subscription
+	delete(c.subscriptions, id)
+	delete(c.endHandlers, id)
+
+	// The server forgot it with the connection
 	if c.conn == nil {
-		return fmt.Errorf("not connected")
+		return nil
 	}
 
-	// Remove callback
-	delete(c.subscriptions, id)
-
 	// Send stop message
 	msg := WebSocketMessage{
 		ID:   id,
@@ -187,6 +324,7 @@ func (c *WebSocketClient) Close() error {
 
 	c.closed = true
 	close(c.closeSignal)
+	c.logger.Info("websocket closed")
 
 	if c.conn != nil {
 		// Send connection_terminate
@@ -203,29 +341,30 @@ func (c *WebSocketClient) Close() error {
 	return nil
 }
 
-// messageLoop handles incoming WebSocket messages
+// messageLoop handles incoming WebSocket messages for the current connection
+// and exits once that connection is closed or replaced
 func (c *WebSocketClient) messageLoop() {
+	c.mu.RLock()
+	conn := c.conn
+	closeSignal := c.closeSignal
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return
+	}
+
 	for {
 		select {
-		case <-c.closeSignal:
+		case <-closeSignal:
 			return
 		default:
 		}
 
-		c.mu.RLock()
-		conn := c.conn
-		c.mu.RUnlock()
-
-		if conn == nil {
-			return
-		}
-
 		var msg WebSocketMessage
 		if err := conn.ReadJSON(&msg); err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				// Unexpected close, attempt reconnect
-				c.handleDisconnect()
-			}
+			// The connection is dead; handleDisconnect ignores this if the
+			// client is shutting down or the connection was already replaced
+			c.handleDisconnect(conn, err)
 			return
 		}
 
@@ -246,90 +385,184 @@ func (c *WebSocketClient) handleMessage(msg WebSocketMessage) {
 	case "data":
 		// Subscription data
 		c.mu.RLock()
-		callback, ok := c.subscriptions[msg.ID]
+		sub, ok := c.subscriptions[msg.ID]
 		c.mu.RUnlock()
 
-		if ok && callback != nil {
-			callback(msg.Payload)
+		if ok && sub.callback != nil {
+			sub.callback(msg.Payload)
 		}
 
-	case "error":
-		// Subscription error
-		// TODO: Log error from msg.Payload
-
-	case "complete":
-		// Subscription completed
+	case "error", "complete":
+		// The server ended the subscription: stop tracking it so it isn't
+		// replayed on reconnect, and tell the subscriber
 		c.mu.Lock()
+		_, active := c.subscriptions[msg.ID]
+		handler := c.endHandlers[msg.ID]
 		delete(c.subscriptions, msg.ID)
+		delete(c.endHandlers, msg.ID)
+		logger := c.logger
 		c.mu.Unlock()
+
+		if !active {
+			return
+		}
+		err := subscriptionEndError(msg)
+		logger.Warn("subscription ended", "id", msg.ID, "error", err)
+		if handler != nil {
+			handler(err)
+		}
 	}
 }
 
-// handleDisconnect attempts to reconnect
-func (c *WebSocketClient) handleDisconnect() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// subscriptionEndError describes why the server ended a subscription.
+func subscriptionEndError(msg WebSocketMessage) error {
+	if msg.Type != "error" {
+		return ErrSubscriptionEnded
+	}
+	if message, ok := msg.Payload["message"].(string); ok && message != "" {
+		return fmt.Errorf("%w: %s", ErrSubscriptionEnded, message)
+	}
 
-	if c.closed {
+	// A list of GraphQL errors
+	errs, _ := msg.Payload["errors"].([]interface{})
+	var messages []string
+	for _, e := range errs {
+		if obj, ok := e.(map[string]interface{}); ok {
+			if message, ok := obj["message"].(string); ok && message != "" {
+				messages = append(messages, message)
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("%w: %s", ErrSubscriptionEnded, strings.Join(messages, "; "))
+	}
+	return fmt.Errorf("%w: server error", ErrSubscriptionEnded)
+}
+
+// handleDisconnect reconnects after the given connection failed with cause
+// and replays all active subscriptions. It retries up to MaxReconnects times
+// before giving up, which closes Done. Failures of a connection that has
+// already been replaced (or of a closed client) are ignored.
+func (c *WebSocketClient) handleDisconnect(failed *websocket.Conn, cause error) {
+	c.mu.Lock()
+	if c.closed || c.conn != failed {
+		c.mu.Unlock()
 		return
 	}
+	_ = c.conn.Close()
+	c.conn = nil
+	closeSignal := c.closeSignal
+	logger := c.logger
+	c.mu.Unlock()
 
-	if c.conn != nil {
-		_ = c.conn.Close()
-		c.conn = nil
+	logger.Warn("websocket disconnected", "error", cause)
+	c.notify(EventDisconnected, nil)
+
+	var lastErr error
+	for attempt := 0; attempt < MaxReconnects; attempt++ {
+		select {
+		case <-closeSignal:
+			return
+		case <-time.After(c.reconnectDelay):
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return
+		}
+		logger = c.logger
+		logger.Info("websocket reconnecting", "attempt", attempt+1, "max", MaxReconnects)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		lastErr = c.connectUnlocked(ctx)
+		cancel()
+		if lastErr == nil {
+			lastErr = c.resubscribeUnlocked()
+		}
+		subscriptions := len(c.subscriptions)
+		c.mu.Unlock()
+
+		if lastErr == nil {
+			logger.Info("websocket reconnected", "attempts", attempt+1, "subscriptions", subscriptions)
+			c.notify(EventReconnected, nil)
+			return
+		}
+		logger.Warn("websocket reconnect failed", "attempt", attempt+1, "error", lastErr)
 	}
 
-	// Don't reconnect if we've exceeded max attempts
-	if c.reconnectCount >= MaxReconnects {
-		c.closed = true
-		close(c.closeSignal)
+	select {
+	case <-closeSignal:
 		return
+	default:
 	}
 
-	c.reconnectCount++
+	logger.Error("websocket reconnect gave up", "attempts", MaxReconnects, "error", lastErr)
 
-	// Wait before reconnecting
-	time.Sleep(ReconnectDelay)
+	// Notify before closing Done so waiters can rely on the handler having run
+	c.notify(EventGaveUp, lastErr)
 
-	// Attempt reconnect
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := c.connectUnlocked(ctx); err != nil {
-		// Reconnect failed, will try again on next disconnect
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
 		return
 	}
+	c.closed = true
+	close(c.closeSignal)
+}
+
+// resubscribeUnlocked re-sends the start message of every active
+// subscription, with its original ID, query and variables, on a fresh
+// connection. Caller must hold c.mu.
+func (c *WebSocketClient) resubscribeUnlocked() error {
+	for id, sub := range c.subscriptions {
+		if err := c.writeMessage(sub.startMessage(id)); err != nil {
+			// Drop the half-open connection so the next attempt starts clean
+			_ = c.conn.Close()
+			c.conn = nil
+			return fmt.Errorf("resubscribe %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// notify reports a connection event to the registered handler, if any
+func (c *WebSocketClient) notify(event ConnectionEvent, err error) {
+	c.mu.RLock()
+	handler := c.onEvent
+	c.mu.RUnlock()
 
-	// Resubscribe to all subscriptions
-	for id, callback := range c.subscriptions {
-		// Note: We'd need to store the original query/variables to resubscribe
-		// For now, this is a simplified implementation
-		_ = id
-		_ = callback
+	if handler != nil {
+		handler(event, err)
 	}
 }
 
-// pingLoop sends periodic pings to keep connection alive
+// pingLoop sends periodic pings to keep the current connection alive and
+// exits once that connection is closed or replaced
 func (c *WebSocketClient) pingLoop() {
+	c.mu.RLock()
+	conn := c.conn
+	closeSignal := c.closeSignal
+	c.mu.RUnlock()
+
 	ticker := time.NewTicker(PingInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-c.closeSignal:
+		case <-closeSignal:
 			return
 		case <-ticker.C:
 			c.mu.RLock()
-			conn := c.conn
+			current := c.conn
 			c.mu.RUnlock()
 
-			if conn == nil {
+			if current != conn {
 				return
 			}
 
 			if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(WriteTimeout)); err != nil {
 				// Ping failed, connection might be dead
-				c.handleDisconnect()
+				c.handleDisconnect(conn, err)
 				return
 			}
 		}
@@ -352,29 +585,65 @@ type VehicleStateSubscription struct {
 	vehicleID  string
 	updateChan chan map[string]interface{}
 	closed     atomic.Bool
+
+	done    chan struct{} // closed when the server ends the subscription
+	errs    chan error    // receives the server's error, if it sent one
+	endErr  error
+	endOnce sync.Once
 }
 
+// Field sets for vehicle state subscriptions. Each field is requested as
+// "{ value timeStamp }".
+var (
+	// VehicleStateFields is everything the dashboard shows live.
+	VehicleStateFields = []string{"batteryLevel", "chargeState", "rangeEstimate", "isLocked", "cabinTemp"}
+
+	// ChargingStateFields is the narrower set for following a charge,
+	// which skips lock and cabin updates.
+	ChargingStateFields = []string{"batteryLevel", "chargeState", "rangeEstimate"}
+)
+
 // SubscribeToVehicleState creates a subscription for vehicle state updates
 func SubscribeToVehicleState(ctx context.Context, client *WebSocketClient, vehicleID string) (*VehicleStateSubscription, error) {
+	return SubscribeToVehicleStateFields(ctx, client, vehicleID, VehicleStateFields)
+}
+
+// SubscribeToChargingState creates a subscription for charging-related
+// updates only.
+func SubscribeToChargingState(ctx context.Context, client *WebSocketClient, vehicleID string) (*VehicleStateSubscription, error) {
+	return SubscribeToVehicleStateFields(ctx, client, vehicleID, ChargingStateFields)
+}
+
+// vehicleStateSubscriptionQuery builds the GraphQL subscription for fields.
+func vehicleStateSubscriptionQuery(fields []string) string {
+	var b strings.Builder
+	b.WriteString("\n\t\tsubscription VehicleStateUpdates($vehicleId: String!) {\n")
+	b.WriteString("\t\t\tvehicleState(id: $vehicleId) {\n")
+	b.WriteString("\t\t\t\t__typename\n")
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\t\t\t\t%s { value timeStamp }\n", field)
+	}
+	b.WriteString("\t\t\t}\n\t\t}\n\t")
+	return b.String()
+}
+
+// SubscribeToVehicleStateFields creates a subscription for updates to the
+// given vehicle state fields, e.g. VehicleStateFields or ChargingStateFields.
+func SubscribeToVehicleStateFields(ctx context.Context, client *WebSocketClient, vehicleID string, fields []string) (*VehicleStateSubscription, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields to subscribe to")
+	}
+
 	subscription := &VehicleStateSubscription{
 		client:     client,
 		vehicleID:  vehicleID,
 		updateChan: make(chan map[string]interface{}, 10),
+		done:       make(chan struct{}),
+		errs:       make(chan error, 1),
 	}
 
 	// GraphQL subscription query for vehicle state changes
-	query := `
-		subscription VehicleStateUpdates($vehicleId: String!) {
-			vehicleState(id: $vehicleId) {
-				__typename
-				batteryLevel { value timeStamp }
-				chargeState { value timeStamp }
-				rangeEstimate { value timeStamp }
-				isLocked { value timeStamp }
-				cabinTemp { value timeStamp }
-			}
-		}
-	`
+	query := vehicleStateSubscriptionQuery(fields)
 
 	variables := map[string]interface{}{
 		"vehicleId": vehicleID,
@@ -392,7 +661,20 @@ func SubscribeToVehicleState(ctx context.Context, client *WebSocketClient, vehic
 		}
 	}
 
-	err := client.Subscribe(ctx, fmt.Sprintf("vehicle-state-%s", vehicleID), query, variables, callback)
+	id := fmt.Sprintf("vehicle-state-%s", vehicleID)
+	client.OnSubscriptionEnd(id, func(err error) {
+		subscription.endOnce.Do(func() {
+			subscription.endErr = err
+			if err != ErrSubscriptionEnded {
+				// An "error" message rather than a plain "complete"; it is
+				// queued before Done closes so waiters on Done can check it
+				subscription.errs <- err
+			}
+			close(subscription.done)
+		})
+	})
+
+	err := client.Subscribe(ctx, id, query, variables, callback)
 	if err != nil {
 		return nil, err
 	}
@@ -400,6 +682,33 @@ func SubscribeToVehicleState(ctx context.Context, client *WebSocketClient, vehic
 	return subscription, nil
 }
 
+// Done returns a channel that is closed when the server ends the
+// subscription. Updates then stop arriving; Err says why.
+func (s *VehicleStateSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the server ended the subscription, or nil while it is
+// active.
+func (s *VehicleStateSubscription) Err() error {
+	select {
+	case <-s.done:
+		return s.endErr
+	default:
+		return nil
+	}
+}
+
+// Errors returns a channel that receives the error when the server rejects
+// or ends the subscription with an "error" message, carrying the server's
+// message. It receives at most one error and is never closed; Done also
+// closes then. A subscription the server merely completes sends nothing,
+// so an empty channel with Done open means no data has arrived yet rather
+// than a rejection.
+func (s *VehicleStateSubscription) Errors() <-chan error {
+	return s.errs
+}
+
 // Updates returns the channel for receiving state updates
 func (s *VehicleStateSubscription) Updates() <-chan map[string]interface{} {
 	return s.updateChan
@@ -1,8 +1,11 @@
 package rivian
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -310,7 +313,7 @@ func TestWebSocketClient_Unsubscribe(t *testing.T) {
 
 	client.mu.Lock()
 	client.conn = conn
-	client.subscriptions["sub-1"] = func(data map[string]interface{}) {}
+	client.subscriptions["sub-1"] = subscription{callback: func(data map[string]interface{}) {}}
 	client.mu.Unlock()
 
 	// Unsubscribe
@@ -466,7 +469,7 @@ func TestWebSocketClient_HandleMessageTypes(t *testing.T) {
 			// Set up subscription for complete/error tests
 			if tt.message.ID != "" {
 				client.mu.Lock()
-				client.subscriptions[tt.message.ID] = func(data map[string]interface{}) {}
+				client.subscriptions[tt.message.ID] = subscription{callback: func(data map[string]interface{}) {}}
 				client.mu.Unlock()
 			}
 
@@ -551,3 +554,479 @@ func TestVehicleStateSubscription(t *testing.T) {
 	// Clean up
 	_ = wsClient.Close()
 }
+
+func TestWebSocketClient_ReconnectResubscribes(t *testing.T) {
+	mock := newMockWebSocketServer()
+	defer mock.close()
+
+	client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "csrf-123", "app-session-123")
+	client.url = mock.url()
+	client.reconnectDelay = 10 * time.Millisecond
+
+	events := make(chan ConnectionEvent, 10)
+	client.SetEventHandler(func(event ConnectionEvent, err error) {
+		events <- event
+	})
+
+	// Collect subscription start messages seen by the server
+	starts := make(chan WebSocketMessage, 10)
+	go func() {
+		for msg := range mock.messages {
+			if msg.Type == "start" {
+				starts <- msg
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	const query = "subscription { test }"
+	data := make(chan struct{}, 10)
+	variables := map[string]interface{}{"vehicleId": "vehicle-123"}
+	if err := client.Subscribe(ctx, "sub-1", query, variables, func(map[string]interface{}) { data <- struct{}{} }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// waitFor waits for the start message and the data the mock sends after it
+	waitFor := func() {
+		t.Helper()
+		select {
+		case msg := <-starts:
+			vars, _ := msg.Payload["variables"].(map[string]interface{})
+			if msg.ID != "sub-1" || msg.Payload["query"] != query || vars["vehicleId"] != "vehicle-123" {
+				t.Errorf("start message = %+v, want ID sub-1 with the original query and variables", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for start message")
+		}
+		select {
+		case <-data:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for subscription data")
+		}
+	}
+	waitFor()
+
+	// Drop the connection server-side
+	mock.mu.Lock()
+	for _, conn := range mock.clients {
+		_ = conn.Close()
+	}
+	mock.mu.Unlock()
+
+	for _, want := range []ConnectionEvent{EventDisconnected, EventReconnected} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("event = %v, want %v", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for event %v", want)
+		}
+	}
+
+	// The subscription must be replayed on the new connection and keep
+	// delivering data
+	waitFor()
+
+	select {
+	case <-client.Done():
+		t.Error("Done() closed after a successful reconnect")
+	default:
+	}
+}
+
+func TestWebSocketClient_UnsubscribeWhileDisconnected(t *testing.T) {
+	mock := newMockWebSocketServer()
+	defer mock.close()
+
+	client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "csrf-123", "app-session-123")
+	client.url = mock.url()
+	client.reconnectDelay = 100 * time.Millisecond
+
+	events := make(chan ConnectionEvent, 10)
+	client.SetEventHandler(func(event ConnectionEvent, err error) {
+		events <- event
+	})
+	starts := make(chan WebSocketMessage, 10)
+	go func() {
+		for msg := range mock.messages {
+			if msg.Type == "start" {
+				starts <- msg
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	if err := client.Subscribe(ctx, "sub-1", "subscription { test }", nil, func(map[string]interface{}) {}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	select {
+	case <-starts:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for start message")
+	}
+
+	mock.mu.Lock()
+	for _, conn := range mock.clients {
+		_ = conn.Close()
+	}
+	mock.mu.Unlock()
+
+	waitFor := func(want ConnectionEvent) {
+		t.Helper()
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("event = %v, want %v", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for event %v", want)
+		}
+	}
+	waitFor(EventDisconnected)
+
+	// Before the reconnect attempt, there's no connection to send a stop on
+	if err := client.Unsubscribe("sub-1"); err != nil {
+		t.Fatalf("Unsubscribe while disconnected failed: %v", err)
+	}
+	waitFor(EventReconnected)
+
+	select {
+	case msg := <-starts:
+		t.Errorf("reconnect restarted the stopped subscription: %+v", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebSocketClient_LogsDisconnect(t *testing.T) {
+	mock := newMockWebSocketServer()
+	defer mock.close()
+	go func() {
+		for range mock.messages {
+		}
+	}()
+
+	client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "", "")
+	client.url = mock.url()
+	client.reconnectDelay = 10 * time.Millisecond
+
+	// Records are written before the event handler runs, so the buffer is
+	// safe to read once EventReconnected arrives
+	var logs bytes.Buffer
+	client.SetLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+	reconnected := make(chan struct{})
+	client.SetEventHandler(func(event ConnectionEvent, err error) {
+		if event == EventReconnected {
+			close(reconnected)
+		}
+	})
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	mock.mu.Lock()
+	for _, conn := range mock.clients {
+		_ = conn.Close()
+	}
+	mock.mu.Unlock()
+
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for reconnect")
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	find := func(msg string) map[string]any {
+		for _, record := range records {
+			if record["msg"] == msg {
+				return record
+			}
+		}
+		t.Fatalf("no %q record in log:\n%s", msg, logs.String())
+		return nil
+	}
+	if disconnected := find("websocket disconnected"); disconnected["level"] != "WARN" || disconnected["error"] == "" {
+		t.Errorf("disconnect record = %v, want a warning with the error", disconnected)
+	}
+	if reconnect := find("websocket reconnected"); reconnect["attempts"] != float64(1) {
+		t.Errorf("reconnect record = %v, want attempts 1", reconnect)
+	}
+}
+
+func TestWebSocketClient_GivesUpAfterMaxReconnects(t *testing.T) {
+	mock := newMockWebSocketServer()
+	go func() {
+		for range mock.messages {
+		}
+	}()
+
+	client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "", "")
+	client.url = mock.url()
+	client.reconnectDelay = time.Millisecond
+
+	var gaveUp atomic.Bool
+	client.SetEventHandler(func(event ConnectionEvent, err error) {
+		if event == EventGaveUp && err != nil {
+			gaveUp.Store(true)
+		}
+	})
+
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	// Take the server down entirely so every reconnect fails
+	mock.close()
+
+	select {
+	case <-client.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done() not closed after reconnects were exhausted")
+	}
+
+	if !gaveUp.Load() {
+		t.Error("expected EventGaveUp with the last reconnect error")
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Close after giving up returned %v", err)
+	}
+}
+
+func TestVehicleStateSubscriptionQuery(t *testing.T) {
+	full := vehicleStateSubscriptionQuery(VehicleStateFields)
+	charging := vehicleStateSubscriptionQuery(ChargingStateFields)
+
+	for _, q := range []string{full, charging} {
+		for _, want := range []string{
+			"subscription VehicleStateUpdates($vehicleId: String!)",
+			"vehicleState(id: $vehicleId)",
+			"batteryLevel { value timeStamp }",
+			"chargeState { value timeStamp }",
+			"rangeEstimate { value timeStamp }",
+		} {
+			if !strings.Contains(q, want) {
+				t.Errorf("query missing %q:\n%s", want, q)
+			}
+		}
+	}
+
+	for _, field := range []string{"isLocked", "cabinTemp"} {
+		if !strings.Contains(full, field) {
+			t.Errorf("full query should request %s", field)
+		}
+		if strings.Contains(charging, field) {
+			t.Errorf("charging query should not request %s:\n%s", field, charging)
+		}
+	}
+}
+
+func TestSubscribeToVehicleStateFields_NoFields(t *testing.T) {
+	if _, err := SubscribeToVehicleStateFields(context.Background(), NewWebSocketClient(nil, "", ""), "vehicle-123", nil); err == nil {
+		t.Error("SubscribeToVehicleStateFields() should reject an empty field list")
+	}
+}
+
+func TestWebSocketClient_SubscriptionEndHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		message WebSocketMessage
+		wantMsg string
+	}{
+		{
+			name:    "complete",
+			message: WebSocketMessage{ID: "sub-1", Type: "complete"},
+			wantMsg: "subscription ended by server",
+		},
+		{
+			name: "error with message",
+			message: WebSocketMessage{
+				ID:      "sub-1",
+				Type:    "error",
+				Payload: map[string]interface{}{"message": "session expired"},
+			},
+			wantMsg: "session expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "csrf-123", "app-session-123")
+			client.subscriptions["sub-1"] = subscription{callback: func(data map[string]interface{}) {}}
+
+			var got error
+			calls := 0
+			client.OnSubscriptionEnd("sub-1", func(err error) {
+				got = err
+				calls++
+			})
+
+			client.handleMessage(tt.message)
+			client.handleMessage(tt.message) // repeated end is ignored
+
+			if calls != 1 {
+				t.Fatalf("handler called %d times, want 1", calls)
+			}
+			if !errors.Is(got, ErrSubscriptionEnded) || !strings.Contains(got.Error(), tt.wantMsg) {
+				t.Errorf("handler error = %v, want ErrSubscriptionEnded containing %q", got, tt.wantMsg)
+			}
+			if _, ok := client.subscriptions["sub-1"]; ok {
+				t.Error("ended subscription should not be replayed on reconnect")
+			}
+		})
+	}
+}
+
+func TestVehicleStateSubscription_Done(t *testing.T) {
+	mock := newMockWebSocketServer()
+	defer mock.close()
+
+	wsClient := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "csrf-123", "app-session-123")
+
+	ctx := context.Background()
+	dialer := websocket.Dialer{}
+	conn, _, err := dialer.DialContext(ctx, mock.url(), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	wsClient.mu.Lock()
+	wsClient.conn = conn
+	wsClient.closed = false
+	wsClient.closeSignal = make(chan struct{})
+	wsClient.mu.Unlock()
+	defer func() { _ = wsClient.Close() }()
+
+	subscription, err := SubscribeToChargingState(ctx, wsClient, "vehicle-123")
+	if err != nil {
+		t.Fatalf("SubscribeToChargingState failed: %v", err)
+	}
+	if subscription.Err() != nil {
+		t.Error("Err() should be nil while the subscription is active")
+	}
+
+	// Server ends the subscription
+	wsClient.handleMessage(WebSocketMessage{ID: "vehicle-state-vehicle-123", Type: "complete"})
+
+	select {
+	case <-subscription.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() should be closed after the server completes the subscription")
+	}
+	if !errors.Is(subscription.Err(), ErrSubscriptionEnded) {
+		t.Errorf("Err() = %v, want ErrSubscriptionEnded", subscription.Err())
+	}
+	select {
+	case err := <-subscription.Errors():
+		t.Errorf("Errors() delivered %v for a plain complete", err)
+	default:
+	}
+}
+
+func TestVehicleStateSubscription_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{
+			name:    "error object",
+			payload: map[string]interface{}{"message": "Unauthorized"},
+			want:    "Unauthorized",
+		},
+		{
+			name: "list of GraphQL errors",
+			payload: []interface{}{
+				map[string]interface{}{"message": "Vehicle not found"},
+				map[string]interface{}{"message": "Not subscribed"},
+			},
+			want: "Vehicle not found; Not subscribed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebSocketServer()
+			defer mock.close()
+			starts := make(chan struct{}, 1)
+			go func() {
+				for msg := range mock.messages {
+					if msg.Type == "start" {
+						starts <- struct{}{}
+					}
+				}
+			}()
+
+			client := NewWebSocketClient(&Credentials{AccessToken: "test-token"}, "", "")
+			client.url = mock.url()
+			ctx := context.Background()
+			if err := client.Connect(ctx); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer func() { _ = client.Close() }()
+
+			subscription, err := SubscribeToVehicleState(ctx, client, "vehicle-123")
+			if err != nil {
+				t.Fatalf("SubscribeToVehicleState failed: %v", err)
+			}
+			select {
+			case <-starts:
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for start message")
+			}
+
+			// Nothing is reported before the server rejects the subscription
+			select {
+			case err := <-subscription.Errors():
+				t.Fatalf("unexpected error before the error frame: %v", err)
+			default:
+			}
+
+			mock.mu.Lock()
+			conn := mock.clients[0]
+			mock.mu.Unlock()
+			frame := map[string]interface{}{"id": "vehicle-state-vehicle-123", "type": "error", "payload": tt.payload}
+			if err := mock.writeJSON(conn, frame); err != nil {
+				t.Fatalf("write error frame: %v", err)
+			}
+
+			select {
+			case err := <-subscription.Errors():
+				if !errors.Is(err, ErrSubscriptionEnded) || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("Errors() delivered %v, want ErrSubscriptionEnded containing %q", err, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for the subscription error")
+			}
+			select {
+			case <-subscription.Done():
+			default:
+				t.Error("Done() should be closed after an error frame")
+			}
+			select {
+			case <-client.Done():
+				t.Error("an error frame must not be treated as a dropped connection")
+			default:
+			}
+		})
+	}
+}
@@ -4,19 +4,28 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 )
 
+// ErrCorrupt is returned by NewStore when the database file is damaged or
+// not a SQLite database at all. Reset backs it up and starts over.
+var ErrCorrupt = errors.New("database is corrupt")
+
 // Store manages local persistence of vehicle state snapshots
 type Store struct {
-	db *sql.DB
+	db      *sql.DB
+	saveRaw bool
 }
 
-// NewStore creates a new store at the given database path
+// NewStore creates a new store at the given database path. A damaged
+// database file fails with ErrCorrupt.
 func NewStore(dbPath string) (*Store, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
@@ -26,7 +35,12 @@ func NewStore(dbPath string) (*Store, error) {
 	// Enable Write-Ahead Logging for better concurrency
 	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
 		_ = db.Close()
-		return nil, fmt.Errorf("enable WAL: %w", err)
+		return nil, corruptionError(fmt.Errorf("enable WAL: %w", err))
+	}
+
+	if err := checkIntegrity(db); err != nil {
+		_ = db.Close()
+		return nil, err
 	}
 
 	// Enable foreign keys
@@ -46,6 +60,59 @@ func NewStore(dbPath string) (*Store, error) {
 	return store, nil
 }
 
+// checkIntegrity runs SQLite's quick check, so damage left by a partial
+// write is caught on open rather than mid-query. Unlike the full integrity
+// check it skips verifying indexes against their tables, which is what makes
+// the full check slow on a large history.
+func checkIntegrity(db *sql.DB) error {
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check(1)").Scan(&result); err != nil {
+		return corruptionError(fmt.Errorf("quick check: %w", err))
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrCorrupt, result)
+	}
+	return nil
+}
+
+// corruptionError wraps err with ErrCorrupt when SQLite reports a damaged or
+// foreign file.
+func corruptionError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrCorrupt || sqliteErr.Code == sqlite3.ErrNotADB) {
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	return err
+}
+
+// Reset moves the database at dbPath (and its WAL files) aside to a
+// timestamped ".corrupt" backup and creates a fresh, empty store in its
+// place. It returns the store and the backup path.
+func Reset(dbPath string) (*Store, string, error) {
+	backup := fmt.Sprintf("%s.corrupt-%s", dbPath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(dbPath, backup); err != nil {
+		return nil, "", fmt.Errorf("back up database: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(dbPath+suffix, backup+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("back up database %s file: %w", strings.TrimPrefix(suffix, "-"), err)
+		}
+	}
+
+	store, err := NewStore(dbPath)
+	if err != nil {
+		return nil, backup, err
+	}
+	return store, backup, nil
+}
+
+// SetSaveRaw sets whether SaveState also keeps the raw API response a state
+// was parsed from, for debugging parser problems after the fact. Off by
+// default, as raw responses are several times the size of a state.
+func (s *Store) SetSaveRaw(saveRaw bool) {
+	s.saveRaw = saveRaw
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -95,17 +162,59 @@ func (s *Store) initSchema() error {
 
 		CREATE INDEX IF NOT EXISTS idx_vehicle_states_vehicle_timestamp
 			ON vehicle_states(vehicle_id, timestamp DESC);
+
+		CREATE TABLE IF NOT EXISTS charging_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			vehicle_id TEXT NOT NULL,
+			start_time DATETIME NOT NULL,
+			end_time DATETIME NOT NULL,
+			start_level REAL,
+			end_level REAL,
+			energy_added REAL,
+			avg_rate REAL,
+			peak_rate REAL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(vehicle_id, start_time)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_charging_sessions_vehicle_start
+			ON charging_sessions(vehicle_id, start_time DESC);
+
+		CREATE TABLE IF NOT EXISTS raw_states (
+			state_id INTEGER PRIMARY KEY REFERENCES vehicle_states(id) ON DELETE CASCADE,
+			raw_json TEXT NOT NULL
+		);
 	`
 
 	_, err := s.db.Exec(schema)
 	return err
 }
 
-// SaveState stores a vehicle state snapshot
+// SaveState stores a vehicle state snapshot. States with missing telemetry
+// are skipped: their zero battery and range would read as real readings to
+// everything built on history.
 func (s *Store) SaveState(ctx context.Context, state *model.VehicleState) error {
 	if state == nil {
 		return fmt.Errorf("state is nil")
 	}
+	if state.TelemetryMissing {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.saveState(ctx, tx, state); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveState inserts a state, and its raw response when saving those, in tx.
+func (s *Store) saveState(ctx context.Context, tx *sql.Tx, state *model.VehicleState) error {
 
 	// Serialize complex fields to JSON
 	doorsJSON, err := json.Marshal(state.Doors)
@@ -169,7 +278,7 @@ func (s *Store) SaveState(ctx context.Context, state *model.VehicleState) error
 		)
 	`
 
-	_, err = s.db.ExecContext(ctx, query,
+	args := []any{
 		state.VehicleID, state.VIN, state.Name, state.Model, state.UpdatedAt,
 		state.BatteryLevel, state.BatteryCapacity, state.RangeEstimate, state.RangeStatus,
 		state.ChargeState, state.ChargeLimit, state.ChargingRate, timeToCharge,
@@ -178,9 +287,55 @@ func (s *Store) SaveState(ctx context.Context, state *model.VehicleState) error
 		latitude, longitude,
 		string(doorsJSON), string(windowsJSON), state.Frunk, state.Liftgate, tonneauCover,
 		string(tireJSON), state.ReadyScore, string(stateJSON),
-	)
+	}
 
-	return err
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if !s.saveRaw || len(state.Raw) == 0 {
+		return nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get state id: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO raw_states (state_id, raw_json) VALUES (?, ?)
+	`, id, string(state.Raw)); err != nil {
+		return fmt.Errorf("save raw state: %w", err)
+	}
+	return nil
+}
+
+// GetRawState retrieves the raw API response of a vehicle's latest state
+// saved at or before the given time (the latest overall when at is zero),
+// along with that state's timestamp. It returns nil when no such state has
+// a raw response, i.e. none were saved with SetSaveRaw.
+func (s *Store) GetRawState(ctx context.Context, vehicleID string, at time.Time) (json.RawMessage, time.Time, error) {
+	if at.IsZero() {
+		at = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	var raw string
+	var timestamp time.Time
+	err := s.db.QueryRowContext(ctx, `
+		SELECT r.raw_json, v.timestamp
+		FROM raw_states r
+		JOIN vehicle_states v ON v.id = r.state_id
+		WHERE v.vehicle_id = ? AND v.timestamp <= ?
+		ORDER BY v.timestamp DESC
+		LIMIT 1
+	`, vehicleID, at).Scan(&raw, &timestamp)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil // No raw state found
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("query raw state: %w", err)
+	}
+
+	return json.RawMessage(raw), timestamp, nil
 }
 
 // GetLatestState retrieves the most recent state for a vehicle
@@ -210,6 +365,72 @@ func (s *Store) GetLatestState(ctx context.Context, vehicleID string) (*model.Ve
 	return &state, nil
 }
 
+// GetStateNearest retrieves the vehicle's stored state whose timestamp is
+// closest to t, before or after it. It returns nil when none is stored.
+func (s *Store) GetStateNearest(ctx context.Context, vehicleID string, t time.Time) (*model.VehicleState, error) {
+	queries := []string{`
+		SELECT state_json, timestamp
+		FROM vehicle_states
+		WHERE vehicle_id = ? AND timestamp <= ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, `
+		SELECT state_json, timestamp
+		FROM vehicle_states
+		WHERE vehicle_id = ? AND timestamp > ?
+		ORDER BY timestamp ASC
+		LIMIT 1
+	`}
+
+	var nearest string
+	var gap time.Duration
+	for _, query := range queries {
+		var stateJSON string
+		var timestamp time.Time
+		err := s.db.QueryRowContext(ctx, query, vehicleID, t).Scan(&stateJSON, &timestamp)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query nearest state: %w", err)
+		}
+
+		d := timestamp.Sub(t).Abs()
+		if nearest == "" || d < gap {
+			nearest, gap = stateJSON, d
+		}
+	}
+	if nearest == "" {
+		return nil, nil // No state found
+	}
+
+	var state model.VehicleState
+	if err := json.Unmarshal([]byte(nearest), &state); err != nil {
+		return nil, fmt.Errorf("unmarshal state: %w", err)
+	}
+	return &state, nil
+}
+
+// GetLastSyncTime returns the timestamp of the newest state stored for a
+// vehicle, i.e. when its data was last synced, or the zero time if none is.
+func (s *Store) GetLastSyncTime(ctx context.Context, vehicleID string) (time.Time, error) {
+	var timestamp time.Time
+	err := s.db.QueryRowContext(ctx, `
+		SELECT timestamp
+		FROM vehicle_states
+		WHERE vehicle_id = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, vehicleID).Scan(&timestamp)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil // Never synced
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query last sync time: %w", err)
+	}
+	return timestamp, nil
+}
+
 // GetStateHistory retrieves historical states for a vehicle
 func (s *Store) GetStateHistory(ctx context.Context, vehicleID string, since time.Time, limit int) ([]*model.VehicleState, error) {
 	query := `
@@ -248,8 +469,65 @@ func (s *Store) GetStateHistory(ctx context.Context, vehicleID string, since tim
 	return states, nil
 }
 
+// GetDailyPeaks returns, for each day since the given time, the state with
+// the highest battery level at or above minLevel, newest first. It spans
+// long histories in at most one state per day.
+func (s *Store) GetDailyPeaks(ctx context.Context, vehicleID string, since time.Time, minLevel float64) ([]*model.VehicleState, error) {
+	// SQLite takes the bare columns from the row with the MAX
+	query := `
+		SELECT state_json, MAX(battery_level)
+		FROM vehicle_states
+		WHERE vehicle_id = ? AND timestamp >= ? AND battery_level >= ?
+		GROUP BY substr(timestamp, 1, 10)
+		ORDER BY timestamp DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, vehicleID, since, minLevel)
+	if err != nil {
+		return nil, fmt.Errorf("query daily peaks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var states []*model.VehicleState
+	for rows.Next() {
+		var stateJSON string
+		var level float64
+		if err := rows.Scan(&stateJSON, &level); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		var state model.VehicleState
+		if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+			return nil, fmt.Errorf("unmarshal state: %w", err)
+		}
+
+		states = append(states, &state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return states, nil
+}
+
 // GetStates retrieves states within a time range
 func (s *Store) GetStates(ctx context.Context, vehicleID string, start, end time.Time) ([]*model.VehicleState, error) {
+	var states []*model.VehicleState
+	err := s.StreamStates(ctx, vehicleID, start, end, func(state *model.VehicleState) error {
+		states = append(states, state)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// StreamStates calls fn for each state in a time range, newest first,
+// without buffering the result set. It stops at the first error from fn
+// and returns it.
+func (s *Store) StreamStates(ctx context.Context, vehicleID string, start, end time.Time, fn func(*model.VehicleState) error) error {
 	query := `
 		SELECT state_json
 		FROM vehicle_states
@@ -259,26 +537,174 @@ func (s *Store) GetStates(ctx context.Context, vehicleID string, start, end time
 
 	rows, err := s.db.QueryContext(ctx, query, vehicleID, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("query states: %w", err)
+		return fmt.Errorf("query states: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	var states []*model.VehicleState
 	for rows.Next() {
 		var stateJSON string
 		if err := rows.Scan(&stateJSON); err != nil {
-			return nil, fmt.Errorf("scan row: %w", err)
+			return fmt.Errorf("scan row: %w", err)
 		}
 
 		var state model.VehicleState
 		if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
-			return nil, fmt.Errorf("unmarshal state: %w", err)
+			return fmt.Errorf("unmarshal state: %w", err)
+		}
+
+		if err := fn(&state); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CountStates returns the number of states stored for a vehicle in a time
+// range.
+func (s *Store) CountStates(ctx context.Context, vehicleID string, start, end time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM vehicle_states
+		WHERE vehicle_id = ? AND timestamp BETWEEN ? AND ?
+	`, vehicleID, start, end).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count states: %w", err)
+	}
+	return count, nil
+}
+
+// LastStateID returns the row ID of the most recently saved state for a
+// vehicle, or 0 if none is stored. Row IDs only grow, so they mark a point
+// in save order that StatesSavedAfter can continue from.
+func (s *Store) LastStateID(ctx context.Context, vehicleID string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(id), 0)
+		FROM vehicle_states
+		WHERE vehicle_id = ?
+	`, vehicleID).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("query last state id: %w", err)
+	}
+	return id, nil
+}
+
+// StatesSavedAfter returns the states saved for a vehicle after row afterID,
+// in the order they were saved, and the row ID of the last one (afterID if
+// there are none). Unlike a timestamp query, it sees every new row, even
+// one with the same or an older timestamp.
+func (s *Store) StatesSavedAfter(ctx context.Context, vehicleID string, afterID int64) ([]*model.VehicleState, int64, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, state_json
+		FROM vehicle_states
+		WHERE vehicle_id = ? AND id > ?
+		ORDER BY id
+	`, vehicleID, afterID)
+	if err != nil {
+		return nil, afterID, fmt.Errorf("query new states: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	lastID := afterID
+	var states []*model.VehicleState
+	for rows.Next() {
+		var id int64
+		var stateJSON string
+		if err := rows.Scan(&id, &stateJSON); err != nil {
+			return nil, afterID, fmt.Errorf("scan row: %w", err)
+		}
+
+		var state model.VehicleState
+		if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+			return nil, afterID, fmt.Errorf("unmarshal state: %w", err)
 		}
 
 		states = append(states, &state)
+		lastID = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, afterID, fmt.Errorf("rows error: %w", err)
+	}
+	return states, lastID, nil
+}
+
+// SaveChargingSession stores a completed charging session. Saving a session
+// with the same vehicle and start time again replaces the earlier row.
+func (s *Store) SaveChargingSession(ctx context.Context, session *model.ChargingSession) error {
+	if session == nil {
+		return fmt.Errorf("session is nil")
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO charging_sessions (
+			vehicle_id, start_time, end_time,
+			start_level, end_level, energy_added,
+			avg_rate, peak_rate
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		session.VehicleID, session.StartTime, session.EndTime,
+		session.StartLevel, session.EndLevel, session.EnergyAdded,
+		session.AvgRateKW, session.PeakRateKW,
+	)
+	if err != nil {
+		return fmt.Errorf("insert charging session: %w", err)
+	}
+
+	return nil
+}
+
+// GetChargingSessions retrieves charging sessions for a vehicle that started
+// at or after since, newest first.
+func (s *Store) GetChargingSessions(ctx context.Context, vehicleID string, since time.Time) ([]*model.ChargingSession, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT vehicle_id, start_time, end_time,
+			start_level, end_level, energy_added,
+			avg_rate, peak_rate
+		FROM charging_sessions
+		WHERE vehicle_id = ? AND start_time >= ?
+		ORDER BY start_time DESC
+	`, vehicleID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query charging sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sessions []*model.ChargingSession
+	for rows.Next() {
+		var session model.ChargingSession
+		if err := rows.Scan(
+			&session.VehicleID, &session.StartTime, &session.EndTime,
+			&session.StartLevel, &session.EndLevel, &session.EnergyAdded,
+			&session.AvgRateKW, &session.PeakRateKW,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return states, rows.Err()
+	return sessions, nil
+}
+
+// CountStatesBefore returns the number of states DeleteOldStates would
+// remove for the same time, across all vehicles.
+func (s *Store) CountStatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM vehicle_states
+		WHERE timestamp < ?
+	`, before).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count old states: %w", err)
+	}
+	return count, nil
 }
 
 // DeleteOldStates removes states older than the given time
@@ -292,9 +718,133 @@ func (s *Store) DeleteOldStates(ctx context.Context, before time.Time) (int64, e
 		return 0, err
 	}
 
+	// foreign_keys is per connection, so the cascade can't be relied on
+	if _, err := s.db.ExecContext(ctx, `
+		DELETE FROM raw_states
+		WHERE state_id NOT IN (SELECT id FROM vehicle_states)
+	`); err != nil {
+		return 0, fmt.Errorf("delete raw states: %w", err)
+	}
+
 	return result.RowsAffected()
 }
 
+// Vacuum rebuilds the database file to reclaim space freed by deletes.
+// SQLite's VACUUM cannot run inside a transaction, rewrites the whole file
+// (temporarily needing up to twice its size on disk), and can take a while
+// on large databases, so call it explicitly rather than after every delete.
+func (s *Store) Vacuum(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
+// ImportStates copies every state from the database at path (e.g. one left
+// at a legacy location) into this store and returns how many were copied.
+// The source is opened read-only and never modified. Charging sessions are
+// not copied; they are rebuilt from the imported states where needed.
+func (s *Store) ImportStates(ctx context.Context, path string) (int, error) {
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer func() { _ = src.Close() }()
+
+	rows, err := src.QueryContext(ctx, `
+		SELECT state_json
+		FROM vehicle_states
+		ORDER BY timestamp ASC
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("query %s: %w", path, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	imported := 0
+	for rows.Next() {
+		var stateJSON string
+		if err := rows.Scan(&stateJSON); err != nil {
+			return imported, fmt.Errorf("scan row: %w", err)
+		}
+
+		var state model.VehicleState
+		if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+			return imported, fmt.Errorf("unmarshal state: %w", err)
+		}
+		if err := s.SaveState(ctx, &state); err != nil {
+			return imported, fmt.Errorf("save state: %w", err)
+		}
+		imported++
+	}
+
+	return imported, rows.Err()
+}
+
+// ExportStates copies a vehicle's states in a time range into a new
+// database at path and returns how many were written. transform, if not nil,
+// is applied to each state before it is saved. The file must not already
+// exist; it is left as a single file, without WAL sidecars, ready to share.
+// On any error the file is removed.
+func (s *Store) ExportStates(ctx context.Context, path, vehicleID string, start, end time.Time, transform func(*model.VehicleState) *model.VehicleState) (int, error) {
+	if _, err := os.Stat(path); err == nil {
+		return 0, fmt.Errorf("%s already exists", path)
+	}
+
+	written, err := s.exportStates(ctx, path, vehicleID, start, end, transform)
+	if err != nil {
+		// Don't leave a partial export behind
+		for _, p := range []string{path, path + "-wal", path + "-shm"} {
+			_ = os.Remove(p)
+		}
+		return 0, err
+	}
+	return written, nil
+}
+
+// exportStates writes the states for ExportStates into a new database at
+// path in a single transaction.
+func (s *Store) exportStates(ctx context.Context, path, vehicleID string, start, end time.Time, transform func(*model.VehicleState) *model.VehicleState) (written int, err error) {
+	dst, err := NewStore(path)
+	if err != nil {
+		return 0, fmt.Errorf("create %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := dst.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("close %s: %w", path, closeErr)
+		}
+	}()
+
+	tx, err := dst.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = s.StreamStates(ctx, vehicleID, start, end, func(state *model.VehicleState) error {
+		if transform != nil {
+			state = transform(state)
+		}
+		if err := dst.saveState(ctx, tx, state); err != nil {
+			return fmt.Errorf("save state: %w", err)
+		}
+		written++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit %s: %w", path, err)
+	}
+
+	// Fold the WAL back into the main file
+	if _, err := dst.db.ExecContext(ctx, "PRAGMA journal_mode=DELETE"); err != nil {
+		return 0, fmt.Errorf("finalize %s: %w", path, err)
+	}
+	return written, nil
+}
+
 // GetStats returns storage statistics
 func (s *Store) GetStats(ctx context.Context) (*StoreStats, error) {
 	var stats StoreStats
@@ -2,8 +2,11 @@ package store
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -184,6 +187,85 @@ func TestGetLatestState(t *testing.T) {
 	}
 }
 
+func TestGetLastSyncTime(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	// A vehicle without rows has never synced
+	synced, err := store.GetLastSyncTime(ctx, "vehicle-123")
+	if err != nil {
+		t.Fatalf("GetLastSyncTime failed: %v", err)
+	}
+	if !synced.IsZero() {
+		t.Errorf("GetLastSyncTime without states = %v, want zero", synced)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	saveTestStates(t, store, ctx, now, 3, time.Minute, func(i int) float64 { return 80 })
+
+	synced, err = store.GetLastSyncTime(ctx, "vehicle-123")
+	if err != nil {
+		t.Fatalf("GetLastSyncTime failed: %v", err)
+	}
+	if want := now.Add(2 * time.Minute); !synced.Equal(want) {
+		t.Errorf("GetLastSyncTime = %v, want %v", synced, want)
+	}
+}
+
+func TestGetStateNearest(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	state, err := store.GetStateNearest(ctx, "vehicle-123", time.Now())
+	if err != nil {
+		t.Fatalf("GetStateNearest failed: %v", err)
+	}
+	if state != nil {
+		t.Fatalf("GetStateNearest without states = %+v, want nil", state)
+	}
+
+	// States an hour apart with battery 80, 81, 82
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	saveTestStates(t, store, ctx, start, 3, time.Hour, func(i int) float64 { return 80 + float64(i) })
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want float64
+	}{
+		{"before all states", start.Add(-24 * time.Hour), 80},
+		{"exact match", start.Add(time.Hour), 81},
+		{"closer to the earlier state", start.Add(80 * time.Minute), 81},
+		{"closer to the later state", start.Add(100 * time.Minute), 82},
+		{"after all states", start.Add(24 * time.Hour), 82},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := store.GetStateNearest(ctx, "vehicle-123", tt.at)
+			if err != nil {
+				t.Fatalf("GetStateNearest failed: %v", err)
+			}
+			if state == nil || state.BatteryLevel != tt.want {
+				t.Errorf("GetStateNearest(%v) = %+v, want the state with battery %v", tt.at, state, tt.want)
+			}
+		})
+	}
+
+	if other, err := store.GetStateNearest(ctx, "vehicle-456", start); err != nil || other != nil {
+		t.Errorf("GetStateNearest for another vehicle = %+v, %v; want nil", other, err)
+	}
+}
+
 func TestGetLatestState_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
@@ -236,6 +318,36 @@ func TestGetStateHistory(t *testing.T) {
 	}
 }
 
+func TestGetDailyPeaks(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Three days of states every 6 hours
+	ctx := context.Background()
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	levels := []float64{70, 85, 90, 82, 60, 81, 79, 50, 95, 94, 93, 92}
+	saveTestStates(t, store, ctx, start, len(levels), 6*time.Hour, func(i int) float64 { return levels[i] })
+
+	peaks, err := store.GetDailyPeaks(ctx, "vehicle-123", start.Add(-time.Hour), 80)
+	if err != nil {
+		t.Fatalf("GetDailyPeaks failed: %v", err)
+	}
+	var got []float64
+	for _, state := range peaks {
+		got = append(got, state.BatteryLevel)
+	}
+	if want := []float64{95, 81, 90}; !slices.Equal(got, want) {
+		t.Errorf("daily peaks = %v, want %v (newest day first)", got, want)
+	}
+
+	if peaks, err := store.GetDailyPeaks(ctx, "vehicle-123", start, 96); err != nil || len(peaks) != 0 {
+		t.Errorf("GetDailyPeaks above every level = %d states, %v; want none", len(peaks), err)
+	}
+}
+
 func TestGetStates(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
@@ -263,6 +375,99 @@ func TestGetStates(t *testing.T) {
 	}
 }
 
+func TestStreamStates(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	saveTestStates(t, store, ctx, now, 10, time.Hour, func(i int) float64 { return float64(50 + i) })
+
+	start := now.Add(3 * time.Hour)
+	end := now.Add(7 * time.Hour)
+
+	count, err := store.CountStates(ctx, "vehicle-123", start, end)
+	if err != nil {
+		t.Fatalf("CountStates failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("CountStates = %d, want 5", count)
+	}
+
+	var levels []float64
+	err = store.StreamStates(ctx, "vehicle-123", start, end, func(state *model.VehicleState) error {
+		levels = append(levels, state.BatteryLevel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamStates failed: %v", err)
+	}
+	if len(levels) != 5 || levels[0] != 57 || levels[4] != 53 {
+		t.Errorf("StreamStates levels = %v, want 57..53 newest first", levels)
+	}
+
+	// An error from the callback stops the stream
+	errStop := errors.New("stop")
+	calls := 0
+	err = store.StreamStates(ctx, "vehicle-123", start, end, func(*model.VehicleState) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("StreamStates = %v after %d calls, want %v after 1", err, calls, errStop)
+	}
+}
+
+func TestStatesSavedAfter(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	lastID, err := store.LastStateID(ctx, "vehicle-123")
+	if err != nil || lastID != 0 {
+		t.Fatalf("LastStateID on empty store = %d, %v; want 0, nil", lastID, err)
+	}
+
+	saveTestStates(t, store, ctx, now, 3, time.Hour, func(i int) float64 { return float64(50 + i) })
+	lastID, err = store.LastStateID(ctx, "vehicle-123")
+	if err != nil {
+		t.Fatalf("LastStateID failed: %v", err)
+	}
+
+	// Nothing new yet
+	states, id, err := store.StatesSavedAfter(ctx, "vehicle-123", lastID)
+	if err != nil || len(states) != 0 || id != lastID {
+		t.Fatalf("StatesSavedAfter = %d states, id %d, %v; want none, id %d", len(states), id, err, lastID)
+	}
+
+	// New rows are returned in save order, even with older timestamps
+	saveTestStates(t, store, ctx, now.Add(-time.Hour), 2, time.Minute, func(i int) float64 { return float64(80 + i) })
+	states, id, err = store.StatesSavedAfter(ctx, "vehicle-123", lastID)
+	if err != nil {
+		t.Fatalf("StatesSavedAfter failed: %v", err)
+	}
+	if len(states) != 2 || states[0].BatteryLevel != 80 || states[1].BatteryLevel != 81 {
+		t.Errorf("StatesSavedAfter returned %d states, want 80 then 81", len(states))
+	}
+	if id != lastID+2 {
+		t.Errorf("StatesSavedAfter id = %d, want %d", id, lastID+2)
+	}
+
+	if other, err := store.LastStateID(ctx, "other-vehicle"); err != nil || other != 0 {
+		t.Errorf("LastStateID(other-vehicle) = %d, %v; want 0, nil", other, err)
+	}
+}
+
 func TestDeleteOldStates(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
@@ -309,6 +514,15 @@ func TestDeleteOldStates(t *testing.T) {
 		}
 	}
 
+	// Counting matches what is deleted, and deletes nothing
+	wouldDelete, err := store.CountStatesBefore(ctx, now)
+	if err != nil {
+		t.Fatalf("CountStatesBefore failed: %v", err)
+	}
+	if wouldDelete != 5 {
+		t.Errorf("CountStatesBefore = %d, want 5", wouldDelete)
+	}
+
 	// Delete states older than now
 	deleted, err := store.DeleteOldStates(ctx, now)
 	if err != nil {
@@ -330,6 +544,70 @@ func TestDeleteOldStates(t *testing.T) {
 	}
 }
 
+func TestGetRawState(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	save := func(at time.Time, raw string) {
+		t.Helper()
+		state := &model.VehicleState{VehicleID: "vehicle-123", UpdatedAt: at, Raw: []byte(raw)}
+		if err := store.SaveState(ctx, state); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+	rawCount := func() int {
+		t.Helper()
+		var count int
+		if err := store.db.QueryRow("SELECT COUNT(*) FROM raw_states").Scan(&count); err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return count
+	}
+
+	// Off by default
+	save(now.Add(-3*time.Hour), `{"batteryLevel":{"value":70}}`)
+	if n := rawCount(); n != 0 {
+		t.Fatalf("raw responses saved without SetSaveRaw: %d", n)
+	}
+	if raw, _, err := store.GetRawState(ctx, "vehicle-123", time.Time{}); err != nil || raw != nil {
+		t.Fatalf("GetRawState = %s, %v; want nothing", raw, err)
+	}
+
+	store.SetSaveRaw(true)
+	save(now.Add(-2*time.Hour), `{"batteryLevel":{"value":80}}`)
+	save(now.Add(-time.Hour), `{"batteryLevel":{"value":90}}`)
+	save(now, "") // e.g. a WebSocket update, which has no raw response
+
+	raw, at, err := store.GetRawState(ctx, "vehicle-123", time.Time{})
+	if err != nil {
+		t.Fatalf("GetRawState failed: %v", err)
+	}
+	if string(raw) != `{"batteryLevel":{"value":90}}` || !at.Equal(now.Add(-time.Hour)) {
+		t.Errorf("latest raw state = %s at %v, want value 90 at %v", raw, at, now.Add(-time.Hour))
+	}
+
+	raw, _, err = store.GetRawState(ctx, "vehicle-123", now.Add(-90*time.Minute))
+	if err != nil {
+		t.Fatalf("GetRawState failed: %v", err)
+	}
+	if string(raw) != `{"batteryLevel":{"value":80}}` {
+		t.Errorf("raw state before -90m = %s, want value 80", raw)
+	}
+
+	// Pruning a state drops its raw response too
+	if _, err := store.DeleteOldStates(ctx, now.Add(-90*time.Minute)); err != nil {
+		t.Fatalf("DeleteOldStates failed: %v", err)
+	}
+	if n := rawCount(); n != 1 {
+		t.Errorf("raw responses left after pruning = %d, want 1", n)
+	}
+}
+
 func TestGetStats(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
@@ -405,3 +683,243 @@ func TestGetStats(t *testing.T) {
 		t.Error("DatabaseSize is 0")
 	}
 }
+
+func TestVacuum(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 200; i++ {
+		state := &model.VehicleState{
+			VehicleID: "vehicle-123",
+			Name:      strings.Repeat("x", 500), // Bulk up rows so pages get freed
+			UpdatedAt: now.Add(-time.Duration(i+1) * time.Hour),
+		}
+		if err := store.SaveState(ctx, state); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	if _, err := store.DeleteOldStates(ctx, now); err != nil {
+		t.Fatalf("DeleteOldStates failed: %v", err)
+	}
+
+	before, err := store.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+
+	if err := store.Vacuum(ctx); err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+
+	after, err := store.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if after.DatabaseSize >= before.DatabaseSize {
+		t.Errorf("Expected database to shrink, before=%d after=%d", before.DatabaseSize, after.DatabaseSize)
+	}
+}
+
+func TestChargingSessions(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStore(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	sessions := []*model.ChargingSession{
+		{VehicleID: "vehicle-123", StartTime: now.Add(-48 * time.Hour), EndTime: now.Add(-46 * time.Hour), StartLevel: 30, EndLevel: 60, EnergyAdded: 40.5, AvgRateKW: 11, PeakRateKW: 11.5},
+		{VehicleID: "vehicle-123", StartTime: now.Add(-3 * time.Hour), EndTime: now.Add(-time.Hour), StartLevel: 50, EndLevel: 80, EnergyAdded: 40.5, AvgRateKW: 9.5, PeakRateKW: 11},
+		{VehicleID: "vehicle-456", StartTime: now.Add(-2 * time.Hour), EndTime: now, StartLevel: 10, EndLevel: 90},
+	}
+	for _, s := range sessions {
+		if err := store.SaveChargingSession(ctx, s); err != nil {
+			t.Fatalf("SaveChargingSession failed: %v", err)
+		}
+	}
+
+	// Re-saving the same session must not duplicate it
+	if err := store.SaveChargingSession(ctx, sessions[1]); err != nil {
+		t.Fatalf("SaveChargingSession (again) failed: %v", err)
+	}
+
+	got, err := store.GetChargingSessions(ctx, "vehicle-123", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetChargingSessions failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("GetChargingSessions returned %d sessions, want 1", len(got))
+	}
+	if !got[0].StartTime.Equal(sessions[1].StartTime) || !got[0].EndTime.Equal(sessions[1].EndTime) {
+		t.Errorf("session times = %v-%v, want %v-%v", got[0].StartTime, got[0].EndTime, sessions[1].StartTime, sessions[1].EndTime)
+	}
+	if got[0].SOCDelta() != 30 || got[0].EnergyAdded != 40.5 || got[0].PeakRateKW != 11 {
+		t.Errorf("session = %+v, want SOC delta 30, 40.5 kWh, peak 11 kW", got[0])
+	}
+
+	all, err := store.GetChargingSessions(ctx, "vehicle-123", time.Time{})
+	if err != nil {
+		t.Fatalf("GetChargingSessions failed: %v", err)
+	}
+	if len(all) != 2 || !all[0].StartTime.After(all[1].StartTime) {
+		t.Errorf("expected 2 sessions newest first, got %d", len(all))
+	}
+
+	if err := store.SaveChargingSession(ctx, nil); err == nil {
+		t.Error("SaveChargingSession(nil) should fail")
+	}
+}
+
+func TestExportStates(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	store, err := NewStore(filepath.Join(tmpDir, "state.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	saveTestStates(t, store, ctx, now, 5, time.Hour, func(i int) float64 { return 80 - float64(i) })
+
+	path := filepath.Join(tmpDir, "subset.db")
+	blankVIN := func(state *model.VehicleState) *model.VehicleState {
+		state.VIN = ""
+		return state
+	}
+	n, err := store.ExportStates(ctx, path, "vehicle-123", now.Add(2*time.Hour), now.Add(4*time.Hour), blankVIN)
+	if err != nil {
+		t.Fatalf("ExportStates failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("ExportStates() = %d, want 3", n)
+	}
+	if _, err := os.Stat(path + "-wal"); !os.IsNotExist(err) {
+		t.Errorf("exported database should not leave a WAL file: %v", err)
+	}
+
+	subset, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("open exported database: %v", err)
+	}
+	defer func() { _ = subset.Close() }()
+	states, err := subset.GetStates(ctx, "vehicle-123", now, now.Add(5*time.Hour))
+	if err != nil {
+		t.Fatalf("GetStates failed: %v", err)
+	}
+	if len(states) != 3 || states[0].BatteryLevel != 76 || states[2].BatteryLevel != 78 {
+		t.Fatalf("exported states = %d, want the 3 in range (76-78%%)", len(states))
+	}
+	if states[0].VIN != "" {
+		t.Errorf("transform not applied: VIN = %q", states[0].VIN)
+	}
+
+	if _, err := store.ExportStates(ctx, path, "vehicle-123", now, now.Add(5*time.Hour), nil); err == nil {
+		t.Error("ExportStates should refuse to overwrite an existing file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("refusing to overwrite should keep the existing file: %v", err)
+	}
+
+	// A failed export leaves nothing behind
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	failed := filepath.Join(tmpDir, "failed.db")
+	if _, err := store.ExportStates(canceled, failed, "vehicle-123", now, now.Add(5*time.Hour), nil); err == nil {
+		t.Fatal("ExportStates with a canceled context should fail")
+	}
+	if _, err := os.Stat(failed); !os.IsNotExist(err) {
+		t.Errorf("failed export should remove %s: %v", failed, err)
+	}
+}
+
+func TestImportStates(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	legacyPath := filepath.Join(tmpDir, "test-cli.db")
+	legacy, err := NewStore(legacyPath)
+	if err != nil {
+		t.Fatalf("NewStore (legacy) failed: %v", err)
+	}
+	saveTestStates(t, legacy, ctx, now, 3, time.Hour, func(i int) float64 { return 80 - float64(i) })
+	if err := legacy.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	before, err := os.ReadFile(legacyPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	store, err := NewStore(filepath.Join(tmpDir, "state.db"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	n, err := store.ImportStates(ctx, legacyPath)
+	if err != nil {
+		t.Fatalf("ImportStates failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("ImportStates() = %d, want 3", n)
+	}
+
+	latest, err := store.GetLatestState(ctx, "vehicle-123")
+	if err != nil || latest == nil {
+		t.Fatalf("GetLatestState failed: %v", err)
+	}
+	if latest.BatteryLevel != 78 || latest.Name != "My R1T" {
+		t.Errorf("latest imported state = %.0f%% %q, want 78%% My R1T", latest.BatteryLevel, latest.Name)
+	}
+
+	// The legacy database is copied, not moved or modified
+	after, err := os.ReadFile(legacyPath)
+	if err != nil {
+		t.Fatalf("legacy database should still exist: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("legacy database was modified by the import")
+	}
+
+	if _, err := store.ImportStates(ctx, filepath.Join(tmpDir, "missing.db")); err == nil {
+		t.Error("ImportStates() should fail for a missing database")
+	}
+}
+
+func TestNewStore_Corrupt(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	if err := os.WriteFile(dbPath, []byte(strings.Repeat("not a database ", 512)), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	_, err := NewStore(dbPath)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("NewStore error = %v, want ErrCorrupt", err)
+	}
+
+	store, backup, err := Reset(dbPath)
+	if err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if data, err := os.ReadFile(backup); err != nil || !strings.HasPrefix(string(data), "not a database") {
+		t.Errorf("backup %s should hold the corrupt file (err %v)", backup, err)
+	}
+	if err := store.SaveState(context.Background(), &model.VehicleState{VehicleID: "v1", UpdatedAt: time.Now()}); err != nil {
+		t.Errorf("fresh store should accept writes: %v", err)
+	}
+}
@@ -1,47 +1,73 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 )
 
+// Charging counts as throttled when the rate drops below this fraction of
+// the session's peak. Below throttleMinLevel a drop is not flagged.
+const (
+	throttleRatio    = 0.7
+	throttleMinLevel = 20.0
+)
+
+// History window searched for the last charge
+const (
+	sinceChargeHistoryWindow = 30 * 24 * time.Hour
+	sinceChargeHistoryLimit  = 300
+)
+
 // ChargeView handles the charging details display
-type ChargeView struct{}
+type ChargeView struct {
+	cache *HistoryCache
+
+	// Peak charging rate of the current session, reset when charging stops
+	// or the vehicle changes
+	peakVehicleID string
+	peakRate      float64
+
+	plain bool
+
+	// Time of day charging should be done by (e.g. 7h for 07:00)
+	readyBy    time.Duration
+	hasReadyBy bool
+}
 
 // NewChargeView creates a new charge view
-func NewChargeView() *ChargeView {
-	return &ChargeView{}
+func NewChargeView(cache *HistoryCache) *ChargeView {
+	return &ChargeView{cache: cache}
 }
 
-// Render renders the charge view
-func (v *ChargeView) Render(state *model.VehicleState, width, height int) string {
-	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
-		Bold(true).
-		MarginTop(1).
-		MarginBottom(1)
+// SetPlain switches between bordered and plain linear rendering
+func (v *ChargeView) SetPlain(plain bool) {
+	v.plain = plain
+}
 
-	sectionStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		MarginBottom(1)
+// SetReadyBy shows whether charging will reach the limit by the next
+// occurrence of the given time of day
+func (v *ChargeView) SetReadyBy(clock time.Duration) {
+	v.readyBy = clock
+	v.hasReadyBy = true
+}
 
-	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
+// Render renders the charge view
+func (v *ChargeView) Render(ctx context.Context, state *model.VehicleState, width, height int) string {
+	styles := newViewStyles(v.plain)
+	titleStyle, sectionStyle, labelStyle, valueStyle := styles.title, styles.section, styles.label, styles.value
 
-	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ffffff")).
-		Bold(true)
+	v.trackPeakRate(state)
 
 	// Main charging status section
 	statusSection := v.renderChargingStatus(state, sectionStyle, labelStyle, valueStyle)
 
 	// Battery details section
-	batterySection := v.renderBatteryDetails(state, sectionStyle, labelStyle, valueStyle)
+	batterySection := v.renderBatteryDetails(ctx, state, sectionStyle, labelStyle, valueStyle)
 
 	// Charging recommendations
 	recommendationsSection := v.renderRecommendations(state, sectionStyle, labelStyle, valueStyle)
@@ -55,12 +81,7 @@ func (v *ChargeView) Render(state *model.VehicleState, width, height int) string
 
 	rightColumn := batterySection
 
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		leftColumn,
-		"  ",
-		rightColumn,
-	)
+	content := styles.columns(leftColumn, rightColumn)
 
 	return titleStyle.Render("🔋 Charging") + "\n" + content
 }
@@ -69,29 +90,29 @@ func (v *ChargeView) renderChargingStatus(state *model.VehicleState, sectionStyl
 	// Charge state with large emoji
 	stateEmoji := "🔌"
 	stateText := "Not Plugged In"
-	stateColor := lipgloss.Color("#888888")
+	stateColor := themeColor("#888888")
 
 	switch state.ChargeState {
 	case model.ChargeStateCharging:
 		stateEmoji = "⚡"
 		stateText = "Charging"
-		stateColor = lipgloss.Color("#00ff00")
+		stateColor = themeColor("#00ff00")
 	case model.ChargeStateComplete:
 		stateEmoji = "✓"
 		stateText = "Charge Complete"
-		stateColor = lipgloss.Color("#00ff00")
+		stateColor = themeColor("#00ff00")
 	case model.ChargeStateScheduled:
 		stateEmoji = "⏱"
 		stateText = "Scheduled"
-		stateColor = lipgloss.Color("#ffff00")
+		stateColor = themeColor("#ffff00")
 	case model.ChargeStateDisconnected:
 		stateEmoji = "🔌"
 		stateText = "Disconnected"
-		stateColor = lipgloss.Color("#888888")
+		stateColor = themeColor("#888888")
 	case model.ChargeStateNotCharging:
 		stateEmoji = "○"
 		stateText = "Not Charging"
-		stateColor = lipgloss.Color("#888888")
+		stateColor = themeColor("#888888")
 	}
 
 	emojiStyle := lipgloss.NewStyle().
@@ -110,13 +131,15 @@ func (v *ChargeView) renderChargingStatus(state *model.VehicleState, sectionStyl
 	content += titleStyle.Render(stateText) + "\n\n"
 
 	// Current battery level with large bar
-	batteryBar := v.renderBatteryBar(state.BatteryLevel, 30)
-	content += fmt.Sprintf("%s\n\n",
-		lipgloss.NewStyle().Align(lipgloss.Center).Render(batteryBar),
-	)
+	if !v.plain {
+		batteryBar := v.renderBatteryBar(state.BatteryLevel, 30)
+		content += fmt.Sprintf("%s\n\n",
+			lipgloss.NewStyle().Align(lipgloss.Center).Render(batteryBar),
+		)
+	}
 
 	percentStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ff00")).
+		Foreground(themeColor("#00ff00")).
 		Bold(true).
 		Align(lipgloss.Center)
 
@@ -159,19 +182,28 @@ func (v *ChargeView) renderChargingStatus(state *model.VehicleState, sectionStyl
 		}
 	}
 
+	if v.hasReadyBy {
+		target := model.NextClockTime(time.Now(), v.readyBy)
+		content = strings.TrimRight(content, "\n") + fmt.Sprintf("\n\n%s %s",
+			labelStyle.Render(fmt.Sprintf("Ready by %s:", target.Format("3:04 PM"))),
+			valueStyle.Render(model.ReadyBySummary(state, target)),
+		)
+	}
+
 	return sectionStyle.Width(40).Render(content)
 }
 
-func (v *ChargeView) renderBatteryDetails(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
+func (v *ChargeView) renderBatteryDetails(ctx context.Context, state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
 	content := fmt.Sprintf("%s %s\n\n",
 		labelStyle.Render("Current Level:"),
 		valueStyle.Render(fmt.Sprintf("%.1f%%", state.BatteryLevel)),
 	)
 
-	content += fmt.Sprintf("%s %s\n\n",
+	content += fmt.Sprintf("%s %s\n",
 		labelStyle.Render("Charge Limit:"),
 		valueStyle.Render(fmt.Sprintf("%d%%", state.ChargeLimit)),
 	)
+	content += v.renderChargeLimitChange(ctx, state, labelStyle) + "\n"
 
 	// Calculate how much more charge is needed
 	neededPercent := float64(state.ChargeLimit) - state.BatteryLevel
@@ -183,7 +215,7 @@ func (v *ChargeView) renderBatteryDetails(state *model.VehicleState, sectionStyl
 	} else {
 		content += fmt.Sprintf("%s %s\n\n",
 			labelStyle.Render("Status:"),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render("At limit"),
+			lipgloss.NewStyle().Foreground(themeColor("#00ff00")).Render("At limit"),
 		)
 	}
 
@@ -202,24 +234,69 @@ func (v *ChargeView) renderBatteryDetails(state *model.VehicleState, sectionStyl
 		)
 	}
 
+	content += v.renderSinceLastCharge(ctx, state, labelStyle, valueStyle)
+
 	// Range
-	rangeColor := lipgloss.Color("#00ff00")
+	rangeColor := themeColor("#00ff00")
 	switch state.RangeStatus {
 	case model.RangeStatusLow:
-		rangeColor = lipgloss.Color("#ffff00")
+		rangeColor = themeColor("#ffff00")
 	case model.RangeStatusCritical:
-		rangeColor = lipgloss.Color("#ff0000")
+		rangeColor = themeColor("#ff0000")
 	}
 	rangeStyle := valueStyle.Foreground(rangeColor)
 
 	content += fmt.Sprintf("\n%s %s",
 		labelStyle.Render("Range:"),
-		rangeStyle.Render(fmt.Sprintf("%.0f mi (%s)", state.RangeEstimate, state.RangeStatus)),
+		rangeStyle.Render(fmt.Sprintf("%s %.0f mi (%s)", rangeSymbol(state.RangeStatus), state.RangeEstimate, state.RangeStatus)),
 	)
 
 	return sectionStyle.Width(30).Render("📊 Battery Details\n\n" + content)
 }
 
+// renderChargeLimitChange notes when and from what the current charge limit
+// was changed, or returns "" when history shows no change.
+func (v *ChargeView) renderChargeLimitChange(ctx context.Context, state *model.VehicleState, labelStyle lipgloss.Style) string {
+	history := v.cache.Get(ctx, state.VehicleID, sinceChargeHistoryWindow, sinceChargeHistoryLimit)
+	change, ok := model.LastChargeLimitChange(withLatest(state, history))
+	if !ok || change.To != state.ChargeLimit {
+		return ""
+	}
+	changeStyle := lipgloss.NewStyle().Foreground(themeColor("#ffff00"))
+	return labelStyle.Render("  was ") + changeStyle.Render(fmt.Sprintf("%d%%, changed %s", change.From, formatDuration(time.Since(change.At)))) + "\n"
+}
+
+// renderSinceLastCharge summarizes driving since the vehicle was last on
+// charge, or returns "" when history doesn't cover a charge.
+func (v *ChargeView) renderSinceLastCharge(ctx context.Context, state *model.VehicleState, labelStyle, valueStyle lipgloss.Style) string {
+	history := v.cache.Get(ctx, state.VehicleID, sinceChargeHistoryWindow, sinceChargeHistoryLimit)
+	miles, used, ok := model.SinceLastCharge(history)
+	if !ok {
+		return ""
+	}
+
+	content := fmt.Sprintf("\n%s\n", labelStyle.Render("Since Last Charge:"))
+	content += fmt.Sprintf("%s %s\n",
+		labelStyle.Render("  Driven:"),
+		valueStyle.Render(fmt.Sprintf("%.1f mi", miles)),
+	)
+	content += fmt.Sprintf("%s %s\n",
+		labelStyle.Render("  Battery Used:"),
+		valueStyle.Render(fmt.Sprintf("%.1f%%", used)),
+	)
+
+	// Efficiency needs pack capacity and a meaningful amount of energy used
+	if state.BatteryCapacity > 0 && used > 1 {
+		kWh := used / 100 * state.BatteryCapacity
+		content += fmt.Sprintf("%s %s\n",
+			labelStyle.Render("  Efficiency:"),
+			valueStyle.Render(fmt.Sprintf("%.2f mi/kWh", miles/kWh)),
+		)
+	}
+
+	return content
+}
+
 func (v *ChargeView) renderRecommendations(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
 	recommendations := v.getChargingRecommendations(state)
 
@@ -238,9 +315,9 @@ func (v *ChargeView) renderRecommendations(state *model.VehicleState, sectionSty
 			icon = "⚠️"
 		}
 
-		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00"))
+		style := lipgloss.NewStyle().Foreground(themeColor("#ffff00"))
 		if rec.critical {
-			style = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+			style = lipgloss.NewStyle().Foreground(themeColor("#ff0000"))
 		}
 
 		content += fmt.Sprintf("%s %s", icon, style.Render(rec.message))
@@ -249,6 +326,21 @@ func (v *ChargeView) renderRecommendations(state *model.VehicleState, sectionSty
 	return sectionStyle.Width(40).Render("💡 Recommendations\n\n" + content)
 }
 
+// trackPeakRate records the highest charging rate seen in the current
+// session. The peak resets when charging stops or another vehicle is shown.
+func (v *ChargeView) trackPeakRate(state *model.VehicleState) {
+	if state.VehicleID != v.peakVehicleID || !state.IsCharging() {
+		v.peakVehicleID = state.VehicleID
+		v.peakRate = 0
+	}
+	if !state.IsCharging() || state.ChargingRate == nil {
+		return
+	}
+	if *state.ChargingRate > v.peakRate {
+		v.peakRate = *state.ChargingRate
+	}
+}
+
 type recommendation struct {
 	message  string
 	critical bool
@@ -289,6 +381,16 @@ func (v *ChargeView) getChargingRecommendations(state *model.VehicleState) []rec
 		})
 	}
 
+	// Rate dropped well below the session peak (heat, weak station, ...)
+	if state.IsCharging() && state.BatteryLevel > throttleMinLevel &&
+		state.ChargingRate != nil && v.peakRate > 0 &&
+		*state.ChargingRate < v.peakRate*throttleRatio {
+		recs = append(recs, recommendation{
+			message:  fmt.Sprintf("Charging throttled (currently %.1f kW, peaked at %.1f kW)", *state.ChargingRate, v.peakRate),
+			critical: false,
+		})
+	}
+
 	// Charge complete but still plugged in
 	if state.ChargeState == model.ChargeStateComplete && state.BatteryLevel >= float64(state.ChargeLimit) {
 		recs = append(recs, recommendation{
@@ -309,15 +411,15 @@ func (v *ChargeView) renderBatteryBar(level float64, width int) string {
 	empty := width - filled
 
 	// Color based on level
-	barColor := lipgloss.Color("#00ff00")
+	barColor := themeColor("#00ff00")
 	if level < 20 {
-		barColor = lipgloss.Color("#ff0000")
+		barColor = themeColor("#ff0000")
 	} else if level < 50 {
-		barColor = lipgloss.Color("#ffff00")
+		barColor = themeColor("#ffff00")
 	}
 
 	filledStyle := lipgloss.NewStyle().Foreground(barColor)
-	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#333333"))
+	emptyStyle := lipgloss.NewStyle().Foreground(themeColor("#333333"))
 
 	bar := filledStyle.Render(strings.Repeat("█", filled)) +
 		emptyStyle.Render(strings.Repeat("░", empty))
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -10,17 +11,17 @@ import (
 )
 
 func TestNewChargeView(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	if view == nil {
 		t.Fatal("NewChargeView returned nil")
 	}
 }
 
 func TestChargeViewRender(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 
-	output := view.Render(state, 120, 40)
+	output := view.Render(context.Background(), state, 120, 40)
 
 	// Check for key sections
 	expectedSections := []string{
@@ -37,7 +38,7 @@ func TestChargeViewRender(t *testing.T) {
 }
 
 func TestRenderChargingStatus(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 
 	tests := []struct {
 		name         string
@@ -85,7 +86,7 @@ func TestRenderChargingStatus(t *testing.T) {
 }
 
 func TestRenderChargingStatusWithRate(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.ChargeState = model.ChargeStateCharging
 	chargingRate := 11.5
@@ -103,7 +104,7 @@ func TestRenderChargingStatusWithRate(t *testing.T) {
 }
 
 func TestRenderChargingStatusWithTimeToCharge(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.ChargeState = model.ChargeStateCharging
 	state.UpdatedAt = time.Now()
@@ -122,8 +123,29 @@ func TestRenderChargingStatusWithTimeToCharge(t *testing.T) {
 	}
 }
 
+func TestRenderChargingStatusReadyBy(t *testing.T) {
+	view := NewChargeView(nil)
+	state := createTestState()
+	state.ChargeState = model.ChargeStateCharging
+	state.BatteryLevel = 40
+	state.ChargeLimit = 80
+	state.BatteryCapacity = 135
+	chargingRate := 11.5
+	state.ChargingRate = &chargingRate
+
+	// 54 kWh in 8-9 hours needs well under 11.5 kW
+	target := time.Now().Add(9 * time.Hour)
+	view.SetReadyBy(time.Duration(target.Hour()) * time.Hour)
+
+	output := view.renderChargingStatus(state, lipgloss.NewStyle(), lipgloss.NewStyle(), lipgloss.NewStyle())
+
+	if !strings.Contains(output, "Ready by") || !strings.Contains(output, "on track") {
+		t.Errorf("Expected ready-by verdict in output, got: %s", output)
+	}
+}
+
 func TestRenderBatteryDetails(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.BatteryLevel = 69.9
 	state.ChargeLimit = 70
@@ -132,7 +154,7 @@ func TestRenderBatteryDetails(t *testing.T) {
 	labelStyle := lipgloss.NewStyle()
 	valueStyle := lipgloss.NewStyle()
 
-	output := view.renderBatteryDetails(state, sectionStyle, labelStyle, valueStyle)
+	output := view.renderBatteryDetails(context.Background(), state, sectionStyle, labelStyle, valueStyle)
 
 	expectedContent := []string{
 		"Battery Details",
@@ -151,8 +173,47 @@ func TestRenderBatteryDetails(t *testing.T) {
 	}
 }
 
+func TestRenderBatteryDetailsChargeLimitChange(t *testing.T) {
+	state := createTestState()
+	state.ChargeLimit = 70
+	changed := createTestState()
+	changed.ChargeLimit = 70
+	changed.UpdatedAt = state.UpdatedAt.Add(-2 * time.Hour)
+	older := createTestState()
+	older.ChargeLimit = 80
+	older.UpdatedAt = state.UpdatedAt.Add(-3 * time.Hour)
+
+	cache := NewHistoryCache(nil)
+	cache.entries[historyKey{vehicleID: state.VehicleID, window: sinceChargeHistoryWindow, limit: sinceChargeHistoryLimit}] = &historyEntry{
+		states: []*model.VehicleState{changed, older}, loadedAt: time.Now(),
+	}
+	view := NewChargeView(cache)
+	style := lipgloss.NewStyle()
+
+	output := view.renderBatteryDetails(context.Background(), state, style, style, style)
+	if !strings.Contains(output, "was 80%, changed 2h ago") {
+		t.Errorf("Expected the previous charge limit, got: %s", output)
+	}
+
+	dashboard := NewDashboardView(cache)
+	cache.entries[historyKey{vehicleID: state.VehicleID, window: chargeLimitNoticeWindow, limit: chargeLimitNoticeLimit}] = &historyEntry{
+		states: []*model.VehicleState{changed, older}, loadedAt: time.Now(),
+	}
+	issues := dashboard.renderIssues(state, dashboard.chargeLimitChange(context.Background(), state), style)
+	if !strings.Contains(issues, "Charge limit changed from 80% to 70%") {
+		t.Errorf("Expected a charge limit issue on the dashboard, got: %s", issues)
+	}
+
+	// A steady limit is not annotated
+	state.ChargeLimit = 80
+	cache.entries[historyKey{vehicleID: state.VehicleID, window: sinceChargeHistoryWindow, limit: sinceChargeHistoryLimit}].states = []*model.VehicleState{older}
+	if output := view.renderBatteryDetails(context.Background(), state, style, style, style); strings.Contains(output, "was ") {
+		t.Errorf("Unchanged limit should not be annotated, got: %s", output)
+	}
+}
+
 func TestRenderBatteryDetailsAtLimit(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.BatteryLevel = 80.0
 	state.ChargeLimit = 80
@@ -161,7 +222,7 @@ func TestRenderBatteryDetailsAtLimit(t *testing.T) {
 	labelStyle := lipgloss.NewStyle()
 	valueStyle := lipgloss.NewStyle()
 
-	output := view.renderBatteryDetails(state, sectionStyle, labelStyle, valueStyle)
+	output := view.renderBatteryDetails(context.Background(), state, sectionStyle, labelStyle, valueStyle)
 
 	if !strings.Contains(output, "At limit") {
 		t.Errorf("Expected 'At limit' message, got: %s", output)
@@ -169,7 +230,7 @@ func TestRenderBatteryDetailsAtLimit(t *testing.T) {
 }
 
 func TestRenderBatteryDetailsBelowLimit(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.BatteryLevel = 60.0
 	state.ChargeLimit = 80
@@ -178,7 +239,7 @@ func TestRenderBatteryDetailsBelowLimit(t *testing.T) {
 	labelStyle := lipgloss.NewStyle()
 	valueStyle := lipgloss.NewStyle()
 
-	output := view.renderBatteryDetails(state, sectionStyle, labelStyle, valueStyle)
+	output := view.renderBatteryDetails(context.Background(), state, sectionStyle, labelStyle, valueStyle)
 
 	if !strings.Contains(output, "To Limit:") {
 		t.Errorf("Expected 'To Limit' label, got: %s", output)
@@ -189,7 +250,7 @@ func TestRenderBatteryDetailsBelowLimit(t *testing.T) {
 }
 
 func TestGetChargingRecommendations(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 
 	tests := []struct {
 		name             string
@@ -284,8 +345,59 @@ func TestGetChargingRecommendations(t *testing.T) {
 	}
 }
 
+func TestChargingThrottledRecommendation(t *testing.T) {
+	view := NewChargeView(nil)
+	state := createTestState()
+	state.ChargeState = model.ChargeStateCharging
+	state.BatteryLevel = 50
+
+	rate := func(kw float64) *float64 { return &kw }
+	throttled := func() bool {
+		for _, rec := range view.getChargingRecommendations(state) {
+			if strings.Contains(rec.message, "Charging throttled") {
+				return true
+			}
+		}
+		return false
+	}
+
+	state.ChargingRate = rate(150)
+	view.Render(context.Background(), state, 120, 40)
+	state.ChargingRate = rate(120)
+	view.Render(context.Background(), state, 120, 40)
+	if throttled() {
+		t.Error("a small drop from peak should not be flagged")
+	}
+
+	state.ChargingRate = rate(60)
+	view.Render(context.Background(), state, 120, 40)
+	recs := view.getChargingRecommendations(state)
+	if !throttled() {
+		t.Fatalf("expected throttling recommendation, got: %+v", recs)
+	}
+	if !strings.Contains(view.Render(context.Background(), state, 120, 40), "peaked at 150.0 kW") {
+		t.Error("recommendation should include the session peak rate")
+	}
+
+	// Low SOC is not flagged
+	state.BatteryLevel = 15
+	if throttled() {
+		t.Error("throttling should not be flagged at or below 20% SOC")
+	}
+
+	// The peak resets when charging stops
+	state.BatteryLevel = 50
+	state.ChargeState = model.ChargeStateComplete
+	view.Render(context.Background(), state, 120, 40)
+	state.ChargeState = model.ChargeStateCharging
+	view.Render(context.Background(), state, 120, 40)
+	if throttled() {
+		t.Error("peak should reset between charging sessions")
+	}
+}
+
 func TestRenderRecommendations(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.RangeStatus = model.RangeStatusCritical
 	state.RangeEstimate = 15
@@ -305,7 +417,7 @@ func TestRenderRecommendations(t *testing.T) {
 }
 
 func TestRenderRecommendationsEmpty(t *testing.T) {
-	view := NewChargeView()
+	view := NewChargeView(nil)
 	state := createTestState()
 	state.RangeStatus = model.RangeStatusNormal
 	state.BatteryLevel = 75
@@ -323,3 +435,33 @@ func TestRenderRecommendationsEmpty(t *testing.T) {
 		t.Errorf("Expected empty output for no recommendations, got: %s", output)
 	}
 }
+
+func TestChargeViewSinceLastCharge(t *testing.T) {
+	now := time.Now()
+	cache := NewHistoryCache(nil)
+	cache.entries[historyKey{vehicleID: "v1", window: sinceChargeHistoryWindow, limit: sinceChargeHistoryLimit}] = &historyEntry{
+		states: []*model.VehicleState{
+			{VehicleID: "v1", UpdatedAt: now, ChargeState: model.ChargeStateDisconnected, Odometer: 1100, BatteryLevel: 60},
+			{VehicleID: "v1", UpdatedAt: now.Add(-4 * time.Hour), ChargeState: model.ChargeStateComplete, Odometer: 1000, BatteryLevel: 80},
+		},
+		loadedAt: now,
+	}
+
+	view := NewChargeView(cache)
+	state := createTestState()
+	state.VehicleID = "v1"
+	state.BatteryCapacity = 135
+
+	output := view.Render(context.Background(), state, 120, 40)
+	for _, want := range []string{"Since Last Charge", "100.0 mi", "20.0%", "3.70 mi/kWh"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("charge view missing %q", want)
+		}
+	}
+
+	// No summary without a charge in history
+	state.VehicleID = "other"
+	if strings.Contains(view.Render(context.Background(), state, 120, 40), "Since Last Charge") {
+		t.Error("summary should be hidden without charge history")
+	}
+}
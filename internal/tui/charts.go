@@ -3,12 +3,13 @@ package tui
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/guptarohit/asciigraph"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
-	"github.com/pfrederiksen/rivian-ls/internal/store"
 )
 
 // ChartMetric represents the type of metric to display
@@ -20,6 +21,9 @@ const (
 	MetricChargingRate
 	MetricTemperature
 	MetricEfficiency
+	MetricDegradation
+
+	metricCount = int(MetricDegradation) + 1
 )
 
 // TimeRange represents the time range for the chart
@@ -31,20 +35,34 @@ const (
 	Range30Days
 )
 
-// ChartsView handles the charts display
+// Capacity degradation is a long-term trend, so its chart ignores the
+// selected time range and loads each day's highest-charge reading over this
+// much history instead.
+const degradationHistoryWindow = 3 * 365 * 24 * time.Hour
+
+// smoothingAlpha is the EMA weight given to each new sample when smoothing
+// is enabled. Lower values smooth more aggressively.
+const smoothingAlpha = 0.3
+
+// ChartsView handles the charts display. Its methods are safe for
+// concurrent use: Render holds the lock throughout, so a metric or range
+// switch takes effect between renders, never halfway through one.
 type ChartsView struct {
-	store          *store.Store
-	vehicleID      string
+	cache     *HistoryCache
+	vehicleID string
+
+	mu             sync.Mutex // Guards the fields below
 	history        []*model.VehicleState
+	total          int // States in the window; more than len(history) when truncated
 	selectedMetric ChartMetric
 	timeRange      TimeRange
-	lastLoad       time.Time
+	smoothed       bool
 }
 
 // NewChartsView creates a new charts view
-func NewChartsView(store *store.Store, vehicleID string) *ChartsView {
+func NewChartsView(cache *HistoryCache, vehicleID string) *ChartsView {
 	return &ChartsView{
-		store:          store,
+		cache:          cache,
 		vehicleID:      vehicleID,
 		selectedMetric: MetricBattery,
 		timeRange:      Range24Hours,
@@ -53,15 +71,21 @@ func NewChartsView(store *store.Store, vehicleID string) *ChartsView {
 
 // NextMetric switches to the next metric
 func (v *ChartsView) NextMetric() {
-	v.selectedMetric = (v.selectedMetric + 1) % 5 // 5 metrics total
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.selectedMetric = (v.selectedMetric + 1) % ChartMetric(metricCount)
 	// Invalidate cache to reload data
 	v.history = nil
 }
 
 // PrevMetric switches to the previous metric
 func (v *ChartsView) PrevMetric() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
 	if v.selectedMetric == 0 {
-		v.selectedMetric = 4 // Wrap to last metric
+		v.selectedMetric = ChartMetric(metricCount - 1) // Wrap to last metric
 	} else {
 		v.selectedMetric--
 	}
@@ -71,23 +95,61 @@ func (v *ChartsView) PrevMetric() {
 
 // NextTimeRange cycles to the next time range
 func (v *ChartsView) NextTimeRange() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
 	v.timeRange = (v.timeRange + 1) % 3 // 3 time ranges total
 	// Invalidate cache to reload data
 	v.history = nil
 }
 
+// ToggleSmoothing toggles EMA smoothing of the plotted series
+func (v *ChartsView) ToggleSmoothing() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.smoothed = !v.smoothed
+	// Invalidate cache to recompute the chart
+	v.history = nil
+}
+
+// smooth applies an exponential moving average to data.
+// alpha is the weight of each new sample (0 < alpha <= 1).
+func smooth(data []float64, alpha float64) []float64 {
+	if len(data) == 0 {
+		return data
+	}
+
+	out := make([]float64, len(data))
+	out[0] = data[0]
+	for i := 1; i < len(data); i++ {
+		out[i] = alpha*data[i] + (1-alpha)*out[i-1]
+	}
+	return out
+}
+
+// plotData returns the series to plot, smoothed if enabled.
+// Statistics are always computed from the raw history.
+func (v *ChartsView) plotData(data []float64) []float64 {
+	if !v.smoothed {
+		return data
+	}
+	return smooth(data, smoothingAlpha)
+}
+
 // Render renders the charts view
-func (v *ChartsView) Render(state *model.VehicleState, width, height int) string {
+func (v *ChartsView) Render(ctx context.Context, state *model.VehicleState, width, height int) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
+		Foreground(themeColor("#00ffff")).
 		Bold(true).
 		MarginTop(1).
 		MarginBottom(1)
 
-	// Load history if not cached or if stale
-	if v.history == nil || time.Since(v.lastLoad) > 30*time.Second {
-		v.loadHistory()
-	}
+	// Refresh history from the shared cache (cheap until the TTL expires)
+	v.loadHistory(ctx)
 
 	// Render title with metric and time range
 	title := v.renderTitle()
@@ -110,6 +172,8 @@ func (v *ChartsView) Render(state *model.VehicleState, width, height int) string
 		chart = v.renderTemperatureChart(width-4, height-15)
 	case MetricEfficiency:
 		chart = v.renderEfficiencyChart(width-4, height-15)
+	case MetricDegradation:
+		chart = v.renderDegradationChart(width-4, height-15)
 	default:
 		chart = "Unknown metric"
 	}
@@ -134,6 +198,8 @@ func (v *ChartsView) renderTitle() string {
 		metricName = "Cabin Temperature"
 	case MetricEfficiency:
 		metricName = "Energy Efficiency"
+	case MetricDegradation:
+		metricName = "Battery Capacity by Odometer"
 	default:
 		metricName = "Unknown"
 	}
@@ -149,6 +215,13 @@ func (v *ChartsView) renderTitle() string {
 	default:
 		timeRangeName = "Unknown"
 	}
+	if v.selectedMetric == MetricDegradation {
+		timeRangeName = "All History"
+	}
+
+	if v.smoothed {
+		timeRangeName += ", smoothed"
+	}
 
 	return fmt.Sprintf("📊 %s (%s)", metricName, timeRangeName)
 }
@@ -156,49 +229,77 @@ func (v *ChartsView) renderTitle() string {
 // renderNoData renders a message when no data is available
 func (v *ChartsView) renderNoData() string {
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
+		Foreground(themeColor("#888888")).
 		Align(lipgloss.Center).
 		Padding(2)
 
 	return style.Render("📊 No historical data available yet\n\nCharts will populate as data is collected")
 }
 
-// loadHistory loads historical data from the store
-func (v *ChartsView) loadHistory() {
-	if v.store == nil {
+// loadHistory loads historical data for the selected time range from the cache
+func (v *ChartsView) loadHistory(ctx context.Context) {
+	if v.cache == nil {
 		return
 	}
 
-	ctx := context.Background()
-
 	// Calculate time range
-	var since time.Time
+	var window time.Duration
 	var limit int
 	switch v.timeRange {
 	case Range24Hours:
-		since = time.Now().Add(-24 * time.Hour)
+		window = 24 * time.Hour
 		limit = 100
 	case Range7Days:
-		since = time.Now().Add(-7 * 24 * time.Hour)
+		window = 7 * 24 * time.Hour
 		limit = 200
 	case Range30Days:
-		since = time.Now().Add(-30 * 24 * time.Hour)
+		window = 30 * 24 * time.Hour
 		limit = 300
 	default:
-		since = time.Now().Add(-24 * time.Hour)
+		window = 24 * time.Hour
 		limit = 100
 	}
+	if v.selectedMetric == MetricDegradation {
+		v.history = v.cache.GetDailyPeaks(ctx, v.vehicleID, degradationHistoryWindow, model.CapacitySampleMinLevel)
+		v.total = len(v.history)
+		return
+	}
+
+	v.history, v.total = v.cache.GetWithTotal(ctx, v.vehicleID, window, limit)
+}
 
-	// Load from store
-	history, err := v.store.GetStateHistory(ctx, v.vehicleID, since, limit)
-	if err == nil {
-		v.history = history
-		v.lastLoad = time.Now()
+// truncationNote explains that the chart only covers the most recent part of
+// the window, or returns "" when every state in it is shown.
+func (v *ChartsView) truncationNote() string {
+	if v.total <= len(v.history) {
+		return ""
 	}
+	return fmt.Sprintf("showing last %d of %d points", len(v.history), v.total)
 }
 
-// renderSimpleChart is a helper to render charts for simple float64 metrics
-func (v *ChartsView) renderSimpleChart(data []float64, metricName, unit string, width, height int) string {
+// Smallest plot area asciigraph can render legibly. Below this the charts
+// fall back to showing the latest value.
+const (
+	minChartWidth  = 10
+	minChartHeight = 3
+)
+
+// chartFits reports whether a plot area is large enough for asciigraph.
+func chartFits(width, height int) bool {
+	return width >= minChartWidth && height >= minChartHeight
+}
+
+// renderSimpleChart is a helper to render charts for simple float64 metrics.
+// The metric name and unit label the y-axis; bounds (asciigraph.LowerBound
+// and UpperBound) widen the axis to a sensible range for the metric.
+func (v *ChartsView) renderSimpleChart(data []float64, metricName, unit string, width, height int, bounds ...asciigraph.Option) string {
+	return v.renderChart(data, metricName, unit, v.generateTimeLabels(), width, height, bounds...)
+}
+
+// renderChart plots data with caption labelling the x-axis. Points are drawn
+// evenly spaced, so callers plotting against something other than sample
+// order must resample first (see resampleByOdometer).
+func (v *ChartsView) renderChart(data []float64, metricName, unit, caption string, width, height int, bounds ...asciigraph.Option) string {
 	if len(v.history) == 0 {
 		return v.renderNoData()
 	}
@@ -207,23 +308,33 @@ func (v *ChartsView) renderSimpleChart(data []float64, metricName, unit string,
 	if len(data) == 1 {
 		return v.renderSingleDataPoint(metricName, data[0], unit)
 	}
+	if !chartFits(width, height) {
+		return v.renderTooSmall(metricName, data[len(data)-1], unit)
+	}
+
+	if note := v.truncationNote(); note != "" {
+		caption += " (" + note + ")"
+	}
 
 	// Render chart
-	graph := asciigraph.Plot(
-		data,
+	opts := append([]asciigraph.Option{
 		asciigraph.Height(height),
 		asciigraph.Width(width),
-		asciigraph.Caption(v.generateTimeLabels()),
-	)
+		asciigraph.Caption(caption),
+	}, bounds...)
+	graph := asciigraph.Plot(v.plotData(data), opts...)
+
+	axisStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#888888"))
 
 	// Style the graph
 	graphStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
+		BorderForeground(themeColor("#5f5fff")).
 		Padding(1).
 		Width(width + 4) // Account for border and padding
 
-	return graphStyle.Render(graph)
+	return graphStyle.Render(axisStyle.Render(fmt.Sprintf("%s (%s)", metricName, unit)) + "\n" + graph)
 }
 
 // renderBatteryChart renders the battery level chart
@@ -238,7 +349,8 @@ func (v *ChartsView) renderBatteryChart(width, height int) string {
 		data = append(data, v.history[i].BatteryLevel)
 	}
 
-	return v.renderSimpleChart(data, "Battery Level", "%", width, height)
+	return v.renderSimpleChart(data, "Battery Level", "%", width, height,
+		asciigraph.LowerBound(0), asciigraph.UpperBound(100))
 }
 
 // renderRangeChart renders the range estimate chart
@@ -253,7 +365,7 @@ func (v *ChartsView) renderRangeChart(width, height int) string {
 		data = append(data, v.history[i].RangeEstimate)
 	}
 
-	return v.renderSimpleChart(data, "Range Estimate", "mi", width, height)
+	return v.renderSimpleChart(data, "Range Estimate", "mi", width, height, asciigraph.LowerBound(0))
 }
 
 // renderChargingRateChart renders the charging rate chart
@@ -275,27 +387,7 @@ func (v *ChartsView) renderChargingRateChart(width, height int) string {
 		}
 	}
 
-	// Handle single data point
-	if len(data) == 1 {
-		return v.renderSingleDataPoint("Charging Rate", data[0], "kW")
-	}
-
-	// Render chart
-	graph := asciigraph.Plot(
-		data,
-		asciigraph.Height(height),
-		asciigraph.Width(width),
-		asciigraph.Caption(v.generateTimeLabels()),
-	)
-
-	// Style the graph
-	graphStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		Width(width + 4)
-
-	return graphStyle.Render(graph)
+	return v.renderSimpleChart(data, "Charging Rate", "kW", width, height, asciigraph.LowerBound(0))
 }
 
 // renderTemperatureChart renders the cabin temperature chart
@@ -316,26 +408,7 @@ func (v *ChartsView) renderTemperatureChart(width, height int) string {
 	if len(data) == 0 {
 		return v.renderNoData()
 	}
-	if len(data) == 1 {
-		return v.renderSingleDataPoint("Cabin Temperature", data[0], "°F")
-	}
-
-	// Render chart
-	graph := asciigraph.Plot(
-		data,
-		asciigraph.Height(height),
-		asciigraph.Width(width),
-		asciigraph.Caption(v.generateTimeLabels()),
-	)
-
-	// Style the graph
-	graphStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		Width(width + 4)
-
-	return graphStyle.Render(graph)
+	return v.renderSimpleChart(data, "Cabin Temperature", "°F", width, height)
 }
 
 // renderEfficiencyChart renders the energy efficiency chart (mi/kWh)
@@ -371,44 +444,81 @@ func (v *ChartsView) renderEfficiencyChart(width, height int) string {
 	// Handle insufficient data
 	if len(data) == 0 {
 		noDataStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
+			Foreground(themeColor("#888888")).
 			Align(lipgloss.Center).
 			Padding(2)
 		return noDataStyle.Render("📊 Not enough data to calculate efficiency\n\nNeed battery and range changes over time")
 	}
-	if len(data) == 1 {
-		return v.renderSingleDataPoint("Efficiency", data[0], "mi/kWh")
+	return v.renderSimpleChart(data, "Efficiency", "mi/kWh", width, height, asciigraph.LowerBound(0))
+}
+
+// renderDegradationChart renders estimated full-pack capacity against
+// odometer rather than time
+func (v *ChartsView) renderDegradationChart(width, height int) string {
+	samples := model.CapacitySamples(v.history)
+	if len(samples) == 0 {
+		noDataStyle := lipgloss.NewStyle().
+			Foreground(themeColor("#888888")).
+			Align(lipgloss.Center).
+			Padding(2)
+		return noDataStyle.Render("📊 Not enough data to estimate capacity\n\nNeed readings taken at 80% charge or above")
 	}
 
-	// Render chart
-	graph := asciigraph.Plot(
-		data,
-		asciigraph.Height(height),
-		asciigraph.Width(width),
-		asciigraph.Caption(v.generateTimeLabels()),
-	)
+	first, last := samples[0].Odometer, samples[len(samples)-1].Odometer
+	caption := fmt.Sprintf("%.0f mi → %.0f mi", first, last)
+	data := []float64{samples[0].Capacity}
+	if last > first {
+		data = resampleByOdometer(samples, width)
+	}
+	return v.renderChart(data, "Est. Capacity", "kWh", caption, width, height, asciigraph.LowerBound(0))
+}
 
-	// Style the graph
-	graphStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		Width(width + 4)
+// resampleByOdometer linearly interpolates samples (sorted by odometer) onto
+// n evenly spaced odometer positions, so the plot's x-axis is distance.
+func resampleByOdometer(samples []model.CapacitySample, n int) []float64 {
+	if len(samples) == 0 || n < 2 {
+		return nil
+	}
 
-	return graphStyle.Render(graph)
+	first, last := samples[0].Odometer, samples[len(samples)-1].Odometer
+	out := make([]float64, n)
+	j := 0
+	for i := range out {
+		x := first + (last-first)*float64(i)/float64(n-1)
+		for j < len(samples)-2 && samples[j+1].Odometer < x {
+			j++
+		}
+		a, b := samples[j], samples[min(j+1, len(samples)-1)]
+		if b.Odometer == a.Odometer {
+			out[i] = b.Capacity
+			continue
+		}
+		t := (x - a.Odometer) / (b.Odometer - a.Odometer)
+		out[i] = a.Capacity + t*(b.Capacity-a.Capacity)
+	}
+	return out
 }
 
 // renderSingleDataPoint renders a display for when there's only one data point
 func (v *ChartsView) renderSingleDataPoint(metric string, value float64, unit string) string {
 	style := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
+		BorderForeground(themeColor("#5f5fff")).
 		Padding(2).
 		Align(lipgloss.Center)
 
 	return style.Render(fmt.Sprintf("%s: %.1f%s\n\nNeed at least 2 data points to display a chart", metric, value, unit))
 }
 
+// renderTooSmall shows the latest value when the terminal is too small to plot
+func (v *ChartsView) renderTooSmall(metric string, value float64, unit string) string {
+	style := lipgloss.NewStyle().
+		Foreground(themeColor("#888888")).
+		Align(lipgloss.Center)
+
+	return style.Render(fmt.Sprintf("%s: %.1f%s\n\nEnlarge the terminal to display a chart", metric, value, unit))
+}
+
 // generateTimeLabels generates time labels for the X-axis
 func (v *ChartsView) generateTimeLabels() string {
 	if len(v.history) == 0 {
@@ -448,10 +558,10 @@ func (v *ChartsView) renderStats(state *model.VehicleState) string {
 	}
 
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
+		Foreground(themeColor("#888888"))
 
 	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ffffff")).
+		Foreground(themeColor("#ffffff")).
 		Bold(true)
 
 	// Calculate stats based on metric
@@ -527,15 +637,27 @@ func (v *ChartsView) renderStats(state *model.VehicleState) string {
 			change = data[len(data)-1] - data[0]
 		}
 		unit = " mi/kWh"
+	case MetricDegradation:
+		samples := model.CapacitySamples(v.history)
+		if len(samples) > 0 {
+			current = samples[len(samples)-1].Capacity
+			min, max = current, current
+			for _, s := range samples {
+				min = math.Min(min, s.Capacity)
+				max = math.Max(max, s.Capacity)
+			}
+			change = current - samples[0].Capacity
+		}
+		unit = " kWh"
 	}
 
 	// Format change with sign
 	changeStr := fmt.Sprintf("%+.1f%s", change, unit)
 	switch {
 	case change > 0:
-		changeStr = valueStyle.Foreground(lipgloss.Color("#00ff00")).Render(changeStr)
+		changeStr = valueStyle.Foreground(themeColor("#00ff00")).Render(changeStr)
 	case change < 0:
-		changeStr = valueStyle.Foreground(lipgloss.Color("#ff0000")).Render(changeStr)
+		changeStr = valueStyle.Foreground(themeColor("#ff0000")).Render(changeStr)
 	default:
 		changeStr = valueStyle.Render(changeStr)
 	}
@@ -552,10 +674,24 @@ func (v *ChartsView) renderStats(state *model.VehicleState) string {
 		labelStyle.Render("Change:"),
 		changeStr,
 	)
+	if v.selectedMetric == MetricDegradation {
+		if trend, ok := model.CapacityTrend(model.CapacitySamples(v.history)); ok {
+			stats += fmt.Sprintf("  │  %s %s",
+				labelStyle.Render("Trend:"),
+				valueStyle.Render(fmt.Sprintf("%+.1f%% / 10k mi", trend)),
+			)
+		}
+	}
+
+	points := fmt.Sprintf("%d", len(v.history))
+	if v.total > len(v.history) {
+		points = fmt.Sprintf("%d of %d", len(v.history), v.total)
+	}
+	stats += fmt.Sprintf("  │  %s %s", labelStyle.Render("Points:"), valueStyle.Render(points))
 
 	statStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
+		BorderForeground(themeColor("#5f5fff")).
 		Padding(0, 1)
 
 	return statStyle.Render(stats)
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"context"
+	"math"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,7 +39,8 @@ func TestChartsView_NextMetric(t *testing.T) {
 		MetricChargingRate, // 1 + 1 = 2
 		MetricTemperature,  // 2 + 1 = 3
 		MetricEfficiency,   // 3 + 1 = 4
-		MetricBattery,      // 4 + 1 = 5 % 5 = 0
+		MetricDegradation,  // 4 + 1 = 5
+		MetricBattery,      // 5 + 1 = 6 % 6 = 0
 	}
 
 	for i, expected := range expectedOrder {
@@ -52,16 +56,16 @@ func TestChartsView_PrevMetric(t *testing.T) {
 		selectedMetric: MetricBattery,
 	}
 
-	// Going backwards from 0 should wrap to 4
+	// Going backwards from 0 should wrap to 5
 	view.PrevMetric()
-	if view.selectedMetric != MetricEfficiency {
-		t.Errorf("PrevMetric() from Battery = %v, want %v", view.selectedMetric, MetricEfficiency)
+	if view.selectedMetric != MetricDegradation {
+		t.Errorf("PrevMetric() from Battery = %v, want %v", view.selectedMetric, MetricDegradation)
 	}
 
-	// Then to 3
+	// Then to 4
 	view.PrevMetric()
-	if view.selectedMetric != MetricTemperature {
-		t.Errorf("PrevMetric() from Efficiency = %v, want %v", view.selectedMetric, MetricTemperature)
+	if view.selectedMetric != MetricEfficiency {
+		t.Errorf("PrevMetric() from Degradation = %v, want %v", view.selectedMetric, MetricEfficiency)
 	}
 }
 
@@ -389,6 +393,48 @@ func TestChartsView_TimeRangeSwitchingInvalidatesCache(t *testing.T) {
 	}
 }
 
+func TestChartsView_ToggleSmoothing(t *testing.T) {
+	view := &ChartsView{
+		history: []*model.VehicleState{
+			{BatteryLevel: 80.0, UpdatedAt: time.Now()},
+		},
+	}
+
+	view.ToggleSmoothing()
+	if !view.smoothed {
+		t.Error("ToggleSmoothing() should enable smoothing")
+	}
+	if view.history != nil {
+		t.Error("ToggleSmoothing() should invalidate history cache")
+	}
+	if !strings.Contains(view.renderTitle(), "smoothed") {
+		t.Errorf("title should mention smoothing, got %q", view.renderTitle())
+	}
+
+	view.ToggleSmoothing()
+	if view.smoothed {
+		t.Error("ToggleSmoothing() should disable smoothing")
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	got := smooth([]float64{10, 20, 20}, 0.5)
+	want := []float64{10, 15, 17.5}
+
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("smooth()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if len(smooth(nil, 0.5)) != 0 {
+		t.Error("smooth(nil) should return empty")
+	}
+}
+
 func TestChartsView_RenderSimpleChart(t *testing.T) {
 	now := time.Now()
 	view := &ChartsView{
@@ -409,6 +455,28 @@ func TestChartsView_RenderSimpleChart(t *testing.T) {
 	}
 }
 
+func TestChartsView_RenderBatteryChart_AxisLabelAndBounds(t *testing.T) {
+	now := time.Now()
+	view := &ChartsView{
+		history: []*model.VehicleState{
+			{BatteryLevel: 72.0, UpdatedAt: now},
+			{BatteryLevel: 74.0, UpdatedAt: now.Add(-1 * time.Hour)},
+			{BatteryLevel: 76.0, UpdatedAt: now.Add(-2 * time.Hour)},
+		},
+		timeRange: Range24Hours,
+	}
+
+	output := view.renderBatteryChart(80, 20)
+
+	if !strings.Contains(output, "Battery Level (%)") {
+		t.Error("renderBatteryChart() should label the y-axis with metric and unit")
+	}
+	// Battery axis is clamped to the full 0-100 range, not the data range
+	if !strings.Contains(output, "100.00") || !strings.Contains(output, " 0.00") {
+		t.Errorf("renderBatteryChart() y-axis should span 0-100, got:\n%s", output)
+	}
+}
+
 func TestChartsView_RenderSimpleChart_NoData(t *testing.T) {
 	view := &ChartsView{
 		history: []*model.VehicleState{},
@@ -442,3 +510,154 @@ func TestChartsView_RenderSimpleChart_SinglePoint(t *testing.T) {
 		t.Error("renderSimpleChart() should include metric name")
 	}
 }
+
+func TestChartsView_RenderTinyDimensions(t *testing.T) {
+	now := time.Now()
+	rate := 11.5
+	temp := 70.0
+	history := make([]*model.VehicleState, 0, 5)
+	for i := 0; i < 5; i++ {
+		history = append(history, &model.VehicleState{
+			BatteryLevel:  80 - float64(i)*5,
+			RangeEstimate: 250 - float64(i)*15,
+			ChargingRate:  &rate,
+			CabinTemp:     &temp,
+			UpdatedAt:     now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+
+	state := createTestState()
+	sizes := [][2]int{{0, 0}, {1, 1}, {4, 15}, {20, 10}, {80, 16}, {-5, -5}}
+	metrics := []ChartMetric{MetricBattery, MetricRange, MetricChargingRate, MetricTemperature, MetricEfficiency}
+
+	for _, metric := range metrics {
+		for _, size := range sizes {
+			view := &ChartsView{history: history, selectedMetric: metric, timeRange: Range24Hours}
+			output := view.Render(context.Background(), state, size[0], size[1])
+			if output == "" {
+				t.Errorf("metric %d at %dx%d rendered nothing", metric, size[0], size[1])
+			}
+		}
+	}
+
+	view := &ChartsView{history: history, timeRange: Range24Hours}
+	if output := view.renderBatteryChart(5, 1); !strings.Contains(output, "Battery Level: 80.0%") {
+		t.Errorf("tiny chart should fall back to the latest value, got: %s", output)
+	}
+}
+
+func TestChartsView_RenderDegradationChart(t *testing.T) {
+	now := time.Now()
+	view := &ChartsView{
+		selectedMetric: MetricDegradation,
+		history: []*model.VehicleState{
+			{Model: "R1T", BatteryLevel: 90, RangeEstimate: 252, Odometer: 20000, UpdatedAt: now},
+			{Model: "R1T", BatteryLevel: 40, RangeEstimate: 120, Odometer: 15000, UpdatedAt: now.Add(-24 * time.Hour)},
+			{Model: "R1T", BatteryLevel: 90, RangeEstimate: 270, Odometer: 10000, UpdatedAt: now.Add(-48 * time.Hour)},
+		},
+	}
+
+	output := view.renderDegradationChart(80, 20)
+	for _, want := range []string{"Est. Capacity (kWh)", "10000 mi → 20000 mi"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("renderDegradationChart() missing %q:\n%s", want, output)
+		}
+	}
+
+	stats := view.renderStats(view.history[0])
+	if !strings.Contains(stats, "Trend:") || !strings.Contains(stats, "/ 10k mi") {
+		t.Errorf("renderStats() should show the capacity trend, got:\n%s", stats)
+	}
+
+	view.history = view.history[1:2] // Only a low-charge reading
+	if out := view.renderDegradationChart(80, 20); !strings.Contains(out, "Not enough data") {
+		t.Errorf("renderDegradationChart() without high-charge readings = %q", out)
+	}
+}
+
+func TestResampleByOdometer(t *testing.T) {
+	samples := []model.CapacitySample{
+		{Odometer: 0, Capacity: 100},
+		{Odometer: 1000, Capacity: 90},
+		{Odometer: 4000, Capacity: 90},
+	}
+
+	got := resampleByOdometer(samples, 5) // Every 1000 mi
+	want := []float64{100, 90, 90, 90, 90}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 0.001 {
+			t.Errorf("resampleByOdometer()[%d] = %.2f, want %.2f", i, got[i], want[i])
+		}
+	}
+}
+
+// TestViews_ConcurrentRenders switches metrics, ranges and focus while other
+// goroutines render and append live states; run with -race.
+func TestViews_ConcurrentRenders(t *testing.T) {
+	now := time.Now()
+	cache := NewHistoryCache(nil)
+	for _, key := range []historyKey{
+		{vehicleID: "v1", window: 24 * time.Hour, limit: 100},
+		{vehicleID: "v1", window: 7 * 24 * time.Hour, limit: 200},
+		{vehicleID: "v1", window: 30 * 24 * time.Hour, limit: 300},
+		{vehicleID: "v1", window: degradationHistoryWindow, peakLevel: model.CapacitySampleMinLevel},
+		{vehicleID: "v1", window: healthHistoryWindow, limit: healthHistoryLimit},
+	} {
+		var states []*model.VehicleState
+		for i := 0; i < 20; i++ {
+			s := createTestState()
+			s.VehicleID = "v1"
+			s.UpdatedAt = now.Add(-time.Duration(i+1) * time.Hour)
+			s.BatteryLevel = float64(50 + i)
+			states = append(states, s)
+		}
+		cache.entries[key] = &historyEntry{states: states, total: len(states), loadedAt: now}
+	}
+
+	charts := NewChartsView(cache, "v1")
+	health := NewHealthView(cache, "v1")
+	state := createTestState()
+	state.VehicleID = "v1"
+
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				fn(i)
+			}
+		}()
+	}
+
+	run(func(int) { charts.Render(context.Background(), state, 120, 40) })
+	run(func(int) { health.Render(context.Background(), state, 120, 40) })
+	run(func(i int) {
+		switch i % 4 {
+		case 0:
+			charts.NextMetric()
+		case 1:
+			charts.PrevMetric()
+		case 2:
+			charts.NextTimeRange()
+		default:
+			charts.ToggleSmoothing()
+		}
+	})
+	run(func(i int) {
+		health.SetFocus(HealthFocusClosures)
+		health.SetTempUnit(TempUnit(i % 2))
+		health.SetFocus(HealthFocusNone)
+	})
+	run(func(i int) {
+		s := createTestState()
+		s.VehicleID = "v1"
+		s.UpdatedAt = now.Add(time.Duration(i) * time.Second)
+		cache.Append(s)
+	})
+	wg.Wait()
+
+	if out := charts.Render(context.Background(), state, 120, 40); out == "" {
+		t.Errorf("charts should still render after concurrent use:\n%s", out)
+	}
+}
@@ -1,45 +1,58 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 )
 
+// Recent history used for the "your driving" range projection
+const (
+	efficiencyHistoryWindow = 14 * 24 * time.Hour
+	efficiencyHistoryLimit  = 500
+)
+
+// A charge limit change is listed under issues for this long
+const (
+	chargeLimitNoticeWindow = 24 * time.Hour
+	chargeLimitNoticeLimit  = 100
+)
+
 // DashboardView handles the main dashboard display
-type DashboardView struct{}
+type DashboardView struct {
+	cache    *HistoryCache
+	tempUnit TempUnit
+	plain    bool
+}
 
-// NewDashboardView creates a new dashboard view
-func NewDashboardView() *DashboardView {
-	return &DashboardView{}
+// NewDashboardView creates a new dashboard view. cache may be nil, which
+// hides the history-based range projection.
+func NewDashboardView(cache *HistoryCache) *DashboardView {
+	return &DashboardView{cache: cache}
+}
+
+// SetTempUnit sets the unit used to display temperatures
+func (v *DashboardView) SetTempUnit(unit TempUnit) {
+	v.tempUnit = unit
+}
+
+// SetPlain switches between bordered and plain linear rendering
+func (v *DashboardView) SetPlain(plain bool) {
+	v.plain = plain
 }
 
 // Render renders the dashboard view
-func (v *DashboardView) Render(state *model.VehicleState, width, height int) string {
+func (v *DashboardView) Render(ctx context.Context, state *model.VehicleState, width, height int) string {
 	// Define styles
-	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
-		Bold(true).
-		MarginTop(1).
-		MarginBottom(1)
-
-	sectionStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		MarginBottom(1)
-
-	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
-
-	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ffffff")).
-		Bold(true)
+	styles := newViewStyles(v.plain)
+	titleStyle, sectionStyle, labelStyle, valueStyle := styles.title, styles.section, styles.label, styles.value
 
 	// Battery & Range Section
-	batterySection := v.renderBatterySection(state, sectionStyle, labelStyle, valueStyle)
+	batterySection := v.renderBatterySection(ctx, state, sectionStyle, labelStyle, valueStyle)
 
 	// Charging Section
 	chargingSection := v.renderChargingSection(state, sectionStyle, labelStyle, valueStyle)
@@ -63,9 +76,9 @@ func (v *DashboardView) Render(state *model.VehicleState, width, height int) str
 	}
 
 	// Issues Section (if any)
-	issuesSection := v.renderIssues(state, sectionStyle)
+	issuesSection := v.renderIssues(state, v.chargeLimitChange(ctx, state), sectionStyle)
 
-	// Arrange sections in a three-column grid layout
+	// Arrange sections in a three-column grid layout (a single column in plain mode)
 	leftColumn := lipgloss.JoinVertical(
 		lipgloss.Left,
 		batterySection,
@@ -84,14 +97,7 @@ func (v *DashboardView) Render(state *model.VehicleState, width, height int) str
 		vehicleSection,
 	)
 
-	topRow := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		leftColumn,
-		"  ",
-		middleColumn,
-		"  ",
-		rightColumn,
-	)
+	topRow := styles.columns(leftColumn, middleColumn, rightColumn)
 
 	bottomRow := ""
 	if readySection != "" {
@@ -109,17 +115,17 @@ func (v *DashboardView) Render(state *model.VehicleState, width, height int) str
 		"\n" + bottomRow
 }
 
-func (v *DashboardView) renderBatterySection(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
+func (v *DashboardView) renderBatterySection(ctx context.Context, state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
 	// Battery level with bar
 	batteryBar := v.renderBatteryBar(state.BatteryLevel, 20)
 
 	// Range with color based on status
-	rangeColor := lipgloss.Color("#00ff00")
+	rangeColor := themeColor("#00ff00")
 	switch state.RangeStatus {
 	case model.RangeStatusLow:
-		rangeColor = lipgloss.Color("#ffff00")
+		rangeColor = themeColor("#ffff00")
 	case model.RangeStatusCritical:
-		rangeColor = lipgloss.Color("#ff0000")
+		rangeColor = themeColor("#ff0000")
 	}
 	rangeStyle := valueStyle.Foreground(rangeColor)
 
@@ -127,12 +133,21 @@ func (v *DashboardView) renderBatterySection(state *model.VehicleState, sectionS
 		labelStyle.Render("Battery:"),
 		valueStyle.Render(fmt.Sprintf("%.1f%%", state.BatteryLevel)),
 	)
-	content += batteryBar + "\n\n"
+	if !v.plain {
+		// The percentage is on the line above; the bar is decoration
+		content += batteryBar + "\n\n"
+	}
 	content += fmt.Sprintf("%s %s (%s)\n",
 		labelStyle.Render("Range:"),
-		rangeStyle.Render(fmt.Sprintf("%.0f mi", state.RangeEstimate)),
+		rangeStyle.Render(fmt.Sprintf("%s %.0f mi", rangeSymbol(state.RangeStatus), state.RangeEstimate)),
 		state.RangeStatus,
 	)
+	if projected := v.projectedRange(ctx, state); projected > 0 {
+		content += fmt.Sprintf("%s %s\n",
+			labelStyle.Render("Real Range:"),
+			valueStyle.Render(fmt.Sprintf("%.0f mi (your driving)", projected)),
+		)
+	}
 	content += fmt.Sprintf("%s %d%%",
 		labelStyle.Render("Charge Limit:"),
 		state.ChargeLimit,
@@ -145,33 +160,33 @@ func (v *DashboardView) renderChargingSection(state *model.VehicleState, section
 	// Charge state with emoji
 	stateEmoji := "🔌"
 	stateText := string(state.ChargeState)
-	stateColor := lipgloss.Color("#ffffff")
+	stateColor := themeColor("#ffffff")
 
 	switch state.ChargeState {
 	case model.ChargeStateCharging:
 		stateEmoji = "⚡"
 		stateText = "Charging"
-		stateColor = lipgloss.Color("#00ff00")
+		stateColor = themeColor("#00ff00")
 	case model.ChargeStateComplete:
 		stateEmoji = "✓"
 		stateText = "Complete"
-		stateColor = lipgloss.Color("#00ff00")
+		stateColor = themeColor("#00ff00")
 	case model.ChargeStateDisconnected:
 		stateEmoji = "🔌"
 		stateText = "Disconnected"
-		stateColor = lipgloss.Color("#888888")
+		stateColor = themeColor("#888888")
 	case model.ChargeStateScheduled:
 		stateEmoji = "⏱"
 		stateText = "Scheduled"
-		stateColor = lipgloss.Color("#ffff00")
+		stateColor = themeColor("#ffff00")
 	case model.ChargeStateNotCharging:
 		stateEmoji = "○"
 		stateText = "Not Charging"
-		stateColor = lipgloss.Color("#888888")
+		stateColor = themeColor("#888888")
 	case model.ChargeStateUnknown, "":
 		stateEmoji = "❓"
 		stateText = "Unknown"
-		stateColor = lipgloss.Color("#888888")
+		stateColor = themeColor("#888888")
 	}
 	stateStyle := valueStyle.Foreground(stateColor)
 
@@ -210,11 +225,11 @@ func (v *DashboardView) renderSecuritySection(state *model.VehicleState, section
 	// Lock status
 	lockEmoji := "🔒"
 	lockStatus := "Locked"
-	lockColor := lipgloss.Color("#00ff00")
+	lockColor := themeColor("#00ff00")
 	if !state.IsLocked {
 		lockEmoji = "🔓"
 		lockStatus = "Unlocked"
-		lockColor = lipgloss.Color("#ffff00")
+		lockColor = themeColor("#ffff00")
 	}
 	lockStyle := valueStyle.Foreground(lockColor)
 
@@ -270,32 +285,66 @@ func (v *DashboardView) renderSecuritySection(state *model.VehicleState, section
 		valueStyle.Render(windowsStatus),
 	)
 
+	// Model-specific closures, skipped when unsupported or unknown
+	profile := state.Profile()
+	if profile.HasFrunk {
+		content += renderClosureLine("Frunk:", state.Frunk, labelStyle, valueStyle)
+	}
+	if profile.HasLiftgate {
+		content += renderClosureLine("Liftgate:", state.Liftgate, labelStyle, valueStyle)
+	}
+	if tonneau, ok := state.Tonneau(); ok {
+		content += renderClosureLine("Tonneau:", tonneau, labelStyle, valueStyle)
+	}
+
 	return sectionStyle.Width(35).Render("🔐 Security\n\n" + content)
 }
 
+// renderClosureLine renders a single closure as "\nLabel: status", or nothing if unknown.
+func renderClosureLine(label string, status model.ClosureStatus, labelStyle, valueStyle lipgloss.Style) string {
+	if status == model.ClosureStatusUnknown || status == "" {
+		return ""
+	}
+
+	color := themeColor("#00ff00")
+	symbol := symbolOK
+	if status == model.ClosureStatusOpen {
+		color = themeColor("#ffff00")
+		symbol = symbolWarning
+	}
+
+	return fmt.Sprintf("\n%s %s",
+		labelStyle.Render(label),
+		valueStyle.Foreground(color).Render(symbol+" "+string(status)),
+	)
+}
+
 func (v *DashboardView) renderStatsSection(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
 	content := ""
 
 	// Temperature
 	if state.CabinTemp != nil {
-		tempColor := lipgloss.Color("#00ff00")
+		tempColor := themeColor("#00ff00")
+		tempSymbol := symbolOK
 		temp := *state.CabinTemp
 		if temp < 60 || temp > 80 {
-			tempColor = lipgloss.Color("#ffff00")
+			tempColor = themeColor("#ffff00")
+			tempSymbol = symbolWarning
 		}
 		if temp < 40 || temp > 90 {
-			tempColor = lipgloss.Color("#ff0000")
+			tempColor = themeColor("#ff0000")
+			tempSymbol = symbolCritical
 		}
 		tempStyle := valueStyle.Foreground(tempColor)
 		content += fmt.Sprintf("%s %s\n",
 			labelStyle.Render("Cabin:"),
-			tempStyle.Render(fmt.Sprintf("%.1f°F", temp)),
+			tempStyle.Render(fmt.Sprintf("%s %s", tempSymbol, v.tempUnit.Format(temp))),
 		)
 	}
 	if state.ExteriorTemp != nil {
 		content += fmt.Sprintf("%s %s\n\n",
 			labelStyle.Render("Exterior:"),
-			valueStyle.Render(fmt.Sprintf("%.1f°F", *state.ExteriorTemp)),
+			valueStyle.Render(v.tempUnit.Format(*state.ExteriorTemp)),
 		)
 	}
 
@@ -322,13 +371,13 @@ func (v *DashboardView) renderTirePressures(state *model.VehicleState, sectionSt
 		var statusColor lipgloss.Color
 		switch status {
 		case model.TirePressureStatusLow:
-			statusColor = lipgloss.Color("#ffff00") // Yellow for low
+			statusColor = themeColor("#ffff00") // Yellow for low
 		case model.TirePressureStatusHigh:
-			statusColor = lipgloss.Color("#ff8800") // Orange for high
+			statusColor = themeColor("#ff8800") // Orange for high
 		case model.TirePressureStatusUnknown, "":
-			statusColor = lipgloss.Color("#888888") // Gray for unknown
+			statusColor = themeColor("#888888") // Gray for unknown
 		default:
-			statusColor = lipgloss.Color("#00ff00") // Green for OK
+			statusColor = themeColor("#00ff00") // Green for OK
 		}
 		statusStyle := valueStyle.Foreground(statusColor)
 
@@ -339,7 +388,7 @@ func (v *DashboardView) renderTirePressures(state *model.VehicleState, sectionSt
 
 		return fmt.Sprintf("%s %s",
 			labelStyle.Render(label+":"),
-			statusStyle.Render(displayStatus),
+			statusStyle.Render(tireSymbol(status)+" "+displayStatus),
 		)
 	}
 
@@ -402,46 +451,65 @@ func (v *DashboardView) renderReadyScore(state *model.VehicleState, sectionStyle
 	scoreBar := v.renderScoreBar(score, 40)
 
 	// Color based on score
-	scoreColor := lipgloss.Color("#00ff00")
+	scoreColor := themeColor("#00ff00")
 	if score < 50 {
-		scoreColor = lipgloss.Color("#ff0000")
+		scoreColor = themeColor("#ff0000")
 	} else if score < 75 {
-		scoreColor = lipgloss.Color("#ffff00")
+		scoreColor = themeColor("#ffff00")
 	}
 	scoreStyle := valueStyle.Foreground(scoreColor)
 
 	content := fmt.Sprintf("%s %s / 100\n\n",
 		labelStyle.Render("Score:"),
-		scoreStyle.Render(fmt.Sprintf("%.1f", score)),
+		scoreStyle.Render(fmt.Sprintf("%s %.1f", scoreSymbol(score), score)),
 	)
 	content += scoreBar
 
 	return sectionStyle.Width(72).Render("🎯 Ready Score\n\n" + content)
 }
 
-func (v *DashboardView) renderIssues(state *model.VehicleState, sectionStyle lipgloss.Style) string {
+// chargeLimitChange returns the recent charge limit change as an info issue,
+// or "" if the limit hasn't changed within chargeLimitNoticeWindow.
+func (v *DashboardView) chargeLimitChange(ctx context.Context, state *model.VehicleState) string {
+	if v.cache == nil {
+		return ""
+	}
+	history := v.cache.Get(ctx, state.VehicleID, chargeLimitNoticeWindow, chargeLimitNoticeLimit)
+	change, ok := model.LastChargeLimitChange(withLatest(state, history))
+	if !ok || change.To != state.ChargeLimit {
+		return ""
+	}
+	return change.Issue()
+}
+
+// renderIssues lists the state's issues, plus extra history-based ones
+// such as a charge limit change (empty ones are skipped).
+func (v *DashboardView) renderIssues(state *model.VehicleState, extra string, sectionStyle lipgloss.Style) string {
 	issues := state.GetIssues()
+	if extra != "" {
+		issues = append(issues, extra)
+	}
 	if len(issues) == 0 {
 		return ""
 	}
 
 	issueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ffff00"))
+		Foreground(themeColor("#ffff00"))
 
 	criticalStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ff0000")).
+		Foreground(themeColor("#ff0000")).
 		Bold(true)
 
 	var content strings.Builder
 	for _, issue := range issues {
 		switch {
 		case strings.Contains(strings.ToLower(issue), "critical"):
-			content.WriteString(criticalStyle.Render("⚠ " + issue))
+			content.WriteString(criticalStyle.Render(symbolCritical + " " + issue))
 		case strings.HasPrefix(issue, "Info:"):
 			// Info items in gray
-			content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("ℹ " + strings.TrimPrefix(issue, "Info: ")))
+			content.WriteString(lipgloss.NewStyle().Foreground(themeColor("#888888")).Render("ℹ " + strings.TrimPrefix(issue, "Info: ")))
 		default:
-			content.WriteString(issueStyle.Render("• " + issue))
+			content.WriteString(issueStyle.Render(symbolWarning + " " + issue))
 		}
 		content.WriteString("\n")
 	}
@@ -449,6 +517,16 @@ func (v *DashboardView) renderIssues(state *model.VehicleState, sectionStyle lip
 	return sectionStyle.Width(72).Render("⚠️  Issues\n\n" + content.String())
 }
 
+// projectedRange returns the range at the efficiency observed in recent
+// history, or 0 without enough history.
+func (v *DashboardView) projectedRange(ctx context.Context, state *model.VehicleState) float64 {
+	if v.cache == nil {
+		return 0
+	}
+	history := v.cache.Get(ctx, state.VehicleID, efficiencyHistoryWindow, efficiencyHistoryLimit)
+	return model.ProjectedRange(state, model.RecentEfficiency(history))
+}
+
 // renderBatteryBar creates a visual battery bar
 func (v *DashboardView) renderBatteryBar(level float64, width int) string {
 	filled := int(level * float64(width) / 100)
@@ -458,15 +536,15 @@ func (v *DashboardView) renderBatteryBar(level float64, width int) string {
 	empty := width - filled
 
 	// Color based on level
-	barColor := lipgloss.Color("#00ff00")
+	barColor := themeColor("#00ff00")
 	if level < 20 {
-		barColor = lipgloss.Color("#ff0000")
+		barColor = themeColor("#ff0000")
 	} else if level < 50 {
-		barColor = lipgloss.Color("#ffff00")
+		barColor = themeColor("#ffff00")
 	}
 
 	filledStyle := lipgloss.NewStyle().Foreground(barColor)
-	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#333333"))
+	emptyStyle := lipgloss.NewStyle().Foreground(themeColor("#333333"))
 
 	bar := filledStyle.Render(strings.Repeat("█", filled)) +
 		emptyStyle.Render(strings.Repeat("░", empty))
@@ -483,15 +561,15 @@ func (v *DashboardView) renderScoreBar(score float64, width int) string {
 	empty := width - filled
 
 	// Color based on score
-	barColor := lipgloss.Color("#00ff00")
+	barColor := themeColor("#00ff00")
 	if score < 50 {
-		barColor = lipgloss.Color("#ff0000")
+		barColor = themeColor("#ff0000")
 	} else if score < 75 {
-		barColor = lipgloss.Color("#ffff00")
+		barColor = themeColor("#ffff00")
 	}
 
 	filledStyle := lipgloss.NewStyle().Foreground(barColor)
-	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#333333"))
+	emptyStyle := lipgloss.NewStyle().Foreground(themeColor("#333333"))
 
 	bar := filledStyle.Render(strings.Repeat("█", filled)) +
 		emptyStyle.Render(strings.Repeat("░", empty))
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -10,17 +11,17 @@ import (
 )
 
 func TestNewDashboardView(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 	if view == nil {
 		t.Fatal("NewDashboardView returned nil")
 	}
 }
 
 func TestDashboardRender(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 	state := createTestState()
 
-	output := view.Render(state, 120, 40)
+	output := view.Render(context.Background(), state, 120, 40)
 
 	// Check for key sections
 	expectedSections := []string{
@@ -41,14 +42,14 @@ func TestDashboardRender(t *testing.T) {
 }
 
 func TestRenderBatterySection(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 	state := createTestState()
 
 	sectionStyle := lipgloss.NewStyle()
 	labelStyle := lipgloss.NewStyle()
 	valueStyle := lipgloss.NewStyle()
 
-	output := view.renderBatterySection(state, sectionStyle, labelStyle, valueStyle)
+	output := view.renderBatterySection(context.Background(), state, sectionStyle, labelStyle, valueStyle)
 
 	// Check for battery information
 	expectedContent := []string{
@@ -68,7 +69,7 @@ func TestRenderBatterySection(t *testing.T) {
 }
 
 func TestRenderChargingSection(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name          string
@@ -124,7 +125,7 @@ func TestRenderChargingSection(t *testing.T) {
 }
 
 func TestRenderSecuritySection(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name         string
@@ -161,8 +162,34 @@ func TestRenderSecuritySection(t *testing.T) {
 	}
 }
 
+func TestRenderSecuritySectionProfile(t *testing.T) {
+	view := NewDashboardView(nil)
+	style := lipgloss.NewStyle()
+	tonneau := model.ClosureStatusClosed
+
+	state := createTestState()
+	state.Frunk = model.ClosureStatusClosed
+	state.Liftgate = model.ClosureStatusUnknown
+	state.TonneauCover = &tonneau
+
+	state.Model = "R1T"
+	output := view.renderSecuritySection(state, style, style, style)
+	if !strings.Contains(output, "Frunk:") || !strings.Contains(output, "Tonneau:") {
+		t.Errorf("R1T output should show frunk and tonneau, got: %s", output)
+	}
+	if strings.Contains(output, "Liftgate:") {
+		t.Errorf("unknown liftgate should not be rendered, got: %s", output)
+	}
+
+	state.Model = "R1S"
+	output = view.renderSecuritySection(state, style, style, style)
+	if strings.Contains(output, "Tonneau:") {
+		t.Errorf("R1S output should not show tonneau, got: %s", output)
+	}
+}
+
 func TestRenderTirePressures(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name   string
@@ -204,7 +231,7 @@ func TestRenderTirePressures(t *testing.T) {
 }
 
 func TestRenderVehicleInfo(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 	state := createTestState()
 	state.BatteryCapacity = 140.5 // Set calculated capacity
 
@@ -233,7 +260,7 @@ func TestRenderVehicleInfo(t *testing.T) {
 }
 
 func TestRenderBatteryBar(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name  string
@@ -264,7 +291,7 @@ func TestRenderBatteryBar(t *testing.T) {
 }
 
 func TestRenderStatsSection(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name       string
@@ -302,7 +329,7 @@ func TestRenderStatsSection(t *testing.T) {
 }
 
 func TestRenderIssues(t *testing.T) {
-	view := NewDashboardView()
+	view := NewDashboardView(nil)
 
 	tests := []struct {
 		name         string
@@ -348,7 +375,7 @@ func TestRenderIssues(t *testing.T) {
 				state.IsLocked = true
 			}
 
-			output := view.renderIssues(state, sectionStyle)
+			output := view.renderIssues(state, "", sectionStyle)
 
 			if tt.hasIssues && output == "" {
 				t.Error("Expected issues section but got empty string")
@@ -409,3 +436,76 @@ func createTestState() *model.VehicleState {
 func ptr(f float64) *float64 {
 	return &f
 }
+
+func TestDashboardStatusSymbols(t *testing.T) {
+	DisableColor()
+	view := NewDashboardView(nil)
+	style := lipgloss.NewStyle()
+
+	state := createTestState()
+	state.RangeStatus = model.RangeStatusCritical
+	state.RangeEstimate = 15
+	state.TirePressures.FrontLeftStatus = model.TirePressureStatusLow
+	state.TirePressures.FrontRightStatus = model.TirePressureStatusOK
+	state.TirePressures.RearLeftStatus = model.TirePressureStatusUnknown
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "range",
+			output: view.renderBatterySection(context.Background(), state, style, style, style),
+			want:   []string{symbolCritical + " 15 mi"},
+		},
+		{
+			name:   "tires",
+			output: view.renderTirePressures(state, style, style, style),
+			want:   []string{symbolWarning + " low", symbolOK + " OK", symbolUnknown + " unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(tt.output, "\x1b[") {
+				t.Errorf("output contains ANSI escapes with color disabled: %q", tt.output)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(tt.output, want) {
+					t.Errorf("output missing %q, got: %s", want, tt.output)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardView_ProjectedRange(t *testing.T) {
+	now := time.Now()
+	cache := NewHistoryCache(nil)
+	cache.entries[historyKey{vehicleID: "v1", window: efficiencyHistoryWindow, limit: efficiencyHistoryLimit}] = &historyEntry{
+		states: []*model.VehicleState{
+			{VehicleID: "v1", Odometer: 1100, BatteryLevel: 60, BatteryCapacity: 100},
+			{VehicleID: "v1", Odometer: 1000, BatteryLevel: 85, BatteryCapacity: 100},
+		},
+		loadedAt: now,
+	}
+
+	view := NewDashboardView(cache)
+	state := createTestState()
+	state.VehicleID = "v1"
+	state.BatteryLevel = 60
+	state.BatteryCapacity = 100
+
+	// 100 mi on 25 kWh = 4 mi/kWh, 60 kWh left
+	output := view.Render(context.Background(), state, 120, 40)
+	if !strings.Contains(output, "240 mi (your driving)") {
+		t.Errorf("dashboard missing projected range:\n%s", output)
+	}
+
+	// Hidden without enough history
+	state.VehicleID = "other"
+	if strings.Contains(view.Render(context.Background(), state, 120, 40), "your driving") {
+		t.Error("projected range should be hidden without history")
+	}
+}
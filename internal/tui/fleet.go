@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+// FleetView renders a one-row-per-vehicle summary of all vehicles
+type FleetView struct{}
+
+// NewFleetView creates a new fleet view
+func NewFleetView() *FleetView {
+	return &FleetView{}
+}
+
+// fleetRowFormat lays out the columns: marker, name, battery, range, charge, lock
+const fleetRowFormat = "%-2s %-24s %8s %8s %-14s %-8s"
+
+// Render renders the fleet table. Vehicles without a cached state show a
+// loading placeholder.
+func (v *FleetView) Render(vehicles []rivian.Vehicle, states map[string]*model.VehicleState, activeID string, width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#00ffff")).
+		Bold(true).
+		MarginTop(1).
+		MarginBottom(1)
+
+	tableStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(themeColor("#5f5fff")).
+		Padding(0, 1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#888888")).
+		Bold(true)
+
+	activeStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#00ffff")).
+		Bold(true)
+
+	loadingStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#666666"))
+
+	rows := []string{
+		headerStyle.Render(fmt.Sprintf(fleetRowFormat, "", "Vehicle", "Battery", "Range", "Charge", "Lock")),
+	}
+
+	for _, vehicle := range vehicles {
+		marker := ""
+		if vehicle.ID == activeID {
+			marker = "▶"
+		}
+
+		state, ok := states[vehicle.ID]
+		if !ok || state == nil {
+			row := fmt.Sprintf(fleetRowFormat, marker, fleetVehicleName(vehicle, nil), "…", "…", "loading…", "…")
+			rows = append(rows, loadingStyle.Render(row))
+			continue
+		}
+
+		lock := "Unlocked"
+		if state.IsLocked {
+			lock = "Locked"
+		}
+
+		row := fmt.Sprintf(fleetRowFormat,
+			marker,
+			fleetVehicleName(vehicle, state),
+			fmt.Sprintf("%.0f%%", state.BatteryLevel),
+			fmt.Sprintf("%.0f mi", state.RangeEstimate),
+			string(state.ChargeState),
+			lock,
+		)
+		if vehicle.ID == activeID {
+			row = activeStyle.Render(row)
+		}
+		rows = append(rows, row)
+	}
+
+	return titleStyle.Render("🚙 Fleet") + "\n" + tableStyle.Render(strings.Join(rows, "\n"))
+}
+
+// fleetVehicleName returns a display name, preferring the freshest state.
+func fleetVehicleName(vehicle rivian.Vehicle, state *model.VehicleState) string {
+	name, vehicleModel := vehicle.Name, vehicle.Model
+	if state != nil {
+		if state.Name != "" {
+			name = state.Name
+		}
+		if state.Model != "" {
+			vehicleModel = state.Model
+		}
+	}
+
+	display := strings.TrimSpace(vehicleModel + " " + name)
+	if display == "" {
+		display = vehicle.ID
+	}
+	if len([]rune(display)) > 24 {
+		display = string([]rune(display)[:23]) + "…"
+	}
+	return display
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func TestFleetViewRender(t *testing.T) {
+	view := NewFleetView()
+
+	vehicles := []rivian.Vehicle{
+		{ID: "v1", Name: "Adventure", Model: "R1T"},
+		{ID: "v2", Name: "Family", Model: "R1S"},
+	}
+	states := map[string]*model.VehicleState{
+		"v1": {
+			VehicleID:     "v1",
+			Name:          "Adventure",
+			Model:         "R1T",
+			BatteryLevel:  82,
+			RangeEstimate: 260,
+			ChargeState:   model.ChargeStateCharging,
+			IsLocked:      true,
+		},
+	}
+
+	output := view.Render(vehicles, states, "v1", 100, 30)
+
+	for _, want := range []string{"Fleet", "R1T Adventure", "82%", "260 mi", "charging", "Locked", "▶"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected fleet output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// Vehicle without a state shows a loading placeholder
+	if !strings.Contains(output, "R1S Family") || !strings.Contains(output, "loading…") {
+		t.Errorf("Expected loading placeholder for unfetched vehicle, got:\n%s", output)
+	}
+}
+
+func TestFleetVehicleName(t *testing.T) {
+	tests := []struct {
+		name    string
+		vehicle rivian.Vehicle
+		state   *model.VehicleState
+		want    string
+	}{
+		{"from vehicle", rivian.Vehicle{ID: "v1", Name: "Truck", Model: "R1T"}, nil, "R1T Truck"},
+		{"state overrides", rivian.Vehicle{ID: "v1", Name: "Old", Model: "R1T"}, &model.VehicleState{Name: "New"}, "R1T New"},
+		{"falls back to ID", rivian.Vehicle{ID: "v1"}, nil, "v1"},
+		{"truncates", rivian.Vehicle{ID: "v1", Name: strings.Repeat("x", 40), Model: "R1S"}, nil, "R1S " + strings.Repeat("x", 19) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fleetVehicleName(tt.vehicle, tt.state); got != tt.want {
+				t.Errorf("fleetVehicleName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
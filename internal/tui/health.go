@@ -4,58 +4,164 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
-	"github.com/pfrederiksen/rivian-ls/internal/store"
 )
 
-// HealthView handles the health and history display
+// History windows used by the health view.
+const (
+	healthHistoryWindow    = 7 * 24 * time.Hour
+	healthHistoryLimit     = 20
+	tempRangeHistoryWindow = 90 * 24 * time.Hour // Correlation needs many samples across temperatures
+	tempRangeHistoryLimit  = 1000
+)
+
+// HealthView handles the health and history display. Like ChartsView, it
+// is safe for concurrent use and Render holds the lock throughout.
 type HealthView struct {
-	store     *store.Store
+	cache     *HistoryCache
 	vehicleID string
-	history   []*model.VehicleState // Cache of recent history
+
+	mu      sync.Mutex            // Guards the fields below
+	history []*model.VehicleState // Recent history, refreshed from cache on render
+
+	// Range-vs-temperature correlation over a longer history window
+	tempRange []model.TempRangePoint
+
+	tempUnit TempUnit
+	plain    bool
+	focus    HealthFocus
+}
+
+// HealthFocus names a section of the health view to bring forward and
+// highlight, e.g. the one explaining an issue on the dashboard.
+type HealthFocus string
+
+const (
+	HealthFocusNone     HealthFocus = ""
+	HealthFocusStatus   HealthFocus = "status" // Range, charge and connectivity
+	HealthFocusClosures HealthFocus = "closures"
+	HealthFocusTires    HealthFocus = "tires"
+)
+
+// issueFocusKeywords maps words in an issue to the section explaining it,
+// checked in order.
+var issueFocusKeywords = []struct {
+	keyword string
+	focus   HealthFocus
+}{
+	{"tire", HealthFocusTires},
+	{"door", HealthFocusClosures},
+	{"window", HealthFocusClosures},
+	{"frunk", HealthFocusClosures},
+	{"liftgate", HealthFocusClosures},
+	{"tonneau", HealthFocusClosures},
+	{"lock", HealthFocusClosures},
+	{"range", HealthFocusStatus},
+	{"battery", HealthFocusStatus},
+	{"charg", HealthFocusStatus},
+	{"offline", HealthFocusStatus},
+	{"online", HealthFocusStatus},
+}
+
+// issueFocus returns the section for the most pressing of state's issues:
+// critical ones first, then in GetIssues order. Info-only issues and
+// issues without a section give HealthFocusNone.
+func issueFocus(state *model.VehicleState) HealthFocus {
+	if state == nil {
+		return HealthFocusNone
+	}
+
+	var ordered []string
+	issues := state.GetIssues()
+	for _, issue := range issues {
+		if strings.HasPrefix(issue, "Critical:") {
+			ordered = append(ordered, issue)
+		}
+	}
+	for _, issue := range issues {
+		if !strings.HasPrefix(issue, "Critical:") && !strings.HasPrefix(issue, "Info:") {
+			ordered = append(ordered, issue)
+		}
+	}
+
+	for _, issue := range ordered {
+		lower := strings.ToLower(issue)
+		for _, k := range issueFocusKeywords {
+			if strings.Contains(lower, k.keyword) {
+				return k.focus
+			}
+		}
+	}
+	return HealthFocusNone
 }
 
 // NewHealthView creates a new health view
-func NewHealthView(store *store.Store, vehicleID string) *HealthView {
+func NewHealthView(cache *HistoryCache, vehicleID string) *HealthView {
 	return &HealthView{
-		store:     store,
+		cache:     cache,
 		vehicleID: vehicleID,
 	}
 }
 
-// Render renders the health view
-func (v *HealthView) Render(state *model.VehicleState, width, height int) string {
-	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
-		Bold(true).
-		MarginTop(1).
-		MarginBottom(1)
-
-	sectionStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
-		Padding(1).
-		MarginBottom(1)
-
-	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
-
-	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ffffff")).
-		Bold(true)
-
-	// Load recent history if not already loaded
-	if v.history == nil && v.store != nil {
-		ctx := context.Background()
-		// Get history from last 7 days, limit to 20 states
-		history, err := v.store.GetStateHistory(ctx, v.vehicleID, time.Now().Add(-7*24*time.Hour), 20)
-		if err == nil {
-			v.history = history
-		}
+// SetTempUnit sets the unit used to display temperatures
+func (v *HealthView) SetTempUnit(unit TempUnit) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.tempUnit = unit
+}
+
+// SetPlain switches between bordered and plain linear rendering
+func (v *HealthView) SetPlain(plain bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.plain = plain
+}
+
+// SetFocus highlights a section and moves it to the top of the view;
+// HealthFocusNone restores the normal layout.
+func (v *HealthView) SetFocus(focus HealthFocus) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.focus = focus
+}
+
+// heading renders a section heading, marked and highlighted when it is the
+// focused section.
+func (v *HealthView) heading(focus HealthFocus, title string, style lipgloss.Style) string {
+	if v.focus == HealthFocusNone || v.focus != focus {
+		return style.Render(title)
+	}
+	return style.Foreground(themeColor("#ffff00")).Bold(true).Render("▶ " + title)
+}
+
+// loadHistory refreshes history from the shared cache (cheap until the TTL
+// expires)
+func (v *HealthView) loadHistory(ctx context.Context) {
+	if v.cache == nil {
+		return
 	}
+	v.history = v.cache.Get(ctx, v.vehicleID, healthHistoryWindow, healthHistoryLimit)
+	v.tempRange = model.RangeTemperatureCorrelation(
+		v.cache.Get(ctx, v.vehicleID, tempRangeHistoryWindow, tempRangeHistoryLimit),
+	)
+}
+
+// Render renders the health view
+func (v *HealthView) Render(ctx context.Context, state *model.VehicleState, width, height int) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	styles := newViewStyles(v.plain)
+	titleStyle, sectionStyle, labelStyle, valueStyle := styles.title, styles.section, styles.label, styles.value
+
+	v.loadHistory(ctx)
 
 	// Current health status
 	healthSection := v.renderHealthStatus(state, sectionStyle, labelStyle, valueStyle)
@@ -67,32 +173,56 @@ func (v *HealthView) Render(state *model.VehicleState, width, height int) string
 	diagnosticsSection := v.renderDiagnostics(state, sectionStyle, labelStyle, valueStyle)
 
 	// Arrange sections
-	topRow := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		healthSection,
-		"  ",
-		trendsSection,
-	)
+	topRow := styles.columns(healthSection, trendsSection)
+
+	content := titleStyle.Render("🏥 Vehicle Health") + "\n"
+	switch v.focus {
+	case HealthFocusClosures, HealthFocusTires:
+		// Diagnostics first, so the focused section is on screen
+		content += diagnosticsSection + "\n" + topRow
+	default:
+		content += topRow + "\n" + diagnosticsSection
+	}
+
+	if len(v.tempRange) > 0 {
+		content += "\n" + v.renderTempRange(sectionStyle, labelStyle, valueStyle)
+	}
+
+	return content
+}
+
+// renderTempRange renders projected full-charge range per exterior temperature bucket
+func (v *HealthView) renderTempRange(sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
+	content := ""
+	for _, p := range v.tempRange {
+		detail := fmt.Sprintf("(%d samples)", p.Samples)
+		if p.RangeLoss >= 1 {
+			detail = fmt.Sprintf("(-%.0f%%, %d samples)", p.RangeLoss, p.Samples)
+		}
+		content += fmt.Sprintf("%s %s %s\n",
+			labelStyle.Render(fmt.Sprintf("%3.0f–%.0f%s:", v.tempUnit.Convert(p.TempLow), v.tempUnit.Convert(p.TempHigh), v.tempUnit.Symbol())),
+			valueStyle.Render(fmt.Sprintf("%.0f mi at 100%%", p.AvgFullRange)),
+			labelStyle.Render(detail),
+		)
+	}
 
-	return titleStyle.Render("🏥 Vehicle Health") + "\n" +
-		topRow + "\n" +
-		diagnosticsSection
+	return sectionStyle.Render("🌡️  Range vs. Temperature\n\n" + strings.TrimSuffix(content, "\n"))
 }
 
 func (v *HealthView) renderHealthStatus(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
 	// Overall health indicator
 	healthEmoji := "✓"
 	healthText := "Healthy"
-	healthColor := lipgloss.Color("#00ff00")
+	healthColor := themeColor("#00ff00")
 
 	if state.HasCriticalIssues() {
 		healthEmoji = "⚠️"
 		healthText = "Needs Attention"
-		healthColor = lipgloss.Color("#ff0000")
-	} else if len(state.GetIssues()) > 0 {
+		healthColor = themeColor("#ff0000")
+	} else if len(state.GetIssues()) > 0 || len(model.TirePressureTrendIssues(v.history)) > 0 {
 		healthEmoji = "⚠"
 		healthText = "Minor Issues"
-		healthColor = lipgloss.Color("#ffff00")
+		healthColor = themeColor("#ffff00")
 	}
 
 	statusStyle := lipgloss.NewStyle().
@@ -117,17 +247,17 @@ func (v *HealthView) renderHealthStatus(state *model.VehicleState, sectionStyle,
 
 	// Ready Score (if available)
 	if state.ReadyScore != nil {
-		scoreColor := lipgloss.Color("#00ff00")
+		scoreColor := themeColor("#00ff00")
 		if *state.ReadyScore < 50 {
-			scoreColor = lipgloss.Color("#ff0000")
+			scoreColor = themeColor("#ff0000")
 		} else if *state.ReadyScore < 75 {
-			scoreColor = lipgloss.Color("#ffff00")
+			scoreColor = themeColor("#ffff00")
 		}
 		scoreStyle := valueStyle.Foreground(scoreColor)
 
 		content += fmt.Sprintf("%s %s\n\n",
 			labelStyle.Render("Ready Score:"),
-			scoreStyle.Render(fmt.Sprintf("%.1f / 100", *state.ReadyScore)),
+			scoreStyle.Render(fmt.Sprintf("%s %.1f / 100", scoreSymbol(*state.ReadyScore), *state.ReadyScore)),
 		)
 	}
 
@@ -145,7 +275,7 @@ func (v *HealthView) renderHealthStatus(state *model.VehicleState, sectionStyle,
 		valueStyle.Render(timeText),
 	)
 
-	return sectionStyle.Width(35).Render("🩺 Current Status\n\n" + content)
+	return sectionStyle.Width(35).Render(v.heading(HealthFocusStatus, "🩺 Current Status", lipgloss.NewStyle()) + "\n\n" + content)
 }
 
 func (v *HealthView) renderTrends(state *model.VehicleState, sectionStyle, labelStyle, valueStyle lipgloss.Style) string {
@@ -169,6 +299,16 @@ func (v *HealthView) renderTrends(state *model.VehicleState, sectionStyle, label
 		v.renderTrendIndicator(rangeTrend),
 	)
 
+	// Tire trends (slow leak detection)
+	if leaks := model.TirePressureTrendIssues(v.history); len(leaks) > 0 {
+		leakStyle := lipgloss.NewStyle().Foreground(themeColor("#ffff00"))
+		content += v.heading(HealthFocusTires, "Tires:", labelStyle) + "\n"
+		for _, leak := range leaks {
+			content += leakStyle.Render("⚠ "+strings.TrimPrefix(leak, "Warning: ")) + "\n"
+		}
+		content += "\n"
+	}
+
 	// Recent activity
 	recent := v.history[0]
 	oldest := v.history[len(v.history)-1]
@@ -196,50 +336,58 @@ func (v *HealthView) renderDiagnostics(state *model.VehicleState, sectionStyle,
 		content += labelStyle.Render("🌡️  Temperature") + "\n"
 		if state.CabinTemp != nil {
 			content += fmt.Sprintf("   Cabin: %s\n",
-				valueStyle.Render(fmt.Sprintf("%.1f°F", *state.CabinTemp)),
+				valueStyle.Render(v.tempUnit.Format(*state.CabinTemp)),
 			)
 		}
 		if state.ExteriorTemp != nil {
 			content += fmt.Sprintf("   Exterior: %s\n",
-				valueStyle.Render(fmt.Sprintf("%.1f°F", *state.ExteriorTemp)),
+				valueStyle.Render(v.tempUnit.Format(*state.ExteriorTemp)),
 			)
 		}
 		content += "\n"
 	}
 
 	// Closure status
-	content += labelStyle.Render("🚪 Closures") + "\n"
+	content += v.heading(HealthFocusClosures, "🚪 Closures", labelStyle) + "\n"
 	content += v.renderClosureStatus("   Doors", state.Doors, valueStyle)
 	content += v.renderClosureStatus("   Windows", state.Windows, valueStyle)
 
-	if state.Frunk != model.ClosureStatusUnknown {
-		frunkStatus := "closed"
-		frunkColor := lipgloss.Color("#00ff00")
+	profile := state.Profile()
+
+	if profile.HasFrunk && state.Frunk != model.ClosureStatusUnknown {
+		frunkStatus := symbolOK + " closed"
+		frunkColor := themeColor("#00ff00")
 		if state.Frunk == model.ClosureStatusOpen {
-			frunkStatus = "open"
-			frunkColor = lipgloss.Color("#ff0000")
+			frunkStatus = symbolWarning + " open"
+			frunkColor = themeColor("#ff0000")
 		}
 		content += fmt.Sprintf("   Frunk: %s\n",
 			valueStyle.Foreground(frunkColor).Render(frunkStatus),
 		)
 	}
 
-	if state.Liftgate != model.ClosureStatusUnknown {
-		liftgateStatus := "closed"
-		liftgateColor := lipgloss.Color("#00ff00")
+	if profile.HasLiftgate && state.Liftgate != model.ClosureStatusUnknown {
+		liftgateStatus := symbolOK + " closed"
+		liftgateColor := themeColor("#00ff00")
 		if state.Liftgate == model.ClosureStatusOpen {
-			liftgateStatus = "open"
-			liftgateColor = lipgloss.Color("#ff0000")
+			liftgateStatus = symbolWarning + " open"
+			liftgateColor = themeColor("#ff0000")
 		}
 		content += fmt.Sprintf("   Liftgate: %s\n",
 			valueStyle.Foreground(liftgateColor).Render(liftgateStatus),
 		)
 	}
 
+	// How long anything has been left open
+	openStyle := lipgloss.NewStyle().Foreground(themeColor("#ffff00"))
+	for _, issue := range model.ClosureOpenIssues(v.historyWith(state), time.Now()) {
+		content += "   " + openStyle.Render(symbolWarning+" "+strings.TrimPrefix(issue, "Warning: ")) + "\n"
+	}
+
 	// Tire pressures (if available and meaningful)
 	if state.TirePressures.FrontLeft > 0 || state.TirePressures.FrontRight > 0 ||
 		state.TirePressures.RearLeft > 0 || state.TirePressures.RearRight > 0 {
-		content += "\n" + labelStyle.Render("🛞 Tires") + "\n"
+		content += "\n" + v.heading(HealthFocusTires, "🛞 Tires", labelStyle) + "\n"
 
 		if state.TirePressures.FrontLeft > 0 {
 			content += fmt.Sprintf("   FL: %s  FR: %s\n",
@@ -253,14 +401,44 @@ func (v *HealthView) renderDiagnostics(state *model.VehicleState, sectionStyle,
 		}
 	}
 
+	// Software (only once the version query has succeeded)
+	if state.SoftwareVersion != "" {
+		content += "\n" + labelStyle.Render("💾 Software") + "\n"
+		content += fmt.Sprintf("   Version: %s\n", valueStyle.Render(state.SoftwareVersion))
+		if state.AvailableUpdate != "" {
+			content += fmt.Sprintf("   Update: %s\n",
+				valueStyle.Foreground(themeColor("#ffff00")).Render(symbolWarning+" "+state.AvailableUpdate+" available"),
+			)
+		} else {
+			content += fmt.Sprintf("   Update: %s\n",
+				valueStyle.Foreground(themeColor("#00ff00")).Render(symbolOK+" up to date"),
+			)
+		}
+	}
+
 	return sectionStyle.Width(72).Render("🔧 Diagnostics\n\n" + content)
 }
 
+// historyWith returns the cached history with state prepended when it is
+// newer than the latest cached snapshot.
+func (v *HealthView) historyWith(state *model.VehicleState) []*model.VehicleState {
+	return withLatest(state, v.history)
+}
+
+// withLatest returns history (newest first) with state prepended when it is
+// newer than the latest snapshot in it.
+func withLatest(state *model.VehicleState, history []*model.VehicleState) []*model.VehicleState {
+	if len(history) > 0 && !state.UpdatedAt.After(history[0].UpdatedAt) {
+		return history
+	}
+	return append([]*model.VehicleState{state}, history...)
+}
+
 func (v *HealthView) renderClosureStatus(label string, closures model.Closures, valueStyle lipgloss.Style) string {
 	if closures.AllClosed() {
 		return fmt.Sprintf("%s: %s\n",
 			label,
-			valueStyle.Foreground(lipgloss.Color("#00ff00")).Render("all closed"),
+			valueStyle.Foreground(themeColor("#00ff00")).Render(symbolOK+" all closed"),
 		)
 	}
 
@@ -281,7 +459,7 @@ func (v *HealthView) renderClosureStatus(label string, closures model.Closures,
 	if len(openList) > 0 {
 		return fmt.Sprintf("%s: %s\n",
 			label,
-			valueStyle.Foreground(lipgloss.Color("#ff0000")).Render(strings.Join(openList, ", ")+" open"),
+			valueStyle.Foreground(themeColor("#ff0000")).Render(symbolWarning+" "+strings.Join(openList, ", ")+" open"),
 		)
 	}
 
@@ -289,14 +467,17 @@ func (v *HealthView) renderClosureStatus(label string, closures model.Closures,
 }
 
 func (v *HealthView) formatTirePressure(pressure float64, valueStyle lipgloss.Style) string {
-	color := lipgloss.Color("#00ff00")
+	color := themeColor("#00ff00")
+	symbol := symbolOK
 	if pressure < 30 {
-		color = lipgloss.Color("#ff0000")
+		color = themeColor("#ff0000")
+		symbol = symbolCritical
 	} else if pressure < 35 {
-		color = lipgloss.Color("#ffff00")
+		color = themeColor("#ffff00")
+		symbol = symbolWarning
 	}
 
-	return valueStyle.Foreground(color).Render(fmt.Sprintf("%.1f PSI", pressure))
+	return valueStyle.Foreground(color).Render(fmt.Sprintf("%s %.1f PSI", symbol, pressure))
 }
 
 func (v *HealthView) calculateTrend(metric string) float64 {
@@ -321,11 +502,11 @@ func (v *HealthView) calculateTrend(metric string) float64 {
 func (v *HealthView) renderTrendIndicator(change float64) string {
 	switch {
 	case change > 5:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render(fmt.Sprintf("↑ +%.1f (increasing)", change))
+		return lipgloss.NewStyle().Foreground(themeColor("#00ff00")).Render(fmt.Sprintf("↑ +%.1f (increasing)", change))
 	case change < -5:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Render(fmt.Sprintf("↓ %.1f (decreasing)", change))
+		return lipgloss.NewStyle().Foreground(themeColor("#ff0000")).Render(fmt.Sprintf("↓ %.1f (decreasing)", change))
 	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("→ stable")
+		return lipgloss.NewStyle().Foreground(themeColor("#888888")).Render("→ stable")
 	}
 }
 
@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 	"github.com/pfrederiksen/rivian-ls/internal/store"
@@ -16,12 +18,12 @@ func TestNewHealthView(t *testing.T) {
 	}
 	defer func() { _ = tmpStore.Close() }()
 
-	view := NewHealthView(tmpStore, "test-vehicle-id")
+	view := NewHealthView(NewHistoryCache(tmpStore), "test-vehicle-id")
 	if view == nil {
 		t.Fatal("NewHealthView returned nil")
 	}
-	if view.store == nil {
-		t.Error("HealthView store is nil")
+	if view.cache == nil {
+		t.Error("HealthView cache is nil")
 	}
 	if view.vehicleID != "test-vehicle-id" {
 		t.Errorf("Expected vehicleID 'test-vehicle-id', got %q", view.vehicleID)
@@ -35,10 +37,10 @@ func TestHealthViewRender(t *testing.T) {
 	}
 	defer func() { _ = tmpStore.Close() }()
 
-	view := NewHealthView(tmpStore, "test-vehicle-id")
+	view := NewHealthView(NewHistoryCache(tmpStore), "test-vehicle-id")
 	state := createTestState()
 
-	output := view.Render(state, 120, 40)
+	output := view.Render(context.Background(), state, 120, 40)
 
 	// Check for key sections
 	expectedSections := []string{
@@ -62,7 +64,7 @@ func TestHealthViewOnlineStatus(t *testing.T) {
 	}
 	defer func() { _ = tmpStore.Close() }()
 
-	view := NewHealthView(tmpStore, "test-vehicle-id")
+	view := NewHealthView(NewHistoryCache(tmpStore), "test-vehicle-id")
 
 	tests := []struct {
 		name         string
@@ -86,7 +88,7 @@ func TestHealthViewOnlineStatus(t *testing.T) {
 			state := createTestState()
 			state.IsOnline = tt.isOnline
 
-			output := view.Render(state, 120, 40)
+			output := view.Render(context.Background(), state, 120, 40)
 
 			if !strings.Contains(output, tt.expectedText) {
 				t.Errorf("Expected %q in output, got: %s", tt.expectedText, output)
@@ -102,7 +104,7 @@ func TestHealthViewWithIssues(t *testing.T) {
 	}
 	defer func() { _ = tmpStore.Close() }()
 
-	view := NewHealthView(tmpStore, "test-vehicle-id")
+	view := NewHealthView(NewHistoryCache(tmpStore), "test-vehicle-id")
 
 	tests := []struct {
 		name         string
@@ -134,7 +136,7 @@ func TestHealthViewWithIssues(t *testing.T) {
 			state := createTestState()
 			tt.setupState(state)
 
-			output := view.Render(state, 120, 40)
+			output := view.Render(context.Background(), state, 120, 40)
 
 			if !strings.Contains(output, tt.expectedText) {
 				t.Errorf("Expected %q in output, got: %s", tt.expectedText, output)
@@ -142,3 +144,131 @@ func TestHealthViewWithIssues(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthViewSlowLeakTrend(t *testing.T) {
+	view := NewHealthView(nil, "test-vehicle-id")
+
+	now := time.Now()
+	statuses := []model.TirePressureStatus{
+		model.TirePressureStatusLow,
+		model.TirePressureStatusLow,
+		model.TirePressureStatusLow,
+		model.TirePressureStatusOK,
+	}
+	for i, status := range statuses {
+		s := createTestState()
+		s.UpdatedAt = now.Add(-time.Duration(i) * 4 * time.Hour)
+		s.TirePressures.RearLeftStatus = status
+		view.history = append(view.history, s)
+	}
+
+	output := view.Render(context.Background(), createTestState(), 120, 40)
+
+	if !strings.Contains(output, "Possible slow leak: rear left") {
+		t.Errorf("Expected slow leak warning in output, got: %s", output)
+	}
+}
+
+func TestHealthViewTempRange(t *testing.T) {
+	view := NewHealthView(nil, "test-vehicle-id")
+	view.tempRange = []model.TempRangePoint{
+		{TempLow: 20, TempHigh: 30, Samples: 4, AvgFullRange: 220, RangeLoss: 27},
+		{TempLow: 70, TempHigh: 80, Samples: 6, AvgFullRange: 300},
+	}
+
+	output := view.Render(context.Background(), createTestState(), 120, 40)
+
+	for _, want := range []string{"Range vs. Temperature", "220 mi at 100%", "-27%", "6 samples"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+}
+
+func TestHealthViewSoftware(t *testing.T) {
+	view := NewHealthView(nil, "test-vehicle-id")
+	state := createTestState()
+
+	if strings.Contains(view.Render(context.Background(), state, 120, 40), "Software") {
+		t.Error("software section should be hidden until the version is known")
+	}
+
+	state.SoftwareVersion = "2024.10.0"
+	output := view.Render(context.Background(), state, 120, 40)
+	if !strings.Contains(output, "2024.10.0") || !strings.Contains(output, "up to date") {
+		t.Errorf("expected installed version and up-to-date status, got:\n%s", output)
+	}
+
+	state.AvailableUpdate = "2024.12.1"
+	if output := view.Render(context.Background(), state, 120, 40); !strings.Contains(output, "2024.12.1 available") {
+		t.Errorf("expected available update, got:\n%s", output)
+	}
+}
+
+func TestHealthViewClosureOpenDuration(t *testing.T) {
+	view := NewHealthView(nil, "test-vehicle-id")
+	state := createTestState()
+	state.UpdatedAt = time.Now()
+	state.Windows.FrontLeft = model.ClosureStatusOpen
+
+	earlier := *state
+	earlier.UpdatedAt = state.UpdatedAt.Add(-3 * time.Hour)
+	closedBefore := *state
+	closedBefore.UpdatedAt = state.UpdatedAt.Add(-4 * time.Hour)
+	closedBefore.Windows.FrontLeft = model.ClosureStatusClosed
+	view.history = []*model.VehicleState{&earlier, &closedBefore}
+
+	output := view.Render(context.Background(), state, 120, 40)
+	if !strings.Contains(output, "Front-left window open for 3h") {
+		t.Errorf("expected open duration in diagnostics, got:\n%s", output)
+	}
+}
+
+func TestIssueFocus(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*model.VehicleState)
+		want  HealthFocus
+	}{
+		{"no issues", func(s *model.VehicleState) { s.IsLocked = true }, HealthFocusNone},
+		{"unlocked is info only", func(s *model.VehicleState) {}, HealthFocusNone},
+		{"door open", func(s *model.VehicleState) { s.Doors.FrontLeft = model.ClosureStatusOpen }, HealthFocusClosures},
+		{"offline", func(s *model.VehicleState) { s.IsLocked = true; s.IsOnline = false }, HealthFocusStatus},
+		{"critical range before open door", func(s *model.VehicleState) {
+			s.Doors.FrontLeft = model.ClosureStatusOpen
+			s.RangeStatus = model.RangeStatusCritical
+		}, HealthFocusStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := createTestState()
+			tt.setup(state)
+			if got := issueFocus(state); got != tt.want {
+				t.Errorf("issueFocus() = %q, want %q (issues: %v)", got, tt.want, state.GetIssues())
+			}
+		})
+	}
+}
+
+func TestHealthViewFocus(t *testing.T) {
+	view := NewHealthView(nil, "test-vehicle-id")
+	state := createTestState()
+
+	output := view.Render(context.Background(), state, 120, 40)
+	if strings.Contains(output, "▶") {
+		t.Errorf("unfocused view should not mark a section, got: %s", output)
+	}
+	if strings.Index(output, "Diagnostics") < strings.Index(output, "Current Status") {
+		t.Error("diagnostics should follow the status section by default")
+	}
+
+	view.SetFocus(HealthFocusClosures)
+	output = view.Render(context.Background(), state, 120, 40)
+	if !strings.Contains(output, "▶ 🚪 Closures") {
+		t.Errorf("closures heading should be marked, got: %s", output)
+	}
+	if strings.Index(output, "Diagnostics") > strings.Index(output, "Current Status") {
+		t.Error("focused diagnostics should come before the status section")
+	}
+}
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+// historyCacheTTL is how long a cached history window is reused before it is
+// reloaded from the store.
+const historyCacheTTL = 30 * time.Second
+
+// historyKey identifies one cached history window.
+type historyKey struct {
+	vehicleID string
+	window    time.Duration
+	limit     int
+	peakLevel float64 // Set for GetDailyPeaks windows
+}
+
+type historyEntry struct {
+	states   []*model.VehicleState // Newest first, at most key.limit states
+	total    int                   // States in the window when loaded, before the limit
+	loadedAt time.Time
+}
+
+// HistoryCache is an in-memory cache of store history shared by the TUI
+// views, so each window is queried once per TTL instead of once per view.
+// Each entry is bounded by its limit; live states are merged in with Append.
+// It is safe for concurrent use. Returned slices are never modified
+// afterwards, so callers can keep rendering from them.
+type HistoryCache struct {
+	store *store.Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex // Guards entries
+	entries map[historyKey]*historyEntry
+}
+
+// NewHistoryCache creates a history cache backed by the given store.
+// A nil store yields a cache that always returns no history.
+func NewHistoryCache(store *store.Store) *HistoryCache {
+	return &HistoryCache{
+		store:   store,
+		ttl:     historyCacheTTL,
+		entries: make(map[historyKey]*historyEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns up to limit states for the vehicle from the last window,
+// newest first. Results are served from the cache until they expire.
+// On a store error (including ctx being canceled) the previously cached
+// states (if any) are returned.
+func (c *HistoryCache) Get(ctx context.Context, vehicleID string, window time.Duration, limit int) []*model.VehicleState {
+	states, _ := c.GetWithTotal(ctx, vehicleID, window, limit)
+	return states
+}
+
+// GetWithTotal is Get that also returns Total for the same snapshot, so the
+// two agree even if Append runs in between.
+func (c *HistoryCache) GetWithTotal(ctx context.Context, vehicleID string, window time.Duration, limit int) ([]*model.VehicleState, int) {
+	key := historyKey{vehicleID: vehicleID, window: window, limit: limit}
+	return c.get(key, func(now time.Time) ([]*model.VehicleState, int, error) {
+		states, err := c.store.GetStateHistory(ctx, vehicleID, now.Add(-window), limit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Only a full result can have been truncated by the limit
+		total := len(states)
+		if total == limit {
+			if n, err := c.store.CountStates(ctx, vehicleID, now.Add(-window), now); err == nil {
+				total = max(n, total)
+			}
+		}
+		return states, total, nil
+	})
+}
+
+// GetDailyPeaks returns the highest-charge state at or above minLevel for
+// each day of the window, newest first (see store.GetDailyPeaks), cached
+// like Get. Live states aren't merged in; they show up on the next reload.
+func (c *HistoryCache) GetDailyPeaks(ctx context.Context, vehicleID string, window time.Duration, minLevel float64) []*model.VehicleState {
+	key := historyKey{vehicleID: vehicleID, window: window, peakLevel: minLevel}
+	states, _ := c.get(key, func(now time.Time) ([]*model.VehicleState, int, error) {
+		states, err := c.store.GetDailyPeaks(ctx, vehicleID, now.Add(-window), minLevel)
+		return states, len(states), err
+	})
+	return states
+}
+
+// get returns the cached entry for key, reloading it with load once it
+// has expired. On a load error the previously cached states (if any) are
+// returned.
+func (c *HistoryCache) get(key historyKey, load func(now time.Time) ([]*model.VehicleState, int, error)) ([]*model.VehicleState, int) {
+	if c == nil {
+		return nil, 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && (c.store == nil || c.now().Sub(entry.loadedAt) < c.ttl) {
+		return entry.states, entry.count()
+	}
+	if c.store == nil {
+		return nil, 0
+	}
+
+	now := c.now()
+	states, total, err := load(now)
+	if err != nil {
+		if ok {
+			return entry.states, entry.count()
+		}
+		return nil, 0
+	}
+
+	entry = &historyEntry{states: states, total: total, loadedAt: now}
+	c.entries[key] = entry
+	return states, entry.count()
+}
+
+// Total returns how many states the window held when it was last loaded by
+// Get, which exceeds len(Get(...)) when the limit truncated it. It is 0 for
+// windows that were never loaded.
+func (c *HistoryCache) Total(vehicleID string, window time.Duration, limit int) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[historyKey{vehicleID: vehicleID, window: window, limit: limit}]
+	if !ok {
+		return 0
+	}
+	return entry.count()
+}
+
+// count returns how many states the entry's window holds.
+func (e *historyEntry) count() int {
+	return max(e.total, len(e.states))
+}
+
+// Append adds a newly received state to every cached window for its
+// vehicle, so views update live without waiting for a reload. States that
+// are not newer than the latest cached state are ignored, as are states
+// with missing telemetry, which the store doesn't keep either.
+func (c *HistoryCache) Append(state *model.VehicleState) {
+	if c == nil || state == nil || state.TelemetryMissing {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if key.vehicleID != state.VehicleID || key.peakLevel > 0 {
+			continue
+		}
+		if len(entry.states) > 0 && !state.UpdatedAt.After(entry.states[0].UpdatedAt) {
+			continue
+		}
+
+		states := append([]*model.VehicleState{state}, entry.states...)
+		total := entry.count() + 1
+
+		// Drop states that fell out of the window, then enforce the limit.
+		// States cut by the limit are still in the window and still count.
+		cutoff := c.now().Add(-key.window)
+		for len(states) > 0 && states[len(states)-1].UpdatedAt.Before(cutoff) {
+			states = states[:len(states)-1]
+			total--
+		}
+		if len(states) > key.limit {
+			states = states[:key.limit]
+		}
+
+		entry.states = states
+		entry.total = max(total, len(states))
+	}
+}
@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/store"
+)
+
+func TestHistoryCache(t *testing.T) {
+	db, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		s := createTestState()
+		s.UpdatedAt = now.Add(-time.Duration(3-i) * time.Hour)
+		if err := db.SaveState(ctx, s); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	cache := NewHistoryCache(db)
+	cache.now = func() time.Time { return now }
+	vehicleID := createTestState().VehicleID
+
+	history := cache.Get(ctx, vehicleID, 24*time.Hour, 3)
+	if len(history) != 3 {
+		t.Fatalf("Get() returned %d states, want 3", len(history))
+	}
+
+	// Within the TTL, new rows aren't picked up
+	extra := createTestState()
+	extra.UpdatedAt = now.Add(-30 * time.Minute)
+	if err := db.SaveState(ctx, extra); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	if got := cache.Get(ctx, vehicleID, 24*time.Hour, 3); got[0].UpdatedAt.Equal(extra.UpdatedAt) {
+		t.Error("Get() reloaded before the TTL expired")
+	}
+
+	// Append adds newer live states and keeps the window bounded
+	live := createTestState()
+	live.UpdatedAt = now
+	cache.Append(live)
+	history = cache.Get(ctx, vehicleID, 24*time.Hour, 3)
+	if len(history) != 3 || history[0] != live {
+		t.Errorf("Append() should prepend the live state and trim to the limit, got %d states", len(history))
+	}
+
+	// Older states and other vehicles are ignored
+	stale := createTestState()
+	stale.UpdatedAt = now.Add(-time.Hour)
+	cache.Append(stale)
+	other := createTestState()
+	other.VehicleID = "other-vehicle"
+	other.UpdatedAt = now.Add(time.Minute)
+	cache.Append(other)
+	missing := createTestState()
+	missing.UpdatedAt = now.Add(time.Minute)
+	missing.TelemetryMissing = true
+	cache.Append(missing)
+	if got := cache.Get(ctx, vehicleID, 24*time.Hour, 3); got[0] != live {
+		t.Error("Append() should ignore stale states, other vehicles and missing telemetry")
+	}
+
+	// After the TTL, the cache reloads from the store
+	cache.now = func() time.Time { return now.Add(historyCacheTTL) }
+	if got := cache.Get(ctx, vehicleID, 24*time.Hour, 3); !got[0].UpdatedAt.Equal(extra.UpdatedAt) {
+		t.Errorf("Get() after TTL should reload, newest = %v, want %v", got[0].UpdatedAt, extra.UpdatedAt)
+	}
+
+	// A canceled query falls back to the cached window, or to nothing
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	cache.now = func() time.Time { return now.Add(2 * historyCacheTTL) }
+	if got := cache.Get(canceled, vehicleID, 24*time.Hour, 3); !got[0].UpdatedAt.Equal(extra.UpdatedAt) {
+		t.Error("Get() with a canceled context should return the cached states")
+	}
+	if got := cache.Get(canceled, vehicleID, time.Hour, 3); got != nil {
+		t.Errorf("Get() with a canceled context and no cached window = %v, want nil", got)
+	}
+}
+
+func TestChartsView_DegradationLoadsDailyPeaks(t *testing.T) {
+	db, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// A year-old full charge followed by many recent low readings, more
+	// than a newest-rows limit would reach past
+	ctx := context.Background()
+	now := time.Now()
+	old := createTestState()
+	old.UpdatedAt, old.BatteryLevel, old.Odometer = now.Add(-365*24*time.Hour), 90, 1000
+	if err := db.SaveState(ctx, old); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		s := createTestState()
+		s.UpdatedAt = now.Add(-time.Duration(i) * time.Minute)
+		if err := db.SaveState(ctx, s); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	view := NewChartsView(NewHistoryCache(db), old.VehicleID)
+	view.selectedMetric = MetricDegradation
+	view.loadHistory(ctx)
+	if len(view.history) != 1 || view.history[0].Odometer != 1000 || view.truncationNote() != "" {
+		t.Errorf("degradation history = %d states, want only the year-old full charge", len(view.history))
+	}
+}
+
+func TestHistoryCache_NilStore(t *testing.T) {
+	cache := NewHistoryCache(nil)
+	if got := cache.Get(context.Background(), "vehicle", time.Hour, 10); got != nil {
+		t.Errorf("Get() with nil store = %v, want nil", got)
+	}
+	cache.Append(createTestState()) // must not panic
+
+	var nilCache *HistoryCache
+	if got := nilCache.Get(context.Background(), "vehicle", time.Hour, 10); got != nil {
+		t.Errorf("nil cache Get() = %v, want nil", got)
+	}
+}
+
+func TestChartsView_UsesSharedCache(t *testing.T) {
+	cache := NewHistoryCache(nil)
+	state := &model.VehicleState{VehicleID: "v1", BatteryLevel: 70, UpdatedAt: time.Now()}
+	cache.entries[historyKey{vehicleID: "v1", window: 24 * time.Hour, limit: 100}] = &historyEntry{loadedAt: time.Now()}
+
+	view := NewChartsView(cache, "v1")
+	cache.Append(state)
+	view.loadHistory(context.Background())
+
+	if len(view.history) != 1 || view.history[0] != state {
+		t.Errorf("charts history = %v, want the appended live state", view.history)
+	}
+}
+
+func TestHistoryCache_Total(t *testing.T) {
+	db, err := store.NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		s := createTestState()
+		s.UpdatedAt = now.Add(-time.Duration(5-i) * time.Hour)
+		if err := db.SaveState(ctx, s); err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+	}
+
+	cache := NewHistoryCache(db)
+	cache.now = func() time.Time { return now }
+	vehicleID := createTestState().VehicleID
+
+	if got := cache.Total(vehicleID, 24*time.Hour, 3); got != 0 {
+		t.Errorf("Total() before loading = %d, want 0", got)
+	}
+	if history := cache.Get(ctx, vehicleID, 24*time.Hour, 3); len(history) != 3 {
+		t.Fatalf("Get() returned %d states, want 3", len(history))
+	}
+	if got := cache.Total(vehicleID, 24*time.Hour, 3); got != 5 {
+		t.Errorf("Total() of a truncated window = %d, want 5", got)
+	}
+
+	cache.Get(ctx, vehicleID, 24*time.Hour, 10)
+	if got := cache.Total(vehicleID, 24*time.Hour, 10); got != 5 {
+		t.Errorf("Total() of a complete window = %d, want 5", got)
+	}
+
+	// Live states that push old ones out of the window keep the count honest
+	cache.Get(ctx, vehicleID, 6*time.Hour, 10)
+	later := now.Add(2 * time.Hour)
+	cache.now = func() time.Time { return later }
+	live := createTestState()
+	live.UpdatedAt = later
+	cache.Append(live)
+	if got := cache.Total(vehicleID, 6*time.Hour, 10); got != 5 {
+		t.Errorf("Total() after Append trimmed the window = %d, want 5", got)
+	}
+}
+
+func TestChartsView_TruncationNote(t *testing.T) {
+	now := time.Now()
+	states := make([]*model.VehicleState, 100)
+	for i := range states {
+		states[i] = &model.VehicleState{VehicleID: "v1", BatteryLevel: 70, UpdatedAt: now.Add(-time.Duration(i) * time.Minute)}
+	}
+	cache := NewHistoryCache(nil)
+	cache.entries[historyKey{vehicleID: "v1", window: 24 * time.Hour, limit: 100}] = &historyEntry{states: states, total: 450, loadedAt: now}
+
+	view := NewChartsView(cache, "v1")
+	out := view.Render(context.Background(), states[0], 120, 40)
+	if !strings.Contains(out, "showing last 100 of 450 points") {
+		t.Errorf("chart caption should note the truncation:\n%s", out)
+	}
+	if !strings.Contains(out, "Points:") || !strings.Contains(out, "100 of 450") {
+		t.Errorf("stats should include the point counts:\n%s", out)
+	}
+
+	cache.entries[historyKey{vehicleID: "v1", window: 24 * time.Hour, limit: 100}].total = 100
+	if out := view.Render(context.Background(), states[0], 120, 40); strings.Contains(out, "showing last") {
+		t.Errorf("complete window should not be annotated:\n%s", out)
+	}
+}
@@ -2,12 +2,16 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pfrederiksen/rivian-ls/internal/config"
 	"github.com/pfrederiksen/rivian-ls/internal/model"
 	"github.com/pfrederiksen/rivian-ls/internal/rivian"
 	"github.com/pfrederiksen/rivian-ls/internal/store"
@@ -21,27 +25,33 @@ const (
 	ViewCharge
 	ViewHealth
 	ViewCharts
+	ViewFleet
 )
 
 // Model is the main Bubble Tea model for the TUI
 type Model struct {
 	// Core dependencies
-	client  rivian.Client
-	store   *store.Store
+	client rivian.Client
+	store  *store.Store
 
 	// Multi-vehicle state
-	vehicles      []rivian.Vehicle                   // All available vehicles
-	activeVehicle int                                // Currently selected vehicle index
-	vehicleStates map[string]*model.VehicleState     // vehicleID -> cached state
-	reducers      map[string]*model.Reducer          // vehicleID -> reducer instance
-	wsClients     map[string]*rivian.WebSocketClient // vehicleID -> WebSocket client
+	vehicles      []rivian.Vehicle                    // All available vehicles
+	activeVehicle int                                 // Currently selected vehicle index
+	vehicleStates map[string]*model.VehicleState      // vehicleID -> cached state
+	reducers      map[string]*model.Reducer           // vehicleID -> reducer instance
+	subCancels    map[string]context.CancelFunc       // vehicleID -> stops the live subscription
 	updateChans   map[string]chan *model.VehicleState // vehicleID -> update channel
+	fieldChans    map[string]chan []string            // vehicleID -> resubscribe with these fields
+	endErrs       map[string]chan error               // vehicleID -> why the server ended the subscription
+	storedStates  map[string]*model.VehicleState      // vehicleID -> stored snapshot (fleet view only)
+	historyCache  *HistoryCache                       // Store history shared by the health and charts views
 
 	// Application state
 	currentView ViewType
 	state       *model.VehicleState // Current active vehicle's state
 	err         error
 	loading     bool
+	authErr     error // credentials expired and could not be refreshed
 
 	// Subscription state
 	ctx    context.Context
@@ -52,26 +62,70 @@ type Model struct {
 	chargeView    *ChargeView
 	healthView    *HealthView
 	chartsView    *ChartsView
+	fleetView     *FleetView
 
 	// Vehicle menu
 	showVehicleMenu bool
 	vehicleMenu     *VehicleMenu
 
+	// Command palette
+	showPalette bool
+	palette     *CommandPalette
+
 	// Terminal dimensions
 	width  int
 	height int
 
 	// Last update time
 	lastUpdate time.Time
+
+	// Real-time connection status shown in the header ("" until known)
+	liveStatus string
+	liveFailed bool // liveStatus reports why live updates failed
+
+	// Live-update watchdog (see watchdog.go)
+	liveSince   time.Time    // last live update, or last fallback refresh while stale
+	liveHeard   atomic.Int64 // UnixNano of the last live update, changed or not; set by the subscription loop
+	liveStale   bool         // no live update within staleLiveAfter
+	liveEnded   bool         // live updates ended or failed to start; polling instead
+	liveRetryAt time.Time    // when polling next retries live updates
+	watchdogGen int          // current watchdog; older ticks are ignored
+
+	// Showing a stored state until the API answers
+	offline bool
+
+	// HTTP polling interval while live updates are unavailable
+	pollInterval time.Duration
+
+	// Periodic HTTP refresh alongside live updates (0 = off)
+	autoRefresh time.Duration
+
+	// Show the header's update time in the local zone
+	localTime bool
+
+	// Temperature display unit for the dashboard and health views
+	tempUnit      TempUnit
+	tempUnitSaved bool // tempUnit was restored from preferences
+
+	// Linear text without boxes, for screen readers and dumb terminals
+	plain bool
+
+	// Records live-update events; never nil, discards by default
+	logger *slog.Logger
 }
 
-// NewModel creates a new TUI model with multi-vehicle support
-func NewModel(client rivian.Client, store *store.Store, vehicles []rivian.Vehicle, startIndex int) *Model {
+// NewModel creates a new TUI model with multi-vehicle support. The last
+// view, chart settings and vehicle are restored from prefs (nil = defaults);
+// pass a negative startIndex to use the remembered vehicle.
+func NewModel(client rivian.Client, store *store.Store, vehicles []rivian.Vehicle, startIndex int, prefs *config.Preferences) *Model {
 	ctx, cancel := context.WithCancel(context.Background())
+	if prefs == nil {
+		prefs = &config.Preferences{}
+	}
 
 	// Validate startIndex
 	if startIndex < 0 || startIndex >= len(vehicles) {
-		startIndex = 0
+		startIndex = preferredVehicle(vehicles, prefs.VehicleID)
 	}
 
 	// Initialize vehicleID for views
@@ -80,43 +134,115 @@ func NewModel(client rivian.Client, store *store.Store, vehicles []rivian.Vehicl
 		vehicleID = vehicles[startIndex].ID
 	}
 
-	return &Model{
+	historyCache := NewHistoryCache(store)
+
+	m := &Model{
 		client:        client,
 		store:         store,
 		vehicles:      vehicles,
 		activeVehicle: startIndex,
 		vehicleStates: make(map[string]*model.VehicleState),
 		reducers:      make(map[string]*model.Reducer),
-		wsClients:     make(map[string]*rivian.WebSocketClient),
+		subCancels:    make(map[string]context.CancelFunc),
 		updateChans:   make(map[string]chan *model.VehicleState),
+		fieldChans:    make(map[string]chan []string),
+		endErrs:       make(map[string]chan error),
+		storedStates:  make(map[string]*model.VehicleState),
+		historyCache:  historyCache,
 		currentView:   ViewDashboard,
 		loading:       true,
 		ctx:           ctx,
 		cancel:        cancel,
-		dashboardView: NewDashboardView(),
-		chargeView:    NewChargeView(),
-		healthView:    NewHealthView(store, vehicleID),
-		chartsView:    NewChartsView(store, vehicleID),
+		dashboardView: NewDashboardView(historyCache),
+		chargeView:    NewChargeView(historyCache),
+		healthView:    NewHealthView(historyCache, vehicleID),
+		chartsView:    NewChartsView(historyCache, vehicleID),
+		fleetView:     NewFleetView(),
+		pollInterval:  endedPollInterval,
+		logger:        slog.New(slog.DiscardHandler),
+	}
+	m.applyPreferences(prefs)
+
+	return m
+}
+
+// SetLocalTime controls whether the header shows the vehicle's last update
+// time converted to the local time zone.
+func (m *Model) SetLocalTime(local bool) {
+	m.localTime = local
+}
+
+// SetTempUnit sets the temperature display unit for the dashboard and
+// health views.
+func (m *Model) SetTempUnit(unit TempUnit) {
+	m.tempUnit = unit
+	m.dashboardView.SetTempUnit(unit)
+	m.healthView.SetTempUnit(unit)
+}
+
+// SetDefaultTempUnit sets the temperature display unit unless one was
+// remembered from an earlier run, where the user toggled it.
+func (m *Model) SetDefaultTempUnit(unit TempUnit) {
+	if !m.tempUnitSaved {
+		m.SetTempUnit(unit)
 	}
 }
 
+// SetReadyBy shows in the charge view whether charging will reach the
+// charge limit by the given time of day
+func (m *Model) SetReadyBy(clock time.Duration) {
+	m.chargeView.SetReadyBy(clock)
+}
+
+// SetPlain renders the dashboard, charge and health views as linear plain
+// text without boxes or columns. Navigation keys are unchanged.
+func (m *Model) SetPlain(plain bool) {
+	m.plain = plain
+	m.dashboardView.SetPlain(plain)
+	m.chargeView.SetPlain(plain)
+	m.healthView.SetPlain(plain)
+}
+
+// SetLogger records WebSocket connects, disconnects, reconnects and
+// subscription errors of the live updates. Logging must not write to the
+// terminal the TUI draws on; nil turns it off.
+func (m *Model) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	m.logger = logger
+}
+
 // Init initializes the model (Bubble Tea lifecycle method)
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.fetchInitialState(),
 		m.subscribeToUpdates(),
 		// Note: We don't call waitForUpdates() here because if WebSocket
 		// connection fails, nothing will ever be sent to the channel.
 		// waitForUpdates() is only called after receiving the first update.
 		tea.EnterAltScreen,
-	)
+	}
+	// A restored fleet view needs its states loaded like when switching to it
+	if m.currentView == ViewFleet {
+		cmds = append(cmds, m.loadFleetStates())
+	}
+	if tick := m.autoRefreshTick(); tick != nil {
+		cmds = append(cmds, tick)
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages and updates the model (Bubble Tea lifecycle method)
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		return m.handleKeyPress(msg)
+		wasCharge := m.currentView == ViewCharge
+		updated, cmd := m.handleKeyPress(msg)
+		if wasCharge != (m.currentView == ViewCharge) {
+			m.resubscribe()
+		}
+		return updated, cmd
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -130,21 +256,77 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.state = msg.state
+		m.offline = msg.stored
 		m.lastUpdate = time.Now()
 		return m, nil
 
 	case stateUpdateMsg:
 		m.state = msg.state
+		m.vehicleStates[msg.state.VehicleID] = msg.state
+		m.offline = false
+		m.historyCache.Append(msg.state)
 		m.lastUpdate = time.Now()
+		m.liveSince = m.lastUpdate
+		m.liveStale = false
 		return m, m.waitForUpdates()
 
 	case errMsg:
 		m.err = msg.err
 		return m, nil
 
+	case authExpiredMsg:
+		m.loading = false
+		m.authErr = msg.err
+		m.stopWatchdog()
+		return m, nil
+
 	case wsConnectedMsg:
 		// WebSocket connected successfully, start waiting for updates
-		return m, m.waitForUpdates()
+		m.liveStatus = liveStatusText(msg.refreshed, nil)
+		m.liveFailed = false
+		return m, tea.Batch(m.waitForUpdates(), m.startWatchdog())
+
+	case liveFailedMsg:
+		m.logger.Warn("live updates unavailable, polling", "error", msg.err, "poll_interval", m.pollInterval)
+		m.liveStatus = liveStatusText(false, msg.err)
+		m.liveFailed = true
+		return m, m.startPolling()
+
+	case liveEndedMsg:
+		// Ignore endings of subscriptions that were already replaced
+		if len(m.vehicles) > 0 && m.updateChans[m.vehicles[m.activeVehicle].ID] == msg.updates {
+			m.logger.Warn("live updates ended, polling", "vehicle_id", m.vehicles[m.activeVehicle].ID, "error", msg.err, "poll_interval", m.pollInterval)
+			if msg.err != nil {
+				// Rejected rather than completed: say so in the header
+				m.liveStatus = liveStatusText(false, msg.err)
+				m.liveFailed = true
+			}
+			m.endLive()
+		}
+		return m, nil
+
+	case watchdogMsg:
+		return m, m.handleWatchdog(msg, time.Now())
+
+	case autoRefreshMsg:
+		return m, tea.Batch(m.refreshState(), m.autoRefreshTick())
+
+	case refreshStateMsg:
+		m.applyRefreshedState(msg)
+		return m, nil
+
+	case fleetStatesMsg:
+		for id, state := range msg.states {
+			m.storedStates[id] = state
+		}
+		return m, nil
+
+	case vehicleListMsg:
+		// Non-fatal: keep the existing list if the refresh failed
+		if msg.err == nil {
+			m.applyVehicleList(msg.vehicles)
+		}
+		return m, nil
 
 	default:
 		return m, nil
@@ -157,6 +339,10 @@ func (m *Model) View() string {
 		return m.renderLoading()
 	}
 
+	if m.authErr != nil {
+		return m.renderAuthExpired()
+	}
+
 	if m.err != nil {
 		return m.renderError()
 	}
@@ -172,13 +358,15 @@ func (m *Model) View() string {
 	var content string
 	switch m.currentView {
 	case ViewDashboard:
-		content = m.dashboardView.Render(m.state, m.width, m.height-lipgloss.Height(header)-3)
+		content = m.dashboardView.Render(m.ctx, m.state, m.width, m.height-lipgloss.Height(header)-3)
 	case ViewCharge:
-		content = m.chargeView.Render(m.state, m.width, m.height-lipgloss.Height(header)-3)
+		content = m.chargeView.Render(m.ctx, m.state, m.width, m.height-lipgloss.Height(header)-3)
 	case ViewHealth:
-		content = m.healthView.Render(m.state, m.width, m.height-lipgloss.Height(header)-3)
+		content = m.healthView.Render(m.ctx, m.state, m.width, m.height-lipgloss.Height(header)-3)
 	case ViewCharts:
-		content = m.chartsView.Render(m.state, m.width, m.height-lipgloss.Height(header)-3)
+		content = m.chartsView.Render(m.ctx, m.state, m.width, m.height-lipgloss.Height(header)-3)
+	case ViewFleet:
+		content = m.fleetView.Render(m.vehicles, m.fleetStates(), m.vehicles[m.activeVehicle].ID, m.width, m.height-lipgloss.Height(header)-3)
 	}
 
 	// Render footer with keyboard shortcuts
@@ -199,7 +387,13 @@ func (m *Model) View() string {
 		// Layer menu over base - this creates the overlay effect
 		return menuOverlay
 	}
+	if m.showPalette && m.palette != nil {
+		return m.palette.Render(m.width, m.height)
+	}
 
+	if m.plain {
+		return plainText(baseView)
+	}
 	return baseView
 }
 
@@ -220,6 +414,18 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// If the command palette is open, route keys to it
+	if m.showPalette {
+		index, done := m.palette.HandleKey(msg.String())
+		if done {
+			m.showPalette = false
+			if index >= 0 {
+				return m, m.palette.actions[index].Run()
+			}
+		}
+		return m, nil
+	}
+
 	// Normal key handling when menu is closed
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -234,6 +440,11 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case ":":
+		m.palette = NewCommandPalette(m.paletteActions())
+		m.showPalette = true
+		return m, nil
+
 	case "1", "d":
 		m.currentView = ViewDashboard
 		return m, nil
@@ -243,6 +454,13 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "3", "h":
+		m.healthView.SetFocus(HealthFocusNone)
+		m.currentView = ViewHealth
+		return m, nil
+
+	case "i":
+		// Jump to the health section explaining the current issues
+		m.healthView.SetFocus(issueFocus(m.state))
 		m.currentView = ViewHealth
 		return m, nil
 
@@ -250,9 +468,13 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.currentView = ViewCharts
 		return m, nil
 
+	case "5", "f":
+		m.currentView = ViewFleet
+		return m, m.loadFleetStates()
+
 	case "r":
-		// Refresh data
-		return m, m.fetchInitialState()
+		// Refresh data and pick up any renamed vehicles
+		return m, tea.Batch(m.fetchInitialState(), m.refreshVehicleList())
 
 	case "left":
 		// Switch to previous metric in charts view
@@ -275,6 +497,18 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "s":
+		// Toggle smoothing in charts view
+		if m.currentView == ViewCharts {
+			m.chartsView.ToggleSmoothing()
+		}
+		return m, nil
+
+	case "u":
+		// Toggle temperature display between °F and °C
+		m.SetTempUnit(m.tempUnit.Toggle())
+		return m, nil
+
 	default:
 		return m, nil
 	}
@@ -283,8 +517,9 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // Messages
 
 type initialStateMsg struct {
-	state *model.VehicleState
-	err   error
+	state  *model.VehicleState
+	err    error
+	stored bool // state came from the store because the API failed
 }
 
 type stateUpdateMsg struct {
@@ -295,8 +530,42 @@ type errMsg struct {
 	err error
 }
 
-type wsConnectedMsg struct{}
+// authExpiredMsg reports that the API rejected the credentials and they
+// could not be refreshed, so nothing will load until the user logs in again.
+type authExpiredMsg struct {
+	err error
+}
+
+// authExpired reports whether err means the session expired, as opposed to
+// a network or API failure.
+func authExpired(err error) bool {
+	return errors.Is(err, rivian.ErrUnauthorized)
+}
+
+type wsConnectedMsg struct {
+	refreshed bool // access token was refreshed to create the session
+}
 
+// liveFailedMsg reports that real-time updates could not be started.
+type liveFailedMsg struct {
+	err error
+}
+
+// liveEndedMsg reports that the server ended the live subscription feeding
+// updates; err is set when it rejected the subscription with an error.
+type liveEndedMsg struct {
+	updates chan *model.VehicleState
+	err     error
+}
+
+type fleetStatesMsg struct {
+	states map[string]*model.VehicleState
+}
+
+type vehicleListMsg struct {
+	vehicles []rivian.Vehicle
+	err      error
+}
 
 // Commands
 
@@ -324,13 +593,16 @@ func (m *Model) fetchInitialState() tea.Cmd {
 
 		// Try to get latest state from API
 		rivState, err := m.client.GetVehicleState(m.ctx, vehicleID)
+		if authExpired(err) {
+			return authExpiredMsg{err: err}
+		}
 		if err != nil {
 			// Fall back to cached data from store
 			if m.store != nil {
 				states, err := m.store.GetStateHistory(m.ctx, vehicleID, time.Now().Add(-30*24*time.Hour), 1)
 				if err == nil && len(states) > 0 {
 					m.vehicleStates[vehicleID] = states[0]
-					return initialStateMsg{state: states[0]}
+					return initialStateMsg{state: states[0], stored: true}
 				}
 			}
 			return initialStateMsg{err: fmt.Errorf("failed to fetch vehicle state: %w", err)}
@@ -343,36 +615,78 @@ func (m *Model) fetchInitialState() tea.Cmd {
 		}
 		reducer.Dispatch(event)
 
-		// Convert to domain model
-		domainState := model.FromRivianVehicleState(rivState)
-
 		// Dispatch VehicleStateReceived
 		stateEvent := model.VehicleStateReceived{State: rivState}
 		finalState := reducer.Dispatch(stateEvent)
 
+		// Software versions are nice-to-have; ignore failures
+		if info, err := m.client.GetVehicleSoftwareInfo(m.ctx, vehicleID); err == nil {
+			finalState = reducer.Dispatch(model.SoftwareInfoReceived{Info: info})
+		}
+
 		// Cache the state
 		m.vehicleStates[vehicleID] = finalState
 
 		// Save to store (silently fail - not critical for TUI operation)
 		if m.store != nil {
-			_ = m.store.SaveState(m.ctx, domainState)
+			_ = m.store.SaveState(m.ctx, finalState)
 		}
 
 		return initialStateMsg{state: finalState}
 	}
 }
 
+// subscriptionFields returns the live fields to request for the current
+// view: the charge view only follows charging, which cuts update churn.
+func (m *Model) subscriptionFields() []string {
+	if m.currentView == ViewCharge {
+		return rivian.ChargingStateFields
+	}
+	return rivian.VehicleStateFields
+}
+
+// resubscribe asks the active vehicle's subscription to switch to the
+// current view's fields. Only the latest request matters, so a pending one
+// is replaced.
+func (m *Model) resubscribe() {
+	if len(m.vehicles) == 0 {
+		return
+	}
+	ch := m.fieldChans[m.vehicles[m.activeVehicle].ID]
+	if ch == nil {
+		return
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- m.subscriptionFields()
+}
+
 func (m *Model) subscribeToUpdates() tea.Cmd {
-	return func() tea.Msg {
-		// Check if we have vehicles loaded
-		if len(m.vehicles) == 0 {
-			// Non-fatal: silently continue without WebSocket
-			return nil
-		}
+	// Check if we have vehicles loaded
+	if len(m.vehicles) == 0 {
+		// Non-fatal: silently continue without WebSocket
+		return nil
+	}
 
-		// Get active vehicle ID
-		vehicleID := m.vehicles[m.activeVehicle].ID
+	// Get active vehicle ID
+	vehicleID := m.vehicles[m.activeVehicle].ID
+
+	// Each subscription gets its own context and update channel, so
+	// switchVehicle can tear it down completely
+	subCtx, updateChan, endErr := m.startSubscription(vehicleID)
+	fields := m.subscriptionFields()
+	fieldChan := make(chan []string, 1)
+	m.fieldChans[vehicleID] = fieldChan
+
+	// Get or create reducer for this vehicle
+	if m.reducers[vehicleID] == nil {
+		m.reducers[vehicleID] = model.NewReducer()
+	}
+	reducer := m.reducers[vehicleID]
 
+	return func() tea.Msg {
 		// Get HTTP client
 		httpClient, ok := m.client.(*rivian.HTTPClient)
 		if !ok {
@@ -380,10 +694,15 @@ func (m *Model) subscribeToUpdates() tea.Cmd {
 			return nil
 		}
 
-		// Create session (gets fresh CSRF and app session tokens)
-		if err := httpClient.CreateSession(m.ctx); err != nil {
-			// Non-fatal: silently continue without WebSocket
-			return nil
+		// Create session (gets fresh CSRF and app session tokens), refreshing
+		// an expired access token once
+		refreshed, err := httpClient.CreateSessionWithRefresh(subCtx)
+		if authExpired(err) {
+			return authExpiredMsg{err: err}
+		}
+		if err != nil {
+			// Non-fatal: continue without WebSocket, but say why in the header
+			return liveFailedMsg{err: err}
 		}
 
 		// Get credentials for WebSocket
@@ -399,77 +718,32 @@ func (m *Model) subscribeToUpdates() tea.Cmd {
 
 		// Create WebSocket client
 		wsClient := rivian.NewWebSocketClient(creds, csrfToken, appSessionID)
-		m.wsClients[vehicleID] = wsClient
-
-		// Connect
-		if err := wsClient.Connect(m.ctx); err != nil {
-			// Non-fatal: silently continue without WebSocket
-			// The user can still manually refresh with 'r' key
-			return nil
-		}
-
-		// Get or create update channel for this vehicle
-		if m.updateChans[vehicleID] == nil {
-			m.updateChans[vehicleID] = make(chan *model.VehicleState, 10)
-		}
-		updateChan := m.updateChans[vehicleID]
+		wsClient.SetTLSConfig(httpClient.TLSConfig())
+		wsClient.SetLogger(m.logger.With("vehicle_id", vehicleID))
 
-		// Get or create reducer for this vehicle
-		if m.reducers[vehicleID] == nil {
-			m.reducers[vehicleID] = model.NewReducer()
+		// Connect, retrying briefly before falling back to polling
+		if err := connectWithRetry(subCtx, wsClient.Connect); err != nil {
+			if subCtx.Err() != nil {
+				return nil // Replaced by a vehicle switch or quitting
+			}
+			return liveFailedMsg{err: err}
 		}
-		reducer := m.reducers[vehicleID]
 
-		// WebSocket connected successfully (no logging to avoid TUI disruption)
-
-		// Start subscription in background
-		go func() {
-			// Subscribe to vehicle state
-			subscription, err := rivian.SubscribeToVehicleState(m.ctx, wsClient, vehicleID)
+		// Start subscription in background; it owns the WebSocket from here
+		open := func(ctx context.Context, fields []string) (liveSubscription, error) {
+			subscription, err := rivian.SubscribeToVehicleStateFields(ctx, wsClient, vehicleID, fields)
 			if err != nil {
-				// Silently fail - user can manually refresh
-				return
-			}
-
-			defer func() { _ = subscription.Close() }()
-
-			for {
-				select {
-				case <-m.ctx.Done():
-					_ = wsClient.Close()
-					return
-
-				case update := <-subscription.Updates():
-					if update != nil {
-						// Apply partial update through reducer
-						event := model.PartialStateUpdate{
-							VehicleID: vehicleID,
-							Updates:   update,
-						}
-						finalState := reducer.Dispatch(event)
-
-						// Save to store (if we have a complete state)
-						if finalState != nil && m.store != nil {
-							// Silently fail - not critical
-							_ = m.store.SaveState(m.ctx, finalState)
-
-							// Cache the state
-							m.vehicleStates[vehicleID] = finalState
-
-							// Send to update channel
-							select {
-							case updateChan <- finalState:
-							default:
-								// Channel full, skip update
-							}
-						}
-					}
-				}
+				return nil, err
 			}
+			return subscription, nil
+		}
+		go func() {
+			defer func() { _ = wsClient.Close() }()
+			m.runSubscription(subCtx, open, vehicleID, fields, fieldChan, updateChan, endErr, reducer)
 		}()
 
 		// Return success message to trigger waitForUpdates
-		return wsConnectedMsg{}
+		return wsConnectedMsg{refreshed: refreshed}
 	}
 }
 
@@ -484,12 +758,119 @@ func (m *Model) waitForUpdates() tea.Cmd {
 		if !ok {
 			return nil
 		}
+		endErr := m.endErrs[vehicleID]
 
-		state := <-updateChan
+		state, ok := <-updateChan
+		if !ok {
+			// The loop queues the server's error before closing updates
+			msg := liveEndedMsg{updates: updateChan}
+			select {
+			case msg.err = <-endErr:
+			default:
+			}
+			return msg
+		}
 		return stateUpdateMsg{state: state}
 	}
 }
 
+func (m *Model) refreshVehicleList() tea.Cmd {
+	return func() tea.Msg {
+		vehicles, err := m.client.GetVehicles(m.ctx)
+		if authExpired(err) {
+			return authExpiredMsg{err: err}
+		}
+		return vehicleListMsg{vehicles: vehicles, err: err}
+	}
+}
+
+// loadFleetStates loads the latest stored snapshot for every vehicle that
+// has no cached state yet, so the fleet view can show more than the active one.
+// Snapshots are kept apart from vehicleStates so switching to a vehicle still
+// fetches live data.
+func (m *Model) loadFleetStates() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, v := range m.vehicles {
+		_, live := m.vehicleStates[v.ID]
+		_, stored := m.storedStates[v.ID]
+		if !live && !stored {
+			missing = append(missing, v.ID)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		states := make(map[string]*model.VehicleState)
+		for _, id := range missing {
+			state, err := m.store.GetLatestState(m.ctx, id)
+			if err == nil && state != nil {
+				states[id] = state
+			}
+		}
+		return fleetStatesMsg{states: states}
+	}
+}
+
+// fleetStates merges live states over stored snapshots for the fleet view.
+func (m *Model) fleetStates() map[string]*model.VehicleState {
+	states := make(map[string]*model.VehicleState, len(m.vehicles))
+	for id, state := range m.storedStates {
+		states[id] = state
+	}
+	for id, state := range m.vehicleStates {
+		states[id] = state
+	}
+	return states
+}
+
+// applyVehicleList replaces the vehicle list with a freshly fetched one and
+// merges updated identity (name, model, VIN) into any cached per-vehicle state.
+func (m *Model) applyVehicleList(vehicles []rivian.Vehicle) {
+	if len(vehicles) == 0 || len(m.vehicles) == 0 {
+		return
+	}
+
+	// Keep the active vehicle selected even if the list order changed
+	activeID := m.vehicles[m.activeVehicle].ID
+	activeIndex := -1
+	for i, v := range vehicles {
+		if v.ID == activeID {
+			activeIndex = i
+			break
+		}
+	}
+	if activeIndex < 0 {
+		// Active vehicle disappeared - ignore the refresh rather than
+		// silently switching vehicles under the user
+		return
+	}
+
+	m.vehicles = vehicles
+	m.activeVehicle = activeIndex
+
+	for _, v := range vehicles {
+		event := model.VehicleMetadataUpdated{Vehicle: v}
+		if reducer, ok := m.reducers[v.ID]; ok {
+			if state := reducer.Dispatch(event); state != nil {
+				m.vehicleStates[v.ID] = state
+			}
+		} else if cached, ok := m.vehicleStates[v.ID]; ok {
+			// State loaded from the store has no reducer yet
+			m.vehicleStates[v.ID] = event.ApplyTo(cached)
+		}
+	}
+
+	if state, ok := m.vehicleStates[activeID]; ok && m.state != nil {
+		m.state = state
+	}
+}
+
 // switchVehicle switches to a different vehicle
 func (m *Model) switchVehicle(newIndex int) tea.Cmd {
 	// Validate new index
@@ -502,19 +883,21 @@ func (m *Model) switchVehicle(newIndex int) tea.Cmd {
 		return nil
 	}
 
-	// Close old WebSocket connection
-	oldVehicleID := m.vehicles[m.activeVehicle].ID
-	if wsClient, ok := m.wsClients[oldVehicleID]; ok {
-		_ = wsClient.Close()
-	}
+	// Tear down the old vehicle's live subscription and WebSocket
+	m.stopSubscription(m.vehicles[m.activeVehicle].ID)
 
 	// Switch to new vehicle
 	m.activeVehicle = newIndex
+	m.liveStatus = ""
+	m.liveFailed = false
+	m.stopWatchdog()
 	newVehicleID := m.vehicles[m.activeVehicle].ID
 
 	// Update views with new vehicle ID
-	m.healthView = NewHealthView(m.store, newVehicleID)
-	m.chartsView = NewChartsView(m.store, newVehicleID)
+	m.healthView = NewHealthView(m.historyCache, newVehicleID)
+	m.chartsView = NewChartsView(m.historyCache, newVehicleID)
+	m.healthView.SetTempUnit(m.tempUnit)
+	m.healthView.SetPlain(m.plain)
 
 	// Return commands to fetch state and subscribe
 	return tea.Batch(
@@ -530,30 +913,41 @@ func (m *Model) renderHeader() string {
 		return ""
 	}
 
-	// Vehicle info
-	vehicleInfo := fmt.Sprintf("%s %s", m.state.Model, m.state.Name)
-	if vehicleInfo == " " {
+	// Vehicle info, with model year and trim from the vehicle list
+	description := m.state.Model
+	if m.activeVehicle < len(m.vehicles) {
+		if d := m.vehicles[m.activeVehicle].Description(); d != "" {
+			description = d
+		}
+	}
+	vehicleInfo := strings.TrimSpace(description + " " + m.state.Name)
+	if vehicleInfo == "" {
 		vehicleInfo = "Rivian Vehicle"
 	}
 
 	// Online status
 	status := "Online"
-	statusColor := lipgloss.Color("#00ff00")
+	statusColor := themeColor("#00ff00")
 	if !m.state.IsOnline {
 		status = "Offline"
-		statusColor = lipgloss.Color("#ff0000")
+		statusColor = themeColor("#ff0000")
 	}
 
 	// Last update
 	updateTime := "never"
-	if !m.lastUpdate.IsZero() {
-		updateTime = m.lastUpdate.Format("15:04:05")
+	if t := m.updateTime(); !t.IsZero() {
+		updateTime = t.Format("15:04:05")
+		// Without live updates the data may be old, so say how old
+		if m.offline || m.liveEnded {
+			updateTime += " (" + model.FormatAge(time.Since(t)) + " old)"
+		}
 	}
 
+	// White on the header's blue reads the same in either theme
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("#ffffff")).
-		Background(lipgloss.Color("#5f5fff")).
+		Background(themeColor("#5f5fff")).
 		Padding(0, 1)
 
 	statusStyle := lipgloss.NewStyle().
@@ -561,7 +955,17 @@ func (m *Model) renderHeader() string {
 		Bold(true)
 
 	leftSection := headerStyle.Render(fmt.Sprintf("🚗 %s", vehicleInfo))
-	rightSection := headerStyle.Render(fmt.Sprintf("%s | Updated: %s", statusStyle.Render(status), updateTime))
+	right := statusStyle.Render(status)
+	if m.liveEnded && m.liveFailed {
+		right += " | " + m.liveStatus + " (polling)"
+	} else if m.liveEnded {
+		right += " | " + symbolWarning + " Live ended (polling)"
+	} else if m.liveStale {
+		right += " | " + symbolWarning + " Live: no updates"
+	} else if m.liveStatus != "" {
+		right += " | " + m.liveStatus
+	}
+	rightSection := headerStyle.Render(fmt.Sprintf("%s | Updated: %s", right, updateTime))
 
 	// Calculate spacing
 	spacingWidth := m.width - lipgloss.Width(leftSection) - lipgloss.Width(rightSection)
@@ -573,20 +977,38 @@ func (m *Model) renderHeader() string {
 	return leftSection + spacing + rightSection
 }
 
+// liveStatusText describes the outcome of starting real-time updates,
+// distinguishing rejected credentials from network failures.
+func liveStatusText(refreshed bool, err error) string {
+	switch {
+	case err != nil && errors.Is(err, rivian.ErrUnauthorized):
+		return symbolCritical + " Live: auth expired"
+	case err != nil && errors.Is(err, rivian.ErrSubscriptionEnded):
+		return symbolCritical + " Live: rejected by server"
+	case err != nil:
+		return symbolCritical + " Live: network error"
+	case refreshed:
+		return symbolOK + " Live (token refreshed)"
+	default:
+		return symbolOK + " Live"
+	}
+}
+
 func (m *Model) renderFooter() string {
 	tabs := []string{
 		"[1] Dashboard",
 		"[2] Charge",
 		"[3] Health",
 		"[4] Charts",
+		"[5] Fleet",
 	}
 
 	activeTabStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
+		Foreground(themeColor("#00ffff")).
 		Bold(true)
 
 	inactiveTabStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
+		Foreground(themeColor("#888888"))
 
 	var renderedTabs []string
 	for i, tab := range tabs {
@@ -600,24 +1022,27 @@ func (m *Model) renderFooter() string {
 	tabBar := lipgloss.JoinHorizontal(lipgloss.Left, renderedTabs...)
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#666666"))
+		Foreground(themeColor("#666666"))
 
 	// Build help text with vehicle selector if multiple vehicles
 	var helpText string
 	if m.currentView == ViewCharts {
 		// Charts view has special keyboard shortcuts
 		if len(m.vehicles) > 1 {
-			helpText = "[←/→] metric | [t] time | [v] vehicles | [r] refresh | [q] quit"
+			helpText = "[←/→] metric | [t] time | [s] smooth | [v] vehicles | [r] refresh | [:] commands | [q] quit"
 		} else {
-			helpText = "[←/→] metric | [t] time | [r] refresh | [q] quit"
+			helpText = "[←/→] metric | [t] time | [s] smooth | [r] refresh | [:] commands | [q] quit"
 		}
 	} else {
 		if len(m.vehicles) > 1 {
-			helpText = "[v] vehicles | [r] refresh | [q] quit"
+			helpText = "[u] °F/°C | [v] vehicles | [r] refresh | [:] commands | [q] quit"
 		} else {
-			helpText = "[r] refresh | [q] quit"
+			helpText = "[u] °F/°C | [r] refresh | [:] commands | [q] quit"
 		}
 	}
+	if m.currentView == ViewDashboard && hasActionableIssues(m.state) {
+		helpText = "[i] issues | " + helpText
+	}
 	help := helpStyle.Render(helpText)
 
 	// Calculate spacing between tabs and help
@@ -636,15 +1061,29 @@ func (m *Model) renderFooter() string {
 
 	// Apply background without setting explicit width (content already sized correctly)
 	footerStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#1a1a1a")).
+		Background(themeColor("#1a1a1a")).
 		Padding(0, 1)
 
 	return footerStyle.Render(footerContent)
 }
 
+// hasActionableIssues reports whether state has a warning or critical
+// issue, the ones the [i] key has a health section to show for.
+func hasActionableIssues(state *model.VehicleState) bool {
+	if state == nil {
+		return false
+	}
+	for _, issue := range state.GetIssueList() {
+		if issue.Severity >= model.IssueWarning {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Model) renderLoading() string {
 	loadingStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
+		Foreground(themeColor("#00ffff")).
 		Bold(true).
 		Align(lipgloss.Center, lipgloss.Center).
 		Width(m.width).
@@ -655,7 +1094,7 @@ func (m *Model) renderLoading() string {
 
 func (m *Model) renderError() string {
 	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#ff0000")).
+		Foreground(themeColor("#ff0000")).
 		Bold(true).
 		Align(lipgloss.Center, lipgloss.Center).
 		Width(m.width).
@@ -663,3 +1102,28 @@ func (m *Model) renderError() string {
 
 	return errorStyle.Render(fmt.Sprintf("Error: %v\n\nPress 'r' to retry or 'q' to quit", m.err))
 }
+
+// renderAuthExpired replaces the (now stale) views with a re-login prompt.
+func (m *Model) renderAuthExpired() string {
+	style := lipgloss.NewStyle().
+		Foreground(themeColor("#ffff00")).
+		Bold(true).
+		Align(lipgloss.Center, lipgloss.Center).
+		Width(m.width).
+		Height(m.height)
+
+	return style.Render(fmt.Sprintf("%s Session expired — quit and re-run to log in\n\n%v\n\nPress 'q' to quit", symbolWarning, m.authErr))
+}
+
+// updateTime returns the time shown in the header: the state's UpdatedAt,
+// or when it was received if unset, in the local zone with --local-time.
+func (m *Model) updateTime() time.Time {
+	t := m.lastUpdate
+	if m.state != nil && !m.state.UpdatedAt.IsZero() {
+		t = m.state.UpdatedAt
+	}
+	if m.localTime {
+		return t.Local()
+	}
+	return t
+}
@@ -0,0 +1,193 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func TestLiveStatusText(t *testing.T) {
+	tests := []struct {
+		name      string
+		refreshed bool
+		err       error
+		want      string
+	}{
+		{name: "connected", want: "Live"},
+		{name: "refreshed", refreshed: true, want: "token refreshed"},
+		{name: "auth error", err: fmt.Errorf("create CSRF token: %w", rivian.ErrUnauthorized), want: "auth expired"},
+		{name: "network error", err: errors.New("connection refused"), want: "network error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := liveStatusText(tt.refreshed, tt.err)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("liveStatusText() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHeader_VehicleDescription(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Road Trip", Model: "R1T", Year: 2024, Trim: "Adventure"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.width = 120
+	m.state = &model.VehicleState{VehicleID: "1", Name: "Road Trip", Model: "R1T", IsOnline: true}
+
+	if header := m.renderHeader(); !strings.Contains(header, "2024 R1T Adventure Road Trip") {
+		t.Errorf("renderHeader() = %q, want year, model, trim and name", header)
+	}
+
+	// Without year and trim the header falls back to the state's model
+	m.vehicles[0].Year, m.vehicles[0].Trim, m.vehicles[0].Model = 0, "", ""
+	if header := m.renderHeader(); !strings.Contains(header, "R1T Road Trip") {
+		t.Errorf("renderHeader() = %q, want model and name", header)
+	}
+}
+
+func TestRenderHeader_DataAge(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Road Trip", Model: "R1T"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.width = 160
+	stored := &model.VehicleState{VehicleID: "1", Name: "Road Trip", UpdatedAt: time.Now().Add(-(3*time.Hour + 12*time.Minute))}
+
+	// A state from the store, because the API couldn't be reached, shows its age
+	m.Update(initialStateMsg{state: stored, stored: true})
+	if header := m.renderHeader(); !strings.Contains(header, "(3h 12m old)") {
+		t.Errorf("renderHeader() = %q, want the data age", header)
+	}
+
+	// Fresh data from the API doesn't
+	m.Update(refreshStateMsg{vehicleID: "1", state: &rivian.VehicleState{VehicleID: "1", UpdatedAt: time.Now()}})
+	if header := m.renderHeader(); strings.Contains(header, " old)") {
+		t.Errorf("renderHeader() = %q, want no data age for fresh data", header)
+	}
+
+	// Polling without live updates shows it again
+	m.liveEnded = true
+	if header := m.renderHeader(); !strings.Contains(header, "(<1m old)") {
+		t.Errorf("renderHeader() = %q, want the data age while polling", header)
+	}
+}
+
+func TestUpdateTime_LocalTime(t *testing.T) {
+	m := NewModel(nil, nil, []rivian.Vehicle{{ID: "1"}}, 0, nil)
+	updated := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	m.state = &model.VehicleState{VehicleID: "1", UpdatedAt: updated}
+
+	if got := m.updateTime(); got.Location() != time.UTC || !got.Equal(updated) {
+		t.Errorf("updateTime() = %v, want %v as stored", got, updated)
+	}
+
+	m.SetLocalTime(true)
+	if got := m.updateTime(); got.Location() != time.Local || !got.Equal(updated) {
+		t.Errorf("updateTime() with local time = %v, want %v in the local zone", got, updated)
+	}
+}
+
+func TestSubscriptionFields_ChargeView(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Road Trip", Model: "R1T"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.currentView = ViewDashboard
+	ch := make(chan []string, 1)
+	m.fieldChans["1"] = ch
+
+	if got := m.subscriptionFields(); len(got) != len(rivian.VehicleStateFields) {
+		t.Errorf("dashboard fields = %v, want %v", got, rivian.VehicleStateFields)
+	}
+
+	// Entering the charge view narrows the subscription
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	select {
+	case fields := <-ch:
+		if strings.Join(fields, ",") != strings.Join(rivian.ChargingStateFields, ",") {
+			t.Errorf("charge view fields = %v, want %v", fields, rivian.ChargingStateFields)
+		}
+	default:
+		t.Fatal("switching to the charge view should resubscribe")
+	}
+
+	// Moving between non-charge views keeps the subscription
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	<-ch
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+	select {
+	case fields := <-ch:
+		t.Errorf("unexpected resubscribe with %v", fields)
+	default:
+	}
+}
+
+// expiredClient is a rivian.Client whose credentials were rejected.
+type expiredClient struct {
+	rivian.Client
+}
+
+func (expiredClient) GetVehicleState(ctx context.Context, vehicleID string) (*rivian.VehicleState, error) {
+	return nil, fmt.Errorf("get vehicle state: %w", rivian.ErrUnauthorized)
+}
+
+func TestAuthExpired_ShowsReloginPrompt(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1"}}
+	m := NewModel(expiredClient{}, nil, vehicles, 0, nil)
+	m.width, m.height = 100, 20
+	m.loading = false
+	m.state = createTestState()
+
+	msg := m.refreshState()()
+	if _, ok := msg.(authExpiredMsg); !ok {
+		t.Fatalf("refresh with rejected credentials = %T, want authExpiredMsg", msg)
+	}
+
+	m.Update(msg)
+	if view := m.View(); !strings.Contains(view, "Session expired") || !strings.Contains(view, "re-run to log in") {
+		t.Errorf("View() should show the re-login prompt, got:\n%s", view)
+	}
+}
+
+func TestIssueKeyJumpsToHealth(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1", Model: "R1T"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.currentView = ViewDashboard
+	m.state = createTestState()
+	m.state.Windows.RearLeft = model.ClosureStatusOpen
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	if m.currentView != ViewHealth {
+		t.Fatalf("currentView = %v, want health", m.currentView)
+	}
+	if m.healthView.focus != HealthFocusClosures {
+		t.Errorf("health focus = %q, want closures", m.healthView.focus)
+	}
+
+	// Opening the health view directly drops the focus
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	if m.healthView.focus != HealthFocusNone {
+		t.Errorf("health focus = %q after 'h', want none", m.healthView.focus)
+	}
+}
+
+func TestFooterIssueHint_ActionableOnly(t *testing.T) {
+	m := NewModel(nil, nil, []rivian.Vehicle{{ID: "1", Model: "R1T"}}, 0, nil)
+	m.currentView = ViewDashboard
+	m.width = 200
+	m.state = createTestState()
+	m.state.IsLocked = false // Info only
+
+	if footer := m.renderFooter(); strings.Contains(footer, "[i] issues") {
+		t.Errorf("footer offers [i] for info-only issues %q", m.state.GetIssues())
+	}
+
+	m.state.Windows.RearLeft = model.ClosureStatusOpen
+	if footer := m.renderFooter(); !strings.Contains(footer, "[i] issues") {
+		t.Errorf("footer missing [i] for warning issues %q", m.state.GetIssues())
+	}
+}
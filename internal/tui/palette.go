@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PaletteAction is an entry in the command palette
+type PaletteAction struct {
+	Name string         // Shown and matched against the filter
+	Key  string         // Existing shortcut shown as a hint ("" = none)
+	Run  func() tea.Cmd // Performs the action
+}
+
+// CommandPalette handles the ":" command palette overlay: typing filters
+// the actions fuzzily, and the arrow keys pick one of the matches.
+type CommandPalette struct {
+	actions       []PaletteAction
+	filter        string
+	matches       []int // Indexes into actions, best match first
+	selectedIndex int   // Index into matches
+}
+
+// NewCommandPalette creates a command palette listing all actions
+func NewCommandPalette(actions []PaletteAction) *CommandPalette {
+	p := &CommandPalette{actions: actions}
+	p.updateMatches()
+	return p
+}
+
+// HandleKey processes keyboard input for the palette
+// Returns: (chosen action index, done selecting); the index is -1 when the
+// palette was canceled or nothing matches.
+func (p *CommandPalette) HandleKey(key string) (int, bool) {
+	switch key {
+	case "up", "ctrl+p":
+		if p.selectedIndex > 0 {
+			p.selectedIndex--
+		}
+		return -1, false
+
+	case "down", "ctrl+n", "tab":
+		if p.selectedIndex < len(p.matches)-1 {
+			p.selectedIndex++
+		}
+		return -1, false
+
+	case "enter":
+		if len(p.matches) == 0 {
+			return -1, false
+		}
+		return p.matches[p.selectedIndex], true
+
+	case "esc", "ctrl+c":
+		return -1, true
+
+	case "backspace":
+		if p.filter != "" {
+			_, size := utf8.DecodeLastRuneInString(p.filter)
+			p.filter = p.filter[:len(p.filter)-size]
+			p.updateMatches()
+		}
+		return -1, false
+	}
+
+	// Anything else that types a single character extends the filter
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsPrint(r) {
+		p.filter += key
+		p.updateMatches()
+	}
+	return -1, false
+}
+
+// updateMatches refilters the actions and selects the best match.
+func (p *CommandPalette) updateMatches() {
+	type match struct {
+		index, score int
+	}
+	var found []match
+	for i, action := range p.actions {
+		if score, ok := fuzzyScore(p.filter, action.Name); ok {
+			found = append(found, match{i, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+
+	p.matches = p.matches[:0]
+	for _, m := range found {
+		p.matches = append(p.matches, m.index)
+	}
+	p.selectedIndex = 0
+}
+
+// fuzzyScore reports whether the characters of query appear in text in
+// order (ignoring case and spaces in query), and how well: consecutive
+// characters and ones starting a word score higher. An empty query matches
+// everything equally.
+func fuzzyScore(query, text string) (int, bool) {
+	query = strings.ToLower(strings.ReplaceAll(query, " ", ""))
+	text = strings.ToLower(text)
+	if query == "" {
+		return 0, true
+	}
+
+	score := 0
+	prev := -2 // Byte offset of the previous matched character
+	qi := 0
+	for ti, r := range text {
+		q, size := utf8.DecodeRuneInString(query[qi:])
+		if r != q {
+			continue
+		}
+
+		score++
+		if ti == prev+utf8.RuneLen(r) {
+			score += 2 // Continues the previous match
+		}
+		if before, _ := utf8.DecodeLastRuneInString(text[:ti]); ti == 0 || !unicode.IsLetter(before) {
+			score += 3 // Starts a word
+		}
+		prev = ti
+		qi += size
+		if qi == len(query) {
+			return score, true
+		}
+	}
+	return 0, false
+}
+
+// Render renders the command palette as an overlay
+func (p *CommandPalette) Render(width, height int) string {
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(themeColor("#5f5fff")).
+		Padding(1, 2).
+		Width(width - 20) // Leave margin
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#00ffff")).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#00ffff")).
+		Bold(true)
+
+	unselectedStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#888888"))
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#666666"))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(themeColor("#666666")).
+		MarginTop(1)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(":" + p.filter + "█"))
+	content.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		content.WriteString(unselectedStyle.Render("  No matching commands"))
+		content.WriteString("\n")
+	}
+	for i, index := range p.matches {
+		action := p.actions[index]
+		style, indicator := unselectedStyle, "  "
+		if i == p.selectedIndex {
+			style, indicator = selectedStyle, "→ "
+		}
+		line := style.Render(indicator + action.Name)
+		if action.Key != "" {
+			line += hintStyle.Render("  [" + action.Key + "]")
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	content.WriteString(helpStyle.Render("Type to filter  [↑/↓] Navigate  [Enter] Run  [Esc] Cancel"))
+
+	return lipgloss.Place(
+		width,
+		height,
+		lipgloss.Center,
+		lipgloss.Center,
+		borderStyle.Render(content.String()),
+		lipgloss.WithWhitespaceChars(" "),
+		lipgloss.WithWhitespaceForeground(themeColor("#1a1a1a")),
+	)
+}
+
+// paletteActions lists the command palette's actions. Those with a
+// shortcut replay its key press, so both always do the same thing.
+func (m *Model) paletteActions() []PaletteAction {
+	press := func(key string) func() tea.Cmd {
+		return func() tea.Cmd {
+			_, cmd := m.handleKeyPress(keyPress(key))
+			return cmd
+		}
+	}
+	inCharts := func(key string) func() tea.Cmd {
+		return func() tea.Cmd {
+			m.currentView = ViewCharts
+			return press(key)()
+		}
+	}
+
+	actions := []PaletteAction{
+		{Name: "Go to dashboard", Key: "1", Run: press("1")},
+		{Name: "Go to charge", Key: "2", Run: press("2")},
+		{Name: "Go to health", Key: "3", Run: press("3")},
+		{Name: "Show issues in health", Key: "i", Run: press("i")},
+		{Name: "Go to charts", Key: "4", Run: press("4")},
+		{Name: "Go to fleet", Key: "5", Run: press("5")},
+		{Name: "Charts: next metric", Key: "→", Run: inCharts("right")},
+		{Name: "Charts: previous metric", Key: "←", Run: inCharts("left")},
+		{Name: "Charts: next time range", Key: "t", Run: inCharts("t")},
+		{Name: "Charts: toggle smoothing", Key: "s", Run: inCharts("s")},
+		{Name: "Refresh", Key: "r", Run: press("r")},
+		{Name: "Toggle temperature unit (°F/°C)", Key: "u", Run: press("u")},
+		{Name: "Toggle plain display (no boxes or colors)", Run: func() tea.Cmd {
+			m.SetPlain(!m.plain)
+			return nil
+		}},
+	}
+
+	if len(m.vehicles) > 1 {
+		actions = append(actions, PaletteAction{Name: "Choose vehicle", Key: "v", Run: press("v")})
+		for i, vehicle := range m.vehicles {
+			if i == m.activeVehicle {
+				continue
+			}
+			name := strings.TrimSpace(vehicle.Description() + " " + vehicle.Name)
+			if name == "" {
+				name = vehicle.VIN
+			}
+			actions = append(actions, PaletteAction{Name: "Switch to " + name, Run: func() tea.Cmd {
+				return m.switchVehicle(i)
+			}})
+		}
+	}
+
+	return append(actions, PaletteAction{Name: "Quit", Key: "q", Run: press("q")})
+}
+
+// keyPress returns the key message for a key name as shown by
+// tea.KeyMsg.String.
+func keyPress(key string) tea.KeyMsg {
+	switch key {
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		match       bool
+	}{
+		{"", "Go to charts", true},
+		{"chrt", "Go to charts", true},
+		{"GTC", "go to charge", true},
+		{"go charts", "Go to charts", true},
+		{"strahc", "Go to charts", false},
+		{"fleetx", "Go to fleet", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.query, tt.text); ok != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.query, tt.text, ok, tt.match)
+		}
+	}
+
+	// Word starts and consecutive characters rank higher
+	prefix, _ := fuzzyScore("ref", "Refresh")
+	scattered, _ := fuzzyScore("ref", "Charts: previous metric")
+	if prefix <= scattered {
+		t.Errorf("prefix score %d should beat scattered score %d", prefix, scattered)
+	}
+}
+
+func TestCommandPalette_HandleKey(t *testing.T) {
+	p := NewCommandPalette([]PaletteAction{
+		{Name: "Go to dashboard"},
+		{Name: "Go to charts"},
+		{Name: "Refresh"},
+	})
+
+	for _, key := range []string{"c", "h", "r"} {
+		if index, done := p.HandleKey(key); index != -1 || done {
+			t.Fatalf("typing %q returned (%d, %v), want (-1, false)", key, index, done)
+		}
+	}
+	if p.filter != "chr" || len(p.matches) != 1 {
+		t.Fatalf("filter %q matches %v, want only charts", p.filter, p.matches)
+	}
+	if index, done := p.HandleKey("enter"); index != 1 || !done {
+		t.Errorf("enter returned (%d, %v), want (1, true)", index, done)
+	}
+
+	// Backspace widens the filter again, arrows move through the matches
+	p.HandleKey("backspace")
+	p.HandleKey("backspace")
+	p.HandleKey("backspace")
+	if len(p.matches) != 3 {
+		t.Errorf("matches after clearing the filter = %v, want all 3", p.matches)
+	}
+	p.HandleKey("down")
+	p.HandleKey("down")
+	p.HandleKey("down")
+	if index, _ := p.HandleKey("enter"); index != 2 {
+		t.Errorf("selected %d after moving down past the end, want 2", index)
+	}
+
+	// Vim keys type into the filter rather than navigating
+	p.HandleKey("j")
+	if index, done := p.HandleKey("enter"); index != -1 || done {
+		t.Errorf("enter with no matches returned (%d, %v), want (-1, false)", index, done)
+	}
+	if !strings.Contains(p.Render(80, 24), "No matching commands") {
+		t.Error("render should say nothing matches")
+	}
+
+	if index, done := p.HandleKey("esc"); index != -1 || !done {
+		t.Errorf("esc returned (%d, %v), want (-1, true)", index, done)
+	}
+}
+
+func TestCommandPalette_RunsActions(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Truck", Model: "R1T"}, {ID: "2", Name: "Family", Model: "R1S"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.currentView = ViewDashboard
+	m.state = createTestState()
+	m.loading = false
+	m.width, m.height = 100, 40
+
+	typeKeys := func(keys ...string) {
+		for _, key := range keys {
+			m.Update(keyPress(key))
+		}
+	}
+
+	typeKeys(":")
+	if !m.showPalette {
+		t.Fatal("':' should open the command palette")
+	}
+	if view := m.View(); !strings.Contains(view, "Go to charts") || !strings.Contains(view, "Family") || strings.Contains(view, "Truck") {
+		t.Errorf("palette should list views and vehicles, got:\n%s", view)
+	}
+
+	// Keys go to the palette while it is open, not to the shortcuts
+	typeKeys("s", "m", "o", "o", "t", "h")
+	if m.currentView != ViewDashboard || !m.showPalette {
+		t.Fatalf("typing in the palette changed the view to %v", m.currentView)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.showPalette {
+		t.Error("running an action should close the palette")
+	}
+	if m.currentView != ViewCharts || !m.chartsView.smoothed {
+		t.Errorf("smoothing action: view %v smoothed %v, want charts view with smoothing on", m.currentView, m.chartsView.smoothed)
+	}
+
+	typeKeys(":", "u", "n", "i", "t")
+	unit := m.tempUnit
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.tempUnit == unit {
+		t.Error("temperature unit action should toggle the unit")
+	}
+
+	// Escape closes the palette without running anything
+	typeKeys(":", "q")
+	m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if m.showPalette || m.currentView != ViewCharts {
+		t.Errorf("esc: palette open %v, view %v; want closed palette, view unchanged", m.showPalette, m.currentView)
+	}
+
+	// Single-key shortcuts still work with the palette closed
+	typeKeys("2")
+	if m.currentView != ViewCharge {
+		t.Errorf("currentView = %v after '2', want charge", m.currentView)
+	}
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// viewStyles are the styles shared by the dashboard, charge and health
+// views. Each view builds the same section content in both modes; plain
+// mode only changes how it is drawn, for screen readers and dumb terminals.
+type viewStyles struct {
+	plain   bool
+	title   lipgloss.Style
+	section lipgloss.Style
+	label   lipgloss.Style
+	value   lipgloss.Style
+}
+
+// newViewStyles returns bordered, colored styles, or borderless ones that
+// read top to bottom when plain is set.
+func newViewStyles(plain bool) viewStyles {
+	if plain {
+		return viewStyles{
+			plain:   true,
+			title:   lipgloss.NewStyle().MarginTop(1).MarginBottom(1),
+			section: lipgloss.NewStyle().MarginBottom(1),
+			label:   lipgloss.NewStyle(),
+			value:   lipgloss.NewStyle(),
+		}
+	}
+
+	return viewStyles{
+		title: lipgloss.NewStyle().
+			Foreground(themeColor("#00ffff")).
+			Bold(true).
+			MarginTop(1).
+			MarginBottom(1),
+		section: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(themeColor("#5f5fff")).
+			Padding(1).
+			MarginBottom(1),
+		label: lipgloss.NewStyle().
+			Foreground(themeColor("#888888")),
+		value: lipgloss.NewStyle().
+			Foreground(themeColor("#ffffff")).
+			Bold(true),
+	}
+}
+
+// columns lays sections out side by side, or one after another in plain
+// mode so they are read in order.
+func (s viewStyles) columns(sections ...string) string {
+	if s.plain {
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	}
+
+	spaced := make([]string, 0, 2*len(sections))
+	for i, section := range sections {
+		if i > 0 {
+			spaced = append(spaced, "  ")
+		}
+		spaced = append(spaced, section)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, spaced...)
+}
+
+// plainText trims the padding lipgloss adds to fixed-width blocks, which
+// screen readers would otherwise announce as blank space.
+func plainText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
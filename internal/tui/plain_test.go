@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func TestModelView_Plain(t *testing.T) {
+	DisableColor()
+
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Road Trip", Model: "R1T"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.width, m.height = 120, 40
+	m.loading = false
+	m.state = createTestState()
+	m.SetPlain(true)
+
+	views := map[ViewType]string{
+		ViewDashboard: "Battery & Range",
+		ViewCharge:    "Charging",
+		ViewHealth:    "Vehicle Health",
+	}
+	for view, heading := range views {
+		m.currentView = view
+		out := m.View()
+
+		if !strings.Contains(out, heading) {
+			t.Errorf("view %v missing %q:\n%s", view, heading, out)
+		}
+		if strings.ContainsAny(out, "╭╮╰╯█") {
+			t.Errorf("view %v should have no boxes or bars in plain mode:\n%s", view, out)
+		}
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasSuffix(line, " ") {
+				t.Errorf("view %v has trailing padding on %q", view, line)
+				break
+			}
+		}
+	}
+
+	// Styled mode keeps the boxed layout
+	m.SetPlain(false)
+	m.currentView = ViewDashboard
+	if out := m.View(); !strings.Contains(out, "╭") {
+		t.Error("styled dashboard should draw boxes")
+	}
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"github.com/pfrederiksen/rivian-ls/internal/config"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+// Names used in the preferences file. Names rather than the enum values keep
+// the file stable if views or metrics are reordered.
+var (
+	viewNames = map[ViewType]string{
+		ViewDashboard: "dashboard",
+		ViewCharge:    "charge",
+		ViewHealth:    "health",
+		ViewCharts:    "charts",
+		ViewFleet:     "fleet",
+	}
+	metricNames = map[ChartMetric]string{
+		MetricBattery:      "battery",
+		MetricRange:        "range",
+		MetricChargingRate: "charging_rate",
+		MetricTemperature:  "temperature",
+		MetricEfficiency:   "efficiency",
+		MetricDegradation:  "degradation",
+	}
+	timeRangeNames = map[TimeRange]string{
+		Range24Hours: "24h",
+		Range7Days:   "7d",
+		Range30Days:  "30d",
+	}
+	tempUnitNames = map[TempUnit]string{
+		TempFahrenheit: "fahrenheit",
+		TempCelsius:    "celsius",
+	}
+)
+
+// lookupName returns the key whose name is name, or ok=false if unknown.
+func lookupName[K comparable](names map[K]string, name string) (K, bool) {
+	for k, n := range names {
+		if n == name {
+			return k, true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+// preferredVehicle returns the index of the remembered vehicle, or 0 if it
+// is no longer on the account.
+func preferredVehicle(vehicles []rivian.Vehicle, vehicleID string) int {
+	for i, v := range vehicles {
+		if v.ID == vehicleID {
+			return i
+		}
+	}
+	return 0
+}
+
+// applyPreferences restores the remembered view, chart and unit settings.
+// Unknown names are ignored so stale files fall back to defaults.
+func (m *Model) applyPreferences(prefs *config.Preferences) {
+	if view, ok := lookupName(viewNames, prefs.View); ok {
+		m.currentView = view
+	}
+	if metric, ok := lookupName(metricNames, prefs.ChartMetric); ok {
+		m.chartsView.selectedMetric = metric
+	}
+	if timeRange, ok := lookupName(timeRangeNames, prefs.TimeRange); ok {
+		m.chartsView.timeRange = timeRange
+	}
+	if unit, ok := lookupName(tempUnitNames, prefs.TempUnit); ok {
+		m.SetTempUnit(unit)
+		m.tempUnitSaved = true
+	}
+}
+
+// Preferences captures the state to remember for the next run.
+func (m *Model) Preferences() *config.Preferences {
+	prefs := &config.Preferences{
+		View:        viewNames[m.currentView],
+		ChartMetric: metricNames[m.chartsView.selectedMetric],
+		TimeRange:   timeRangeNames[m.chartsView.timeRange],
+		TempUnit:    tempUnitNames[m.tempUnit],
+	}
+	if len(m.vehicles) > 0 {
+		prefs.VehicleID = m.vehicles[m.activeVehicle].ID
+	}
+	return prefs
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/pfrederiksen/rivian-ls/internal/config"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func TestModelPreferences(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "v1", Name: "R1T"}, {ID: "v2", Name: "R1S"}}
+
+	// Defaults without preferences
+	m := NewModel(nil, nil, vehicles, -1, nil)
+	if m.currentView != ViewDashboard || m.chartsView.selectedMetric != MetricBattery || m.activeVehicle != 0 {
+		t.Fatalf("NewModel() without preferences: view=%d metric=%d vehicle=%d", m.currentView, m.chartsView.selectedMetric, m.activeVehicle)
+	}
+
+	m.activeVehicle = 1
+	m.currentView = ViewCharts
+	m.chartsView.NextMetric()
+	m.chartsView.NextTimeRange()
+	prefs := m.Preferences()
+
+	restored := NewModel(nil, nil, vehicles, -1, prefs)
+	if restored.currentView != ViewCharts {
+		t.Errorf("view = %d, want charts", restored.currentView)
+	}
+	if restored.chartsView.selectedMetric != MetricRange || restored.chartsView.timeRange != Range7Days {
+		t.Errorf("charts = metric %d range %d, want range/7d", restored.chartsView.selectedMetric, restored.chartsView.timeRange)
+	}
+	if restored.activeVehicle != 1 || restored.chartsView.vehicleID != "v2" {
+		t.Errorf("activeVehicle = %d, want the remembered vehicle 1", restored.activeVehicle)
+	}
+
+	// An explicit start index wins over the remembered vehicle
+	if explicit := NewModel(nil, nil, vehicles, 0, prefs); explicit.activeVehicle != 0 {
+		t.Errorf("activeVehicle = %d, want explicit index 0", explicit.activeVehicle)
+	}
+
+	// Unknown names fall back to defaults
+	stale := &config.Preferences{View: "gone", VehicleID: "sold"}
+	if m := NewModel(nil, nil, vehicles, -1, stale); m.currentView != ViewDashboard || m.activeVehicle != 0 {
+		t.Errorf("stale preferences should use defaults, got view=%d vehicle=%d", m.currentView, m.activeVehicle)
+	}
+}
+
+func TestSetDefaultTempUnit(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "v1", Name: "R1T"}}
+
+	// The configured unit applies when none is remembered
+	m := NewModel(nil, nil, vehicles, -1, nil)
+	m.SetDefaultTempUnit(TempCelsius)
+	if m.tempUnit != TempCelsius || m.dashboardView.tempUnit != TempCelsius {
+		t.Errorf("tempUnit = %v, want the configured Celsius", m.tempUnit)
+	}
+
+	// A unit toggled in an earlier run wins
+	m.SetTempUnit(TempFahrenheit)
+	restored := NewModel(nil, nil, vehicles, -1, m.Preferences())
+	restored.SetDefaultTempUnit(TempCelsius)
+	if restored.tempUnit != TempFahrenheit {
+		t.Errorf("tempUnit = %v, want the remembered Fahrenheit", restored.tempUnit)
+	}
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+// Status symbols accompany every colored indicator so the meaning never
+// depends on color alone.
+const (
+	symbolOK       = "✓"
+	symbolWarning  = "⚠"
+	symbolCritical = "✗"
+	symbolUnknown  = "?"
+)
+
+// DisableColor turns off all terminal colors (e.g. for --no-color or NO_COLOR).
+// Status symbols and text are unaffected.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// rangeSymbol returns the status symbol for a range status.
+func rangeSymbol(status model.RangeStatus) string {
+	switch status {
+	case model.RangeStatusCritical:
+		return symbolCritical
+	case model.RangeStatusLow:
+		return symbolWarning
+	default:
+		return symbolOK
+	}
+}
+
+// scoreSymbol returns the status symbol for a 0-100 ready score.
+func scoreSymbol(score float64) string {
+	switch {
+	case score < 50:
+		return symbolCritical
+	case score < 75:
+		return symbolWarning
+	default:
+		return symbolOK
+	}
+}
+
+// tireSymbol returns the status symbol for a tire pressure status.
+func tireSymbol(status model.TirePressureStatus) string {
+	switch status {
+	case model.TirePressureStatusOK:
+		return symbolOK
+	case model.TirePressureStatusLow, model.TirePressureStatusHigh:
+		return symbolWarning
+	default:
+		return symbolUnknown
+	}
+}
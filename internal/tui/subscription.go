@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+)
+
+// liveSubscription is the part of *rivian.VehicleStateSubscription the
+// subscription loop uses.
+type liveSubscription interface {
+	Updates() <-chan map[string]interface{}
+	Done() <-chan struct{}
+	Errors() <-chan error
+	Close() error
+}
+
+// subscriptionOpener opens a live subscription for the given fields.
+type subscriptionOpener func(ctx context.Context, fields []string) (liveSubscription, error)
+
+// startSubscription registers a live subscription for vehicleID, stopping
+// any previous one, and returns the context its loop must run under, the
+// channel it delivers updates on and the one it reports a server error on.
+func (m *Model) startSubscription(vehicleID string) (context.Context, chan *model.VehicleState, chan error) {
+	m.stopSubscription(vehicleID)
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	updates := make(chan *model.VehicleState, 10)
+	endErr := make(chan error, 1)
+	m.subCancels[vehicleID] = cancel
+	m.updateChans[vehicleID] = updates
+	m.endErrs[vehicleID] = endErr
+	return ctx, updates, endErr
+}
+
+// stopSubscription tears down vehicleID's live subscription. Canceling its
+// context ends the loop, which closes the update channel and WebSocket;
+// the vehicle's map entries are removed so nothing refers to them.
+func (m *Model) stopSubscription(vehicleID string) {
+	if cancel, ok := m.subCancels[vehicleID]; ok {
+		cancel()
+	}
+	delete(m.subCancels, vehicleID)
+	delete(m.updateChans, vehicleID)
+	delete(m.fieldChans, vehicleID)
+	delete(m.endErrs, vehicleID)
+}
+
+// runSubscription forwards updates from a subscription to updates until ctx
+// is canceled or the server ends the subscription. Requests on fieldChan
+// swap to a subscription for other fields. It closes updates on return, so
+// waitForUpdates never blocks on a finished subscription; when the server
+// rejected the subscription, its error is queued on endErr first.
+func (m *Model) runSubscription(ctx context.Context, open subscriptionOpener, vehicleID string, fields []string, fieldChan <-chan []string, updates chan<- *model.VehicleState, endErr chan<- error, reducer *model.Reducer) {
+	defer close(updates)
+
+	subscription, err := open(ctx, fields)
+	if err != nil {
+		// The user can still refresh manually
+		m.logger.Warn("subscribe failed", "vehicle_id", vehicleID, "error", err)
+		return
+	}
+	defer func() {
+		if subscription != nil {
+			_ = subscription.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-subscription.Done():
+			// Server ended the subscription; waitForUpdates reports it,
+			// with the server's error if it sent one
+			select {
+			case err := <-subscription.Errors():
+				endErr <- err
+			default:
+			}
+			return
+
+		case fields := <-fieldChan:
+			// View changed: swap to a subscription for its fields
+			_ = subscription.Close()
+			subscription, err = open(ctx, fields)
+			if err != nil {
+				m.logger.Warn("resubscribe for view fields failed", "vehicle_id", vehicleID, "error", err)
+				subscription = nil
+				return
+			}
+
+		case update := <-subscription.Updates():
+			if update == nil {
+				continue
+			}
+			// Even repeated values show the connection is alive
+			m.liveHeard.Store(time.Now().UnixNano())
+
+			// Apply partial update through reducer
+			event := model.PartialStateUpdate{
+				VehicleID: vehicleID,
+				Updates:   update,
+			}
+			finalState, changed := reducer.DispatchPartial(event)
+			if !changed {
+				// Repeated values: nothing to save or re-render
+				continue
+			}
+
+			if m.store != nil {
+				// Silently fail - not critical
+				_ = m.store.SaveState(ctx, finalState)
+			}
+
+			// Update handles the state from here, on the UI goroutine
+			select {
+			case updates <- finalState:
+			default:
+				// Channel full, skip update
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+// fakeSubscription is a liveSubscription that never sends updates.
+type fakeSubscription struct {
+	updates chan map[string]interface{}
+	done    chan struct{}
+	errs    chan error
+}
+
+func openFakeSubscription(ctx context.Context, fields []string) (liveSubscription, error) {
+	return &fakeSubscription{
+		updates: make(chan map[string]interface{}),
+		done:    make(chan struct{}),
+		errs:    make(chan error, 1),
+	}, nil
+}
+
+func (s *fakeSubscription) Updates() <-chan map[string]interface{} { return s.updates }
+func (s *fakeSubscription) Done() <-chan struct{}                  { return s.done }
+func (s *fakeSubscription) Errors() <-chan error                   { return s.errs }
+func (s *fakeSubscription) Close() error                           { return nil }
+
+// runFakeSubscription starts a subscription loop for the active vehicle
+// and returns its update channel.
+func runFakeSubscription(m *Model) chan *model.VehicleState {
+	vehicleID := m.vehicles[m.activeVehicle].ID
+	ctx, updates, endErr := m.startSubscription(vehicleID)
+	fieldChan := make(chan []string, 1)
+	m.fieldChans[vehicleID] = fieldChan
+	go m.runSubscription(ctx, openFakeSubscription, vehicleID, rivian.VehicleStateFields, fieldChan, updates, endErr, model.NewReducer())
+	return updates
+}
+
+func TestSwitchVehicle_TearsDownSubscription(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1"}, {ID: "2"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	updates := runFakeSubscription(m)
+
+	m.switchVehicle(1)
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("old update channel should be closed, got an update")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("old update channel was not closed")
+	}
+	for name, has := range map[string]bool{
+		"subCancels":  m.subCancels["1"] != nil,
+		"updateChans": m.updateChans["1"] != nil,
+		"fieldChans":  m.fieldChans["1"] != nil,
+		"endErrs":     m.endErrs["1"] != nil,
+	} {
+		if has {
+			t.Errorf("%s still has an entry for the old vehicle", name)
+		}
+	}
+}
+
+func TestSwitchVehicle_NoGoroutineLeak(t *testing.T) {
+	vehicles := []rivian.Vehicle{{ID: "1"}, {ID: "2"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 50; i++ {
+		runFakeSubscription(m)
+		m.switchVehicle(1 - m.activeVehicle)
+	}
+	m.stopSubscription(m.vehicles[m.activeVehicle].ID)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines leaked: %d running, %d before switching", runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(m.subCancels) != 0 || len(m.updateChans) != 0 || len(m.fieldChans) != 0 {
+		t.Errorf("map entries left after teardown: subCancels=%d updateChans=%d fieldChans=%d",
+			len(m.subCancels), len(m.updateChans), len(m.fieldChans))
+	}
+}
+
+func TestRunSubscription_ReportsRejection(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.width = 160
+
+	rejected := &fakeSubscription{
+		updates: make(chan map[string]interface{}),
+		done:    make(chan struct{}),
+		errs:    make(chan error, 1),
+	}
+	rejected.errs <- fmt.Errorf("%w: Unauthorized", rivian.ErrSubscriptionEnded)
+	close(rejected.done)
+	open := func(ctx context.Context, fields []string) (liveSubscription, error) { return rejected, nil }
+
+	ctx, updates, endErr := m.startSubscription("1")
+	go m.runSubscription(ctx, open, "1", rivian.VehicleStateFields, make(chan []string), updates, endErr, model.NewReducer())
+
+	msg, ok := m.waitForUpdates()().(liveEndedMsg)
+	if !ok {
+		t.Fatal("waitForUpdates should report the ended subscription")
+	}
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "Unauthorized") {
+		t.Fatalf("liveEndedMsg.err = %v, want the server's error", msg.err)
+	}
+
+	m.Update(msg)
+	if header := m.renderHeader(); !strings.Contains(header, "Live: rejected by server (polling)") {
+		t.Errorf("renderHeader() = %q, want rejection notice", header)
+	}
+}
@@ -0,0 +1,35 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// lightPalette replaces the colors the views are written with, which suit a
+// dark terminal, with ones that stay readable on a light background. Colors
+// not listed read well on both.
+var lightPalette = map[string]string{
+	"#00ff00": "#008700",
+	"#ffff00": "#af8700",
+	"#ff0000": "#d70000",
+	"#ff8800": "#d75f00",
+	"#00ffff": "#008787",
+	"#888888": "#6c6c6c",
+	"#ffffff": "#000000",
+	"#333333": "#d0d0d0",
+	"#1a1a1a": "#e4e4e4",
+}
+
+// palette maps view colors to the ones drawn; nil draws them unchanged.
+var palette map[string]string
+
+// UseLightTheme switches to colors readable on a light terminal background.
+// Call it before the TUI starts.
+func UseLightTheme() {
+	palette = lightPalette
+}
+
+// themeColor returns the color to draw for hex in the current theme.
+func themeColor(hex string) lipgloss.Color {
+	if c, ok := palette[hex]; ok {
+		return lipgloss.Color(c)
+	}
+	return lipgloss.Color(hex)
+}
@@ -0,0 +1,23 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestThemeColor(t *testing.T) {
+	t.Cleanup(func() { palette = nil })
+
+	if got := themeColor("#00ff00"); got != lipgloss.Color("#00ff00") {
+		t.Errorf("dark theme: themeColor(#00ff00) = %v, want it unchanged", got)
+	}
+
+	UseLightTheme()
+	if got := themeColor("#00ff00"); got != lipgloss.Color("#008700") {
+		t.Errorf("light theme: themeColor(#00ff00) = %v, want #008700", got)
+	}
+	if got := themeColor("#5f5fff"); got != lipgloss.Color("#5f5fff") {
+		t.Errorf("light theme: themeColor(#5f5fff) = %v, want colors readable on both unchanged", got)
+	}
+}
@@ -0,0 +1,41 @@
+package tui
+
+import "fmt"
+
+// TempUnit selects how temperatures are displayed. Vehicle state always
+// stores Fahrenheit; conversion happens only when rendering.
+type TempUnit int
+
+const (
+	TempFahrenheit TempUnit = iota
+	TempCelsius
+)
+
+// Toggle returns the other unit.
+func (u TempUnit) Toggle() TempUnit {
+	if u == TempCelsius {
+		return TempFahrenheit
+	}
+	return TempCelsius
+}
+
+// Convert converts a Fahrenheit value to this unit.
+func (u TempUnit) Convert(fahrenheit float64) float64 {
+	if u == TempCelsius {
+		return (fahrenheit - 32) * 5 / 9
+	}
+	return fahrenheit
+}
+
+// Symbol returns the unit suffix, e.g. "°F".
+func (u TempUnit) Symbol() string {
+	if u == TempCelsius {
+		return "°C"
+	}
+	return "°F"
+}
+
+// Format renders a Fahrenheit value in this unit with one decimal place.
+func (u TempUnit) Format(fahrenheit float64) string {
+	return fmt.Sprintf("%.1f%s", u.Convert(fahrenheit), u.Symbol())
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTempUnit(t *testing.T) {
+	tests := []struct {
+		unit TempUnit
+		in   float64
+		want string
+	}{
+		{TempFahrenheit, 72, "72.0°F"},
+		{TempCelsius, 212, "100.0°C"},
+		{TempCelsius, 32, "0.0°C"},
+		{TempCelsius, -40, "-40.0°C"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.unit.Format(tt.in); got != tt.want {
+			t.Errorf("Format(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if TempFahrenheit.Toggle() != TempCelsius || TempCelsius.Toggle() != TempFahrenheit {
+		t.Error("Toggle() should switch between units")
+	}
+}
+
+func TestToggleTempUnitKey(t *testing.T) {
+	m := NewModel(nil, nil, nil, 0, nil)
+	state := createTestState()
+	cabin := 68.0
+	state.CabinTemp = &cabin
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.tempUnit != TempCelsius {
+		t.Fatalf("tempUnit = %d after 'u', want Celsius", m.tempUnit)
+	}
+	if out := m.dashboardView.Render(context.Background(), state, 120, 40); !strings.Contains(out, "20.0°C") {
+		t.Error("dashboard should show the cabin temperature in °C")
+	}
+	if out := m.healthView.Render(context.Background(), state, 120, 40); !strings.Contains(out, "20.0°C") {
+		t.Error("health view should show the cabin temperature in °C")
+	}
+	if m.Preferences().TempUnit != "celsius" {
+		t.Errorf("preferences TempUnit = %q, want celsius", m.Preferences().TempUnit)
+	}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if out := m.dashboardView.Render(context.Background(), state, 120, 40); !strings.Contains(out, "68.0°F") {
+		t.Error("second 'u' should switch back to °F")
+	}
+}
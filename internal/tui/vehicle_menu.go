@@ -67,24 +67,24 @@ func (m *VehicleMenu) Render(width, height int) string {
 	// Styles
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#5f5fff")).
+		BorderForeground(themeColor("#5f5fff")).
 		Padding(1, 2).
 		Width(width - 20) // Leave margin
 
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
+		Foreground(themeColor("#00ffff")).
 		Bold(true).
 		Align(lipgloss.Center)
 
 	selectedStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00ffff")).
+		Foreground(themeColor("#00ffff")).
 		Bold(true)
 
 	unselectedStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
+		Foreground(themeColor("#888888"))
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#666666")).
+		Foreground(themeColor("#666666")).
 		Align(lipgloss.Center).
 		MarginTop(1)
 
@@ -110,16 +110,20 @@ func (m *VehicleMenu) Render(width, height int) string {
 		line.WriteString(fmt.Sprintf("%d. ", i+1))
 
 		// Vehicle info
-		vehicleInfo := fmt.Sprintf("%s %q", vehicle.Model, vehicle.Name)
-		if vehicle.Model == "" {
+		description := vehicle.Description()
+		vehicleInfo := fmt.Sprintf("%s %q", description, vehicle.Name)
+		if description == "" {
 			vehicleInfo = fmt.Sprintf("%q", vehicle.Name)
 		}
 		if vehicle.Name == "" {
-			vehicleInfo = vehicle.Model
+			vehicleInfo = description
 			if vehicleInfo == "" {
 				vehicleInfo = fmt.Sprintf("VIN: ...%s", vehicle.VIN[len(vehicle.VIN)-6:])
 			}
 		}
+		if vehicle.DriveTrain != "" {
+			vehicleInfo += fmt.Sprintf(" (%s)", vehicle.DriveTrain)
+		}
 
 		// Battery level and status
 		batteryStr := "[--]"
@@ -159,7 +163,7 @@ func (m *VehicleMenu) Render(width, height int) string {
 		lipgloss.Center,
 		menu,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("#1a1a1a")),
+		lipgloss.WithWhitespaceForeground(themeColor("#1a1a1a")),
 	)
 
 	return centered
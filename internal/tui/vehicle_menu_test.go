@@ -139,7 +139,7 @@ func TestVehicleMenu_HandleKey(t *testing.T) {
 
 func TestVehicleMenu_Render(t *testing.T) {
 	vehicles := []rivian.Vehicle{
-		{ID: "1", Name: "Road Trip", Model: "R1T", VIN: "1234567890ABCDEF"},
+		{ID: "1", Name: "Road Trip", Model: "R1T", VIN: "1234567890ABCDEF", Year: 2024, Trim: "Adventure", DriveTrain: "Quad-Motor"},
 		{ID: "2", Name: "Family Hauler", Model: "R1S", VIN: "FEDCBA0987654321"},
 	}
 
@@ -174,7 +174,8 @@ func TestVehicleMenu_Render(t *testing.T) {
 			checkContent: []string{
 				"Select Vehicle",
 				"Road Trip",
-				"R1T",
+				"2024 R1T Adventure",
+				"(Quad-Motor)",
 				"[85%]",
 				"🟢",
 				"Family Hauler",
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+const (
+	// staleLiveAfter is how long live mode may go without an update before
+	// the header flags it and the state is re-fetched over HTTP.
+	staleLiveAfter = 10 * time.Minute
+
+	// watchdogInterval is how often the watchdog checks for staleness.
+	watchdogInterval = 30 * time.Second
+
+	// endedPollInterval is how often the state is fetched over HTTP by
+	// default while live updates are unavailable (see SetPollInterval).
+	endedPollInterval = time.Minute
+
+	// liveRetryInterval is how often live updates are retried while polling.
+	liveRetryInterval = 5 * time.Minute
+
+	// connectAttempts is how many times the WebSocket connection is tried
+	// before falling back to polling.
+	connectAttempts = 3
+)
+
+// connectBackoff is the wait after the first failed WebSocket connection;
+// it doubles after each further failure (a variable so tests can shorten it).
+var connectBackoff = 2 * time.Second
+
+// watchdogMsg is a watchdog tick. gen ties it to one live connection, so
+// ticks from a connection replaced by a vehicle switch are dropped.
+type watchdogMsg struct {
+	gen int
+}
+
+// refreshStateMsg carries a state fetched over HTTP by the watchdog or
+// auto-refresh. It is reduced in Update, not in the fetching goroutine.
+type refreshStateMsg struct {
+	vehicleID string
+	state     *rivian.VehicleState
+	err       error
+}
+
+// startWatchdog begins watching a new live connection.
+func (m *Model) startWatchdog() tea.Cmd {
+	m.watchdogGen++
+	m.liveSince = time.Now()
+	m.liveStale = false
+	m.liveEnded = false
+	return m.watchdogTick(m.watchdogGen)
+}
+
+// stopWatchdog makes any pending ticks obsolete.
+func (m *Model) stopWatchdog() {
+	m.watchdogGen++
+	m.liveStale = false
+	m.liveEnded = false
+}
+
+// SetPollInterval sets how often the state is fetched over HTTP while live
+// updates are unavailable. Zero or negative keeps the default.
+func (m *Model) SetPollInterval(interval time.Duration) {
+	if interval > 0 {
+		m.pollInterval = interval
+	}
+}
+
+// endLive switches to polling after the server ended the live
+// subscription: the running watchdog refreshes over HTTP every
+// pollInterval, starting with the next tick.
+func (m *Model) endLive() {
+	m.liveEnded = true
+	m.liveStale = false
+	m.liveSince = time.Time{}
+	m.liveRetryAt = time.Now().Add(liveRetryInterval)
+}
+
+// startPolling switches to polling when live updates could not be started
+// at all: it refreshes over HTTP now and then every pollInterval, retrying
+// live updates every liveRetryInterval until they connect.
+func (m *Model) startPolling() tea.Cmd {
+	m.watchdogGen++
+	m.endLive()
+	m.liveSince = time.Now()
+	return tea.Batch(m.refreshState(), m.watchdogTick(m.watchdogGen))
+}
+
+// watchdogTick schedules the next check: every watchdogInterval while
+// live, or every pollInterval when that is shorter and polling.
+func (m *Model) watchdogTick(gen int) tea.Cmd {
+	interval := watchdogInterval
+	if m.liveEnded && m.pollInterval < interval {
+		interval = m.pollInterval
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return watchdogMsg{gen: gen}
+	})
+}
+
+// handleWatchdog flags live mode as stale when nothing arrived for
+// staleLiveAfter and falls back to an HTTP refresh, repeated every
+// staleLiveAfter until live updates resume. While polling, it refreshes
+// every pollInterval and retries live updates every liveRetryInterval; a
+// successful reconnect starts a new watchdog, which retires this one.
+func (m *Model) handleWatchdog(msg watchdogMsg, now time.Time) tea.Cmd {
+	if msg.gen != m.watchdogGen {
+		return nil
+	}
+
+	cmds := []tea.Cmd{m.watchdogTick(msg.gen)}
+	threshold := staleLiveAfter
+	if m.liveEnded {
+		threshold = m.pollInterval
+		if !now.Before(m.liveRetryAt) {
+			m.liveRetryAt = now.Add(liveRetryInterval)
+			m.logger.Info("retrying live updates")
+			cmds = append(cmds, m.subscribeToUpdates())
+		}
+	}
+	if heard := time.Unix(0, m.liveHeard.Load()); !m.liveEnded && heard.After(m.liveSince) {
+		// Updates that changed nothing never reach Update
+		m.liveSince = heard
+		m.liveStale = false
+	}
+	if now.Sub(m.liveSince) < threshold {
+		return tea.Batch(cmds...)
+	}
+
+	m.liveStale = !m.liveEnded
+	m.liveSince = now
+	return tea.Batch(append(cmds, m.refreshState())...)
+}
+
+// refreshState fetches the active vehicle's state over HTTP, bypassing
+// the cache that fetchInitialState returns.
+func (m *Model) refreshState() tea.Cmd {
+	if len(m.vehicles) == 0 {
+		return nil
+	}
+	vehicleID := m.vehicles[m.activeVehicle].ID
+
+	return func() tea.Msg {
+		rivState, err := m.client.GetVehicleState(m.ctx, vehicleID)
+		if authExpired(err) {
+			return authExpiredMsg{err: err}
+		}
+		return refreshStateMsg{vehicleID: vehicleID, state: rivState, err: err}
+	}
+}
+
+// applyRefreshedState shows a refreshed state if it is still for the active
+// vehicle. It goes through the vehicle's reducer, which the live
+// subscription shares, so the two merge rather than overwrite each other.
+// Failures are ignored; the next tick retries.
+func (m *Model) applyRefreshedState(msg refreshStateMsg) {
+	if msg.err != nil || msg.state == nil || len(m.vehicles) == 0 {
+		return
+	}
+	vehicle := m.vehicles[m.activeVehicle]
+	if msg.vehicleID != vehicle.ID {
+		return
+	}
+
+	reducer := m.reducers[vehicle.ID]
+	if reducer == nil {
+		reducer = model.NewReducer()
+		reducer.Dispatch(model.VehicleListReceived{Vehicles: []rivian.Vehicle{vehicle}, VehicleID: vehicle.ID})
+		m.reducers[vehicle.ID] = reducer
+	}
+	state := reducer.Dispatch(model.VehicleStateReceived{State: msg.state})
+
+	m.state = state
+	m.offline = false
+	m.vehicleStates[vehicle.ID] = state
+	m.historyCache.Append(state)
+	m.lastUpdate = time.Now()
+	if m.store != nil {
+		_ = m.store.SaveState(m.ctx, state)
+	}
+}
+
+// autoRefreshMsg is an auto-refresh tick.
+type autoRefreshMsg struct{}
+
+// SetAutoRefresh makes the TUI re-fetch the state over HTTP every interval,
+// in addition to live updates. Zero disables it.
+func (m *Model) SetAutoRefresh(interval time.Duration) {
+	m.autoRefresh = interval
+}
+
+func (m *Model) autoRefreshTick() tea.Cmd {
+	if m.autoRefresh <= 0 {
+		return nil
+	}
+	return tea.Tick(m.autoRefresh, func(time.Time) tea.Msg {
+		return autoRefreshMsg{}
+	})
+}
+
+// connectWithRetry calls connect up to connectAttempts times, backing off
+// between attempts, and returns the last error.
+func connectWithRetry(ctx context.Context, connect func(context.Context) error) error {
+	backoff := connectBackoff
+	var err error
+	for attempt := 1; attempt <= connectAttempts; attempt++ {
+		if err = connect(ctx); err == nil {
+			return nil
+		}
+		if attempt == connectAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
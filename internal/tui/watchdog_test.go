@@ -0,0 +1,301 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/rivian-ls/internal/model"
+	"github.com/pfrederiksen/rivian-ls/internal/rivian"
+)
+
+func newWatchdogTestModel(t *testing.T) *Model {
+	t.Helper()
+
+	vehicles := []rivian.Vehicle{{ID: "1", Name: "Road Trip", Model: "R1T"}}
+	m := NewModel(nil, nil, vehicles, 0, nil)
+	m.width = 120
+	m.state = &model.VehicleState{VehicleID: "1", Name: "Road Trip", IsOnline: true}
+	m.liveStatus = liveStatusText(false, nil)
+	return m
+}
+
+func TestWatchdog_FlagsStaleAndRefreshes(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.startWatchdog()
+	gen := m.watchdogGen
+	start := m.liveSince
+
+	// Within the window: just keep ticking
+	if cmd := m.handleWatchdog(watchdogMsg{gen: gen}, start.Add(staleLiveAfter-time.Second)); cmd == nil {
+		t.Fatal("handleWatchdog() should schedule the next tick")
+	}
+	if m.liveStale {
+		t.Error("live mode should not be stale before staleLiveAfter")
+	}
+
+	// Past the window: flag and fall back to HTTP
+	now := start.Add(staleLiveAfter)
+	if cmd := m.handleWatchdog(watchdogMsg{gen: gen}, now); cmd == nil {
+		t.Fatal("handleWatchdog() should schedule a refresh and the next tick")
+	}
+	if !m.liveStale {
+		t.Error("live mode should be stale after staleLiveAfter")
+	}
+	if !m.liveSince.Equal(now) {
+		t.Error("fallback refresh should restart the staleness window")
+	}
+	if header := m.renderHeader(); !strings.Contains(header, "Live: no updates") {
+		t.Errorf("renderHeader() = %q, want stale warning", header)
+	}
+
+	// A live update clears the flag
+	m.Update(stateUpdateMsg{state: &model.VehicleState{VehicleID: "1", IsOnline: true}})
+	if m.liveStale {
+		t.Error("a live update should clear the stale flag")
+	}
+}
+
+func TestWatchdog_IgnoresOldGeneration(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.startWatchdog()
+	old := m.watchdogGen
+
+	// Switching vehicles retires the running watchdog
+	m.stopWatchdog()
+	if cmd := m.handleWatchdog(watchdogMsg{gen: old}, time.Now().Add(2*staleLiveAfter)); cmd != nil {
+		t.Error("handleWatchdog() should drop ticks from a retired watchdog")
+	}
+	if m.liveStale {
+		t.Error("a retired watchdog should not flag staleness")
+	}
+}
+
+func TestWatchdog_UnchangedLiveUpdatesKeepLive(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.startWatchdog()
+	start := m.liveSince
+
+	live := &fakeSubscription{
+		updates: make(chan map[string]interface{}),
+		done:    make(chan struct{}),
+		errs:    make(chan error, 1),
+	}
+	open := func(ctx context.Context, fields []string) (liveSubscription, error) { return live, nil }
+	ctx, updates, endErr := m.startSubscription("1")
+	go m.runSubscription(ctx, open, "1", rivian.VehicleStateFields, make(chan []string), updates, endErr, model.NewReducer())
+
+	update := map[string]interface{}{"batteryLevel": 80.0}
+	live.updates <- update
+	<-updates
+	m.liveHeard.Store(0)
+
+	// The same values again change nothing and never reach Update
+	live.updates <- update
+	m.stopSubscription("1")
+	for range updates {
+	}
+
+	m.handleWatchdog(watchdogMsg{gen: m.watchdogGen}, start.Add(staleLiveAfter))
+	if m.liveStale {
+		t.Error("unchanged live updates should keep live mode from going stale")
+	}
+}
+
+func TestApplyStaleRefresh(t *testing.T) {
+	m := newWatchdogTestModel(t)
+
+	m.applyRefreshedState(refreshStateMsg{vehicleID: "1", err: errors.New("timeout")})
+	if m.state.BatteryLevel != 0 {
+		t.Error("a failed refresh should keep the current state")
+	}
+
+	m.applyRefreshedState(refreshStateMsg{vehicleID: "2", state: &rivian.VehicleState{VehicleID: "2", BatteryLevel: 50}})
+	if m.state.VehicleID != "1" {
+		t.Error("a refresh for another vehicle should be ignored")
+	}
+
+	m.applyRefreshedState(refreshStateMsg{vehicleID: "1", state: &rivian.VehicleState{VehicleID: "1", BatteryLevel: 80}})
+	if m.state.BatteryLevel != 80 {
+		t.Errorf("BatteryLevel = %v, want 80 after refresh", m.state.BatteryLevel)
+	}
+	if m.reducers["1"] == nil || m.reducers["1"].GetState().BatteryLevel != 80 {
+		t.Error("a refresh should go through the vehicle's registered reducer")
+	}
+	if m.state.Name != "Road Trip" {
+		t.Errorf("Name = %q, want the vehicle's identity on a refreshed state", m.state.Name)
+	}
+}
+
+func TestRefreshState_ConcurrentWithLiveUpdates(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	reducer := model.NewReducer()
+	m.reducers["1"] = reducer
+
+	live := &fakeSubscription{
+		updates: make(chan map[string]interface{}),
+		done:    make(chan struct{}),
+		errs:    make(chan error, 1),
+	}
+	open := func(ctx context.Context, fields []string) (liveSubscription, error) { return live, nil }
+	ctx, updates, endErr := m.startSubscription("1")
+	go m.runSubscription(ctx, open, "1", rivian.VehicleStateFields, make(chan []string), updates, endErr, reducer)
+
+	// Partial updates on the subscription goroutine while Update applies
+	// HTTP refreshes through the same reducer; run with -race
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < 100; i++ {
+			live.updates <- map[string]interface{}{"cabinTemp": float64(i)}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		m.Update(refreshStateMsg{vehicleID: "1", state: &rivian.VehicleState{VehicleID: "1", BatteryLevel: 80}})
+	}
+	<-sent
+
+	// The last refresh is kept; a later live update merges onto it
+	live.updates <- map[string]interface{}{"cabinTemp": 200.0}
+	m.stopSubscription("1")
+	for range updates {
+	}
+	if state := reducer.GetState(); state.BatteryLevel != 80 || state.CabinTemp == nil || *state.CabinTemp != 200 {
+		t.Errorf("reducer state = battery %v cabin %v, want both the refresh and the live update", state.BatteryLevel, state.CabinTemp)
+	}
+}
+
+func TestLiveEnded_SwitchesToPolling(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.startWatchdog()
+	gen := m.watchdogGen
+
+	current := make(chan *model.VehicleState)
+	m.updateChans["1"] = current
+
+	// An ending from a replaced subscription is ignored
+	m.Update(liveEndedMsg{updates: make(chan *model.VehicleState)})
+	if m.liveEnded {
+		t.Fatal("ending of a replaced subscription should be ignored")
+	}
+
+	m.Update(liveEndedMsg{updates: current})
+	if !m.liveEnded {
+		t.Fatal("liveEndedMsg should switch to polling")
+	}
+	if header := m.renderHeader(); !strings.Contains(header, "Live ended (polling)") {
+		t.Errorf("renderHeader() = %q, want ended notice", header)
+	}
+
+	// Polls on the next tick, then every endedPollInterval
+	now := time.Now()
+	m.handleWatchdog(watchdogMsg{gen: gen}, now)
+	if !m.liveSince.Equal(now) {
+		t.Error("watchdog should refresh on the first tick after the subscription ended")
+	}
+	m.handleWatchdog(watchdogMsg{gen: gen}, now.Add(endedPollInterval/2))
+	if !m.liveSince.Equal(now) {
+		t.Error("watchdog should wait endedPollInterval between refreshes")
+	}
+	if m.liveStale {
+		t.Error("an ended subscription is reported as ended, not stale")
+	}
+}
+
+func TestLiveFailed_PollsUntilReconnect(t *testing.T) {
+	m := newWatchdogTestModel(t)
+	m.SetPollInterval(20 * time.Second)
+
+	if _, cmd := m.Update(liveFailedMsg{err: errors.New("dial tcp: connection refused")}); cmd == nil {
+		t.Fatal("liveFailedMsg should start polling")
+	}
+	if !m.liveEnded {
+		t.Fatal("a failed connection should switch to polling")
+	}
+	if header := m.renderHeader(); !strings.Contains(header, "Live: network error (polling)") {
+		t.Errorf("renderHeader() = %q, want polling notice", header)
+	}
+
+	// Polls every pollInterval
+	gen := m.watchdogGen
+	start := m.liveSince
+	m.handleWatchdog(watchdogMsg{gen: gen}, start.Add(10*time.Second))
+	if !m.liveSince.Equal(start) {
+		t.Error("watchdog should wait pollInterval between refreshes")
+	}
+	now := start.Add(20 * time.Second)
+	m.handleWatchdog(watchdogMsg{gen: gen}, now)
+	if !m.liveSince.Equal(now) {
+		t.Error("watchdog should refresh once pollInterval has passed")
+	}
+
+	// Retries live updates every liveRetryInterval
+	if _, ok := m.subCancels["1"]; ok {
+		t.Fatal("no live retry expected yet")
+	}
+	m.handleWatchdog(watchdogMsg{gen: gen}, start.Add(liveRetryInterval))
+	if _, ok := m.subCancels["1"]; !ok {
+		t.Error("watchdog should retry live updates after liveRetryInterval")
+	}
+
+	// A successful reconnect retires the poller
+	m.Update(wsConnectedMsg{})
+	if m.liveEnded {
+		t.Error("reconnecting should stop polling")
+	}
+	if cmd := m.handleWatchdog(watchdogMsg{gen: gen}, now.Add(time.Hour)); cmd != nil {
+		t.Error("the poller's ticks should be ignored after reconnecting")
+	}
+	if header := m.renderHeader(); strings.Contains(header, "polling") {
+		t.Errorf("renderHeader() = %q, want live status only", header)
+	}
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	defer func(d time.Duration) { connectBackoff = d }(connectBackoff)
+	connectBackoff = time.Millisecond
+
+	calls := 0
+	err := connectWithRetry(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("handshake failed")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("connectWithRetry() = %v after %d calls, want success on the 2nd", err, calls)
+	}
+
+	calls = 0
+	err = connectWithRetry(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("handshake failed")
+	})
+	if err == nil || calls != connectAttempts {
+		t.Errorf("connectWithRetry() = %v after %d calls, want an error after %d", err, calls, connectAttempts)
+	}
+}
+
+func TestAutoRefresh(t *testing.T) {
+	m := newWatchdogTestModel(t)
+
+	if m.autoRefreshTick() != nil {
+		t.Error("auto-refresh should be off by default")
+	}
+
+	m.SetAutoRefresh(5 * time.Minute)
+	if m.autoRefreshTick() == nil {
+		t.Fatal("autoRefreshTick() should schedule a tick when enabled")
+	}
+	if _, cmd := m.Update(autoRefreshMsg{}); cmd == nil {
+		t.Error("autoRefreshMsg should trigger a refresh and the next tick")
+	}
+
+	m.SetAutoRefresh(0)
+	if m.autoRefreshTick() != nil {
+		t.Error("auto-refresh should stop when set to 0")
+	}
+}